@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"sdk-microservices/internal/platform/httpmw"
+)
+
+// Config is gatewayd's configuration, loaded from GATEWAY_* env vars (see
+// config.Load for the file and flag layers).
+type Config struct {
+	HTTPAddr      string `env:"HTTP_ADDR" default:":8080"`
+	HelloEndpoint string `env:"HELLO_GRPC_ADDR,noprefix" default:"localhost:50051"`
+	AuthEndpoint  string `env:"AUTH_GRPC_ADDR,noprefix" default:"localhost:50052"`
+
+	// LogLevel (log_level in the config file) follows config reloads.
+	LogLevel string `env:"LOG_LEVEL,noprefix" oneof:"debug info warn error"`
+
+	// Downstream calls get DeadlineFraction of the remaining request deadline,
+	// minus DeadlineOverhead.
+	DeadlineFraction    float64       `env:"DEADLINE_FRACTION" default:"0.9"`
+	DeadlineOverhead    time.Duration `env:"DEADLINE_OVERHEAD" default:"5ms"`
+	GRPCMaxRecvMsgBytes int           `env:"GRPC_MAX_RECV_MSG_BYTES"`
+	GRPCMaxSendMsgBytes int           `env:"GRPC_MAX_SEND_MSG_BYTES"`
+	GRPCLBPolicy        string        `env:"GRPC_LB_POLICY" default:"round_robin"`
+
+	// A soft downstream only degrades readiness when it is down.
+	HelloReadySoft bool `env:"HELLO_READY_SOFT"`
+	AuthReadySoft  bool `env:"AUTH_READY_SOFT"`
+
+	// RateLimitBurst defaults to 2*RateLimitRPS (at least 1). The rate and
+	// burst follow config reloads; the mode needs a restart.
+	RateLimitMode  string  `env:"RATELIMIT_MODE" default:"token" oneof:"token sliding"`
+	RateLimitRPS   float64 `env:"RATELIMIT_RPS" default:"200"`
+	RateLimitBurst int     `env:"RATELIMIT_BURST"`
+
+	DenyListFile   string        `env:"DENYLIST_FILE"`
+	DenyListReload time.Duration `env:"DENYLIST_RELOAD" default:"5s"`
+
+	TrustedProxies     []string      `env:"TRUSTED_PROXIES"`
+	Timeout            time.Duration `env:"TIMEOUT" default:"30s"`
+	MaxInFlight        int           `env:"MAX_INFLIGHT" default:"512"`
+	MaxQueue           int           `env:"MAX_QUEUE"`
+	QueueWait          time.Duration `env:"QUEUE_WAIT" default:"100ms"`
+	MaxBodyBytes       int64         `env:"MAX_BODY_BYTES" default:"1048576"`
+	RequestIDFromTrace bool          `env:"REQUEST_ID_FROM_TRACE"`
+	HealthLogSample    float64       `env:"HEALTH_LOG_SAMPLE" default:"0.01"`
+	Gzip               bool          `env:"GZIP" default:"true"`
+	CoalesceGets       bool          `env:"COALESCE_GETS"`
+	CORSOrigins        []string      `env:"CORS_ORIGINS"`
+
+	// CORSAllowCredentials lets browsers send cookies to CORSOrigins; it
+	// cannot be combined with the "*" origin.
+	CORSAllowCredentials bool `env:"CORS_ALLOW_CREDENTIALS"`
+
+	// Security headers; zero values keep httpmw.DefaultSecurityPolicy.
+	HSTSMaxAge          time.Duration `env:"HSTS_MAX_AGE"`
+	TrustForwardedProto bool          `env:"TRUST_FORWARDED_PROTO"`
+	CSP                 string        `env:"CSP"`
+
+	// LogRedactKeys are header names / log field keys redacted on top of
+	// logging.DefaultSensitiveKeys.
+	LogRedactKeys []string `env:"LOG_REDACT_KEYS"`
+}
+
+func (c *Config) Validate() error {
+	// Zero would block every client after one request (token) or admit all of
+	// them (sliding); NaN and Inf are no better.
+	if !(c.RateLimitRPS > 0) || math.IsInf(c.RateLimitRPS, 1) {
+		return fmt.Errorf("GATEWAY_RATELIMIT_RPS must be a positive number, got %v", c.RateLimitRPS)
+	}
+	if err := httpmw.CheckCORSOrigins(c.CORSOrigins, c.CORSAllowCredentials); err != nil {
+		return fmt.Errorf("GATEWAY_CORS_ORIGINS with GATEWAY_CORS_ALLOW_CREDENTIALS: %w", err)
+	}
+	return nil
+}
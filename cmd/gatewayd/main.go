@@ -2,109 +2,228 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
 	"os"
-	"strconv"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	authv1 "sdk-microservices/gen/api/proto/auth/v1"
 	hellov1 "sdk-microservices/gen/api/proto/hello/v1"
 	"sdk-microservices/internal/platform/authctx"
 	"sdk-microservices/internal/platform/boot"
+	"sdk-microservices/internal/platform/config"
+	"sdk-microservices/internal/platform/grpcutil"
+	"sdk-microservices/internal/platform/health"
 	"sdk-microservices/internal/platform/httpmw"
+	"sdk-microservices/internal/platform/logging"
+	"sdk-microservices/internal/platform/metrics"
+	"sdk-microservices/internal/platform/otel"
+	"sdk-microservices/internal/platform/shutdown"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 )
 
 func main() {
-	_ = boot.Run(context.Background(), boot.Options{
+	cli := boot.MustParseFlags("gatewayd")
+	conf, err := boot.LoadConfig[Config](config.LoadOptions{EnvPrefix: "GATEWAY_", File: cli.ConfigFileOr("GATEWAY_CONFIG_FILE"), Args: cli.Args})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gatewayd:", err)
+		os.Exit(2)
+	}
+	cfg := conf.Current()
+
+	if err := boot.Run(context.Background(), boot.Options{
 		ServiceName:     "gateway",
+		EnvPrefix:       "GATEWAY",
 		AdminAddrEnv:    "GATEWAY_ADMIN_ADDR",
 		ShutdownTimeout: 10 * time.Second,
+		Config:          conf,
+		Flags:           cli,
+		Routes: append([]string{"GET /healthz"}, boot.HTTPRoutes(
+			hellov1.File_api_proto_hello_v1_hello_proto.Services().ByName("HelloService"),
+			authv1.File_api_proto_auth_v1_auth_proto.Services().ByName("AuthService"),
+		)...),
+		// Probes are noise in traces; auth flows are rare and worth keeping in full.
+		TraceSamplingRules: []otel.SamplingRule{
+			{Pattern: "/healthz", Ratio: 0},
+			{Pattern: "/readyz", Ratio: 0},
+			{Pattern: "/v1/auth/", Ratio: 1},
+		},
 	}, func(ctx context.Context, deps boot.Deps) (boot.Main, error) {
 		log := deps.Log
+		// The log level, rate limits and edge policy follow config reloads
+		// (SIGHUP); addresses, endpoints and the deny list file need a restart.
+		if err := logging.SetLevel(deps.LogLevel, cfg.LogLevel); err != nil {
+			return boot.Main{}, err
+		}
+		conf.OnChange(func(next *Config, changed config.Report) {
+			if changed.Has("LOG_LEVEL") {
+				if err := logging.SetLevel(deps.LogLevel, next.LogLevel); err != nil {
+					log.Warn("log level not changed", zap.Error(err))
+				}
+			}
+		})
 
-		httpAddr := env("GATEWAY_HTTP_ADDR", ":8080")
-		helloEndpoint := env("HELLO_GRPC_ADDR", "localhost:50051")
-		authEndpoint := env("AUTH_GRPC_ADDR", "localhost:50052")
+		httpAddr := cfg.HTTPAddr
+		helloEndpoint := cfg.HelloEndpoint
+		authEndpoint := cfg.AuthEndpoint
 
-		helloConn, err := grpc.DialContext(ctx, helloEndpoint,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			grpc.WithBlock(),
-		)
+		// Never let a downstream call outlive the incoming request deadline.
+		budget := grpcutil.DeadlineBudget{
+			Fraction: cfg.DeadlineFraction,
+			Overhead: &cfg.DeadlineOverhead,
+		}
+
+		clientOpts := grpcutil.ClientOptions{
+			Block:          true,
+			Budget:         &budget,
+			MaxRecvMsgSize: cfg.GRPCMaxRecvMsgBytes,
+			MaxSendMsgSize: cfg.GRPCMaxSendMsgBytes,
+			LoadBalancing:  cfg.GRPCLBPolicy,
+		}
+
+		if cm, err := metrics.NewGRPCClientMetrics("gateway"); err == nil {
+			clientOpts.Metrics = cm
+		} else {
+			log.Warn("grpc client metrics disabled (init failed)", zap.Error(err))
+		}
+
+		helloOpts := clientOpts
+		helloOpts.TargetName = "hello"
+		helloConn, err := grpcutil.Dial(ctx, helloEndpoint, helloOpts)
 		if err != nil {
 			return boot.Main{}, err
 		}
+		// Downstream connections close once the HTTP server has drained.
+		deps.Shutdown.Register(shutdown.Close, "hello client", func(context.Context) error { return helloConn.Close() })
 
-		authConn, err := grpc.DialContext(ctx, authEndpoint,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			grpc.WithBlock(),
-		)
+		authOpts := clientOpts
+		authOpts.TargetName = "auth"
+		authConn, err := grpcutil.Dial(ctx, authEndpoint, authOpts)
 		if err != nil {
-			_ = helloConn.Close()
 			return boot.Main{}, err
 		}
+		deps.Shutdown.Register(shutdown.Close, "auth client", func(context.Context) error { return authConn.Close() })
+
+		// Readiness follows the downstreams' gRPC health. A downstream marked soft
+		// (GATEWAY_<NAME>_READY_SOFT=true) only degrades readiness when it is down.
+		for _, ds := range []struct {
+			name, service string
+			conn          *grpc.ClientConn
+			soft          bool
+		}{
+			{"hello", "hello.v1.HelloService", helloConn, cfg.HelloReadySoft},
+			{"auth", "auth.v1.AuthService", authConn, cfg.AuthReadySoft},
+		} {
+			n := deps.ReadyRoot.Add(ds.name, health.GRPCHealthCheck(ds.conn, ds.service))
+			if ds.soft {
+				n.Criticality = health.Soft
+			}
+		}
 
 		mux := runtime.NewServeMux(
+			// Label HTTP metrics with the matched proto route template, never the raw path.
+			runtime.WithMiddlewares(func(next runtime.HandlerFunc) runtime.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+					if pat, ok := runtime.HTTPPattern(r.Context()); ok {
+						metrics.SetRoute(r.Context(), routeTemplate(pat.String()))
+					}
+					next(w, r, params)
+				}
+			}),
+			runtime.WithIncomingHeaderMatcher(incomingHeader),
 			runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
 				md := metadata.MD{}
 				if rid := r.Header.Get("x-request-id"); rid != "" {
-					md.Append("x-request-id", rid)
+					md.Set("x-request-id", rid)
 				}
 				if auth := r.Header.Get("authorization"); auth != "" {
-					md.Append("authorization", auth)
+					md.Set("authorization", auth)
+				}
+				if ip, ok := httpmw.ClientIP(r.Context()); ok {
+					md.Set(grpcutil.ClientIPHeader, ip)
+				}
+				if key := r.Header.Get("idempotency-key"); key != "" {
+					md.Set(grpcutil.IdempotencyKeyHeader, key)
 				}
 				return md
 			}),
 		)
 
 		if err := hellov1.RegisterHelloServiceHandlerClient(ctx, mux, hellov1.NewHelloServiceClient(helloConn)); err != nil {
-			_ = helloConn.Close()
-			_ = authConn.Close()
 			return boot.Main{}, err
 		}
 		if err := authv1.RegisterAuthServiceHandlerClient(ctx, mux, authv1.NewAuthServiceClient(authConn)); err != nil {
-			_ = helloConn.Close()
-			_ = authConn.Close()
 			return boot.Main{}, err
 		}
 
 		root := http.NewServeMux()
 		root.Handle("/", mux)
-		root.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		root.Handle("/healthz", metrics.RouteHandler("/healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte("ok"))
-		})
+		})))
 
-		// Default 200 rps / ip, burst 400.
-		rl := httpmw.NewIPLimiter(
-			rate.Limit(envFloat("GATEWAY_RATELIMIT_RPS", 200)),
-			envInt("GATEWAY_RATELIMIT_BURST", 400),
-			2*time.Minute,
-		)
+		rl, err := newRateLimiter(cfg)
+		if err != nil {
+			return boot.Main{}, fmt.Errorf("GATEWAY_RATELIMIT_RPS: %w", err)
+		}
 
-		edge := httpmw.EdgePolicy{
-			ServiceName: "gateway",
-			Timeout:     envDuration("GATEWAY_TIMEOUT", 30*time.Second),
-			MaxInFlight: envInt("GATEWAY_MAX_INFLIGHT", 512),
-			Leaf: httpmw.Chain{
-				rl.Wrap,
-				func(next http.Handler) http.Handler {
-					return authctx.GatewayAuth("/v1/auth/", next)
-				},
-			},
+		// Runtime deny list: PUT /denylist on the admin port (admin token
+		// required), or a watched JSON file.
+		deny := httpmw.NewDenyList(log)
+		if err := deps.Admin.HandleProtected("/denylist", deny.Handler()); err != nil {
+			return boot.Main{}, err
+		}
+		if path := cfg.DenyListFile; path != "" {
+			if err := deny.WatchFile(ctx, path, cfg.DenyListReload); err != nil {
+				return boot.Main{}, fmt.Errorf("GATEWAY_DENYLIST_FILE: %w", err)
+			}
 		}
 
-		h := httpmw.BuildEdgeHandler(log, edge, root)
+		ed := edgeDeps{log: log, root: root, deny: deny, limiter: rl}
+		if hm, err := metrics.NewHTTPServerMetrics("gateway"); err == nil {
+			ed.metrics = hm.Middleware
+		} else {
+			log.Warn("http metrics disabled (init failed)", zap.Error(err))
+		}
+		h, err := newEdgeHandler(cfg, ed)
+		if err != nil {
+			return boot.Main{}, err
+		}
+		// A reload rebuilds the edge handler around the same limiter, deny list
+		// and metrics, so per-client state survives it; a config that fails to
+		// build leaves the previous handler serving.
+		var edgeH atomic.Pointer[http.Handler]
+		edgeH.Store(&h)
+		conf.OnChange(func(next *Config, changed config.Report) {
+			if changed.Has("GATEWAY_RATELIMIT_MODE") {
+				log.Warn("GATEWAY_RATELIMIT_MODE change needs a restart")
+			}
+			if changed.Has("GATEWAY_RATELIMIT_RPS") || changed.Has("GATEWAY_RATELIMIT_BURST") {
+				if err := setRateLimit(rl, next); err != nil {
+					log.Warn("rate limit not changed", zap.Error(err))
+				}
+			}
+			h, err := newEdgeHandler(next, ed)
+			if err != nil {
+				log.Warn("edge policy not reloaded", zap.Error(err))
+				return
+			}
+			edgeH.Store(&h)
+		})
 
 		srv := &http.Server{
 			Addr:              httpAddr,
-			Handler:           h,
+			Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { (*edgeH.Load()).ServeHTTP(w, r) }),
 			ReadHeaderTimeout: 5 * time.Second,
 			ReadTimeout:       30 * time.Second,
 			WriteTimeout:      30 * time.Second,
@@ -120,55 +239,199 @@ func main() {
 				)
 				return srv.ListenAndServe()
 			},
-			Shutdown: func(ctx context.Context) error {
-				_ = helloConn.Close()
-				_ = authConn.Close()
-				return srv.Shutdown(ctx)
-			},
+			Shutdown: srv.Shutdown,
 		}, nil
-	})
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "gatewayd:", err)
+		os.Exit(1)
+	}
 }
 
-func env(k, d string) string {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
-	}
-	return v
+// routeTemplate turns a grpc-gateway pattern ("/v1/hello/{name=*}") into the
+// route template from the proto annotation ("/v1/hello/{name}").
+func routeTemplate(pat string) string {
+	return strings.ReplaceAll(pat, "=*}", "}")
+}
+
+// trustedMetadata are set by the gateway itself (or derived downstream from a
+// verified token); clients must not send them as Grpc-Metadata-* headers.
+var trustedMetadata = map[string]bool{
+	grpcutil.UserIDHeader:   true,
+	grpcutil.TenantIDHeader: true,
+	grpcutil.ClientIPHeader: true,
 }
 
-func envInt(k string, d int) int {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
+// incomingHeader is grpc-gateway's default header matcher minus the identity,
+// tenant and client-IP metadata, which would otherwise reach the services
+// ahead of the gateway's own values.
+func incomingHeader(key string) (string, bool) {
+	name, ok := runtime.DefaultHeaderMatcher(key)
+	if !ok || trustedMetadata[strings.ToLower(name)] {
+		return "", false
 	}
-	i, err := strconv.Atoi(v)
+	return name, true
+}
+
+// edgeDeps are the parts of the edge handler that outlive a config reload.
+type edgeDeps struct {
+	log     *zap.Logger
+	root    http.Handler
+	deny    *httpmw.DenyList
+	limiter rateLimiter
+	metrics httpmw.Middleware // nil when HTTP metrics are disabled
+}
+
+// newEdgeHandler builds the edge policy from cfg around d.root.
+func newEdgeHandler(cfg *Config, d edgeDeps) (http.Handler, error) {
+	trusted, err := httpmw.ParseCIDRs(cfg.TrustedProxies...)
 	if err != nil {
-		return d
+		return nil, fmt.Errorf("GATEWAY_TRUSTED_PROXIES: %w", err)
+	}
+
+	jsonOnly := httpmw.WithContentNegotiation(httpmw.ContentPolicy{})
+
+	edge := httpmw.EdgePolicy{
+		ServiceName:    "gateway",
+		Timeout:        cfg.Timeout,
+		MaxInFlight:    cfg.MaxInFlight,
+		MaxQueue:       cfg.MaxQueue,
+		MaxQueueWait:   cfg.QueueWait,
+		MaxBodyBytes:   cfg.MaxBodyBytes,
+		TrustedProxies: trusted,
+		RequestID:      httpmw.RequestIDOptions{FromTrace: cfg.RequestIDFromTrace},
+		// Probes hit these constantly; keep only a sample of their successful access logs.
+		LogSampling: httpmw.LogSampling{
+			Rules: []httpmw.SampleRule{
+				{Pattern: "/healthz", Rate: cfg.HealthLogSample},
+				{Pattern: "/readyz", Rate: cfg.HealthLogSample},
+			},
+		},
+		Leaf: httpmw.Chain{
+			d.deny.Wrap,
+			// Token responses must never be cached; everything else is no-store by default too.
+			httpmw.WithCacheControl(httpmw.CachePolicy{
+				Rules: []httpmw.CacheRule{{Prefix: "/v1/auth/", Value: httpmw.CacheNoStore}},
+			}),
+			d.limiter.Wrap,
+			httpmw.Baggage,
+		},
+		// Register/login and health endpoints are public; everything else needs Authorization.
+		// API routes speak JSON only.
+		Routes: httpmw.Routes{
+			Routes: []httpmw.Route{
+				{Pattern: "/v1/auth/", Chain: httpmw.Chain{jsonOnly}},
+				{Pattern: "/healthz"},
+				{Pattern: "/readyz"},
+			},
+			Default: httpmw.Chain{authctx.RequireAuthorization, jsonOnly},
+		},
 	}
-	return i
+
+	if d.metrics != nil {
+		// Outermost, so shed/rejected requests are measured too.
+		edge.Outer = append(edge.Outer, d.metrics)
+	}
+	if cfg.Gzip {
+		// Outside the timeout handler, which buffers the whole response.
+		edge.Outer = append(edge.Outer, httpmw.WithCompress(httpmw.CompressOptions{}))
+	}
+	if cfg.CoalesceGets {
+		// Merge identical concurrent GETs (per caller) into one upstream call.
+		edge.Leaf = append(edge.Leaf, httpmw.Coalesce(httpmw.CoalesceOptions{}))
+	}
+	if len(cfg.CORSOrigins) > 0 {
+		edge.CORS = &httpmw.CORSOptions{
+			AllowOrigin:      httpmw.MatchOrigins(cfg.CORSOrigins...),
+			AllowCredentials: cfg.CORSAllowCredentials,
+		}
+	}
+
+	sec := httpmw.DefaultSecurityPolicy()
+	if cfg.HSTSMaxAge != 0 {
+		sec.HSTSMaxAge = cfg.HSTSMaxAge
+	}
+	sec.TrustForwardedProto = cfg.TrustForwardedProto
+	if cfg.CSP != "" {
+		sec.ContentSecurityPolicy = cfg.CSP
+	}
+	edge.Security = &sec
+
+	if len(cfg.LogRedactKeys) > 0 {
+		// Extra header names / log field keys to redact on top of the defaults.
+		edge.Redactor = logging.NewRedactor(slices.Concat(logging.DefaultSensitiveKeys, cfg.LogRedactKeys)...)
+	}
+
+	return httpmw.BuildEdgeHandler(d.log, edge, d.root), nil
+}
+
+// rateLimiter is the edge limiter: a token bucket or a sliding window.
+type rateLimiter interface {
+	Wrap(http.Handler) http.Handler
 }
 
-func envFloat(k string, d float64) float64 {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
+// newRateLimiter returns the edge rate limiter: default 200 rps per client IP,
+// burst 400. GATEWAY_RATELIMIT_MODE=sliding admits the burst per burst/rps
+// window instead (the same average rate, without boundary bursts). It is keyed
+// by the client IP as resolved by RealIP (GATEWAY_TRUSTED_PROXIES) only: no
+// user is known yet at the edge and X-API-Key is not verified here, so keying
+// by it would hand a client a fresh bucket for every random value it sends.
+func newRateLimiter(cfg *Config) (rateLimiter, error) {
+	r, burst := rateLimit(cfg)
+	if cfg.RateLimitMode == "sliding" {
+		window, err := slidingWindow(r, burst)
+		if err != nil {
+			return nil, err
+		}
+		return httpmw.NewKeyedSlidingWindowLimiter(
+			httpmw.KeyByIP,
+			burst,
+			window,
+			max(2*time.Minute, 2*window),
+		).Instrument("gateway"), nil
 	}
-	f, err := strconv.ParseFloat(v, 64)
-	if err != nil {
-		return d
+	return httpmw.NewKeyedLimiter(
+		httpmw.KeyByIP,
+		r,
+		burst,
+		2*time.Minute,
+	).Instrument("gateway"), nil
+}
+
+// rateLimit returns the per-IP rate and burst; the burst defaults to twice
+// the rate, and at least 1 so rates below 1 rps still admit requests.
+func rateLimit(cfg *Config) (rate.Limit, int) {
+	burst := cfg.RateLimitBurst
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(cfg.RateLimitRPS*2)))
 	}
-	return f
+	return rate.Limit(cfg.RateLimitRPS), burst
 }
 
-func envDuration(k string, d time.Duration) time.Duration {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
+// slidingWindow is the window in which the sliding limiter admits burst
+// requests, so that it averages r. A rate that is not positive and finite has
+// no such window: the limiter would reset its counts on every request.
+func slidingWindow(r rate.Limit, burst int) (time.Duration, error) {
+	window := time.Duration(float64(burst) / float64(r) * float64(time.Second))
+	if !(r > 0) || math.IsInf(float64(r), 1) || window <= 0 {
+		return 0, fmt.Errorf("no sliding window admits %d requests at %v rps", burst, float64(r))
 	}
-	dur, err := time.ParseDuration(v)
+	return window, nil
+}
+
+// setRateLimit applies cfg's rate and burst to rl, keeping its mode. A rate
+// rl cannot enforce leaves the current limit in place.
+func setRateLimit(rl rateLimiter, cfg *Config) error {
+	r, burst := rateLimit(cfg)
+	// slidingWindow rejects the rates neither mode can enforce.
+	window, err := slidingWindow(r, burst)
 	if err != nil {
-		return d
+		return err
+	}
+	switch l := rl.(type) {
+	case *httpmw.KeyedLimiter:
+		l.SetLimit(r, burst)
+	case *httpmw.SlidingWindowLimiter:
+		l.SetLimit(burst, window)
 	}
-	return dur
+	return nil
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func mustRateLimiter(t *testing.T, cfg *Config) rateLimiter {
+	t.Helper()
+	rl, err := newRateLimiter(cfg)
+	if err != nil {
+		t.Fatalf("newRateLimiter: %v", err)
+	}
+	return rl
+}
+
+func TestRateLimiterIgnoresAPIKey(t *testing.T) {
+	for _, mode := range []string{"token", "sliding"} {
+		t.Run(mode, func(t *testing.T) {
+			rl := mustRateLimiter(t, &Config{RateLimitMode: mode, RateLimitRPS: 2, RateLimitBurst: 2})
+			h := rl.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			limited := 0
+			for i := range 10 {
+				req := httptest.NewRequest(http.MethodGet, "/v1/hello/x", nil)
+				req.RemoteAddr = "203.0.113.7:4321"
+				// A fresh, unverified key on every request must not buy a fresh bucket.
+				req.Header.Set("X-API-Key", "key-"+strconv.Itoa(i))
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if rec.Code == http.StatusTooManyRequests {
+					limited++
+				}
+			}
+			if limited == 0 {
+				t.Fatal("rotating X-API-Key values bypassed the per-IP limit")
+			}
+		})
+	}
+}
+
+func TestIncomingHeaderDropsTrustedMetadata(t *testing.T) {
+	for _, h := range []string{"Grpc-Metadata-X-Client-Ip", "Grpc-Metadata-X-User-Id", "grpc-metadata-x-tenant-id"} {
+		if name, ok := incomingHeader(h); ok {
+			t.Errorf("%s forwarded as %q", h, name)
+		}
+	}
+	if name, ok := incomingHeader("Grpc-Metadata-X-Trace-Tag"); !ok || name != "X-Trace-Tag" {
+		t.Errorf("Grpc-Metadata-X-Trace-Tag = %q, %v; want X-Trace-Tag forwarded", name, ok)
+	}
+	if _, ok := incomingHeader("Authorization"); !ok {
+		t.Error("Authorization not forwarded")
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cfg     Config
+		allowed int
+	}{
+		{"token below 1 rps", Config{RateLimitMode: "token", RateLimitRPS: 0.2}, 1},
+		{"sliding below 1 rps", Config{RateLimitMode: "sliding", RateLimitRPS: 0.2}, 1},
+		{"token burst", Config{RateLimitMode: "token", RateLimitRPS: 1, RateLimitBurst: 5}, 5},
+		{"sliding burst", Config{RateLimitMode: "sliding", RateLimitRPS: 1, RateLimitBurst: 5}, 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := mustRateLimiter(t, &tc.cfg).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			allowed := 0
+			for range 10 {
+				req := httptest.NewRequest(http.MethodGet, "/v1/hello/x", nil)
+				req.RemoteAddr = "203.0.113.7:4321"
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if rec.Code != http.StatusTooManyRequests {
+					allowed++
+				}
+			}
+			if allowed != tc.allowed {
+				t.Fatalf("allowed %d of 10, want %d", allowed, tc.allowed)
+			}
+		})
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	for _, mode := range []string{"token", "sliding"} {
+		t.Run(mode, func(t *testing.T) {
+			rl := mustRateLimiter(t, &Config{RateLimitMode: mode, RateLimitRPS: 1, RateLimitBurst: 1})
+			h := rl.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			serve := func() *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/v1/hello/x", nil)
+				req.RemoteAddr = "203.0.113.7:4321"
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				return rec
+			}
+			if got := serve().Header().Get("RateLimit-Limit"); got != "1" {
+				t.Fatalf("RateLimit-Limit = %q, want 1", got)
+			}
+
+			// A reload changes the limit for the client already tracked.
+			if err := setRateLimit(rl, &Config{RateLimitMode: mode, RateLimitRPS: 1, RateLimitBurst: 5}); err != nil {
+				t.Fatal(err)
+			}
+			if got := serve().Header().Get("RateLimit-Limit"); got != "5" {
+				t.Fatalf("RateLimit-Limit after reload = %q, want 5", got)
+			}
+
+			// An invalid rate keeps the current limit rather than turning
+			// limiting off (sliding) or blocking everyone (token).
+			if err := setRateLimit(rl, &Config{RateLimitMode: mode, RateLimitRPS: 0, RateLimitBurst: 7}); err == nil {
+				t.Fatal("setRateLimit accepted 0 rps")
+			}
+			if got := serve().Header().Get("RateLimit-Limit"); got != "5" {
+				t.Fatalf("RateLimit-Limit after invalid reload = %q, want 5", got)
+			}
+		})
+	}
+}
+
+func TestConfigRejectsWildcardCORSWithCredentials(t *testing.T) {
+	cfg := Config{RateLimitRPS: 200, CORSOrigins: []string{"https://app.example.com", "*"}, CORSAllowCredentials: true}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal(`Validate accepted "*" with credentials`)
+	}
+	cfg.CORSAllowCredentials = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestConfigRejectsNonPositiveRateLimit(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		cfg := Config{RateLimitRPS: rps}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate accepted GATEWAY_RATELIMIT_RPS=%v", rps)
+		}
+	}
+}
+
+func TestSlidingWindowRejectsNonPositiveRate(t *testing.T) {
+	for _, r := range []rate.Limit{0, -1, rate.Inf} {
+		if w, err := slidingWindow(r, 2); err == nil {
+			t.Errorf("slidingWindow(%v, 2) = %v, want error", r, w)
+		}
+		if _, err := newRateLimiter(&Config{RateLimitMode: "sliding", RateLimitRPS: float64(r), RateLimitBurst: 2}); err == nil {
+			t.Errorf("newRateLimiter accepted a sliding window at %v rps", r)
+		}
+	}
+	if w, err := slidingWindow(2, 4); err != nil || w != 2*time.Second {
+		t.Fatalf("slidingWindow(2, 4) = %v, %v, want 2s", w, err)
+	}
+}
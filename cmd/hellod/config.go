@@ -0,0 +1,34 @@
+package main
+
+import "time"
+
+// Config is hellod's configuration, loaded from HELLO_* env vars (see
+// config.Load for the file and flag layers).
+type Config struct {
+	Addr string `env:"ADDR" default:":50051"`
+	// LogLevel (log_level in the config file) follows config reloads, as do the
+	// rate limits.
+	LogLevel string `env:"LOG_LEVEL,noprefix" oneof:"debug info warn error"`
+
+	// Bearer tokens are verified and required when JWTSecret is set.
+	JWTSecret string `env:"JWT_SECRET,secret"`
+	JWTIssuer string `env:"JWT_ISSUER" default:"sdk-microservices"`
+
+	RPCTimeout             time.Duration `env:"RPC_TIMEOUT" default:"10s"`
+	MaxInFlight            int           `env:"MAX_INFLIGHT" default:"256"`
+	MaxQueue               int           `env:"MAX_QUEUE"`
+	QueueWait              time.Duration `env:"QUEUE_WAIT" default:"100ms"`
+	MaxRecvMsgBytes        int           `env:"MAX_RECV_MSG_BYTES"`
+	MaxSendMsgBytes        int           `env:"MAX_SEND_MSG_BYTES"`
+	SlowRPCThreshold       time.Duration `env:"SLOW_RPC_THRESHOLD" default:"1s"`
+	TrustForwardedIdentity bool          `env:"TRUST_FORWARDED_IDENTITY"`
+	XDS                    bool          `env:"XDS"`
+	PreStopDelay           time.Duration `env:"PRESTOP_DELAY"`
+
+	// Per-caller rate limiting is off while RateLimitRPS is 0. RateLimitBurst
+	// defaults to 2*RPS (at least 1); RateLimitRedisAddr shares the buckets
+	// across replicas.
+	RateLimitRPS       float64 `env:"RATELIMIT_RPS"`
+	RateLimitBurst     int     `env:"RATELIMIT_BURST"`
+	RateLimitRedisAddr string  `env:"RATELIMIT_REDIS_ADDR"`
+}
@@ -2,99 +2,157 @@ package main
 
 import (
 	"context"
-	"net"
+	"fmt"
+	"math"
 	"os"
-	"strconv"
 	"time"
 
 	hellov1 "sdk-microservices/gen/api/proto/hello/v1"
+	"sdk-microservices/internal/platform/authjwt"
 	"sdk-microservices/internal/platform/boot"
+	"sdk-microservices/internal/platform/config"
 	"sdk-microservices/internal/platform/grpcutil"
+	"sdk-microservices/internal/platform/health"
+	"sdk-microservices/internal/platform/logging"
+	"sdk-microservices/internal/platform/shutdown"
 	hellosrv "sdk-microservices/internal/services/hello/server"
 
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
-	grpc_health "google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
-	_ = boot.Run(context.Background(), boot.Options{
-		ServiceName: "hello",
-		AdminAddrEnv: "HELLO_ADMIN_ADDR",
-		ShutdownTimeout: 10 * time.Second,
-	}, func(ctx context.Context, deps boot.Deps) (boot.Main, error) {
+	cli := boot.MustParseFlags("hellod")
+	conf, err := boot.LoadConfig[Config](config.LoadOptions{EnvPrefix: "HELLO_", File: cli.ConfigFileOr("HELLO_CONFIG_FILE"), Args: cli.Args})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "hellod:", err)
+		os.Exit(2)
+	}
+	cfg := conf.Current()
+
+	if err := boot.RunGRPC(context.Background(), boot.GRPCOptions{
+		Options: boot.Options{
+			ServiceName:     "hello",
+			EnvPrefix:       "HELLO",
+			AdminAddrEnv:    "HELLO_ADMIN_ADDR",
+			ShutdownTimeout: 10 * time.Second,
+			Config:          conf,
+			Flags:           cli,
+			Routes:          boot.GRPCRoutes(&hellov1.HelloService_ServiceDesc),
+		},
+		Addr:         cfg.Addr,
+		XDS:          cfg.XDS,
+		PreStopDelay: cfg.PreStopDelay,
+	}, func(ctx context.Context, deps boot.Deps) (boot.GRPCService, error) {
 		log := deps.Log
-		addr := env("HELLO_ADDR", ":50051")
+		if err := logging.SetLevel(deps.LogLevel, cfg.LogLevel); err != nil {
+			return boot.GRPCService{}, err
+		}
 
-		lis, err := net.Listen("tcp", addr)
-		if err != nil {
-			return boot.Main{}, err
+		// Verify bearer tokens when a JWT secret is configured.
+		var jwtSvc *authjwt.Service
+		if cfg.JWTSecret != "" {
+			jwtSvc = authjwt.New([]byte(cfg.JWTSecret), cfg.JWTIssuer, 0)
 		}
 
-		gs := grpc.NewServer(grpcutil.ServerOptionsWithNameAndLimits("hello", log, grpcutil.Limits{
-			DefaultTimeout: envDuration("HELLO_RPC_TIMEOUT", 10*time.Second),
-			MaxInFlight:    envInt("HELLO_MAX_INFLIGHT", 256),
-		})...)
+		var opts []grpc.ServerOption
+		// Enforce bearer auth when a JWT secret is configured (health checks stay public).
+		if jwtSvc != nil {
+			public := []string{
+				healthpb.Health_Check_FullMethodName,
+				healthpb.Health_Watch_FullMethodName,
+			}
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(grpcutil.AuthUnaryInterceptor(jwtSvc, public...)),
+				grpc.ChainStreamInterceptor(grpcutil.AuthStreamInterceptor(jwtSvc, public...)),
+			)
+		}
 
-		hellov1.RegisterHelloServiceServer(gs, &hellosrv.Server{})
+		// Per-caller rate limiting (user id / API key / peer IP); shared via Redis when configured.
+		var setRateLimit func(r rate.Limit, burst int)
+		if cfg.RateLimitRPS > 0 {
+			r, burst := rateLimit(cfg)
+			local := grpcutil.NewLocalRateLimiter(r, burst, 2*time.Minute)
+			var rl grpcutil.RateLimiter = local
+			setRateLimit = local.SetLimit
+			if addr := cfg.RateLimitRedisAddr; addr != "" {
+				rdb := redis.NewClient(&redis.Options{Addr: addr})
+				deps.Shutdown.Register(shutdown.Close, "redis", func(context.Context) error { return rdb.Close() })
+				shared := grpcutil.NewRedisRateLimiter(rdb, "hello:ratelimit:", r, burst)
+				rl, setRateLimit = shared, shared.SetLimit
+				// The limiter fails open, so Redis being down degrades rather than unreadies.
+				deps.ReadyRoot.AddSoft("redis", health.RedisPing(rdb))
+			}
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(grpcutil.UnaryRateLimit(rl)),
+				grpc.ChainStreamInterceptor(grpcutil.StreamRateLimit(rl)),
+			)
+		}
 
-		hs := grpc_health.NewServer()
-		hs.SetServingStatus("hello.v1.HelloService", healthpb.HealthCheckResponse_SERVING)
-		healthpb.RegisterHealthServer(gs, hs)
+		// The log level and rate limits follow config reloads (SIGHUP); other
+		// settings need a restart.
+		conf.OnChange(func(next *Config, changed config.Report) {
+			if changed.Has("LOG_LEVEL") {
+				if err := logging.SetLevel(deps.LogLevel, next.LogLevel); err != nil {
+					log.Warn("log level not changed", zap.Error(err))
+				}
+			}
+			if changed.Has("HELLO_RATELIMIT_RPS") || changed.Has("HELLO_RATELIMIT_BURST") {
+				if setRateLimit == nil || next.RateLimitRPS <= 0 {
+					log.Warn("turning rate limiting on or off needs a restart")
+					return
+				}
+				setRateLimit(rateLimit(next))
+			}
+		})
 
-		return boot.Main{
-			Serve: func() error {
-				log.Info("hellod listening", zap.String("addr", addr))
-				return gs.Serve(lis)
+		svc := boot.GRPCService{
+			Limits: grpcutil.Limits{
+				DefaultTimeout: cfg.RPCTimeout,
+				MaxInFlight:    cfg.MaxInFlight,
+				MaxQueue:       cfg.MaxQueue,
+				MaxQueueWait:   cfg.QueueWait,
+				MaxRecvMsgSize: cfg.MaxRecvMsgBytes,
+				MaxSendMsgSize: cfg.MaxSendMsgBytes,
+				SlowThreshold:  cfg.SlowRPCThreshold,
+				Identity: grpcutil.IdentityOptions{
+					Verifier:       jwtSvc,
+					TrustForwarded: cfg.TrustForwardedIdentity,
+				},
 			},
-			Shutdown: func(ctx context.Context) error {
-				hs.SetServingStatus("hello.v1.HelloService", healthpb.HealthCheckResponse_NOT_SERVING)
-				done := make(chan struct{})
-				go func() {
-					gs.GracefulStop()
-					close(done)
-				}()
-				select {
-				case <-done:
-				case <-ctx.Done():
-					gs.Stop()
-				}
-				_ = lis.Close()
-				return nil
+			ServerOptions: opts,
+			Register: func(s grpc.ServiceRegistrar) {
+				hellov1.RegisterHelloServiceServer(s, &hellosrv.Server{})
 			},
-		}, nil
-	})
-}
-
-func env(k, d string) string {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
-	}
-	return v
-}
-
-func envInt(k string, d int) int {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
-	}
-	i, err := strconv.Atoi(v)
-	if err != nil {
-		return d
+			Health: map[string][]string{"hello.v1.HelloService": nil},
+		}
+		if jwtSvc != nil {
+			// Exercise the token parser before taking traffic.
+			svc.Warmup = func(context.Context) error {
+				tok, _, err := jwtSvc.NewAccessToken("warmup", "", time.Minute)
+				if err != nil {
+					return err
+				}
+				_, err = jwtSvc.Parse(tok)
+				return err
+			}
+		}
+		return svc, nil
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "hellod:", err)
+		os.Exit(1)
 	}
-	return i
 }
 
-func envDuration(k string, d time.Duration) time.Duration {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
-	}
-	dur, err := time.ParseDuration(v)
-	if err != nil {
-		return d
+// rateLimit returns the per-caller rate and burst; the burst defaults to
+// twice the rate, and at least 1 so rates below 1 rps still admit calls.
+func rateLimit(cfg *Config) (rate.Limit, int) {
+	burst := cfg.RateLimitBurst
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(cfg.RateLimitRPS*2)))
 	}
-	return dur
+	return rate.Limit(cfg.RateLimitRPS), burst
 }
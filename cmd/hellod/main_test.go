@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitBurst(t *testing.T) {
+	for _, tc := range []struct {
+		rps   float64
+		burst int
+		want  int
+	}{
+		{rps: 0.2, want: 1},
+		{rps: 1.5, want: 3},
+		{rps: 10, want: 20},
+		{rps: 10, burst: 4, want: 4},
+	} {
+		r, burst := rateLimit(&Config{RateLimitRPS: tc.rps, RateLimitBurst: tc.burst})
+		if r != rate.Limit(tc.rps) || burst != tc.want {
+			t.Errorf("rateLimit(rps=%v, burst=%d) = %v, %d; want burst %d", tc.rps, tc.burst, r, burst, tc.want)
+		}
+	}
+}
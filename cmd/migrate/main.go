@@ -0,0 +1,196 @@
+// Command migrate manages a service's schema with the migrations embedded from
+// migrations/<service>:
+//
+//	migrate -service auth up
+//	migrate -service auth down 1
+//	migrate -service auth status
+//	migrate -service auth create add_users_name
+//
+// The database is -dsn, else <SERVICE>_DB_DSN (e.g. AUTH_DB_DSN).
+//
+// For schema-per-tenant deployments, -tenants acme,globex runs the command in
+// each tenant's schema (created on first up), and -all-tenants in every
+// existing tenant schema:
+//
+//	migrate -service auth -tenants acme up
+//	migrate -service auth -all-tenants up
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"sdk-microservices/internal/db"
+	"sdk-microservices/internal/db/migrate"
+	"sdk-microservices/migrations"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fl := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	service := fl.String("service", "", "service whose migrations to use (e.g. auth)")
+	dsn := fl.String("dsn", "", "database URL (default $<SERVICE>_DB_DSN)")
+	tenants := fl.String("tenants", "", "comma-separated tenant ids; run the command in each tenant's schema")
+	allTenants := fl.Bool("all-tenants", false, "run the command in every existing tenant schema")
+	tenantPrefix := fl.String("tenant-prefix", db.DefaultTenantSchemaPrefix, "tenant schema name prefix")
+	dir := fl.String("dir", "", "read migrations from this directory instead of the embedded ones; create writes here (default migrations/<service>)")
+	fl.Usage = func() {
+		fmt.Fprintln(fl.Output(), "usage: migrate -service NAME [flags] up | down N | status | create NAME")
+		fl.PrintDefaults()
+	}
+	if err := fl.Parse(args); err != nil {
+		return err
+	}
+	if *service == "" || fl.NArg() == 0 {
+		fl.Usage()
+		return fmt.Errorf("-service and a command are required")
+	}
+	cmd, rest := fl.Arg(0), fl.Args()[1:]
+
+	if cmd == "create" {
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: create NAME")
+		}
+		d := *dir
+		if d == "" {
+			d = filepath.Join("migrations", *service)
+		}
+		path, err := migrate.Create(d, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	}
+
+	var src fs.FS
+	if *dir != "" {
+		src = os.DirFS(*dir)
+	} else {
+		var err error
+		if src, err = migrations.For(*service); err != nil {
+			return fmt.Errorf("no embedded migrations for %q", *service)
+		}
+	}
+	if *dsn == "" {
+		*dsn = os.Getenv(strings.ToUpper(*service) + "_DB_DSN")
+	}
+	if *dsn == "" {
+		return fmt.Errorf("-dsn or %s_DB_DSN is required", strings.ToUpper(*service))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.NewPool(ctx, *dsn, db.Options{MaxConns: 2, DisableTracing: true})
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	m, err := migrate.New(pool, src)
+	if err != nil {
+		return err
+	}
+
+	var schemas []string
+	for _, t := range strings.Split(*tenants, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		schema, err := db.TenantSchema(*tenantPrefix, t)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, schema)
+	}
+	if *allTenants {
+		found, err := db.TenantSchemas(ctx, pool, *tenantPrefix)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, found...)
+	}
+	if *tenants == "" && !*allTenants {
+		return runCommand(ctx, m, cmd, rest, fl.Usage)
+	}
+
+	// Keep going past a failing tenant so one bad schema doesn't block the rest;
+	// every failure is reported at the end.
+	var errs []error
+	for _, schema := range schemas {
+		fmt.Printf("== %s\n", schema)
+		if err := runCommand(ctx, m.InSchema(schema), cmd, rest, fl.Usage); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", schema, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(schemas) == 0 {
+		fmt.Println("no tenant schemas")
+	}
+	return errors.Join(errs...)
+}
+
+func runCommand(ctx context.Context, m *migrate.Migrator, cmd string, rest []string, usage func()) error {
+	switch cmd {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %03d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: down N")
+		}
+		n, err := strconv.Atoi(rest[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("down: N must be a positive integer")
+		}
+		reverted, err := m.Down(ctx, n)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %03d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version %d", st.Version)
+		if st.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+		for _, mig := range st.Applied {
+			fmt.Printf("  applied  %03d_%s\n", mig.Version, mig.Name)
+		}
+		for _, mig := range st.Pending {
+			fmt.Printf("  pending  %03d_%s\n", mig.Version, mig.Name)
+		}
+		return nil
+	default:
+		usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// maxParams is Postgres' limit on bind parameters per statement.
+const maxParams = 65535
+
+// BulkConn is satisfied by *pgxpool.Pool, *pgx.Conn and pgx.Tx. Passing a pool
+// commits each batch on its own; passing a tx makes the whole load atomic.
+type BulkConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+}
+
+// BulkOptions configures CopyFrom and BulkInsert.
+type BulkOptions struct {
+	// Service names the meter for db.client.bulk.rows and .duration (optional).
+	Service string
+	// BatchSize is the rows per round trip (default 1000). BulkInsert lowers it
+	// to stay under Postgres' 65535 bind parameters.
+	BatchSize int
+	// BatchTimeout bounds each batch (default 30s), so a large load can't hold a
+	// connection indefinitely while still allowing it to take long overall.
+	BatchTimeout time.Duration
+	// OnConflict is appended to BulkInsert's statement, e.g.
+	// "ON CONFLICT (id) DO NOTHING". Ignored by CopyFrom.
+	OnConflict string
+}
+
+func (o BulkOptions) withDefaults() BulkOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	if o.BatchTimeout <= 0 {
+		o.BatchTimeout = 30 * time.Second
+	}
+	return o
+}
+
+// CopyFrom loads rows into table using COPY, BatchSize rows at a time, and
+// returns the number of rows copied. On error the count covers the batches
+// already written (committed, unless conn is a tx). table may be schema-qualified
+// ("audit.events").
+func CopyFrom(ctx context.Context, conn BulkConn, table string, columns []string, rows [][]any, opts BulkOptions) (int64, error) {
+	opts = opts.withDefaults()
+	ident := pgx.Identifier(strings.Split(table, "."))
+	return runBatches(ctx, "copy", table, rows, opts.BatchSize, opts, func(ctx context.Context, batch [][]any) (int64, error) {
+		return conn.CopyFrom(ctx, ident, columns, pgx.CopyFromRows(batch))
+	})
+}
+
+// BulkInsert inserts rows with multi-row INSERT statements. Unlike CopyFrom it
+// supports OnConflict (e.g. idempotent relays); the count is rows affected.
+func BulkInsert(ctx context.Context, conn BulkConn, table string, columns []string, rows [][]any, opts BulkOptions) (int64, error) {
+	if len(columns) == 0 {
+		return 0, errors.New("db: BulkInsert needs columns")
+	}
+	opts = opts.withDefaults()
+	size := min(opts.BatchSize, maxParams/len(columns))
+
+	cols := make([]string, len(columns))
+	for i, c := range columns {
+		cols[i] = pgx.Identifier{c}.Sanitize()
+	}
+	head := "INSERT INTO " + pgx.Identifier(strings.Split(table, ".")).Sanitize() + " (" + strings.Join(cols, ", ") + ") VALUES "
+
+	return runBatches(ctx, "insert", table, rows, size, opts, func(ctx context.Context, batch [][]any) (int64, error) {
+		var sb strings.Builder
+		sb.WriteString(head)
+		args := make([]any, 0, len(batch)*len(columns))
+		for i, row := range batch {
+			if len(row) != len(columns) {
+				return 0, fmt.Errorf("db: row has %d values, want %d", len(row), len(columns))
+			}
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('(')
+			for j := range row {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteByte('$')
+				sb.WriteString(strconv.Itoa(len(args) + j + 1))
+			}
+			sb.WriteByte(')')
+			args = append(args, row...)
+		}
+		if opts.OnConflict != "" {
+			sb.WriteByte(' ')
+			sb.WriteString(opts.OnConflict)
+		}
+		tag, err := conn.Exec(ctx, sb.String(), args...)
+		return tag.RowsAffected(), err
+	})
+}
+
+// runBatches calls write for each batch of rows under its own deadline.
+func runBatches(ctx context.Context, op, table string, rows [][]any, size int, opts BulkOptions, write func(context.Context, [][]any) (int64, error)) (int64, error) {
+	inst := bulkInstruments(opts.Service)
+	attrs := metric.WithAttributes(
+		attribute.String("db.operation.name", op),
+		attribute.String("db.collection.name", table),
+	)
+	var total int64
+	for start := 0; start < len(rows); start += size {
+		batch := rows[start:min(start+size, len(rows))]
+		bctx, cancel := context.WithTimeout(ctx, opts.BatchTimeout)
+		began := time.Now()
+		n, err := write(bctx, batch)
+		cancel()
+		total += n
+		if inst.rows != nil {
+			inst.rows.Add(ctx, n, attrs)
+			inst.duration.Record(ctx, time.Since(began).Seconds(), attrs)
+		}
+		if err != nil {
+			return total, fmt.Errorf("db: %s %s (rows %d-%d): %w", op, table, start, start+len(batch)-1, err)
+		}
+	}
+	return total, nil
+}
+
+type bulkMetrics struct {
+	rows     metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// bulkInstruments returns the bulk instruments for service; the SDK dedupes
+// repeated registrations. Without a service no metrics are recorded.
+func bulkInstruments(service string) bulkMetrics {
+	if service == "" {
+		return bulkMetrics{}
+	}
+	m := otel.Meter("sdk-microservices/" + service)
+	rows, err := m.Int64Counter("db.client.bulk.rows",
+		metric.WithDescription("Rows written by bulk COPY/INSERT"),
+		metric.WithUnit("{row}"))
+	if err != nil {
+		return bulkMetrics{}
+	}
+	duration, err := m.Float64Histogram("db.client.bulk.duration",
+		metric.WithDescription("Duration of one bulk COPY/INSERT batch"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return bulkMetrics{}
+	}
+	return bulkMetrics{rows: rows, duration: duration}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type fakeBulkConn struct {
+	execs    []string
+	args     [][]any
+	copies   []int
+	failOn   int // 1-based call that fails
+	calls    int
+	deadline []time.Duration
+}
+
+func (f *fakeBulkConn) call(ctx context.Context) error {
+	f.calls++
+	if d, ok := ctx.Deadline(); ok {
+		f.deadline = append(f.deadline, time.Until(d))
+	}
+	if f.calls == f.failOn {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (f *fakeBulkConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := f.call(ctx); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	f.execs = append(f.execs, sql)
+	f.args = append(f.args, args)
+	return pgconn.NewCommandTag("INSERT 0 " + strconv.Itoa(len(args)/2)), nil
+}
+
+func (f *fakeBulkConn) CopyFrom(ctx context.Context, _ pgx.Identifier, _ []string, rows pgx.CopyFromSource) (int64, error) {
+	if err := f.call(ctx); err != nil {
+		return 0, err
+	}
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	f.copies = append(f.copies, n)
+	return int64(n), nil
+}
+
+func rowsN(n int) [][]any {
+	out := make([][]any, n)
+	for i := range out {
+		out[i] = []any{i, "x"}
+	}
+	return out
+}
+
+func TestCopyFrom_Batches(t *testing.T) {
+	f := &fakeBulkConn{}
+	n, err := CopyFrom(context.Background(), f, "audit.events", []string{"id", "v"}, rowsN(7), BulkOptions{BatchSize: 3, BatchTimeout: time.Minute})
+	if err != nil || n != 7 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if len(f.copies) != 3 || f.copies[0] != 3 || f.copies[2] != 1 {
+		t.Fatalf("batches = %v", f.copies)
+	}
+	for _, d := range f.deadline {
+		if d <= 0 || d > time.Minute {
+			t.Fatalf("per-batch deadline = %v", d)
+		}
+	}
+}
+
+func TestBulkInsert(t *testing.T) {
+	f := &fakeBulkConn{}
+	n, err := BulkInsert(context.Background(), f, "events", []string{"id", "v"}, rowsN(3), BulkOptions{BatchSize: 2, OnConflict: "ON CONFLICT DO NOTHING"})
+	if err != nil || n != 3 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	want := `INSERT INTO "events" ("id", "v") VALUES ($1, $2), ($3, $4) ON CONFLICT DO NOTHING`
+	if f.execs[0] != want {
+		t.Fatalf("sql = %s\nwant  %s", f.execs[0], want)
+	}
+	if len(f.args[1]) != 2 {
+		t.Fatalf("second batch args = %v", f.args[1])
+	}
+}
+
+func TestBulk_PartialFailure(t *testing.T) {
+	f := &fakeBulkConn{failOn: 2}
+	n, err := CopyFrom(context.Background(), f, "events", []string{"id", "v"}, rowsN(5), BulkOptions{BatchSize: 2})
+	if err == nil || n != 2 || !strings.Contains(err.Error(), "rows 2-3") {
+		t.Fatalf("n=%d err=%v; want the first batch counted and the failing range reported", n, err)
+	}
+}
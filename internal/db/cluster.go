@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type readOnlyKey struct{}
+
+// WithReadOnly marks ctx as only reading, so Cluster.Pool may route it to a
+// replica. Only use it where slightly stale data (replication lag) is acceptable.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+// IsReadOnly reports whether ctx was marked with WithReadOnly.
+func IsReadOnly(ctx context.Context) bool {
+	v, _ := ctx.Value(readOnlyKey{}).(bool)
+	return v
+}
+
+// Cluster is a primary pool plus read-replica pools. Writes (and unmarked reads)
+// go to the primary; reads marked with WithReadOnly are spread over replicas whose
+// last lag check passed, falling back to the primary when none did.
+type Cluster struct {
+	Primary  *pgxpool.Pool
+	Replicas []*Replica
+
+	next atomic.Uint64
+}
+
+// Replica is one read replica and its last known lag.
+type Replica struct {
+	Name string
+	Pool *pgxpool.Pool
+
+	maxLag time.Duration
+	lag    atomic.Int64 // nanoseconds; negative until first measured
+	ok     atomic.Bool
+}
+
+// ClusterOptions configures NewCluster.
+type ClusterOptions struct {
+	// Options apply to the primary and every replica pool.
+	Options
+	// MaxReplicaLag is how far behind a replica may be and still serve reads
+	// (default 5s).
+	MaxReplicaLag time.Duration
+}
+
+// NewCluster opens the primary and replica pools. Replicas serve reads right away;
+// their lag is tracked by the checks from Replica.LagCheck.
+func NewCluster(ctx context.Context, primaryDSN string, replicaDSNs []string, opts ClusterOptions) (*Cluster, error) {
+	if opts.MaxReplicaLag <= 0 {
+		opts.MaxReplicaLag = 5 * time.Second
+	}
+	primary, err := NewPool(ctx, primaryDSN, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cluster{Primary: primary}
+	replicaOpts := opts.Options
+	if f := replicaOpts.Failover; f != nil && f.ReadWrite {
+		rf := *f
+		rf.ReadWrite = false
+		replicaOpts.Failover = &rf
+	}
+	for i, dsn := range replicaDSNs {
+		p, err := NewPool(ctx, dsn, replicaOpts)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("db: replica %d: %w", i, err)
+		}
+		r := &Replica{Name: fmt.Sprintf("replica-%d", i), Pool: p, maxLag: opts.MaxReplicaLag}
+		r.lag.Store(-1)
+		r.ok.Store(true)
+		c.Replicas = append(c.Replicas, r)
+	}
+	return c, nil
+}
+
+// Pool returns the pool for ctx: a healthy replica (round-robin) if ctx is
+// read-only, else the primary.
+func (c *Cluster) Pool(ctx context.Context) *pgxpool.Pool {
+	if c == nil {
+		return nil
+	}
+	if n := len(c.Replicas); n > 0 && IsReadOnly(ctx) {
+		start := c.next.Add(1)
+		for i := range n {
+			if r := c.Replicas[(start+uint64(i))%uint64(n)]; r.ok.Load() {
+				return r.Pool
+			}
+		}
+	}
+	return c.Primary
+}
+
+// Close closes every pool.
+func (c *Cluster) Close() {
+	if c == nil {
+		return
+	}
+	if c.Primary != nil {
+		c.Primary.Close()
+	}
+	for _, r := range c.Replicas {
+		r.Pool.Close()
+	}
+}
+
+// Lag returns the last measured replication lag (negative if never measured).
+func (r *Replica) Lag() time.Duration {
+	return time.Duration(r.lag.Load())
+}
+
+// LagCheck measures the replica's lag, fails if it exceeds the cluster's
+// MaxReplicaLag, and takes the replica out of (or back into) read routing
+// accordingly. Add it to the readiness graph, typically as a Soft dependency, so
+// it runs periodically.
+func (r *Replica) LagCheck() func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx2, cancel := context.WithTimeout(ctx, 1*time.Second)
+		defer cancel()
+		lag, err := ReplicaLag(ctx2, r.Pool)
+		if err != nil {
+			r.ok.Store(false)
+			return err
+		}
+		r.lag.Store(int64(lag))
+		if lag > r.maxLag {
+			r.ok.Store(false)
+			return fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), r.maxLag)
+		}
+		r.ok.Store(true)
+		return nil
+	}
+}
+
+// ReplicaLag returns how far a streaming replica's replay is behind. A replica
+// that has replayed everything it received reports 0 even if the primary is idle.
+func ReplicaLag(ctx context.Context, pool *pgxpool.Pool) (time.Duration, error) {
+	if pool == nil {
+		return 0, errors.New("db: nil pool")
+	}
+	var secs float64
+	err := pool.QueryRow(ctx, `
+		SELECT CASE
+			WHEN NOT pg_is_in_recovery() THEN 0
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		END::float8
+	`).Scan(&secs)
+	if err != nil {
+		return 0, fmt.Errorf("db: replica lag: %w", err)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
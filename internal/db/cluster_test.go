@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestClusterPool(t *testing.T) {
+	primary, r1, r2 := &pgxpool.Pool{}, &pgxpool.Pool{}, &pgxpool.Pool{}
+	c := &Cluster{Primary: primary, Replicas: []*Replica{{Pool: r1}, {Pool: r2}}}
+	for _, r := range c.Replicas {
+		r.ok.Store(true)
+	}
+	ctx := context.Background()
+	ro := WithReadOnly(ctx)
+
+	if c.Pool(ctx) != primary {
+		t.Fatal("unmarked ctx must use the primary")
+	}
+	seen := map[*pgxpool.Pool]bool{}
+	for range 4 {
+		seen[c.Pool(ro)] = true
+	}
+	if !seen[r1] || !seen[r2] || seen[primary] {
+		t.Fatalf("read-only ctx should round-robin replicas, got %v", seen)
+	}
+
+	c.Replicas[0].ok.Store(false)
+	for range 4 {
+		if p := c.Pool(ro); p != r2 {
+			t.Fatal("lagging replica must be skipped")
+		}
+	}
+	c.Replicas[1].ok.Store(false)
+	if c.Pool(ro) != primary {
+		t.Fatal("no healthy replica: fall back to the primary")
+	}
+}
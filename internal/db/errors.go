@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// UniqueViolation is a unique constraint violation (SQLSTATE 23505).
+type UniqueViolation struct {
+	// Constraint is the violated constraint or index, e.g. "users_email_key".
+	Constraint string
+	Err        *pgconn.PgError
+}
+
+func (e *UniqueViolation) Error() string { return "db: unique violation: " + e.Constraint }
+func (e *UniqueViolation) Unwrap() error { return e.Err }
+
+// ForeignKeyViolation is a foreign key violation (SQLSTATE 23503).
+type ForeignKeyViolation struct {
+	Constraint string
+	Err        *pgconn.PgError
+}
+
+func (e *ForeignKeyViolation) Error() string { return "db: foreign key violation: " + e.Constraint }
+func (e *ForeignKeyViolation) Unwrap() error { return e.Err }
+
+// SerializationFailure is a serialization failure (40001) or deadlock (40P01):
+// the transaction can simply be run again (WithTx does).
+type SerializationFailure struct {
+	Deadlock bool
+	Err      *pgconn.PgError
+}
+
+func (e *SerializationFailure) Error() string {
+	if e.Deadlock {
+		return "db: deadlock detected"
+	}
+	return "db: serialization failure"
+}
+func (e *SerializationFailure) Unwrap() error { return e.Err }
+
+// ConnectionError means the database couldn't be reached or dropped the
+// connection (dial failures, SQLSTATE class 08, server shutdown, ErrFailover).
+// Whether the statement ran is unknown.
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string { return "db: connection error: " + e.Err.Error() }
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// ClassifyError maps err to one of the typed errors above, which wrap it, so
+// callers match with errors.As instead of inspecting SQLSTATEs or messages.
+// Other errors (including nil and pgx.ErrNoRows) are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505":
+			return &UniqueViolation{Constraint: pgErr.ConstraintName, Err: pgErr}
+		case pgErr.Code == "23503":
+			return &ForeignKeyViolation{Constraint: pgErr.ConstraintName, Err: pgErr}
+		case pgErr.Code == "40001", pgErr.Code == "40P01":
+			return &SerializationFailure{Deadlock: pgErr.Code == "40P01", Err: pgErr}
+		case strings.HasPrefix(pgErr.Code, "08"),
+			pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03":
+			return &ConnectionError{Err: err}
+		}
+		return err
+	}
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) || errors.Is(err, ErrFailover) {
+		return &ConnectionError{Err: err}
+	}
+	// Context errors satisfy net.Error but mean the caller gave up, not that
+	// the server is unreachable.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &ConnectionError{Err: err}
+	}
+	return err
+}
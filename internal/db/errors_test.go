@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassifyError(t *testing.T) {
+	wrap := func(err error) error { return fmt.Errorf("create user: %w", err) }
+
+	var uv *UniqueViolation
+	if err := ClassifyError(wrap(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})); !errors.As(err, &uv) || uv.Constraint != "users_email_key" {
+		t.Fatalf("23505: got %v", err)
+	}
+	var fk *ForeignKeyViolation
+	if err := ClassifyError(&pgconn.PgError{Code: "23503", ConstraintName: "sessions_user_id_fkey"}); !errors.As(err, &fk) || fk.Constraint != "sessions_user_id_fkey" {
+		t.Fatalf("23503: got %v", err)
+	}
+	var sf *SerializationFailure
+	if err := ClassifyError(&pgconn.PgError{Code: "40P01"}); !errors.As(err, &sf) || !sf.Deadlock {
+		t.Fatalf("40P01: got %v", err)
+	}
+	var ce *ConnectionError
+	for _, in := range []error{
+		&pgconn.PgError{Code: "08006"},
+		&pgconn.PgError{Code: "57P01"},
+		wrap(io.ErrUnexpectedEOF),
+		wrap(ErrFailover),
+	} {
+		if err := ClassifyError(in); !errors.As(err, &ce) {
+			t.Fatalf("%v: got %v, want ConnectionError", in, err)
+		}
+	}
+
+	// Typed errors still unwrap to the original.
+	pgErr := &pgconn.PgError{Code: "23505"}
+	if err := ClassifyError(pgErr); !errors.Is(err, pgErr) {
+		t.Fatal("UniqueViolation does not unwrap to the PgError")
+	}
+	for _, in := range []error{nil, pgx.ErrNoRows, &pgconn.PgError{Code: "42P01"}, context.DeadlineExceeded} {
+		if got := ClassifyError(in); got != in {
+			t.Fatalf("ClassifyError(%v) = %v, want unchanged", in, got)
+		}
+	}
+}
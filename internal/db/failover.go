@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrFailover is returned when acquiring a connection while the pool's failover
+// circuit is open (see FailoverOptions). ClassifyError reports it as a
+// ConnectionError.
+var ErrFailover = errors.New("db: database failover in progress")
+
+// Hooks are connection lifecycle callbacks, run after the package's own (IAM
+// passwords, tenancy, failover tracking).
+type Hooks struct {
+	// AfterConnect runs on every new connection; an error discards it.
+	AfterConnect func(ctx context.Context, conn *pgx.Conn) error
+	// BeforeClose runs before a connection is closed and dropped from the pool.
+	BeforeClose func(conn *pgx.Conn)
+}
+
+// FailoverOptions let a pool ride out a Postgres HA failover without a restart.
+//
+// When a query or connect fails with a connection error (or, with ReadWrite,
+// hits a server that became read-only) the circuit opens: every pooled
+// connection is dropped, since they all point at the old primary, and new
+// connections are attempted at most once per ProbeInterval while other
+// acquisitions fail fast with ErrFailover instead of piling up on dial
+// timeouts. Each attempt resolves the host again, so a DNS name moved to the
+// new primary is picked up. The first successful connect closes the circuit.
+type FailoverOptions struct {
+	// ReadWrite only accepts connections to a writable server, as
+	// target_session_attrs=read-write in the DSN does. Combined with a
+	// multi-host DSN (host=a,b) it finds the new primary. Ignored for replica
+	// pools.
+	ReadWrite bool
+	// ProbeInterval spaces connection attempts while the circuit is open
+	// (default 1s).
+	ProbeInterval time.Duration
+	// OnStateChange is called when the circuit opens (true) or closes (false),
+	// e.g. to log or count failovers.
+	OnStateChange func(open bool)
+}
+
+type breaker struct {
+	opts FailoverOptions
+	pool atomic.Pointer[pgxpool.Pool]
+
+	mu        sync.Mutex
+	open      bool
+	lastProbe time.Time
+}
+
+func newBreaker(opts FailoverOptions) *breaker {
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = time.Second
+	}
+	return &breaker{opts: opts}
+}
+
+// trip opens the circuit and drops every pooled connection.
+func (b *breaker) trip() {
+	b.mu.Lock()
+	if b.open {
+		b.mu.Unlock()
+		return
+	}
+	b.open = true
+	b.lastProbe = time.Time{}
+	b.mu.Unlock()
+
+	if b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(true)
+	}
+	if p := b.pool.Load(); p != nil {
+		// Not inline: we may be inside a query on one of the pool's connections.
+		go p.Reset()
+	}
+}
+
+// beforeConnect lets one connection attempt through per ProbeInterval while open.
+func (b *breaker) beforeConnect(context.Context, *pgx.ConnConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return nil
+	}
+	if time.Since(b.lastProbe) < b.opts.ProbeInterval {
+		return ErrFailover
+	}
+	b.lastProbe = time.Now()
+	return nil
+}
+
+// afterConnect closes the circuit: the server answered (and, with ReadWrite,
+// passed validation).
+func (b *breaker) afterConnect(context.Context, *pgx.Conn) error {
+	b.mu.Lock()
+	was := b.open
+	b.open = false
+	b.mu.Unlock()
+	if was && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(false)
+	}
+	return nil
+}
+
+// observe trips the circuit if err means the server is gone or demoted. Errors
+// from callers giving up (ctx done) say nothing about the server.
+func (b *breaker) observe(ctx context.Context, err error) {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ErrFailover) {
+		return
+	}
+	var connErr *ConnectionError
+	if errors.As(ClassifyError(err), &connErr) {
+		b.trip()
+		return
+	}
+	var pgErr *pgconn.PgError
+	if b.opts.ReadWrite && errors.As(err, &pgErr) && pgErr.Code == "25006" { // read_only_sql_transaction
+		b.trip()
+	}
+}
+
+// failoverTracer feeds query and acquire errors to a breaker, delegating to the
+// query tracer it wraps (nil if tracing is disabled).
+type failoverTracer struct {
+	next pgx.QueryTracer
+	b    *breaker
+}
+
+func (t *failoverTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if t.next != nil {
+		return t.next.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (t *failoverTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+	t.b.observe(ctx, data.Err)
+}
+
+func (t *failoverTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	return ctx
+}
+
+func (t *failoverTracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	t.b.observe(ctx, data.Err)
+}
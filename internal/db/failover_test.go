@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestBreaker(t *testing.T) {
+	var states []bool
+	b := newBreaker(FailoverOptions{ProbeInterval: 50 * time.Millisecond, OnStateChange: func(open bool) { states = append(states, open) }})
+	ctx := context.Background()
+
+	if err := b.beforeConnect(ctx, nil); err != nil {
+		t.Fatalf("closed circuit: %v", err)
+	}
+
+	b.observe(ctx, io.ErrUnexpectedEOF)
+	if err := b.beforeConnect(ctx, nil); err != nil {
+		t.Fatalf("first probe after trip: %v", err)
+	}
+	if err := b.beforeConnect(ctx, nil); !errors.Is(err, ErrFailover) {
+		t.Fatalf("second attempt within interval: %v, want ErrFailover", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := b.beforeConnect(ctx, nil); err != nil {
+		t.Fatalf("probe after interval: %v", err)
+	}
+
+	_ = b.afterConnect(ctx, nil)
+	if err := b.beforeConnect(ctx, nil); err != nil {
+		t.Fatalf("after reconnect: %v", err)
+	}
+	if len(states) != 2 || !states[0] || states[1] {
+		t.Fatalf("state changes = %v, want [true false]", states)
+	}
+}
+
+func TestBreakerObserve(t *testing.T) {
+	readOnly := &pgconn.PgError{Code: "25006"}
+
+	b := newBreaker(FailoverOptions{})
+	b.observe(context.Background(), readOnly)
+	b.observe(context.Background(), &pgconn.PgError{Code: "23505"})
+	b.observe(context.Background(), ErrFailover)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.observe(canceled, io.ErrUnexpectedEOF)
+	if b.open {
+		t.Fatal("tripped on an error that doesn't indicate failover")
+	}
+
+	rw := newBreaker(FailoverOptions{ReadWrite: true})
+	rw.observe(context.Background(), readOnly)
+	if !rw.open {
+		t.Fatal("ReadWrite pool did not trip on a read-only server")
+	}
+}
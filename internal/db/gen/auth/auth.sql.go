@@ -7,193 +7,269 @@ package authdb
 
 import (
 	"context"
+	"net/netip"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const consumeEmailVerification = `-- name: ConsumeEmailVerification :one
-UPDATE email_verifications
-SET consumed_at = $2
-WHERE token_hash = $1
-  AND consumed_at IS NULL
-  AND expires_at > $2
-RETURNING user_id::text AS user_id
-`
-
-type ConsumeEmailVerificationParams struct {
-	TokenHash  []byte             `db:"token_hash" json:"token_hash"`
-	ConsumedAt pgtype.Timestamptz `db:"consumed_at" json:"consumed_at"`
-}
-
-func (q *Queries) ConsumeEmailVerification(ctx context.Context, arg ConsumeEmailVerificationParams) (string, error) {
-	row := q.db.QueryRow(ctx, consumeEmailVerification, arg.TokenHash, arg.ConsumedAt)
-	var user_id string
-	err := row.Scan(&user_id)
-	return user_id, err
-}
-
-const createEmailVerification = `-- name: CreateEmailVerification :exec
-INSERT INTO email_verifications (user_id, token_hash, expires_at)
-VALUES ($1::uuid, $2, $3)
-`
-
-type CreateEmailVerificationParams struct {
-	Column1   pgtype.UUID        `db:"column_1" json:"column_1"`
-	TokenHash []byte             `db:"token_hash" json:"token_hash"`
-	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
-}
-
-func (q *Queries) CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) error {
-	_, err := q.db.Exec(ctx, createEmailVerification, arg.Column1, arg.TokenHash, arg.ExpiresAt)
-	return err
-}
-
-const createSession = `-- name: CreateSession :exec
-INSERT INTO sessions (user_id, token_hash, expires_at)
-VALUES ($1::uuid, $2, $3)
+const archiveSessions = `-- name: ArchiveSessions :execrows
+WITH moved AS (
+  DELETE FROM sessions
+  WHERE id IN (
+    SELECT s.id FROM sessions s
+    WHERE s.expires_at < $1 OR s.revoked_at < $1
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+  )
+  RETURNING id, user_id, refresh_token_hash, created_at, expires_at, revoked_at, user_agent, ip, rotated_from
+)
+INSERT INTO sessions_archive (id, user_id, refresh_token_hash, created_at, expires_at, revoked_at, user_agent, ip, rotated_from)
+SELECT id, user_id, refresh_token_hash, created_at, expires_at, revoked_at, user_agent, ip, rotated_from
+FROM moved
 `
 
-type CreateSessionParams struct {
-	Column1   pgtype.UUID        `db:"column_1" json:"column_1"`
-	TokenHash []byte             `db:"token_hash" json:"token_hash"`
-	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+type ArchiveSessionsParams struct {
+	Cutoff    time.Time `db:"cutoff" json:"cutoff"`
+	BatchSize int32     `db:"batch_size" json:"batch_size"`
 }
 
-func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
-	_, err := q.db.Exec(ctx, createSession, arg.Column1, arg.TokenHash, arg.ExpiresAt)
-	return err
+func (q *Queries) ArchiveSessions(ctx context.Context, arg ArchiveSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, archiveSessions, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (email, password_hash)
 VALUES ($1, $2)
-RETURNING id::text AS id, email, password_hash, email_verified_at
+RETURNING id, email, password_hash, created_at, updated_at, version
 `
 
 type CreateUserParams struct {
 	Email        string `db:"email" json:"email"`
-	PasswordHash []byte `db:"password_hash" json:"password_hash"`
+	PasswordHash string `db:"password_hash" json:"password_hash"`
 }
 
-type CreateUserRow struct {
-	ID              string             `db:"id" json:"id"`
-	Email           string             `db:"email" json:"email"`
-	PasswordHash    []byte             `db:"password_hash" json:"password_hash"`
-	EmailVerifiedAt pgtype.Timestamptz `db:"email_verified_at" json:"email_verified_at"`
-}
-
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
 	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.PasswordHash)
-	var i CreateUserRow
+	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Email,
 		&i.PasswordHash,
-		&i.EmailVerifiedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
-const getSessionUserIDByTokenHash = `-- name: GetSessionUserIDByTokenHash :one
-SELECT user_id::text AS user_id
-FROM sessions
-WHERE token_hash = $1
-  AND revoked_at IS NULL
-  AND expires_at > $2
-`
-
-type GetSessionUserIDByTokenHashParams struct {
-	TokenHash []byte             `db:"token_hash" json:"token_hash"`
-	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
-}
-
-func (q *Queries) GetSessionUserIDByTokenHash(ctx context.Context, arg GetSessionUserIDByTokenHashParams) (string, error) {
-	row := q.db.QueryRow(ctx, getSessionUserIDByTokenHash, arg.TokenHash, arg.ExpiresAt)
-	var user_id string
-	err := row.Scan(&user_id)
-	return user_id, err
-}
-
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id::text AS id, email, password_hash, email_verified_at
+SELECT id, email, password_hash, created_at, updated_at, version
 FROM users
 WHERE email = $1
 `
 
-type GetUserByEmailRow struct {
-	ID              string             `db:"id" json:"id"`
-	Email           string             `db:"email" json:"email"`
-	PasswordHash    []byte             `db:"password_hash" json:"password_hash"`
-	EmailVerifiedAt pgtype.Timestamptz `db:"email_verified_at" json:"email_verified_at"`
-}
-
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
 	row := q.db.QueryRow(ctx, getUserByEmail, email)
-	var i GetUserByEmailRow
+	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Email,
 		&i.PasswordHash,
-		&i.EmailVerifiedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id::text AS id, email, password_hash, email_verified_at
+SELECT id, email, password_hash, created_at, updated_at, version
 FROM users
 WHERE id = $1::uuid
 `
 
-type GetUserByIDRow struct {
-	ID              string             `db:"id" json:"id"`
-	Email           string             `db:"email" json:"email"`
-	PasswordHash    []byte             `db:"password_hash" json:"password_hash"`
-	EmailVerifiedAt pgtype.Timestamptz `db:"email_verified_at" json:"email_verified_at"`
-}
-
-func (q *Queries) GetUserByID(ctx context.Context, dollar_1 pgtype.UUID) (GetUserByIDRow, error) {
-	row := q.db.QueryRow(ctx, getUserByID, dollar_1)
-	var i GetUserByIDRow
+func (q *Queries) GetUserByID(ctx context.Context, id string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByID, id)
+	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Email,
 		&i.PasswordHash,
-		&i.EmailVerifiedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
-const markEmailVerified = `-- name: MarkEmailVerified :exec
-UPDATE users
-SET email_verified_at = $2,
-    updated_at = now()
+const insertSession = `-- name: InsertSession :one
+INSERT INTO sessions (user_id, refresh_token_hash, expires_at, user_agent, ip)
+VALUES ($1::uuid, $2, $3, $4, $5)
+RETURNING id
+`
+
+type InsertSessionParams struct {
+	UserID           string      `db:"user_id" json:"user_id"`
+	RefreshTokenHash []byte      `db:"refresh_token_hash" json:"refresh_token_hash"`
+	ExpiresAt        time.Time   `db:"expires_at" json:"expires_at"`
+	UserAgent        pgtype.Text `db:"user_agent" json:"user_agent"`
+	Ip               *netip.Addr `db:"ip" json:"ip"`
+}
+
+func (q *Queries) InsertSession(ctx context.Context, arg InsertSessionParams) (string, error) {
+	row := q.db.QueryRow(ctx, insertSession,
+		arg.UserID,
+		arg.RefreshTokenHash,
+		arg.ExpiresAt,
+		arg.UserAgent,
+		arg.Ip,
+	)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
+const listActiveSessionIDs = `-- name: ListActiveSessionIDs :many
+SELECT id
+FROM sessions
+WHERE user_id = $1::uuid
+  AND revoked_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListActiveSessionIDs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listActiveSessionIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUser = `-- name: LockUser :one
+SELECT id
+FROM users
 WHERE id = $1::uuid
+FOR UPDATE
 `
 
-type MarkEmailVerifiedParams struct {
-	Column1         pgtype.UUID        `db:"column_1" json:"column_1"`
-	EmailVerifiedAt pgtype.Timestamptz `db:"email_verified_at" json:"email_verified_at"`
+func (q *Queries) LockUser(ctx context.Context, userID string) (string, error) {
+	row := q.db.QueryRow(ctx, lockUser, userID)
+	var id string
+	err := row.Scan(&id)
+	return id, err
 }
 
-func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) error {
-	_, err := q.db.Exec(ctx, markEmailVerified, arg.Column1, arg.EmailVerifiedAt)
-	return err
+const purgeArchivedSessions = `-- name: PurgeArchivedSessions :execrows
+DELETE FROM sessions_archive
+WHERE id IN (
+  SELECT a.id FROM sessions_archive a
+  WHERE a.archived_at < $1
+  LIMIT $2
+)
+`
+
+type PurgeArchivedSessionsParams struct {
+	Cutoff    time.Time `db:"cutoff" json:"cutoff"`
+	BatchSize int32     `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) PurgeArchivedSessions(ctx context.Context, arg PurgeArchivedSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeArchivedSessions, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const revokeSessionByTokenHash = `-- name: RevokeSessionByTokenHash :exec
+const revokeSessions = `-- name: RevokeSessions :execrows
 UPDATE sessions
-SET revoked_at = $2
-WHERE token_hash = $1
+SET revoked_at = now()
+WHERE id = ANY($1::uuid[])
   AND revoked_at IS NULL
 `
 
-type RevokeSessionByTokenHashParams struct {
-	TokenHash []byte             `db:"token_hash" json:"token_hash"`
-	RevokedAt pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+func (q *Queries) RevokeSessions(ctx context.Context, ids []string) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeSessions, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const sessionActive = `-- name: SessionActive :one
+SELECT EXISTS (
+  SELECT 1
+  FROM sessions
+  WHERE id = $1::uuid
+    AND user_id = $2::uuid
+    AND revoked_at IS NULL
+    AND expires_at > now()
+)
+`
+
+type SessionActiveParams struct {
+	ID     string `db:"id" json:"id"`
+	UserID string `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) SessionActive(ctx context.Context, arg SessionActiveParams) (bool, error) {
+	row := q.db.QueryRow(ctx, sessionActive, arg.ID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
 }
 
-func (q *Queries) RevokeSessionByTokenHash(ctx context.Context, arg RevokeSessionByTokenHashParams) error {
-	_, err := q.db.Exec(ctx, revokeSessionByTokenHash, arg.TokenHash, arg.RevokedAt)
-	return err
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET email = $1,
+    password_hash = $2,
+    version = version + 1,
+    updated_at = now()
+WHERE id = $3::uuid
+  AND version = $4
+RETURNING id, email, password_hash, created_at, updated_at, version
+`
+
+type UpdateUserParams struct {
+	Email        string `db:"email" json:"email"`
+	PasswordHash string `db:"password_hash" json:"password_hash"`
+	ID           string `db:"id" json:"id"`
+	Version      int64  `db:"version" json:"version"`
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUser,
+		arg.Email,
+		arg.PasswordHash,
+		arg.ID,
+		arg.Version,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
 }
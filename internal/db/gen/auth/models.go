@@ -5,33 +5,42 @@
 package authdb
 
 import (
+	"net/netip"
+	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type EmailVerification struct {
-	ID         pgtype.UUID        `db:"id" json:"id"`
-	UserID     pgtype.UUID        `db:"user_id" json:"user_id"`
-	TokenHash  []byte             `db:"token_hash" json:"token_hash"`
-	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	ExpiresAt  pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
-	ConsumedAt pgtype.Timestamptz `db:"consumed_at" json:"consumed_at"`
+type Session struct {
+	ID               string             `db:"id" json:"id"`
+	UserID           string             `db:"user_id" json:"user_id"`
+	RefreshTokenHash []byte             `db:"refresh_token_hash" json:"refresh_token_hash"`
+	CreatedAt        time.Time          `db:"created_at" json:"created_at"`
+	ExpiresAt        time.Time          `db:"expires_at" json:"expires_at"`
+	RevokedAt        pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+	UserAgent        pgtype.Text        `db:"user_agent" json:"user_agent"`
+	Ip               *netip.Addr        `db:"ip" json:"ip"`
+	RotatedFrom      pgtype.UUID        `db:"rotated_from" json:"rotated_from"`
 }
 
-type Session struct {
-	ID         pgtype.UUID        `db:"id" json:"id"`
-	UserID     pgtype.UUID        `db:"user_id" json:"user_id"`
-	TokenHash  []byte             `db:"token_hash" json:"token_hash"`
-	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	ExpiresAt  pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
-	RevokedAt  pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
-	LastSeenAt pgtype.Timestamptz `db:"last_seen_at" json:"last_seen_at"`
+type SessionsArchive struct {
+	ID               string             `db:"id" json:"id"`
+	UserID           string             `db:"user_id" json:"user_id"`
+	RefreshTokenHash []byte             `db:"refresh_token_hash" json:"refresh_token_hash"`
+	CreatedAt        time.Time          `db:"created_at" json:"created_at"`
+	ExpiresAt        time.Time          `db:"expires_at" json:"expires_at"`
+	RevokedAt        pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+	UserAgent        pgtype.Text        `db:"user_agent" json:"user_agent"`
+	Ip               *netip.Addr        `db:"ip" json:"ip"`
+	RotatedFrom      pgtype.UUID        `db:"rotated_from" json:"rotated_from"`
+	ArchivedAt       time.Time          `db:"archived_at" json:"archived_at"`
 }
 
 type User struct {
-	ID              pgtype.UUID        `db:"id" json:"id"`
-	Email           string             `db:"email" json:"email"`
-	PasswordHash    []byte             `db:"password_hash" json:"password_hash"`
-	EmailVerifiedAt pgtype.Timestamptz `db:"email_verified_at" json:"email_verified_at"`
-	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ID           string    `db:"id" json:"id"`
+	Email        string    `db:"email" json:"email"`
+	PasswordHash string    `db:"password_hash" json:"password_hash"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	Version      int64     `db:"version" json:"version"`
 }
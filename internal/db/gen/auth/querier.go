@@ -6,20 +6,20 @@ package authdb
 
 import (
 	"context"
-
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
-	ConsumeEmailVerification(ctx context.Context, arg ConsumeEmailVerificationParams) (string, error)
-	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) error
-	CreateSession(ctx context.Context, arg CreateSessionParams) error
-	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
-	GetSessionUserIDByTokenHash(ctx context.Context, arg GetSessionUserIDByTokenHashParams) (string, error)
-	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
-	GetUserByID(ctx context.Context, dollar_1 pgtype.UUID) (GetUserByIDRow, error)
-	MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) error
-	RevokeSessionByTokenHash(ctx context.Context, arg RevokeSessionByTokenHashParams) error
+	ArchiveSessions(ctx context.Context, arg ArchiveSessionsParams) (int64, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id string) (User, error)
+	InsertSession(ctx context.Context, arg InsertSessionParams) (string, error)
+	ListActiveSessionIDs(ctx context.Context, userID string) ([]string, error)
+	LockUser(ctx context.Context, userID string) (string, error)
+	PurgeArchivedSessions(ctx context.Context, arg PurgeArchivedSessionsParams) (int64, error)
+	RevokeSessions(ctx context.Context, ids []string) (int64, error)
+	SessionActive(ctx context.Context, arg SessionActiveParams) (bool, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRDSAuthToken(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET", SessionToken: "tok/en+"}
+	tok := rdsAuthToken("db.example.com", 5432, "us-east-1", "app user", c, now)
+
+	host, rawQuery, ok := strings.Cut(tok, "/?")
+	if !ok || host != "db.example.com:5432" {
+		t.Fatalf("token %q: want host:port/?query", tok)
+	}
+	if strings.Contains(rawQuery, "+") {
+		t.Fatalf("query %q: spaces must be %%20, not +", rawQuery)
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{
+		"Action":               "connect",
+		"DBUser":               "app user",
+		"X-Amz-Credential":     "AKID/20240301/us-east-1/rds-db/aws4_request",
+		"X-Amz-Date":           "20240301T120000Z",
+		"X-Amz-Expires":        "900",
+		"X-Amz-SignedHeaders":  "host",
+		"X-Amz-Security-Token": "tok/en+",
+	} {
+		if got := q.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+	if sig := q.Get("X-Amz-Signature"); len(sig) != 64 {
+		t.Fatalf("signature %q: want 64 hex chars", sig)
+	}
+
+	if again := rdsAuthToken("db.example.com", 5432, "us-east-1", "app user", c, now); again != tok {
+		t.Fatal("token not deterministic")
+	}
+	c.SecretAccessKey = "OTHER"
+	if other := rdsAuthToken("db.example.com", 5432, "us-east-1", "app user", c, now); other == tok {
+		t.Fatal("signature does not depend on the secret key")
+	}
+}
+
+func TestCloudSQLIAMTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"ya29.x","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer srv.Close()
+
+	tok, exp, err := CloudSQLIAMTokens(srv.URL)(context.Background(), "h", 5432, "u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok != "ya29.x" {
+		t.Fatalf("token = %q", tok)
+	}
+	if d := time.Until(exp); d < 59*time.Minute || d > time.Hour {
+		t.Fatalf("expiry in %v, want ~1h", d)
+	}
+}
+
+func TestCachedTokens(t *testing.T) {
+	var calls atomic.Int32
+	ttl := time.Hour
+	src := func(ctx context.Context, host string, port uint16, user string) (string, time.Time, error) {
+		n := calls.Add(1)
+		return host + "-" + string(rune('0'+n)), time.Now().Add(ttl), nil
+	}
+	p := CachedTokens(src, time.Minute)
+	ctx := context.Background()
+
+	a, _ := p.Password(ctx, "a", 5432, "u")
+	a2, _ := p.Password(ctx, "a", 5432, "u")
+	if a != a2 || calls.Load() != 1 {
+		t.Fatalf("cached token refetched: %q %q (%d calls)", a, a2, calls.Load())
+	}
+	if b, _ := p.Password(ctx, "b", 5432, "u"); b == a {
+		t.Fatal("tokens shared across endpoints")
+	}
+
+	// Near expiry: the old token is served while a refresh runs.
+	tc := p.(*tokenCache)
+	tc.mu.Lock()
+	tc.tokens["u@a:5432"].expiry = time.Now().Add(30 * time.Second)
+	tc.mu.Unlock()
+	if got, _ := p.Password(ctx, "a", 5432, "u"); got != a {
+		t.Fatalf("got %q, want still-valid %q", got, a)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, _ := p.Password(ctx, "a", 5432, "u")
+		if got != a {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("token not refreshed in background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Expired: fetched inline.
+	tc.mu.Lock()
+	tc.tokens["u@a:5432"].expiry = time.Now().Add(-time.Second)
+	tc.mu.Unlock()
+	before := calls.Load()
+	if _, err := p.Password(ctx, "a", 5432, "u"); err != nil || calls.Load() != before+1 {
+		t.Fatalf("expired token not refetched inline (err=%v)", err)
+	}
+}
+
+func TestTLSOptionsConfig(t *testing.T) {
+	if (TLSOptions{}).enabled() {
+		t.Fatal("zero TLSOptions enabled")
+	}
+	cfg, err := TLSOptions{ServerName: "db.internal"}.config("10.0.0.1")
+	if err != nil || cfg.ServerName != "db.internal" {
+		t.Fatalf("config = %+v, %v", cfg, err)
+	}
+	if _, err := (TLSOptions{CertFile: "c.pem"}).config("h"); err == nil {
+		t.Fatal("want error for cert without key")
+	}
+	if _, err := (TLSOptions{RootCAFile: "/nonexistent/ca.pem"}).config("h"); err == nil {
+		t.Fatal("want error for missing CA file")
+	}
+}
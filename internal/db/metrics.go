@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type MetricsOptions struct {
+	// Service names the meter and is reported as db.client.connection.pool.name.
+	Service string
+	// Interval is how often acquire waits are sampled into the wait_time
+	// histogram (default 10s). Gauges and counters are read at collection time.
+	Interval time.Duration
+}
+
+// poolStats is the subset of pgxpool.Stat that is exported.
+type poolStats struct {
+	acquired, idle, max, constructing             int64
+	acquireCount, canceledAcquires, emptyAcquires int64
+	acquireDuration                               time.Duration
+}
+
+func statsOf(s *pgxpool.Stat) poolStats {
+	return poolStats{
+		acquired:         int64(s.AcquiredConns()),
+		idle:             int64(s.IdleConns()),
+		max:              int64(s.MaxConns()),
+		constructing:     int64(s.ConstructingConns()),
+		acquireCount:     s.AcquireCount(),
+		canceledAcquires: s.CanceledAcquireCount(),
+		emptyAcquires:    s.EmptyAcquireCount(),
+		acquireDuration:  s.AcquireDuration(),
+	}
+}
+
+// Metrics exports pool saturation as OTel metrics, so an exhausted pool shows up
+// before queries start timing out:
+//
+//   - db.client.connection.count{state=used|idle}, .max and .pending (being dialed)
+//   - db.client.connection.acquires, .canceled_acquires and .empty_acquires
+//     (acquires that had to wait for a connection)
+//   - db.client.connection.wait_time: mean acquire wait per sampling interval
+//
+// The returned stop function unregisters the instruments and ends sampling; call
+// it before closing the pool.
+func Metrics(pool *pgxpool.Pool, opts MetricsOptions) (stop func(), err error) {
+	if pool == nil {
+		return nil, errors.New("db: nil pool")
+	}
+	return exportMetrics(func() poolStats { return statsOf(pool.Stat()) }, opts)
+}
+
+func exportMetrics(stat func() poolStats, opts MetricsOptions) (func(), error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	m := otel.Meter("sdk-microservices/" + opts.Service)
+	pool := attribute.String("db.client.connection.pool.name", opts.Service)
+
+	count, err := m.Int64ObservableUpDownCounter("db.client.connection.count",
+		metric.WithDescription("Connections in the pool by state"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return nil, err
+	}
+	maxConns, err := m.Int64ObservableUpDownCounter("db.client.connection.max",
+		metric.WithDescription("Maximum connections the pool allows"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return nil, err
+	}
+	pending, err := m.Int64ObservableUpDownCounter("db.client.connection.pending",
+		metric.WithDescription("Connections currently being established"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return nil, err
+	}
+	acquires, err := m.Int64ObservableCounter("db.client.connection.acquires",
+		metric.WithDescription("Successful connection acquires"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return nil, err
+	}
+	canceled, err := m.Int64ObservableCounter("db.client.connection.canceled_acquires",
+		metric.WithDescription("Acquires abandoned because their context ended"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return nil, err
+	}
+	empty, err := m.Int64ObservableCounter("db.client.connection.empty_acquires",
+		metric.WithDescription("Acquires that waited because no idle connection was available"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return nil, err
+	}
+	waitTime, err := m.Float64Histogram("db.client.connection.wait_time",
+		metric.WithDescription("Mean time to acquire a connection, sampled per interval"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	used := metric.WithAttributes(pool, attribute.String("state", "used"))
+	idle := metric.WithAttributes(pool, attribute.String("state", "idle"))
+	attrs := metric.WithAttributes(pool)
+
+	reg, err := m.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := stat()
+		o.ObserveInt64(count, s.acquired, used)
+		o.ObserveInt64(count, s.idle, idle)
+		o.ObserveInt64(maxConns, s.max, attrs)
+		o.ObserveInt64(pending, s.constructing, attrs)
+		o.ObserveInt64(acquires, s.acquireCount, attrs)
+		o.ObserveInt64(canceled, s.canceledAcquires, attrs)
+		o.ObserveInt64(empty, s.emptyAcquires, attrs)
+		return nil
+	}, count, maxConns, pending, acquires, canceled, empty)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(opts.Interval)
+		defer t.Stop()
+		prev := stat()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			cur := stat()
+			if wait, ok := meanAcquireWait(prev, cur); ok {
+				waitTime.Record(context.Background(), wait.Seconds(), attrs)
+			}
+			prev = cur
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			_ = reg.Unregister()
+		})
+	}, nil
+}
+
+// meanAcquireWait is the average acquire duration between two samples; ok is
+// false when no connections were acquired in between.
+func meanAcquireWait(prev, cur poolStats) (time.Duration, bool) {
+	n := cur.acquireCount - prev.acquireCount
+	if n <= 0 {
+		return 0, false
+	}
+	return (cur.acquireDuration - prev.acquireDuration) / time.Duration(n), true
+}
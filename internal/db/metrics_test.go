@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMeanAcquireWait(t *testing.T) {
+	prev := poolStats{acquireCount: 10, acquireDuration: time.Second}
+	cur := poolStats{acquireCount: 14, acquireDuration: 3 * time.Second}
+	if got, ok := meanAcquireWait(prev, cur); !ok || got != 500*time.Millisecond {
+		t.Fatalf("meanAcquireWait = %v, %v; want 500ms, true", got, ok)
+	}
+	if _, ok := meanAcquireWait(cur, cur); ok {
+		t.Fatalf("expected no sample without new acquires")
+	}
+}
+
+func TestExportMetrics_ObservesPoolStats(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(prev) })
+
+	stop, err := exportMetrics(func() poolStats {
+		return poolStats{acquired: 3, idle: 2, max: 8, canceledAcquires: 4}
+	}, MetricsOptions{Service: "test", Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			d, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range d.DataPoints {
+				name := m.Name
+				if state, ok := dp.Attributes.Value("state"); ok {
+					name += "/" + state.AsString()
+				}
+				got[name] = dp.Value
+			}
+		}
+	}
+	want := map[string]int64{
+		"db.client.connection.count/used":        3,
+		"db.client.connection.count/idle":        2,
+		"db.client.connection.max":               8,
+		"db.client.connection.canceled_acquires": 4,
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %d, want %d", k, got[k], v)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Package migrate applies the versioned SQL migrations under migrations/<service>
+// (NNN_name.up.sql). Progress is tracked in a golang-migrate compatible
+// schema_migrations table, so databases migrated with either tool stay in sync.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sdk-microservices/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultTable is the version table shared with golang-migrate.
+const DefaultTable = "schema_migrations"
+
+// ErrDirty means a previous migration failed halfway; fix the schema by hand and
+// then clear the dirty flag (update schema_migrations set dirty = false).
+var ErrDirty = errors.New("migrate: database is dirty")
+
+// ErrNoDown is returned by Down for a migration without a .down.sql file. Down
+// migrations are banned by policy (see MIGRATIONS.md): ship a forward fix instead.
+var ErrNoDown = errors.New("migrate: no down migration")
+
+// ErrPending means the database has not yet reached the latest known migration.
+var ErrPending = errors.New("migrate: migrations pending")
+
+// Migration is one version: its up SQL and, if present, its down SQL.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+var fileRE = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in the root of fsys, ordered by version. Files that
+// don't match NNN_name.(up|down).sql are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	ents, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read dir: %w", err)
+	}
+	byVersion := map[uint64]*Migration{}
+	for _, e := range ents {
+		m := fileRE.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		v, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s: %w", e.Name(), err)
+		}
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+		mig := byVersion[v]
+		if mig == nil {
+			mig = &Migration{Version: v, Name: m[2]}
+			byVersion[v] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrate: version %d used by %q and %q", v, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(b)
+		} else {
+			mig.Down = string(b)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: version %d (%s) has no up migration", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Migrator applies migrations to one database.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	table      string
+	schema     string
+}
+
+// New returns a Migrator for the migrations in fsys (see Load).
+func New(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	if pool == nil {
+		return nil, errors.New("migrate: nil pool")
+	}
+	migs, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migs, table: DefaultTable}, nil
+}
+
+// InSchema returns a Migrator that applies the migrations inside schema,
+// creating it if needed, with its own version table there. Use it to migrate
+// each tenant schema of a schema-per-tenant deployment (see db.TenantOptions).
+func (m *Migrator) InSchema(schema string) *Migrator {
+	c := *m
+	c.schema = schema
+	return &c
+}
+
+// Status is the state of the database relative to the known migrations.
+type Status struct {
+	// Version is the last applied version (0 if none).
+	Version uint64
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Status reports the current version and which migrations are pending.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var st Status
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		var err error
+		st.Version, st.Dirty, err = m.version(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return Status{}, err
+	}
+	for _, mig := range m.migrations {
+		if mig.Version <= st.Version {
+			st.Applied = append(st.Applied, mig)
+		} else {
+			st.Pending = append(st.Pending, mig)
+		}
+	}
+	return st, nil
+}
+
+// Current reports whether every known migration has been applied, returning
+// ErrPending (or ErrDirty) if not. Unlike Status it takes no lock and creates
+// nothing, so services can poll it at startup with a DML-only role.
+func (m *Migrator) Current(ctx context.Context) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: acquire: %w", err)
+	}
+	defer conn.Release()
+
+	version, dirty, err := m.version(ctx, conn)
+	switch {
+	case err != nil:
+		return err
+	case dirty:
+		return ErrDirty
+	}
+	if n := len(m.migrations); n > 0 && m.migrations[n-1].Version > version {
+		return fmt.Errorf("%w: at version %d, want %d", ErrPending, version, m.migrations[n-1].Version)
+	}
+	return nil
+}
+
+// Up applies every pending migration in order and returns those applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		cur, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, cur)
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= cur {
+				continue
+			}
+			if err := m.run(ctx, conn, mig.Version, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migrate: %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last n applied migrations and returns those reverted. It stops
+// with ErrNoDown at the first one without down SQL.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		cur, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, cur)
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < n; i-- {
+			mig := m.migrations[i]
+			if mig.Version > cur {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%w for %d_%s", ErrNoDown, mig.Version, mig.Name)
+			}
+			var prev uint64
+			if i > 0 {
+				prev = m.migrations[i-1].Version
+			}
+			if err := m.run(ctx, conn, mig.Version, mig.Down, prev); err != nil {
+				return fmt.Errorf("migrate: down %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// run marks version dirty, executes sql and records to (0 clears the table). Like
+// golang-migrate, sql is not wrapped in a transaction (so CREATE INDEX
+// CONCURRENTLY works); a failure leaves the database dirty.
+func (m *Migrator) run(ctx context.Context, conn *pgxpool.Conn, version uint64, sql string, to uint64) error {
+	if err := m.setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	// No arguments: pgx uses the simple protocol, which allows multiple statements.
+	if _, err := conn.Exec(ctx, sql); err != nil {
+		return err
+	}
+	if to == 0 {
+		_, err := conn.Exec(ctx, "DELETE FROM "+m.ident())
+		return err
+	}
+	return m.setVersion(ctx, conn, to, false)
+}
+
+func (m *Migrator) setVersion(ctx context.Context, conn *pgxpool.Conn, version uint64, dirty bool) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM "+m.ident()); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO "+m.ident()+" (version, dirty) VALUES ($1, $2)", int64(version), dirty)
+		return err
+	})
+}
+
+func (m *Migrator) version(ctx context.Context, conn *pgxpool.Conn) (version uint64, dirty bool, err error) {
+	var v int64
+	err = conn.QueryRow(ctx, "SELECT version, dirty FROM "+m.ident()+" LIMIT 1").Scan(&v, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("migrate: read version: %w", err)
+	}
+	return uint64(v), dirty, nil
+}
+
+// withLock runs fn on one connection holding a session advisory lock, so
+// concurrent migrators (e.g. several replicas starting) apply each version once.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: acquire: %w", err)
+	}
+	defer conn.Release()
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("migrate:" + m.schema + "." + m.table))
+	key := int64(h.Sum64())
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return fmt.Errorf("migrate: lock: %w", err)
+	}
+	defer func() { _, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key) }()
+
+	// Long statements are fine, waiting on locks held by live traffic is not.
+	if err := db.SetTimeouts(ctx, conn, db.Migration, false); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	defer func() { _ = db.ResetTimeouts(context.WithoutCancel(ctx), conn) }()
+
+	if m.schema != "" {
+		// Unqualified names in the migrations resolve to the schema.
+		if _, err := conn.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS "+pgx.Identifier{m.schema}.Sanitize()); err != nil {
+			return fmt.Errorf("migrate: create schema: %w", err)
+		}
+		if _, err := conn.Exec(ctx, "SELECT pg_catalog.set_config('search_path', $1, false)", pgx.Identifier{m.schema}.Sanitize()); err != nil {
+			return fmt.Errorf("migrate: set search_path: %w", err)
+		}
+		defer func() { _, _ = conn.Exec(context.WithoutCancel(ctx), "RESET search_path") }()
+	}
+	if _, err := conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+m.ident()+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"); err != nil {
+		return fmt.Errorf("migrate: create %s: %w", m.table, err)
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) ident() string {
+	if m.schema != "" {
+		return pgx.Identifier{m.schema, m.table}.Sanitize()
+	}
+	return pgx.Identifier{m.table}.Sanitize()
+}
+
+var nameRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty NNN_name.up.sql in dir, numbered after the highest
+// existing version, and returns its path. No down file is created (see
+// MIGRATIONS.md).
+func Create(dir, name string) (string, error) {
+	slug := strings.Trim(nameRE.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", fmt.Errorf("migrate: invalid name %q", name)
+	}
+	migs, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", err
+	}
+	var next uint64 = 1
+	if len(migs) > 0 {
+		next = migs[len(migs)-1].Version + 1
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%03d_%s.up.sql", next, slug))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("migrate: %w", err)
+	}
+	_, err = fmt.Fprintf(f, "-- %s\n", name)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return path, err
+}
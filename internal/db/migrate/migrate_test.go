@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"sdk-microservices/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_name.up.sql":   {Data: []byte("ALTER TABLE users ADD COLUMN name text;")},
+		"002_add_name.down.sql": {Data: []byte("ALTER TABLE users DROP COLUMN name;")},
+		"001_init.up.sql":       {Data: []byte("CREATE TABLE users (id int);")},
+		"README.md":             {Data: []byte("ignored")},
+	}
+	migs, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migs) != 2 || migs[0].Version != 1 || migs[1].Version != 2 {
+		t.Fatalf("migs = %+v", migs)
+	}
+	if migs[0].Down != "" || migs[1].Down == "" || migs[1].Name != "add_name" {
+		t.Fatalf("migs = %+v", migs)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"down only": {"001_init.down.sql": {}},
+		"name clash": {
+			"001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"001_b.up.sql": {Data: []byte("SELECT 1;")},
+		},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	fsys, err := migrations.For("auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migs, err := Load(fsys)
+	if err != nil || len(migs) == 0 {
+		t.Fatalf("auth migrations: %v (%d)", err, len(migs))
+	}
+	if _, err := migrations.For("nope"); err == nil {
+		t.Fatal("expected error for unknown service")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "007_init.up.sql"), []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := Create(dir, "Add Users Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "008_add_users_name.up.sql" {
+		t.Fatalf("path = %s", path)
+	}
+	if _, err := Create(dir, "!!!"); err == nil {
+		t.Fatal("expected error for empty name")
+	}
+}
@@ -0,0 +1,123 @@
+// Package pagination implements OFFSET-free keyset pagination: the last row of a
+// page becomes an opaque token, and the next page is "rows after that key" in the
+// same order, which stays fast and stable however deep the client pages.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultSize = 50
+	MaxSize     = 500
+)
+
+// ErrInvalidToken is returned for page tokens that weren't produced by Encode (or
+// belong to a different listing).
+var ErrInvalidToken = errors.New("pagination: invalid page token")
+
+// Size clamps a client-requested page size to [1, MaxSize], with 0 meaning
+// DefaultSize.
+func Size(n int) int {
+	switch {
+	case n <= 0:
+		return DefaultSize
+	case n > MaxSize:
+		return MaxSize
+	default:
+		return n
+	}
+}
+
+// Encode returns an opaque page token for cursor, typically a small struct with
+// the ordering columns of the last row returned.
+func Encode(cursor any) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("pagination: encode: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Decode parses token into cursor. An empty token leaves cursor untouched and
+// returns false (first page).
+func Decode(token string, cursor any) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return false, ErrInvalidToken
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cursor); err != nil {
+		return false, ErrInvalidToken
+	}
+	return true, nil
+}
+
+// Order is one column of a listing's sort order. The columns together must be
+// unique (end with the primary key) for pages not to skip or repeat rows.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// OrderBy returns the ORDER BY list for order, e.g. "created_at DESC, id DESC".
+func OrderBy(order []Order) string {
+	parts := make([]string, len(order))
+	for i, o := range order {
+		parts[i] = o.Column
+		if o.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// After returns a WHERE condition selecting rows strictly after the key values
+// (one per order column) in that order, with placeholders numbered from
+// firstArg. When all columns sort the same way it uses a row comparison, which
+// Postgres can satisfy with a matching composite index.
+func After(order []Order, values []any, firstArg int) (string, []any) {
+	if len(order) != len(values) {
+		panic("pagination: After needs one value per order column")
+	}
+	cmp := func(o Order) string {
+		if o.Desc {
+			return "<"
+		}
+		return ">"
+	}
+	ph := func(i int) string { return fmt.Sprintf("$%d", firstArg+i) }
+
+	sameDir := true
+	for _, o := range order[1:] {
+		sameDir = sameDir && o.Desc == order[0].Desc
+	}
+	if sameDir {
+		cols := make([]string, len(order))
+		phs := make([]string, len(order))
+		for i, o := range order {
+			cols[i], phs[i] = o.Column, ph(i)
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), cmp(order[0]), strings.Join(phs, ", ")), values
+	}
+
+	// Mixed directions: (a > $1) OR (a = $1 AND b < $2) OR ...
+	ors := make([]string, len(order))
+	for i, o := range order {
+		ands := make([]string, 0, i+1)
+		for j := range i {
+			ands = append(ands, fmt.Sprintf("%s = %s", order[j].Column, ph(j)))
+		}
+		ands = append(ands, fmt.Sprintf("%s %s %s", o.Column, cmp(o), ph(i)))
+		ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", values
+}
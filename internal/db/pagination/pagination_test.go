@@ -0,0 +1,58 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	in := cursor{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC), ID: "abc"}
+	tok, err := Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out cursor
+	if ok, err := Decode(tok, &out); err != nil || !ok || !out.CreatedAt.Equal(in.CreatedAt) || out.ID != in.ID {
+		t.Fatalf("Decode = %+v, %v, %v", out, ok, err)
+	}
+
+	if ok, err := Decode("", &out); ok || err != nil {
+		t.Fatalf("empty token: ok=%v err=%v", ok, err)
+	}
+	for _, bad := range []string{"%%%", "bm90IGpzb24", "eyJ4IjoxfQ"} { // not base64, not JSON, unknown field
+		if _, err := Decode(bad, &out); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Decode(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func TestAfter(t *testing.T) {
+	desc := []Order{{"created_at", true}, {"id", true}}
+	where, args := After(desc, []any{1, 2}, 3)
+	if where != "(created_at, id) < ($3, $4)" || len(args) != 2 {
+		t.Fatalf("same direction: %q %v", where, args)
+	}
+	if ob := OrderBy(desc); ob != "created_at DESC, id DESC" {
+		t.Fatalf("OrderBy = %q", ob)
+	}
+
+	mixed := []Order{{"email", false}, {"id", true}}
+	where, _ = After(mixed, []any{"a", "b"}, 1)
+	if want := "((email > $1) OR (email = $1 AND id < $2))"; where != want {
+		t.Fatalf("mixed = %q, want %q", where, want)
+	}
+}
+
+func TestSize(t *testing.T) {
+	for in, want := range map[int]int{0: DefaultSize, -1: DefaultSize, 10: 10, 10000: MaxSize} {
+		if got := Size(in); got != want {
+			t.Errorf("Size(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PasswordProvider supplies the password for each new connection, e.g. a
+// short-lived cloud IAM token. It is called with the endpoint being dialed, so
+// one provider serves a primary and its replicas.
+type PasswordProvider interface {
+	Password(ctx context.Context, host string, port uint16, user string) (string, error)
+}
+
+// PasswordFunc adapts a function to PasswordProvider, e.g. one returning a
+// rotating secret's current value.
+type PasswordFunc func(ctx context.Context, host string, port uint16, user string) (string, error)
+
+func (f PasswordFunc) Password(ctx context.Context, host string, port uint16, user string) (string, error) {
+	return f(ctx, host, port, user)
+}
+
+// TokenSource fetches a token for one endpoint and reports when it expires.
+type TokenSource func(ctx context.Context, host string, port uint16, user string) (token string, expiry time.Time, err error)
+
+// CachedTokens returns a PasswordProvider that caches src's tokens per endpoint.
+// A token within refreshBefore of expiry (default 1m) is still used while a
+// replacement is fetched in the background; an expired one is refetched inline.
+func CachedTokens(src TokenSource, refreshBefore time.Duration) PasswordProvider {
+	if refreshBefore <= 0 {
+		refreshBefore = time.Minute
+	}
+	return &tokenCache{src: src, refreshBefore: refreshBefore, tokens: map[string]*cachedToken{}}
+}
+
+type tokenCache struct {
+	src           TokenSource
+	refreshBefore time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+}
+
+type cachedToken struct {
+	token      string
+	expiry     time.Time
+	refreshing bool
+}
+
+func (c *tokenCache) Password(ctx context.Context, host string, port uint16, user string) (string, error) {
+	key := user + "@" + host + ":" + strconv.Itoa(int(port))
+	now := time.Now()
+
+	c.mu.Lock()
+	t := c.tokens[key]
+	if t != nil && now.Before(t.expiry) {
+		if !t.refreshing && now.After(t.expiry.Add(-c.refreshBefore)) {
+			t.refreshing = true
+			go c.refresh(key, host, port, user)
+		}
+		tok := t.token
+		c.mu.Unlock()
+		return tok, nil
+	}
+	c.mu.Unlock()
+
+	tok, exp, err := c.src(ctx, host, port, user)
+	if err != nil {
+		return "", fmt.Errorf("db: fetch token: %w", err)
+	}
+	c.mu.Lock()
+	c.tokens[key] = &cachedToken{token: tok, expiry: exp}
+	c.mu.Unlock()
+	return tok, nil
+}
+
+func (c *tokenCache) refresh(key, host string, port uint16, user string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	tok, exp, err := c.src(ctx, host, port, user)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.tokens[key]
+	if err != nil {
+		// Keep the current token; the next Password call retries.
+		if t != nil {
+			t.refreshing = false
+		}
+		return
+	}
+	c.tokens[key] = &cachedToken{token: tok, expiry: exp}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -23,6 +25,33 @@ type Options struct {
 
 	// Startup readiness check
 	InitialPingTimeout time.Duration
+
+	// StatementTimeout and LockTimeout are the connections' default
+	// statement_timeout/lock_timeout (zero keeps the server's). Transactions can
+	// override them per QueryClass (see WithQueryClass).
+	StatementTimeout time.Duration
+	LockTimeout      time.Duration
+
+	// TLS overrides the DSN's certificate settings (see TLSOptions).
+	TLS TLSOptions
+
+	// Password, if set, supplies the password for every new connection instead
+	// of the DSN's, e.g. CachedTokens(RDSIAMTokens(...)).
+	Password PasswordProvider
+
+	// Tenancy, if set, scopes every connection to the acquiring context's tenant
+	// schema (see TenantOptions).
+	Tenancy *TenantOptions
+
+	// Failover, if set, makes the pool recover from a primary failover on its
+	// own (see FailoverOptions).
+	Failover *FailoverOptions
+
+	// Hooks are extra connection lifecycle callbacks.
+	Hooks Hooks
+
+	// DisableTracing turns off the per-query spans (see queryTracer).
+	DisableTracing bool
 }
 
 func (o Options) withDefaults() Options {
@@ -71,11 +100,111 @@ func NewPool(ctx context.Context, dsn string, opts Options) (*pgxpool.Pool, erro
 	if opts.HealthCheckPeriod > 0 {
 		cfg.HealthCheckPeriod = opts.HealthCheckPeriod
 	}
+	if opts.StatementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = pgDuration(opts.StatementTimeout)
+	}
+	if opts.LockTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["lock_timeout"] = pgDuration(opts.LockTimeout)
+	}
+	if opts.TLS.enabled() {
+		tc, err := opts.TLS.config(cfg.ConnConfig.Host)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ConnConfig.TLSConfig = tc
+		// Keep the other hosts of a multi-host DSN (failover), but not the
+		// plaintext fallbacks of sslmode=prefer/allow, which would silently drop
+		// the TLS we asked for.
+		var fbs []*pgconn.FallbackConfig
+		for _, fb := range cfg.ConnConfig.Fallbacks {
+			if fb.TLSConfig == nil {
+				continue
+			}
+			if fb.TLSConfig, err = opts.TLS.config(fb.Host); err != nil {
+				return nil, err
+			}
+			fbs = append(fbs, fb)
+		}
+		cfg.ConnConfig.Fallbacks = fbs
+	}
+	// Connection hooks: the failover gate, then password, tenancy, failover
+	// tracking and finally the caller's.
+	var (
+		beforeConnect []func(context.Context, *pgx.ConnConfig) error
+		afterConnect  []func(context.Context, *pgx.Conn) error
+		beforeClose   []func(*pgx.Conn)
+	)
+	if opts.Password != nil {
+		pw := opts.Password
+		beforeConnect = append(beforeConnect, func(ctx context.Context, cc *pgx.ConnConfig) error {
+			p, err := pw.Password(ctx, cc.Host, cc.Port, cc.User)
+			if err != nil {
+				return err
+			}
+			cc.Password = p
+			return nil
+		})
+	}
+	if opts.Tenancy != nil {
+		t := newTenancy(*opts.Tenancy)
+		cfg.PrepareConn = t.prepare
+		beforeClose = append(beforeClose, t.forget)
+	}
+	if !opts.DisableTracing {
+		cfg.ConnConfig.Tracer = newQueryTracer()
+	}
+	var brk *breaker
+	if opts.Failover != nil {
+		brk = newBreaker(*opts.Failover)
+		if opts.Failover.ReadWrite && cfg.ConnConfig.ValidateConnect == nil {
+			cfg.ConnConfig.ValidateConnect = pgconn.ValidateConnectTargetSessionAttrsReadWrite
+		}
+		// The breaker goes first so a probe slot isn't spent on, e.g., a token fetch failure.
+		beforeConnect = append([]func(context.Context, *pgx.ConnConfig) error{brk.beforeConnect}, beforeConnect...)
+		afterConnect = append(afterConnect, brk.afterConnect)
+		cfg.ConnConfig.Tracer = &failoverTracer{next: cfg.ConnConfig.Tracer, b: brk}
+	}
+	if opts.Hooks.AfterConnect != nil {
+		afterConnect = append(afterConnect, opts.Hooks.AfterConnect)
+	}
+	if opts.Hooks.BeforeClose != nil {
+		beforeClose = append(beforeClose, opts.Hooks.BeforeClose)
+	}
+	if len(beforeConnect) > 0 {
+		cfg.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+			for _, f := range beforeConnect {
+				if err := f(ctx, cc); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if len(afterConnect) > 0 {
+		cfg.AfterConnect = func(ctx context.Context, c *pgx.Conn) error {
+			for _, f := range afterConnect {
+				if err := f(ctx, c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if len(beforeClose) > 0 {
+		cfg.BeforeClose = func(c *pgx.Conn) {
+			for _, f := range beforeClose {
+				f(c)
+			}
+		}
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("db: create pool: %w", err)
 	}
+	if brk != nil {
+		brk.pool.Store(pool)
+	}
 
 	    // Initial ping: keeps "fail fast" behavior on bad DSN/auth, but also
     // tolerates slow container/compose startups by retrying until the timeout.
@@ -1,49 +1,85 @@
+-- Static queries for the auth store. Keyset-paginated listings (ListUsers,
+-- ListSessions) build their WHERE clause at runtime and stay in the store.
+
 -- name: CreateUser :one
 INSERT INTO users (email, password_hash)
 VALUES ($1, $2)
-RETURNING id::text AS id, email, password_hash, email_verified_at;
+RETURNING *;
 
 -- name: GetUserByEmail :one
-SELECT id::text AS id, email, password_hash, email_verified_at
+SELECT *
 FROM users
 WHERE email = $1;
 
 -- name: GetUserByID :one
-SELECT id::text AS id, email, password_hash, email_verified_at
+SELECT *
 FROM users
-WHERE id = $1::uuid;
+WHERE id = sqlc.arg(id)::uuid;
+
+-- name: ArchiveSessions :execrows
+WITH moved AS (
+  DELETE FROM sessions
+  WHERE id IN (
+    SELECT s.id FROM sessions s
+    WHERE s.expires_at < sqlc.arg(cutoff) OR s.revoked_at < sqlc.arg(cutoff)
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+  )
+  RETURNING id, user_id, refresh_token_hash, created_at, expires_at, revoked_at, user_agent, ip, rotated_from
+)
+INSERT INTO sessions_archive (id, user_id, refresh_token_hash, created_at, expires_at, revoked_at, user_agent, ip, rotated_from)
+SELECT id, user_id, refresh_token_hash, created_at, expires_at, revoked_at, user_agent, ip, rotated_from
+FROM moved;
+
+-- name: PurgeArchivedSessions :execrows
+DELETE FROM sessions_archive
+WHERE id IN (
+  SELECT a.id FROM sessions_archive a
+  WHERE a.archived_at < sqlc.arg(cutoff)
+  LIMIT sqlc.arg(batch_size)
+);
 
--- name: MarkEmailVerified :exec
+-- name: UpdateUser :one
 UPDATE users
-SET email_verified_at = $2,
+SET email = sqlc.arg(email),
+    password_hash = sqlc.arg(password_hash),
+    version = version + 1,
     updated_at = now()
-WHERE id = $1::uuid;
+WHERE id = sqlc.arg(id)::uuid
+  AND version = sqlc.arg(version)
+RETURNING *;
 
--- name: CreateSession :exec
-INSERT INTO sessions (user_id, token_hash, expires_at)
-VALUES ($1::uuid, $2, $3);
+-- name: LockUser :one
+SELECT id
+FROM users
+WHERE id = sqlc.arg(user_id)::uuid
+FOR UPDATE;
 
--- name: GetSessionUserIDByTokenHash :one
-SELECT user_id::text AS user_id
+-- name: ListActiveSessionIDs :many
+SELECT id
 FROM sessions
-WHERE token_hash = $1
+WHERE user_id = sqlc.arg(user_id)::uuid
   AND revoked_at IS NULL
-  AND expires_at > $2;
+  AND expires_at > now()
+ORDER BY created_at, id;
 
--- name: RevokeSessionByTokenHash :exec
+-- name: RevokeSessions :execrows
 UPDATE sessions
-SET revoked_at = $2
-WHERE token_hash = $1
+SET revoked_at = now()
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
   AND revoked_at IS NULL;
 
--- name: CreateEmailVerification :exec
-INSERT INTO email_verifications (user_id, token_hash, expires_at)
-VALUES ($1::uuid, $2, $3);
-
--- name: ConsumeEmailVerification :one
-UPDATE email_verifications
-SET consumed_at = $2
-WHERE token_hash = $1
-  AND consumed_at IS NULL
-  AND expires_at > $2
-RETURNING user_id::text AS user_id;
+-- name: SessionActive :one
+SELECT EXISTS (
+  SELECT 1
+  FROM sessions
+  WHERE id = sqlc.arg(id)::uuid
+    AND user_id = sqlc.arg(user_id)::uuid
+    AND revoked_at IS NULL
+    AND expires_at > now()
+);
+
+-- name: InsertSession :one
+INSERT INTO sessions (user_id, refresh_token_hash, expires_at, user_agent, ip)
+VALUES (sqlc.arg(user_id)::uuid, sqlc.arg(refresh_token_hash), sqlc.arg(expires_at), sqlc.narg(user_agent), sqlc.narg(ip))
+RETURNING id;
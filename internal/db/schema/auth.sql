@@ -1,35 +0,0 @@
-CREATE TABLE IF NOT EXISTS users (
-  id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-  email             TEXT NOT NULL UNIQUE,
-  password_hash     BYTEA NOT NULL,
-  email_verified_at TIMESTAMPTZ NULL,
-  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
-  updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
-);
-
--- Opaque sessions. We store only token_hash (sha256) so DB leaks don't leak active tokens.
-CREATE TABLE IF NOT EXISTS sessions (
-  id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-  user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-  token_hash   BYTEA NOT NULL UNIQUE,
-  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
-  expires_at   TIMESTAMPTZ NOT NULL,
-  revoked_at   TIMESTAMPTZ NULL,
-  last_seen_at TIMESTAMPTZ NULL
-);
-
-CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
-CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
-
--- Email verification tokens (also stored hashed)
-CREATE TABLE IF NOT EXISTS email_verifications (
-  id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-  user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-  token_hash  BYTEA NOT NULL UNIQUE,
-  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
-  expires_at  TIMESTAMPTZ NOT NULL,
-  consumed_at TIMESTAMPTZ NULL
-);
-
-CREATE INDEX IF NOT EXISTS idx_email_verifications_user_id ON email_verifications(user_id);
-CREATE INDEX IF NOT EXISTS idx_email_verifications_expires_at ON email_verifications(expires_at);
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultTenantSchemaPrefix prefixes tenant ids to form their schema names.
+const DefaultTenantSchemaPrefix = "tenant_"
+
+// ErrInvalidTenant is returned when a tenant id can't be mapped to a schema name.
+var ErrInvalidTenant = errors.New("db: invalid tenant id")
+
+// TenantOptions enable schema-per-tenant isolation: each tenant's tables live
+// in their own schema, and every connection acquired from the pool has its
+// search_path set to the schema of the tenant in the acquiring context
+// (authctx.TenantID, or WithSchema). A connection acquired without a tenant
+// gets an empty search_path, so unqualified queries fail instead of reaching
+// another tenant's (or the public schema's) tables.
+//
+// Set search_path only through this mechanism: the pool tracks what each
+// connection was last set to and skips redundant round trips.
+type TenantOptions struct {
+	// SchemaPrefix defaults to DefaultTenantSchemaPrefix.
+	SchemaPrefix string
+	// Shared schemas are searched after the tenant's, e.g. one holding
+	// extension functions. Never list a schema with tenant tables here.
+	Shared []string
+}
+
+type schemaKey struct{}
+
+// WithSchema scopes connections acquired with ctx to schema, overriding the
+// tenant in ctx. Use it for per-tenant maintenance that iterates schemas (see
+// TenantSchemas) rather than serving a tenant's request.
+func WithSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaKey{}, schema)
+}
+
+var tenantRE = regexp.MustCompile(`^[a-z0-9_]{1,48}$`)
+
+// TenantSchema returns the schema name for tenant: prefix + the lower-cased id
+// with '-' mapped to '_' (so UUIDs work). Ids with other characters are
+// rejected rather than escaped, keeping schema names predictable for operators.
+func TenantSchema(prefix, tenant string) (string, error) {
+	if prefix == "" {
+		prefix = DefaultTenantSchemaPrefix
+	}
+	id := strings.ReplaceAll(strings.ToLower(tenant), "-", "_")
+	if !tenantRE.MatchString(id) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTenant, tenant)
+	}
+	return prefix + id, nil
+}
+
+// TenantSchemas lists the existing schemas named with prefix (default
+// DefaultTenantSchemaPrefix), sorted.
+func TenantSchemas(ctx context.Context, pool *pgxpool.Pool, prefix string) ([]string, error) {
+	if prefix == "" {
+		prefix = DefaultTenantSchemaPrefix
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT nspname FROM pg_catalog.pg_namespace
+		WHERE starts_with(nspname, $1)
+		ORDER BY nspname
+	`, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("db: list tenant schemas: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+type tenancy struct {
+	opts TenantOptions
+
+	mu   sync.Mutex
+	path map[*pgx.Conn]string
+}
+
+func newTenancy(opts TenantOptions) *tenancy {
+	if opts.SchemaPrefix == "" {
+		opts.SchemaPrefix = DefaultTenantSchemaPrefix
+	}
+	return &tenancy{opts: opts, path: map[*pgx.Conn]string{}}
+}
+
+// searchPath returns the search_path for ctx.
+func (t *tenancy) searchPath(ctx context.Context) (string, error) {
+	schema, _ := ctx.Value(schemaKey{}).(string)
+	if schema == "" {
+		if id, ok := authctx.TenantID(ctx); ok {
+			var err error
+			if schema, err = TenantSchema(t.opts.SchemaPrefix, id); err != nil {
+				return "", err
+			}
+		}
+	}
+	if schema == "" {
+		return "", nil
+	}
+	parts := []string{pgx.Identifier{schema}.Sanitize()}
+	for _, s := range t.opts.Shared {
+		parts = append(parts, pgx.Identifier{s}.Sanitize())
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// prepare is the pool's PrepareConn hook.
+func (t *tenancy) prepare(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	path, err := t.searchPath(ctx)
+	if err != nil {
+		return true, err
+	}
+	t.mu.Lock()
+	cur, known := t.path[conn]
+	t.mu.Unlock()
+	if known && cur == path {
+		return true, nil
+	}
+	if _, err := conn.Exec(ctx, "SELECT pg_catalog.set_config('search_path', $1, false)", path); err != nil {
+		// The connection's search_path is now unknown; don't reuse it.
+		return false, fmt.Errorf("db: set search_path: %w", err)
+	}
+	t.mu.Lock()
+	t.path[conn] = path
+	t.mu.Unlock()
+	return true, nil
+}
+
+// forget is the pool's BeforeClose hook.
+func (t *tenancy) forget(conn *pgx.Conn) {
+	t.mu.Lock()
+	delete(t.path, conn)
+	t.mu.Unlock()
+}
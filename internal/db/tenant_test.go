@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"sdk-microservices/internal/platform/authctx"
+)
+
+func TestTenantSchema(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"acme", "tenant_acme"},
+		{"ACME", "tenant_acme"},
+		{"0b9c3a4e-1f2d-4c5b-9a8e-7d6c5b4a3f21", "tenant_0b9c3a4e_1f2d_4c5b_9a8e_7d6c5b4a3f21"},
+	} {
+		got, err := TenantSchema("", tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("TenantSchema(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "a;drop", `a"b`, "a.b", strings.Repeat("a", 49)} {
+		if _, err := TenantSchema("", bad); !errors.Is(err, ErrInvalidTenant) {
+			t.Errorf("TenantSchema(%q) err = %v, want ErrInvalidTenant", bad, err)
+		}
+	}
+}
+
+func TestTenancySearchPath(t *testing.T) {
+	tn := newTenancy(TenantOptions{Shared: []string{"extensions"}})
+	ctx := context.Background()
+
+	if p, err := tn.searchPath(ctx); err != nil || p != "" {
+		t.Fatalf("no tenant: path = %q, %v; want empty", p, err)
+	}
+	if p, _ := tn.searchPath(authctx.WithTenantID(ctx, "acme")); p != `"tenant_acme", "extensions"` {
+		t.Fatalf("tenant path = %q", p)
+	}
+	// An explicit schema wins over the request's tenant.
+	sctx := WithSchema(authctx.WithTenantID(ctx, "acme"), "tenant_globex")
+	if p, _ := tn.searchPath(sctx); p != `"tenant_globex", "extensions"` {
+		t.Fatalf("WithSchema path = %q", p)
+	}
+	if _, err := tn.searchPath(authctx.WithTenantID(ctx, "bad;id")); !errors.Is(err, ErrInvalidTenant) {
+		t.Fatalf("err = %v, want ErrInvalidTenant", err)
+	}
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueryClass bounds how long a kind of work may hold a connection. For each
+// timeout, zero keeps the connection's setting (see Options.StatementTimeout) and
+// a negative value disables the timeout.
+type QueryClass struct {
+	Name string
+	// StatementTimeout aborts any single statement running longer.
+	StatementTimeout time.Duration
+	// LockTimeout aborts a statement waiting longer than this for a lock.
+	LockTimeout time.Duration
+	// IdleInTxTimeout ends the session if the transaction sits idle this long.
+	IdleInTxTimeout time.Duration
+}
+
+// Built-in query classes.
+var (
+	// Interactive is request-path work: fail fast rather than queue behind locks.
+	Interactive = QueryClass{Name: "interactive", StatementTimeout: 5 * time.Second, LockTimeout: 2 * time.Second, IdleInTxTimeout: 10 * time.Second}
+	// Background is sweepers and batch jobs: longer statements, still bounded.
+	Background = QueryClass{Name: "background", StatementTimeout: 2 * time.Minute, LockTimeout: 10 * time.Second, IdleInTxTimeout: time.Minute}
+	// Migration is schema changes: statements may run long (backfills, index
+	// builds), but waiting on locks held by live traffic must not be.
+	Migration = QueryClass{Name: "migration", StatementTimeout: -1, LockTimeout: 5 * time.Second}
+)
+
+type queryClassKey struct{}
+
+// WithQueryClass makes transactions started with ctx (WithTx and friends) apply
+// c's timeouts for their duration.
+func WithQueryClass(ctx context.Context, c QueryClass) context.Context {
+	return context.WithValue(ctx, queryClassKey{}, c)
+}
+
+// QueryClassFrom returns the class set by WithQueryClass.
+func QueryClassFrom(ctx context.Context) (QueryClass, bool) {
+	c, ok := ctx.Value(queryClassKey{}).(QueryClass)
+	return c, ok
+}
+
+// SetTimeouts applies c's timeouts to q. With local, they last until the end of
+// the current transaction (like SET LOCAL); otherwise for the session, which then
+// must be reset before the connection goes back to a pool (see ResetTimeouts).
+func SetTimeouts(ctx context.Context, q execer, c QueryClass, local bool) error {
+	for _, p := range c.params() {
+		if _, err := q.Exec(ctx, "SELECT set_config($1, $2, $3)", p.name, p.value, local); err != nil {
+			return fmt.Errorf("db: set %s: %w", p.name, err)
+		}
+	}
+	return nil
+}
+
+// ResetTimeouts restores the session timeouts changed by SetTimeouts.
+func ResetTimeouts(ctx context.Context, q execer) error {
+	_, err := q.Exec(ctx, "RESET statement_timeout; RESET lock_timeout; RESET idle_in_transaction_session_timeout")
+	return err
+}
+
+// execer is satisfied by pgx.Tx, *pgx.Conn, *pgxpool.Conn and *pgxpool.Pool.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+type timeoutParam struct{ name, value string }
+
+func (c QueryClass) params() []timeoutParam {
+	var out []timeoutParam
+	add := func(name string, d time.Duration) {
+		switch {
+		case d < 0:
+			out = append(out, timeoutParam{name, "0"})
+		case d > 0:
+			out = append(out, timeoutParam{name, pgDuration(d)})
+		}
+	}
+	add("statement_timeout", c.StatementTimeout)
+	add("lock_timeout", c.LockTimeout)
+	add("idle_in_transaction_session_timeout", c.IdleInTxTimeout)
+	return out
+}
+
+// pgDuration formats d as Postgres milliseconds, rounding sub-millisecond
+// durations up so they don't turn into 0 (no timeout).
+func pgDuration(d time.Duration) string {
+	ms := d.Milliseconds()
+	if ms == 0 && d > 0 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10) + "ms"
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryClassParams(t *testing.T) {
+	got := QueryClass{StatementTimeout: 1500 * time.Millisecond, LockTimeout: -1, IdleInTxTimeout: 0}.params()
+	want := []timeoutParam{{"statement_timeout", "1500ms"}, {"lock_timeout", "0"}}
+	if len(got) != len(want) {
+		t.Fatalf("params = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("params = %v, want %v", got, want)
+		}
+	}
+	if s := pgDuration(time.Microsecond); s != "1ms" {
+		t.Fatalf("pgDuration(1µs) = %s; sub-millisecond must not become 0 (no timeout)", s)
+	}
+}
+
+func TestWithQueryClass(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := QueryClassFrom(ctx); ok {
+		t.Fatal("unexpected class")
+	}
+	if c, ok := QueryClassFrom(WithQueryClass(ctx, Background)); !ok || c.Name != "background" {
+		t.Fatalf("class = %+v, %v", c, ok)
+	}
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSOptions configure server verification and client certificates for the
+// connection, overriding the DSN's sslrootcert/sslcert/sslkey. The zero value
+// leaves the DSN's TLS settings untouched.
+type TLSOptions struct {
+	// RootCAFile is a PEM bundle used to verify the server (e.g. the RDS or
+	// Cloud SQL CA). Setting it enables full verification.
+	RootCAFile string
+	// CertFile and KeyFile are the client certificate pair for mTLS.
+	CertFile string
+	KeyFile  string
+	// ClientCertificate, if set, supplies the client certificate on each
+	// handshake instead of CertFile/KeyFile, so new connections pick up a
+	// rotated certificate (see secrets.KeyPair).
+	ClientCertificate func() (*tls.Certificate, error)
+	// ServerName overrides the host name verified against the server
+	// certificate (defaults to the DSN host).
+	ServerName string
+}
+
+func (o TLSOptions) enabled() bool {
+	return o.RootCAFile != "" || o.CertFile != "" || o.KeyFile != "" || o.ServerName != "" || o.ClientCertificate != nil
+}
+
+// config builds the tls.Config for host.
+func (o TLSOptions) config(host string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host}
+	if o.ServerName != "" {
+		cfg.ServerName = o.ServerName
+	}
+	if o.RootCAFile != "" {
+		pem, err := os.ReadFile(o.RootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("db: read root CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("db: no certificates in %s", o.RootCAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if o.ClientCertificate != nil {
+		get := o.ClientCertificate
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return get()
+		}
+		return cfg, nil
+	}
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("db: TLS CertFile and KeyFile must be set together")
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("db: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
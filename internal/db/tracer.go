@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLen bounds the statement recorded on query spans.
+const maxStatementLen = 2048
+
+// queryTracer is a pgx.QueryTracer that records a client span per query.
+//
+// Spans are only started under an existing span (e.g. the RPC being served), so
+// background queries such as health checks don't produce root traces. Statements
+// are sanitized (see SanitizeSQL); arguments are never recorded.
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func newQueryTracer() *queryTracer {
+	return &queryTracer{tracer: otel.Tracer("sdk-microservices/db")}
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	op := operation(data.SQL)
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", op),
+		attribute.String("db.query.text", SanitizeSQL(data.SQL)),
+	}
+	if conn != nil {
+		if cfg := conn.Config(); cfg != nil {
+			attrs = append(attrs,
+				attribute.String("db.namespace", cfg.Database),
+				attribute.String("server.address", cfg.Host),
+				attribute.Int("server.port", int(cfg.Port)),
+			)
+		}
+	}
+	ctx, _ = t.tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	defer span.End()
+	span.SetAttributes(attribute.Int64("db.response.rows_affected", data.CommandTag.RowsAffected()))
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+}
+
+// operation returns the statement's leading keyword (SELECT, INSERT, ...), which
+// names the span.
+func operation(sql string) string {
+	f := strings.Fields(stripComments(sql))
+	if len(f) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(f[0])
+}
+
+// SanitizeSQL prepares a statement for telemetry: comments are dropped, string and
+// numeric literals are replaced with "?" (placeholders like $1 are kept),
+// whitespace is collapsed, and the result is truncated.
+func SanitizeSQL(sql string) string {
+	sql = stripComments(sql)
+	var b strings.Builder
+	b.Grow(len(sql))
+	space := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		case c == '\'':
+			// Skip to the closing quote; '' is an escaped quote.
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			c = '?'
+		case c >= '0' && c <= '9' && !identChar(prev(sql, i)):
+			for i+1 < len(sql) && (sql[i+1] >= '0' && sql[i+1] <= '9' || sql[i+1] == '.') {
+				i++
+			}
+			c = '?'
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteByte(c)
+		if b.Len() >= maxStatementLen {
+			break
+		}
+	}
+	return b.String()
+}
+
+func prev(s string, i int) byte {
+	if i == 0 {
+		return ' '
+	}
+	return s[i-1]
+}
+
+// identChar reports whether c can precede a digit inside a token ($1, t1, col_2).
+func identChar(c byte) bool {
+	return c == '$' || c == '_' || c == '.' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// stripComments removes -- line and /* */ block comments.
+func stripComments(sql string) string {
+	if !strings.Contains(sql, "--") && !strings.Contains(sql, "/*") {
+		return sql
+	}
+	var b strings.Builder
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			b.WriteByte(' ')
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(sql[i])
+		}
+	}
+	return b.String()
+}
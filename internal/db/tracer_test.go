@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"SELECT id FROM users WHERE email = $1", "SELECT id FROM users WHERE email = $1"},
+		{"select *\n\tfrom t1 where name = 'o''brien' and age > 42", "select * from t1 where name = ? and age > ?"},
+		{"/* app */ UPDATE users SET n = 1.5 -- trailing\nWHERE id = 7", "UPDATE users SET n = ? WHERE id = ?"},
+	}
+	for _, c := range cases {
+		if got := SanitizeSQL(c.in); got != c.want {
+			t.Errorf("SanitizeSQL(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestQueryTracer_ChildSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	qt := &queryTracer{tracer: tp.Tracer("test")}
+
+	// No parent span: nothing is recorded.
+	ctx := qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	if n := len(rec.Ended()); n != 0 {
+		t.Fatalf("recorded %d spans without a parent", n)
+	}
+
+	parent, span := tp.Tracer("test").Start(context.Background(), "rpc")
+	ctx = qt.TraceQueryStart(parent, nil, pgx.TraceQueryStartData{SQL: "INSERT INTO users (email) VALUES ($1)"})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("INSERT 0 1"), Err: errors.New("boom")})
+	span.End()
+
+	ended := rec.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("got %d spans, want 2", len(ended))
+	}
+	q := ended[0]
+	if q.Name() != "INSERT" || q.Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Fatalf("query span = %q (parent %s)", q.Name(), q.Parent().SpanID())
+	}
+	if q.Status().Code != codes.Error {
+		t.Fatalf("status = %v, want error", q.Status().Code)
+	}
+	var rows int64 = -1
+	for _, a := range q.Attributes() {
+		if a.Key == "db.response.rows_affected" {
+			rows = a.Value.AsInt64()
+		}
+	}
+	if rows != 1 {
+		t.Fatalf("rows_affected = %d, want 1", rows)
+	}
+}
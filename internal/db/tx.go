@@ -4,18 +4,41 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// WithTx runs fn inside a database transaction.
+// TxRetry bounds how WithTx retries transactions that fail with a serialization
+// failure (SQLSTATE 40001) or deadlock (40P01).
+type TxRetry struct {
+	// MaxAttempts includes the first try; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay doubles after every failed attempt, up to MaxDelay, with jitter.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultTxRetry is the policy used by WithTx.
+var DefaultTxRetry = TxRetry{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 200 * time.Millisecond}
+
+// WithTx runs fn inside a database transaction, retrying the whole transaction
+// per DefaultTxRetry on serialization failures and deadlocks.
 //
 // Rules:
 //   - fn must not call Commit/Rollback.
 //   - if fn returns an error, the tx is rolled back.
 //   - commit errors are returned.
-func WithTx(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) (err error) {
+//   - fn may run more than once, so side effects outside the tx must be safe to repeat.
+//   - a QueryClass set with WithQueryClass applies its timeouts to the tx.
+func WithTx(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	return WithTxRetry(ctx, pool, opts, DefaultTxRetry, fn)
+}
+
+// WithTxRetry is WithTx with an explicit retry policy.
+func WithTxRetry(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, retry TxRetry, fn func(ctx context.Context, tx pgx.Tx) error) error {
 	if ctx == nil {
 		return errors.New("db: nil context")
 	}
@@ -25,7 +48,44 @@ func WithTx(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, fn func
 	if fn == nil {
 		return errors.New("db: nil fn")
 	}
+	return retryTx(ctx, retry, func() error { return runTx(ctx, pool, opts, fn) })
+}
 
+// retryTx calls attempt until it succeeds, fails with a non-retryable error, or
+// the policy's attempts run out.
+func retryTx(ctx context.Context, retry TxRetry, attempt func() error) error {
+	delay := retry.BaseDelay
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || i >= retry.MaxAttempts || !isSerializationFailure(err) {
+			return err
+		}
+		d := delay
+		if d > 0 {
+			d = d/2 + rand.N(d/2+1) // jitter so retrying transactions don't collide again
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		if delay *= 2; retry.MaxDelay > 0 && delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+}
+
+// isSerializationFailure reports whether err is a serialization failure or a
+// deadlock, after which the transaction can simply be run again.
+func isSerializationFailure(err error) bool {
+	var sf *SerializationFailure
+	return errors.As(ClassifyError(err), &sf)
+}
+
+// runTx runs fn in one transaction attempt.
+func runTx(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) (err error) {
 	tx, err := pool.BeginTx(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("db: begin tx: %w", err)
@@ -42,6 +102,11 @@ func WithTx(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions, fn func
 		}
 	}()
 
+	if c, ok := QueryClassFrom(ctx); ok {
+		if err = SetTimeouts(ctx, tx, c, true); err != nil {
+			return err
+		}
+	}
 	if err = fn(ctx, tx); err != nil {
 		return err
 	}
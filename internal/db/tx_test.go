@@ -2,9 +2,13 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func TestWithTx_NilGuards(t *testing.T) {
@@ -20,3 +24,35 @@ func TestWithTx_NilGuards(t *testing.T) {
 		t.Fatalf("expected error for nil fn")
 	}
 }
+
+func TestRetryTx(t *testing.T) {
+	ctx := context.Background()
+	policy := TxRetry{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	serialization := fmt.Errorf("db: commit tx: %w", &pgconn.PgError{Code: "40001"})
+
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  bool
+		attempts int
+	}{
+		{"success", []error{nil}, false, 1},
+		{"serialization then success", []error{serialization, nil}, false, 2},
+		{"deadlock then success", []error{&pgconn.PgError{Code: "40P01"}, nil}, false, 2},
+		{"gives up", []error{serialization, serialization, serialization, nil}, true, 3},
+		{"not retryable", []error{&pgconn.PgError{Code: "23505"}, nil}, true, 1},
+		{"plain error", []error{errors.New("boom"), nil}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := 0
+			err := retryTx(ctx, policy, func() error {
+				n++
+				return tt.errs[n-1]
+			})
+			if (err != nil) != tt.wantErr || n != tt.attempts {
+				t.Fatalf("err = %v after %d attempts; want error %v after %d", err, n, tt.wantErr, tt.attempts)
+			}
+		})
+	}
+}
@@ -3,33 +3,21 @@
 package integration_test
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net"
 	"net/http"
-	"os"
-	"path/filepath"
-	"sort"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	authv1 "sdk-microservices/gen/api/proto/auth/v1"
-	hellov1 "sdk-microservices/gen/api/proto/hello/v1"
-	"sdk-microservices/internal/db"
-	authsrv "sdk-microservices/internal/services/auth/server"
-	"sdk-microservices/internal/services/auth/jwt"
+	"sdk-microservices/internal/db/migrate"
 	"sdk-microservices/internal/services/auth/store"
-	hellosrv "sdk-microservices/internal/services/hello/server"
-
-	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/testcontainers/testcontainers-go/modules/postgres"
-	"go.uber.org/zap"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"sdk-microservices/internal/testkit"
+	"sdk-microservices/migrations"
+
 	"google.golang.org/grpc/status"
 )
 
@@ -37,13 +25,7 @@ func TestIntegration_MigrationsAndSmoke(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	pg := startPostgres(t, ctx)
-	dsn := mustConnString(t, ctx, pg)
-
-	pool := mustPool(t, ctx, dsn)
-	defer pool.Close()
-
-	applyAuthMigrations(t, ctx, pool)
+	pool := testkit.StartPostgres(t, ctx, "auth")
 
 	// smoke query: ensure users table exists
 	var ok bool
@@ -53,316 +35,244 @@ func TestIntegration_MigrationsAndSmoke(t *testing.T) {
 	if !ok {
 		t.Fatalf("users table missing after migrations")
 	}
+
+	// authd's startup step: current once applied, pending when the binary knows
+	// a newer migration.
+	src, err := migrations.For("auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.New(pool, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Current(ctx); err != nil {
+		t.Fatalf("Current after migrations: %v", err)
+	}
+	ahead, err := migrate.New(pool, fstest.MapFS{"999_next.up.sql": {Data: []byte("SELECT 1;")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ahead.Current(ctx); !errors.Is(err, migrate.ErrPending) {
+		t.Fatalf("Current with a newer migration: %v, want ErrPending", err)
+	}
 }
 
-func TestIntegration_gRPC_and_HTTP_Smoke(t *testing.T) {
+func TestIntegration_UpdateUserVersionConflict(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	pg := startPostgres(t, ctx)
-	dsn := mustConnString(t, ctx, pg)
-
-	pool := mustPool(t, ctx, dsn)
-	defer pool.Close()
-
-	applyAuthMigrations(t, ctx, pool)
+	pool := testkit.StartPostgres(t, ctx, "auth")
 
-	// Start auth gRPC.
-	authAddr, authStop := startAuthGRPC(t, ctx, pool)
-	defer authStop()
-
-	// Start hello gRPC.
-	helloAddr, helloStop := startHelloGRPC(t, ctx)
-	defer helloStop()
-
-	// Start gateway HTTP.
-	gatewayURL, gwStop := startGatewayHTTP(t, ctx, helloAddr, authAddr)
-	defer gwStop()
-
-	// Register via HTTP.
-	email := fmt.Sprintf("u_%d@example.com", time.Now().UnixNano())
-	password := "supersecurepassword" // >= 12
-	reg := map[string]any{"email": email, "password": password}
-	regBody := mustJSON(t, reg)
-	resp := mustHTTP(t, ctx, "POST", gatewayURL+"/v1/auth/register", regBody, nil)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("register status=%d body=%s", resp.StatusCode, mustReadAll(t, resp.Body))
+	st := store.New(pool)
+	u, err := st.CreateUser(ctx, "occ@example.com", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
 	}
-	_ = resp.Body.Close()
 
-	// Login via HTTP.
-	loginBody := mustJSON(t, map[string]any{"email": email, "password": password})
-	resp = mustHTTP(t, ctx, "POST", gatewayURL+"/v1/auth/login", loginBody, nil)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("login status=%d body=%s", resp.StatusCode, mustReadAll(t, resp.Body))
-	}
-	var lr struct {
-		UserID      string `json:"userId"`
-		AccessToken string `json:"accessToken"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
-		t.Fatalf("decode login err=%v", err)
+	first := *u
+	first.Email = "first@example.com"
+	updated, err := st.UpdateUser(ctx, &first)
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
 	}
-	_ = resp.Body.Close()
-	if lr.UserID == "" || lr.AccessToken == "" {
-		t.Fatalf("login response missing fields: %+v", lr)
+	if updated.Version != u.Version+1 {
+		t.Fatalf("version = %d, want %d", updated.Version, u.Version+1)
 	}
 
-	// Call hello via HTTP with bearer token (gateway protects non-auth endpoints).
-	headers := map[string]string{"Authorization": "Bearer " + lr.AccessToken}
-	resp = mustHTTP(t, ctx, "GET", gatewayURL+"/v1/hello/tyler", nil, headers)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("hello status=%d body=%s", resp.StatusCode, mustReadAll(t, resp.Body))
+	// A second writer still holding the original version must not overwrite.
+	stale := *u
+	stale.Email = "second@example.com"
+	if _, err := st.UpdateUser(ctx, &stale); !errors.Is(err, store.ErrVersionConflict) {
+		t.Fatalf("stale UpdateUser err = %v, want ErrVersionConflict", err)
 	}
-	_ = resp.Body.Close()
 }
 
-func TestContract_gRPC_StatusCodes(t *testing.T) {
+func TestIntegration_SessionLimit(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	pg := startPostgres(t, ctx)
-	dsn := mustConnString(t, ctx, pg)
-	pool := mustPool(t, ctx, dsn)
-	defer pool.Close()
-	applyAuthMigrations(t, ctx, pool)
+	pool := testkit.StartPostgres(t, ctx, "auth")
 
-	authAddr, stop := startAuthGRPC(t, ctx, pool)
-	defer stop()
-
-	conn, err := grpc.DialContext(ctx, authAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	st := store.New(pool)
+	u, err := st.CreateUser(ctx, "sessions@example.com", "hash")
 	if err != nil {
-		t.Fatalf("dial auth err=%v", err)
+		t.Fatalf("CreateUser: %v", err)
+	}
+	limit := store.SessionLimit{Max: 2}
+	newSession := func(i int) store.NewSession {
+		return store.NewSession{
+			UserID:           u.ID,
+			RefreshTokenHash: []byte(fmt.Sprintf("hash-%d", i)),
+			ExpiresAt:        time.Now().Add(time.Hour),
+			IP:               "203.0.113.7",
+		}
 	}
-	defer conn.Close()
-
-	c := authv1.NewAuthServiceClient(conn)
 
-	// Invalid email -> InvalidArgument.
-	_, err = c.Register(ctx, &authv1.RegisterRequest{Email: "nope", Password: "supersecurepassword"})
-	if st := status.Convert(err); st == nil || st.Code() == 0 {
-		t.Fatalf("expected gRPC error")
-	} else if st.Code().String() != "InvalidArgument" {
-		t.Fatalf("expected InvalidArgument, got %v", st.Code())
+	first, _, err := st.CreateSession(ctx, newSession(1), limit)
+	if err != nil {
+		t.Fatalf("CreateSession 1: %v", err)
 	}
-
-	// Login unknown user -> Unauthenticated (avoid enumeration).
-	_, err = c.Login(ctx, &authv1.LoginRequest{Email: "nobody@example.com", Password: "supersecurepassword"})
-	if st := status.Convert(err); st == nil {
-		t.Fatalf("expected gRPC error")
-	} else if st.Code().String() != "Unauthenticated" {
-		t.Fatalf("expected Unauthenticated, got %v", st.Code())
+	if _, _, err := st.CreateSession(ctx, newSession(2), limit); err != nil {
+		t.Fatalf("CreateSession 2: %v", err)
 	}
-}
-
-// --- helpers ---
-
-func startPostgres(t *testing.T, ctx context.Context) *postgres.PostgresContainer {
-	t.Helper()
-	pg, err := postgres.Run(ctx,
-		"postgres:16",
-		postgres.WithDatabase("auth"),
-		postgres.WithUsername("postgres"),
-		postgres.WithPassword("postgres"),
-	)
+	_, evicted, err := st.CreateSession(ctx, newSession(3), limit)
 	if err != nil {
-		t.Fatalf("start postgres err=%v", err)
+		t.Fatalf("CreateSession 3: %v", err)
+	}
+	if len(evicted) != 1 || evicted[0] != first {
+		t.Fatalf("evicted = %v, want [%s]", evicted, first)
 	}
-	t.Cleanup(func() { _ = pg.Terminate(context.Background()) })
-	return pg
-}
 
-func mustConnString(t *testing.T, ctx context.Context, pg *postgres.PostgresContainer) string {
-	t.Helper()
-	dsn, err := pg.ConnectionString(ctx, "sslmode=disable")
-	if err != nil {
-		t.Fatalf("conn string err=%v", err)
+	limit.Reject = true
+	if _, _, err := st.CreateSession(ctx, newSession(4), limit); !errors.Is(err, store.ErrTooManySessions) {
+		t.Fatalf("CreateSession at rejecting cap: err = %v, want ErrTooManySessions", err)
 	}
-	return dsn
 }
 
-func mustPool(t *testing.T, ctx context.Context, dsn string) *pgxpool.Pool {
-	t.Helper()
-	pool, err := db.NewPool(ctx, dsn, db.Options{InitialPingTimeout: 2 * time.Second})
+func TestIntegration_SearchSessions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	pool := testkit.StartPostgres(t, ctx, "auth")
+
+	st := store.New(pool)
+	u, err := st.CreateUser(ctx, "search@example.com", "hash")
 	if err != nil {
-		t.Fatalf("NewPool err=%v", err)
+		t.Fatalf("CreateUser: %v", err)
+	}
+	for i, s := range []struct{ ip, ua string }{
+		{"203.0.113.7", "curl/8.0"},
+		{"203.0.113.9", "Mozilla/5.0 (X11; Linux x86_64)"},
+		{"198.51.100.1", "Mozilla/5.0 (Macintosh)"},
+	} {
+		_, _, err := st.CreateSession(ctx, store.NewSession{
+			UserID:           u.ID,
+			RefreshTokenHash: []byte(fmt.Sprintf("search-%d", i)),
+			ExpiresAt:        time.Now().Add(time.Hour),
+			UserAgent:        s.ua,
+			IP:               s.ip,
+		}, store.SessionLimit{})
+		if err != nil {
+			t.Fatalf("CreateSession %d: %v", i, err)
+		}
 	}
-	return pool
-}
 
-func applyAuthMigrations(t *testing.T, ctx context.Context, pool *pgxpool.Pool) {
-	t.Helper()
-	root := projectRoot(t)
-	migDir := filepath.Join(root, "migrations", "auth")
-	ents, err := os.ReadDir(migDir)
+	got, next, err := st.SearchSessions(ctx, store.SessionFilter{IP: "203.0.113.0/24", UserAgentContains: "mozilla"}, 10, "")
 	if err != nil {
-		t.Fatalf("ReadDir migrations err=%v", err)
+		t.Fatalf("SearchSessions: %v", err)
 	}
-	var files []string
-	for _, e := range ents {
-		if e.IsDir() {
-			continue
-		}
-		name := e.Name()
-		if filepath.Ext(name) != ".sql" {
-			continue
-		}
-		if filepath.Base(name) == name && filepath.Ext(name) == ".sql" {
-			// ok
-		}
-		if filepath.Base(name) != name {
-			continue
-		}
-		if filepath.Ext(name) == ".sql" && filepathHasSuffix(name, ".up.sql") {
-			files = append(files, filepath.Join(migDir, name))
-		}
+	if len(got) != 1 || got[0].IP != "203.0.113.9" || next != "" {
+		t.Fatalf("SearchSessions = %+v (next %q), want the 203.0.113.9 session", got, next)
 	}
-	sort.Strings(files)
-	for _, f := range files {
-		b, err := os.ReadFile(f)
+
+	// Keyset pagination walks every session exactly once.
+	seen := map[string]bool{}
+	token := ""
+	for {
+		page, next, err := st.SearchSessions(ctx, store.SessionFilter{UserID: u.ID}, 2, token)
 		if err != nil {
-			t.Fatalf("ReadFile %s err=%v", f, err)
+			t.Fatalf("SearchSessions page: %v", err)
+		}
+		for _, s := range page {
+			if seen[s.ID] {
+				t.Fatalf("session %s returned twice", s.ID)
+			}
+			seen[s.ID] = true
 		}
-		if _, err := pool.Exec(ctx, string(b)); err != nil {
-			t.Fatalf("exec migration %s err=%v", f, err)
+		if next == "" {
+			break
 		}
+		token = next
 	}
-}
-
-func filepathHasSuffix(name, suf string) bool {
-	if len(suf) > len(name) {
-		return false
+	if len(seen) != 3 {
+		t.Fatalf("paged through %d sessions, want 3", len(seen))
 	}
-	return name[len(name)-len(suf):] == suf
 }
 
-func startAuthGRPC(t *testing.T, ctx context.Context, pool *pgxpool.Pool) (addr string, stop func()) {
-	t.Helper()
-	st := store.New(pool)
-	jwtSvc := jwt.New("test-secret", "sdk-microservices")
-	srv := authsrv.New(zap.NewNop(), st, jwtSvc, authsrv.Options{AccessTTL: 2 * time.Minute, RefreshTTL: 10 * time.Minute})
+func TestIntegration_gRPC_and_HTTP_Smoke(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-	lis, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		t.Fatalf("listen err=%v", err)
-	}
-	gs := grpc.NewServer()
-	authv1.RegisterAuthServiceServer(gs, srv)
+	pool := testkit.StartPostgres(t, ctx, "auth")
+	h := testkit.New(t).
+		WithAuth(store.New(pool)).
+		WithHello().
+		WithGateway().
+		Start(ctx)
 
-	go func() { _ = gs.Serve(lis) }()
-	return lis.Addr().String(), func() {
-		gs.Stop()
-		_ = lis.Close()
+	// Register via HTTP.
+	email := fmt.Sprintf("u_%d@example.com", time.Now().UnixNano())
+	password := "supersecurepassword" // >= 12
+	reg := map[string]any{"email": email, "password": password}
+	resp := h.Do(ctx, "POST", "/v1/auth/register", testkit.JSON(t, reg), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("register status=%d body=%s", resp.StatusCode, testkit.ReadAll(t, resp.Body))
 	}
-}
 
-func startHelloGRPC(t *testing.T, ctx context.Context) (addr string, stop func()) {
-	t.Helper()
-	lis, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		t.Fatalf("listen err=%v", err)
+	// Login via HTTP.
+	loginBody := testkit.JSON(t, map[string]any{"email": email, "password": password})
+	resp = h.Do(ctx, "POST", "/v1/auth/login", loginBody, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login status=%d body=%s", resp.StatusCode, testkit.ReadAll(t, resp.Body))
 	}
-	gs := grpc.NewServer()
-	hellov1.RegisterHelloServiceServer(gs, &hellosrv.Server{})
-	go func() { _ = gs.Serve(lis) }()
-	return lis.Addr().String(), func() {
-		gs.Stop()
-		_ = lis.Close()
+	var lr struct {
+		UserID      string `json:"userId"`
+		AccessToken string `json:"accessToken"`
 	}
-}
-
-func startGatewayHTTP(t *testing.T, ctx context.Context, helloAddr, authAddr string) (baseURL string, stop func()) {
-	t.Helper()
-	// gRPC dials for handlers.
-	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-	connHello, err := grpc.DialContext(dialCtx, helloAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		t.Fatalf("dial hello err=%v", err)
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		t.Fatalf("decode login err=%v", err)
 	}
-	connAuth, err := grpc.DialContext(dialCtx, authAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		_ = connHello.Close()
-		t.Fatalf("dial auth err=%v", err)
+	if lr.UserID == "" || lr.AccessToken == "" {
+		t.Fatalf("login response missing fields: %+v", lr)
 	}
 
-	mux := runtime.NewServeMux()
-	if err := hellov1.RegisterHelloServiceHandlerClient(ctx, mux, hellov1.NewHelloServiceClient(connHello)); err != nil {
-		_ = connHello.Close()
-		_ = connAuth.Close()
-		t.Fatalf("register hello gw err=%v", err)
-	}
-	if err := authv1.RegisterAuthServiceHandlerClient(ctx, mux, authv1.NewAuthServiceClient(connAuth)); err != nil {
-		_ = connHello.Close()
-		_ = connAuth.Close()
-		t.Fatalf("register auth gw err=%v", err)
+	// Call hello via HTTP with bearer token (gateway protects non-auth endpoints).
+	resp = h.Do(ctx, "GET", "/v1/hello/tyler", nil, testkit.Bearer(lr.AccessToken))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("hello status=%d body=%s", resp.StatusCode, testkit.ReadAll(t, resp.Body))
 	}
+}
 
-	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 2 * time.Second}
-	lis, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		_ = connHello.Close()
-		_ = connAuth.Close()
-		t.Fatalf("listen http err=%v", err)
-	}
+// With a JWT secret configured, hello itself rejects calls without a valid token.
+func TestIntegration_HelloRequiresToken(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-	go func() { _ = srv.Serve(lis) }()
-	return "http://" + lis.Addr().String(), func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		_ = srv.Shutdown(shutdownCtx)
-		_ = connHello.Close()
-		_ = connAuth.Close()
-	}
-}
+	h := testkit.New(t).WithHelloAuth().WithGateway().Start(ctx)
 
-func projectRoot(t *testing.T) string {
-	t.Helper()
-	// Walk up from the test file's working dir until we find go.mod.
-	d, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("Getwd err=%v", err)
+	resp := h.Do(ctx, "GET", "/v1/hello/tyler", nil, testkit.Bearer(h.MintToken("u1", "u1@example.com", time.Minute)))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("hello status=%d body=%s", resp.StatusCode, testkit.ReadAll(t, resp.Body))
 	}
-	for i := 0; i < 10; i++ {
-		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
-			return d
-		}
-		d = filepath.Dir(d)
+	resp = h.Do(ctx, "GET", "/v1/hello/tyler", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("hello without token status=%d, want 401", resp.StatusCode)
 	}
-	t.Fatalf("could not locate project root from %s", d)
-	return ""
 }
 
-func mustJSON(t *testing.T, v any) io.Reader {
-	t.Helper()
-	b, err := json.Marshal(v)
-	if err != nil {
-		t.Fatalf("json marshal err=%v", err)
-	}
-	return bytes.NewReader(b)
-}
+func TestContract_gRPC_StatusCodes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-func mustHTTP(t *testing.T, ctx context.Context, method, url string, body io.Reader, headers map[string]string) *http.Response {
-	t.Helper()
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
-	if err != nil {
-		t.Fatalf("NewRequest err=%v", err)
-	}
-	if method == http.MethodPost {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		t.Fatalf("http do err=%v", err)
+	pool := testkit.StartPostgres(t, ctx, "auth")
+	h := testkit.New(t).WithAuth(store.New(pool)).Start(ctx)
+
+	c := authv1.NewAuthServiceClient(h.Conn(h.AuthAddr))
+
+	// Invalid email -> InvalidArgument.
+	_, err := c.Register(ctx, &authv1.RegisterRequest{Email: "nope", Password: "supersecurepassword"})
+	if st := status.Convert(err); st == nil || st.Code() == 0 {
+		t.Fatalf("expected gRPC error")
+	} else if st.Code().String() != "InvalidArgument" {
+		t.Fatalf("expected InvalidArgument, got %v", st.Code())
 	}
-	return resp
-}
 
-func mustReadAll(t *testing.T, r io.Reader) string {
-	t.Helper()
-	b, _ := io.ReadAll(r)
-	return string(b)
+	// Login unknown user -> Unauthenticated (avoid enumeration).
+	_, err = c.Login(ctx, &authv1.LoginRequest{Email: "nobody@example.com", Password: "supersecurepassword"})
+	if st := status.Convert(err); st == nil {
+		t.Fatalf("expected gRPC error")
+	} else if st.Code().String() != "Unauthenticated" {
+		t.Fatalf("expected Unauthenticated, got %v", st.Code())
+	}
 }
@@ -2,30 +2,70 @@ package admin
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"net"
 	"net/http"
+	"slices"
 	"time"
 
+	"sdk-microservices/internal/platform/buildinfo"
+	"sdk-microservices/internal/platform/config"
 	"sdk-microservices/internal/platform/health"
+	"sdk-microservices/internal/platform/httperr"
+	"sdk-microservices/internal/platform/httpmw"
 
 	"go.uber.org/zap"
 )
 
-// Server is a small admin HTTP server exposing /metrics, /livez, /readyz.
+// Server is a small admin HTTP server exposing /metrics, /livez, /readyz,
+// /startupz, /buildinfo, /configz (and /debug/pprof/ when enabled).
 type Server struct {
 	http *http.Server
 	ln   net.Listener
+	mux  *http.ServeMux
+	// token guards the mutating endpoints (Options.PprofToken).
+	token string
 }
 
 type Options struct {
-	Addr         string
-	ServiceName  string
-	Metrics      http.Handler // optional
-	ReadyRoot    *health.Node // optional
-	ServingFn    func() bool  // optional (NOT_SERVING gate)
+	Addr        string
+	ServiceName string
+	Metrics     http.Handler // optional
+	ReadyRoot   *health.Node // optional
+	// ReadyEvaluator, if set, serves /readyz from its cached result instead of
+	// evaluating ReadyRoot on every probe.
+	ReadyEvaluator *health.Evaluator
+	ServingFn      func() bool // optional (NOT_SERVING gate)
+	// SetServing, if set, mounts POST /drain and POST /serve so operators can take
+	// the instance out of (and back into) rotation without signals. An error is
+	// reported as 409 (e.g. the process is already shutting down). Both require
+	// PprofToken and are refused while it is unset.
+	SetServing func(serving bool) error
+	// ConfigPrefixes select the unrecorded environment variables /configz shows in
+	// addition to the settings recorded via the config package (e.g. "AUTH_").
+	ConfigPrefixes []string
+	// Startup, if set, is served on /startupz for the Kubernetes startupProbe.
+	Startup      *health.Startup
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	// Pprof mounts net/http/pprof under /debug/pprof/. PprofToken, if set, is required
+	// as "Authorization: Bearer <token>" on those endpoints and on /configz; it is
+	// also the admin token the mutating endpoints (/drain, /serve, HandleProtected)
+	// always require.
+	Pprof      bool
+	PprofToken string
+
+	// ExtraHandlers mounts service-specific operational endpoints (cache flush,
+	// queue stats) by ServeMux pattern, e.g. "POST /cache/flush". Start fails if a
+	// pattern collides with a built-in endpoint. Handlers can also be added later
+	// with Server.Handle.
+	ExtraHandlers map[string]http.Handler
+	// Middleware wraps every admin endpoint (first is outermost), e.g. to require
+	// a token or log operator actions.
+	Middleware httpmw.Chain
 }
 
 func Start(log *zap.Logger, opts Options) (*Server, error) {
@@ -34,16 +74,44 @@ func Start(log *zap.Logger, opts Options) (*Server, error) {
 	}
 	mux := http.NewServeMux()
 	mux.Handle("/livez", health.Livez())
-	if opts.ReadyRoot != nil {
+	mux.Handle("/buildinfo", buildinfo.Handler())
+	// /configz reveals topology (hosts, users, endpoints) even redacted, so it
+	// shares the profiling token.
+	mux.Handle("/configz", bearer(opts.PprofToken, config.Handler(opts.ConfigPrefixes...)))
+	switch {
+	case opts.ReadyEvaluator != nil:
+		mux.Handle("/readyz", opts.ReadyEvaluator.Handler(opts.ServingFn))
+	case opts.ReadyRoot != nil:
 		mux.Handle("/readyz", health.Handler(opts.ReadyRoot, opts.ServingFn))
 	}
+	if opts.Startup != nil {
+		mux.Handle("/startupz", opts.Startup.Handler())
+	}
+	if opts.SetServing != nil {
+		mux.Handle("POST /drain", requireToken(opts.PprofToken, servingToggle(log, opts.SetServing, false)))
+		mux.Handle("POST /serve", requireToken(opts.PprofToken, servingToggle(log, opts.SetServing, true)))
+	}
 	if opts.Metrics != nil {
 		mux.Handle("/metrics", opts.Metrics)
 	}
+	patterns := slices.Sorted(maps.Keys(opts.ExtraHandlers))
+	for _, pattern := range patterns {
+		if err := handle(mux, pattern, opts.ExtraHandlers[pattern]); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Pprof {
+		mountPprof(mux, opts.PprofToken)
+		// CPU profiles and traces stream for ?seconds=N (default 30s); pprof refuses
+		// durations beyond the server's WriteTimeout.
+		if opts.WriteTimeout <= 0 {
+			opts.WriteTimeout = 65 * time.Second
+		}
+	}
 
 	srv := &http.Server{
 		Addr:         opts.Addr,
-		Handler:      mux,
+		Handler:      opts.Middleware.Then(mux),
 		ReadTimeout:  orDur(opts.ReadTimeout, 5*time.Second),
 		WriteTimeout: orDur(opts.WriteTimeout, 10*time.Second),
 		IdleTimeout:  orDur(opts.IdleTimeout, 60*time.Second),
@@ -54,7 +122,7 @@ func Start(log *zap.Logger, opts Options) (*Server, error) {
 		return nil, err
 	}
 
-	as := &Server{http: srv, ln: ln}
+	as := &Server{http: srv, ln: ln, mux: mux, token: opts.PprofToken}
 	go func() {
 		log.Info("admin server listening", zap.String("addr", opts.Addr))
 		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
@@ -64,6 +132,52 @@ func Start(log *zap.Logger, opts Options) (*Server, error) {
 	return as, nil
 }
 
+// servingToggle answers POST /drain and /serve by calling set(serving).
+func servingToggle(log *zap.Logger, set func(bool) error, serving bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := set(serving); err != nil {
+			httperr.WriteError(w, r, http.StatusConflict, "SHUTTING_DOWN", err.Error(), nil)
+			return
+		}
+		log.Warn("serving state changed via admin", zap.Bool("serving", serving), zap.String("remote", r.RemoteAddr))
+		if serving {
+			_, _ = w.Write([]byte("SERVING\n"))
+		} else {
+			_, _ = w.Write([]byte("NOT_SERVING\n"))
+		}
+	})
+}
+
+// Handle mounts an extra operational endpoint on the running admin server. It
+// fails if pattern is invalid or collides with an endpoint already mounted.
+func (s *Server) Handle(pattern string, h http.Handler) error {
+	return handle(s.mux, pattern, h)
+}
+
+// HandleProtected is Handle for endpoints that change the instance (denylists,
+// cache flushes): they require the admin token and are refused without one.
+func (s *Server) HandleProtected(pattern string, h http.Handler) error {
+	return handle(s.mux, pattern, requireToken(s.token, h))
+}
+
+// handle is mux.Handle returning an error where ServeMux panics (invalid or
+// conflicting patterns), so a service's extra endpoint can't crash startup.
+func handle(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("admin: mount %q: %v", pattern, r)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
+
+// Addr is the address the admin server listens on (with the actual port when
+// Options.Addr asked for :0).
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	if s == nil || s.http == nil {
 		return nil
@@ -0,0 +1,169 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sdk-microservices/internal/platform/errs"
+
+	"go.uber.org/zap"
+)
+
+func startAdmin(t *testing.T, opts Options) string {
+	t.Helper()
+	opts.Addr = "127.0.0.1:0"
+	srv, err := Start(nil, opts)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+	return "http://" + srv.Addr().String()
+}
+
+func get(t *testing.T, method, url, token string) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestConfigzRequiresPprofToken(t *testing.T) {
+	base := startAdmin(t, Options{PprofToken: "t0k"})
+	if code := get(t, http.MethodGet, base+"/configz", ""); code != http.StatusUnauthorized {
+		t.Fatalf("no token: %d, want 401", code)
+	}
+	if code := get(t, http.MethodGet, base+"/configz", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: %d, want 401", code)
+	}
+	if code := get(t, http.MethodGet, base+"/configz", "t0k"); code != http.StatusOK {
+		t.Fatalf("token: %d, want 200", code)
+	}
+	if code := get(t, http.MethodGet, base+"/livez", ""); code != http.StatusOK {
+		t.Fatalf("/livez: %d, want 200", code)
+	}
+}
+
+func TestServingToggleRequiresToken(t *testing.T) {
+	var serving []bool
+	set := func(v bool) error { serving = append(serving, v); return nil }
+
+	open := startAdmin(t, Options{SetServing: set})
+	if code := get(t, http.MethodPost, open+"/drain", ""); code != http.StatusForbidden {
+		t.Fatalf("no token configured: %d, want 403", code)
+	}
+
+	base := startAdmin(t, Options{SetServing: set, PprofToken: "t0k"})
+	if code := get(t, http.MethodPost, base+"/drain", ""); code != http.StatusUnauthorized {
+		t.Fatalf("no token: %d, want 401", code)
+	}
+	if code := get(t, http.MethodPost, base+"/drain", "t0k"); code != http.StatusOK {
+		t.Fatalf("drain: %d, want 200", code)
+	}
+	if code := get(t, http.MethodPost, base+"/serve", "t0k"); code != http.StatusOK {
+		t.Fatalf("serve: %d, want 200", code)
+	}
+	if len(serving) != 2 || serving[0] || !serving[1] {
+		t.Fatalf("SetServing calls = %v, want [false true]", serving)
+	}
+}
+
+func TestServingToggleConflictIsProblemJSON(t *testing.T) {
+	set := func(bool) error { return errors.New("shutting down") }
+	rr := httptest.NewRecorder()
+	servingToggle(zap.NewNop(), set, true).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/serve", nil))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("Content-Type = %q, want application/problem+json", ct)
+	}
+	var p errs.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if p.Status != http.StatusConflict || p.Reason != "SHUTTING_DOWN" || p.Detail != "shutting down" {
+		t.Fatalf("problem = %+v", p)
+	}
+}
+
+func TestHandleProtectedRequiresToken(t *testing.T) {
+	ok := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	for _, tc := range []struct {
+		name, configured, sent string
+		want                   int
+	}{
+		{"no token configured", "", "", http.StatusForbidden},
+		{"missing token", "t0k", "", http.StatusUnauthorized},
+		{"wrong token", "t0k", "wrong", http.StatusUnauthorized},
+		{"token", "t0k", "t0k", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, err := Start(nil, Options{Addr: "127.0.0.1:0", PprofToken: tc.configured})
+			if err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+			if err := srv.HandleProtected("/denylist", ok); err != nil {
+				t.Fatal(err)
+			}
+			if code := get(t, http.MethodPut, "http://"+srv.Addr().String()+"/denylist", tc.sent); code != tc.want {
+				t.Fatalf("PUT /denylist: %d, want %d", code, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtraHandlers(t *testing.T) {
+	flush := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("flushed")) })
+	base := startAdmin(t, Options{ExtraHandlers: map[string]http.Handler{"POST /cache/flush": flush}})
+	if code := get(t, http.MethodPost, base+"/cache/flush", ""); code != http.StatusOK {
+		t.Fatalf("POST /cache/flush: %d, want 200", code)
+	}
+	if code := get(t, http.MethodGet, base+"/cache/flush", ""); code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /cache/flush: %d, want 405", code)
+	}
+}
+
+func TestExtraHandlersRejectsDuplicatePatterns(t *testing.T) {
+	ok := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	for _, pattern := range []string{"/livez", "/metrics", "/configz", "bad pattern"} {
+		t.Run(pattern, func(t *testing.T) {
+			srv, err := Start(nil, Options{
+				Addr:          "127.0.0.1:0",
+				Metrics:       ok,
+				ExtraHandlers: map[string]http.Handler{pattern: ok},
+			})
+			if err == nil {
+				_ = srv.Shutdown(context.Background())
+				t.Fatalf("Start accepted %q", pattern)
+			}
+		})
+	}
+
+	srv, err := Start(nil, Options{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+	if err := srv.Handle("/buildinfo", ok); err == nil {
+		t.Fatal("Handle accepted a built-in pattern")
+	}
+	if err := srv.HandleProtected("/extra", ok); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.HandleProtected("/extra", ok); err == nil {
+		t.Fatal("HandleProtected accepted a duplicate pattern")
+	}
+}
@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+
+	"sdk-microservices/internal/platform/httperr"
+)
+
+// mountPprof registers the net/http/pprof handlers. Named profiles (heap,
+// goroutine, allocs, block, mutex, threadcreate) are served by the index handler.
+func mountPprof(mux *http.ServeMux, token string) {
+	guard := func(h http.HandlerFunc) http.Handler { return bearer(token, h) }
+
+	mux.Handle("/debug/pprof/", guard(pprof.Index))
+	mux.Handle("/debug/pprof/cmdline", guard(pprof.Cmdline))
+	mux.Handle("/debug/pprof/profile", guard(pprof.Profile))
+	mux.Handle("/debug/pprof/symbol", guard(pprof.Symbol))
+	mux.Handle("/debug/pprof/trace", guard(pprof.Trace))
+}
+
+// bearer requires "Authorization: Bearer <token>" for h; an empty token leaves
+// h open.
+func bearer(token string, h http.Handler) http.Handler {
+	if token == "" {
+		return h
+	}
+	return requireToken(token, h)
+}
+
+// requireToken is bearer for endpoints that change the instance: without a
+// token configured they refuse every request.
+func requireToken(token string, h http.Handler) http.Handler {
+	if token == "" {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httperr.WriteError(w, r, http.StatusForbidden, "ADMIN_TOKEN_REQUIRED", "set the admin token to use this endpoint", nil)
+		})
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			httperr.Unauthorized(w, r, "INVALID_TOKEN")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,121 @@
+// Package audit records security-relevant events (logins, registrations, permission
+// changes) to dedicated sinks, kept apart from operational zap logs so retention and
+// compliance policies can differ.
+//
+// Audit writes never fail the request: sink errors are reported on the operational
+// logger instead.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/logging"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Outcome values.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// Event is one audit record.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Type names the action, e.g. "auth.login".
+	Type    string `json:"type"`
+	Outcome string `json:"outcome"`
+	// Reason is a stable machine-readable cause for failures (e.g. "invalid_credentials").
+	Reason  string `json:"reason,omitempty"`
+	Service string `json:"service,omitempty"`
+
+	// ActorID is who acted (defaults to the authctx user); Subject is who or what
+	// was acted on (e.g. the user that logged in).
+	ActorID  string `json:"actor_id,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	ClientIP string `json:"client_ip,omitempty"`
+
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+
+	// Attrs holds extra event-specific detail. Never put secrets here.
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// Sink persists events.
+type Sink interface {
+	Write(ctx context.Context, e Event) error
+	Close() error
+}
+
+// Logger fills in event context and fans events out to sinks.
+// A nil *Logger discards events.
+type Logger struct {
+	service string
+	sinks   []Sink
+	log     *zap.Logger
+}
+
+// New returns a Logger writing to sinks. log receives sink errors.
+func New(service string, log *zap.Logger, sinks ...Sink) *Logger {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Logger{service: service, sinks: sinks, log: log}
+}
+
+// Record completes e from ctx (time, service, actor, tenant, request and trace ids)
+// and writes it to every sink.
+func (l *Logger) Record(ctx context.Context, e Event) {
+	if l == nil || len(l.sinks) == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Service == "" {
+		e.Service = l.service
+	}
+	if e.ActorID == "" {
+		e.ActorID, _ = authctx.UserID(ctx)
+	}
+	if e.TenantID == "" {
+		e.TenantID, _ = authctx.TenantID(ctx)
+	}
+	if e.RequestID == "" {
+		e.RequestID, _ = logging.RequestID(ctx)
+	}
+	if sc := trace.SpanContextFromContext(ctx); e.TraceID == "" && sc.HasTraceID() {
+		e.TraceID = sc.TraceID().String()
+	}
+
+	for _, s := range l.sinks {
+		if err := s.Write(ctx, e); err != nil {
+			l.log.Error("audit sink write failed", zap.String("audit.type", e.Type), zap.Error(err))
+		}
+	}
+}
+
+// Close closes every sink.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	var errs []error
+	for _, s := range l.sinks {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Marshal encodes e as the JSON document every built-in sink writes.
+func Marshal(e Event) ([]byte, error) {
+	return json.Marshal(e)
+}
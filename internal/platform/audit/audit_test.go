@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/logging"
+)
+
+func TestRecordFillsContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("auth", nil, NewWriterSink(&buf))
+
+	ctx := authctx.WithUserID(context.Background(), "u1")
+	ctx = logging.WithRequestID(ctx, "rid-1")
+	l.Record(ctx, Event{Type: "auth.login", Outcome: Success, Subject: "u1"})
+
+	var e Event
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	if e.Service != "auth" || e.ActorID != "u1" || e.RequestID != "rid-1" || e.Time.IsZero() {
+		t.Fatalf("event = %+v", e)
+	}
+
+	// A nil Logger is a no-op.
+	var nilLogger *Logger
+	nilLogger.Record(ctx, Event{Type: "x"})
+}
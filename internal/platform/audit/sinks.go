@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes events as JSON lines to an io.Writer (e.g. a dedicated
+// stdout stream or file picked up by a separate log pipeline).
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+	c  io.Closer
+}
+
+// NewWriterSink writes to w; it never closes w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewFileSink appends to the file at path, creating it (0600) if needed.
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterSink{w: f, c: f}, nil
+}
+
+func (s *WriterSink) Write(_ context.Context, e Event) error {
+	b, err := Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+func (s *WriterSink) Close() error {
+	if s.c == nil {
+		return nil
+	}
+	return s.c.Close()
+}
+
+// Publisher is the slice of a message-broker client (Kafka producer, NATS
+// connection, ...) that PublisherSink needs.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// PublisherSink publishes events as JSON to topic on a broker, keyed by actor so
+// one actor's events stay ordered within a partition.
+type PublisherSink struct {
+	pub   Publisher
+	topic string
+}
+
+func NewPublisherSink(pub Publisher, topic string) *PublisherSink {
+	return &PublisherSink{pub: pub, topic: topic}
+}
+
+func (s *PublisherSink) Write(ctx context.Context, e Event) error {
+	b, err := Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.pub.Publish(ctx, s.topic, []byte(e.ActorID), b)
+}
+
+// Close is a no-op; the Publisher's owner closes it.
+func (s *PublisherSink) Close() error { return nil }
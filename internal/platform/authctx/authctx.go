@@ -4,6 +4,8 @@ import "context"
 
 type ctxKey struct{}
 
+type tenantKey struct{}
+
 // WithUserID stores an authenticated user id in context.
 func WithUserID(ctx context.Context, userID string) context.Context {
 	if userID == "" {
@@ -21,3 +23,20 @@ func UserID(ctx context.Context) (string, bool) {
 	}
 	return s, true
 }
+
+// WithTenantID stores the caller's tenant id in context.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantID returns the caller's tenant id, if present.
+func TenantID(ctx context.Context) (string, bool) {
+	s, ok := ctx.Value(tenantKey{}).(string)
+	if !ok || s == "" {
+		return "", false
+	}
+	return s, true
+}
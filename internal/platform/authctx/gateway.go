@@ -3,6 +3,8 @@ package authctx
 import (
 	"net/http"
 	"strings"
+
+	"sdk-microservices/internal/platform/httperr"
 )
 
 // GatewayAuth enforces Authorization header for all routes except the given public prefix.
@@ -28,11 +30,22 @@ func GatewayAuth(publicPrefix string, next http.Handler) http.Handler {
 		}
 
 		// require Authorization for everything else
-		if r.Header.Get("Authorization") == "" {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-			return
-		}
+		requireAuthorization(w, r, next)
+	})
+}
 
-		next.ServeHTTP(w, r)
+// RequireAuthorization rejects requests without an Authorization header with 401.
+// Pair it with route-scoped chains (httpmw.Routes) to decide which routes are public.
+func RequireAuthorization(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requireAuthorization(w, r, next)
 	})
 }
+
+func requireAuthorization(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if r.Header.Get("Authorization") == "" {
+		httperr.Unauthorized(w, r, "MISSING_CREDENTIALS")
+		return
+	}
+	next.ServeHTTP(w, r)
+}
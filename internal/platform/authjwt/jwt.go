@@ -1,8 +1,12 @@
 package authjwt
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
@@ -13,20 +17,76 @@ var (
 )
 
 type Service struct {
-	secret []byte
+	keys   atomic.Pointer[keys]
 	issuer string
 	ttl    int64
 }
 
 func New(secret []byte, issuer string, ttl int64) *Service {
-	return &Service{secret: secret, issuer: issuer, ttl: ttl}
+	s := &Service{issuer: issuer, ttl: ttl}
+	s.keys.Store(&keys{current: secret})
+	return s
+}
+
+// keys are the signing key and the one it replaced.
+type keys struct {
+	current, previous []byte
+}
+
+// Rotate makes secret the signing key. Tokens signed with the key it replaces
+// keep verifying until they expire, so a rotation doesn't invalidate sessions;
+// a second rotation retires that key.
+func (s *Service) Rotate(secret []byte) {
+	old := s.keys.Load()
+	if string(old.current) == string(secret) {
+		return
+	}
+	s.keys.Store(&keys{current: secret, previous: old.current})
 }
 
 type Claims struct {
 	Email string `json:"email,omitempty"`
+	// Scope is a space-delimited list of granted scopes (RFC 8693 "scope").
+	Scope string `json:"scope,omitempty"`
+	// Roles are coarse-grained role names.
+	Roles []string `json:"roles,omitempty"`
+	// TenantID is the tenant the token was issued for; servers take the
+	// caller's tenant from it rather than from forwarded metadata.
+	TenantID string `json:"tid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasScope reports whether scope is among the granted scopes.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the claims carry role.
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+type claimsKey struct{}
+
+// WithClaims stores verified claims in context (see ClaimsFrom).
+func WithClaims(ctx context.Context, c *Claims) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, claimsKey{}, c)
+}
+
+// ClaimsFrom returns the verified claims stored by WithClaims, if any.
+func ClaimsFrom(ctx context.Context) (*Claims, bool) {
+	c, ok := ctx.Value(claimsKey{}).(*Claims)
+	return c, ok && c != nil
+}
+
 func (s *Service) NewAccessToken(userID, email string, ttl time.Duration) (token string, exp time.Time, err error) {
 	now := time.Now().UTC()
 	exp = now.Add(ttl)
@@ -42,7 +102,7 @@ func (s *Service) NewAccessToken(userID, email string, ttl time.Duration) (token
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := t.SignedString(s.secret)
+	signed, err := t.SignedString(s.keys.Load().current)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("sign: %w", err)
 	}
@@ -67,7 +127,7 @@ func (s *Service) NewRefreshToken(userID, email string, ttl time.Duration) (stri
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := t.SignedString(s.secret)
+	signed, err := t.SignedString(s.keys.Load().current)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("sign: %w", err)
 	}
@@ -79,7 +139,11 @@ func (s *Service) Parse(token string) (*Claims, error) {
 		if t.Method != jwt.SigningMethodHS256 {
 			return nil, ErrInvalidToken
 		}
-		return s.secret, nil
+		k := s.keys.Load()
+		if k.previous == nil {
+			return k.current, nil
+		}
+		return jwt.VerificationKeySet{Keys: []jwt.VerificationKey{k.current, k.previous}}, nil
 	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
 	if err != nil {
 		return nil, ErrInvalidToken
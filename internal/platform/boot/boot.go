@@ -3,40 +3,88 @@ package boot
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"sdk-microservices/internal/platform/admin"
+	"sdk-microservices/internal/platform/buildinfo"
 	"sdk-microservices/internal/platform/config"
+	"sdk-microservices/internal/platform/errreport"
 	"sdk-microservices/internal/platform/health"
+	"sdk-microservices/internal/platform/httpmw"
 	"sdk-microservices/internal/platform/logging"
 	"sdk-microservices/internal/platform/otel"
+	"sdk-microservices/internal/platform/shutdown"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-// Main represents the primary server (HTTP or gRPC) for a service.
+// Main represents the servers (HTTP or gRPC) for a service: the primary one as
+// Serve/Shutdown, and any others (a debug listener, HTTP next to HTTPS) in
+// Servers.
 type Main struct {
 	Serve    func() error
 	Shutdown func(context.Context) error
+	// SetServing, if set, is called when an operator drains (false) or resumes
+	// (true) the instance via the admin server, e.g. to flip gRPC health status.
+	SetServing func(serving bool)
+
+	// Servers run alongside the primary server. All start together; when any of
+	// them exits, the service shuts down, stopping the primary server first and
+	// then Servers in order.
+	Servers []Server
+
+	// Warmup, if set, runs once the servers have started (prime caches, pre-dial
+	// downstreams, exercise the JWT parser). Until it returns, the "warmup"
+	// readiness node is unhealthy, so no traffic is routed to a cold instance.
+	// It is bounded by <SERVICE>_WARMUP_TIMEOUT; on failure the instance
+	// becomes ready anyway.
+	Warmup func(ctx context.Context) error
 }
 
 // Deps are the shared platform dependencies provided to each service.
 type Deps struct {
-	Log       *zap.Logger
+	Log *zap.Logger
+	// LogLevel is Log's level; services may change it, e.g. on a config reload.
+	LogLevel  zap.AtomicLevel
 	Metrics   http.Handler
 	ReadyRoot *health.Node
-	Serving   *atomic.Bool
+	// ReadyEvaluator refreshes ReadyRoot in the background; subscribe to it to
+	// follow readiness (e.g. grpcutil.SyncHealth). Nil when <SERVICE>_READY_INTERVAL=0.
+	ReadyEvaluator *health.Evaluator
+	// Startup gates /startupz: register one-time initialization steps (migrations,
+	// cache warmup, first DB ping) on it. Boot itself completes once build returns.
+	Startup *health.Startup
+	Serving *atomic.Bool
+	// Admin is the running admin server; services may mount extra endpoints on it.
+	Admin *admin.Server
+	// Hooks starts as Options.Hooks; build appends hooks for what it creates.
+	Hooks *Hooks
+	// Shutdown stops the service phase by phase; build registers closers for
+	// what it creates (jobs to drain, buffers to flush, pools to close). Boot
+	// stops the servers early in the Drain phase and flushes telemetry last.
+	Shutdown *shutdown.Manager
 }
 
 // Options configures the platform boot.
 type Options struct {
 	ServiceName string
+	// EnvPrefix names the platform's <SERVICE>_* variables, e.g. "AUTH" for
+	// AUTH_ADMIN_ADDR (a trailing "_" is optional). Defaults to
+	// ServiceEnvPrefix(ServiceName); set it when that guess is wrong.
+	EnvPrefix string
 
 	// AdminAddrEnv is the env var for the admin listener (defaults to <SERVICE>_ADMIN_ADDR).
 	// AdminAddrFallback is used if env var is empty (defaults to :8081).
@@ -45,9 +93,43 @@ type Options struct {
 
 	// OTELExtraAttrs are added to both tracing + metrics resources.
 	OTELExtraAttrs []attribute.KeyValue
+	// TraceSamplingRules override the env-configured trace sampling ratio per route
+	// (e.g. never sample /healthz). See otel.Sampling.
+	TraceSamplingRules []otel.SamplingRule
+	// HistogramBuckets override histogram boundaries by instrument name; latency
+	// histograms otherwise use OTEL_METRICS_LATENCY_BUCKETS or
+	// otel.DefaultLatencyBuckets.
+	HistogramBuckets map[string][]float64
+
+	// AdminHandlers are extra endpoints mounted on the admin server (see
+	// admin.Options.ExtraHandlers); AdminMiddleware wraps every admin endpoint.
+	AdminHandlers   map[string]http.Handler
+	AdminMiddleware httpmw.Chain
+
+	// ShutdownTimeout bounds the shutdown.Drain phase (the servers' graceful
+	// stop); ShutdownPhaseTimeouts override shutdown.DefaultTimeouts for the
+	// other phases.
+	ShutdownTimeout       time.Duration
+	ShutdownPhaseTimeouts map[shutdown.Phase]time.Duration
+
+	// Hooks run at fixed points of the lifecycle; see Hooks.
+	Hooks Hooks
+
+	// Config, if set, is the service's declared environment (see config.Load
+	// for the tags marking variables required or secret). Its settings are
+	// logged, redacted, at startup along with boot's own. It is reloaded on
+	// SIGHUP and, every <SERVICE>_CONFIG_WATCH, when its file changes;
+	// subscribers (e.g. config.Reloader.OnChange) apply the new values.
+	Config Reloadable
 
-	// ShutdownTimeout bounds graceful shutdown.
-	ShutdownTimeout time.Duration
+	// Flags are the daemon's command-line flags (see MustParseFlags). With
+	// -validate-config, -print-env or -print-routes, Run loads and validates
+	// the configuration, prints what was asked and returns without starting
+	// anything.
+	Flags Flags
+	// Routes lists the service's API routes for -print-routes (see GRPCRoutes
+	// and HTTPRoutes).
+	Routes []string
 }
 
 // Run boots common platform pieces (logger, OTEL, metrics, admin server, readiness root),
@@ -56,6 +138,14 @@ func Run(ctx context.Context, opts Options, build func(ctx context.Context, deps
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	// Until watchConfig runs, a SIGHUP waits on hup instead of killing the
+	// process mid-startup.
+	var hup chan os.Signal
+	if opts.Config != nil {
+		var stopHUP func()
+		hup, stopHUP = notifyHUP()
+		defer stopHUP()
+	}
 	if opts.ServiceName == "" {
 		return errors.New("boot: ServiceName is required")
 	}
@@ -63,12 +153,66 @@ func Run(ctx context.Context, opts Options, build func(ctx context.Context, deps
 		opts.ShutdownTimeout = 10 * time.Second
 	}
 
-	log, err := logging.New(opts.ServiceName)
+	envPrefix := strings.TrimSuffix(opts.EnvPrefix, "_")
+	if envPrefix == "" {
+		envPrefix = ServiceEnvPrefix(opts.ServiceName)
+	}
+	// Every missing or invalid variable is reported at once, before anything starts.
+	var cfg settings
+	env, err := config.Load(&cfg, config.LoadOptions{EnvPrefix: envPrefix + "_"})
+	if err != nil {
+		return err
+	}
+	if opts.Config != nil {
+		env = slices.Concat(opts.Config.Report(), env)
+		sort.Slice(env, func(i, j int) bool { return env[i].Env < env[j].Env })
+	}
+	if opts.Flags.inspecting() {
+		return opts.Flags.inspect(os.Stdout, env, opts.Routes)
+	}
+
+	log, logLevel, err := logging.NewLeveled(opts.ServiceName, logging.OptionsFromEnv())
 	if err != nil {
 		return err
 	}
 	defer func() { _ = log.Sync() }()
 
+	// Optional error reporting (Sentry or compatible): panics plus Error+ logs.
+	var reporter *errreport.SentryReporter
+	if dsn := cfg.SentryDSN; dsn != "" {
+		reporter, err = errreport.NewSentryReporter(errreport.SentryOptions{
+			DSN:         dsn,
+			Environment: cfg.SentryEnvironment,
+			Log:         log,
+		})
+		if err != nil {
+			return err
+		}
+		errreport.SetReporter(reporter, opts.ServiceName)
+		log = log.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return errreport.Core(c, zapcore.ErrorLevel)
+		}))
+	}
+
+	// Everything started from here on is stopped by down, phase by phase; stop
+	// runs it after a failed start.
+	phaseTimeouts := maps.Clone(opts.ShutdownPhaseTimeouts)
+	if phaseTimeouts == nil {
+		phaseTimeouts = map[shutdown.Phase]time.Duration{}
+	}
+	phaseTimeouts[shutdown.Drain] = opts.ShutdownTimeout
+	down := shutdown.New(shutdown.Options{Timeouts: phaseTimeouts, Log: log})
+	stop := func(err error) error {
+		return errors.Join(err, down.Shutdown(context.Background()))
+	}
+	if reporter != nil {
+		// Registered first, so flushed last in its phase.
+		down.Register(shutdown.Flush, "error reports", func(ctx context.Context) error {
+			errreport.SetReporter(nil, "")
+			return reporter.Close(ctx)
+		})
+	}
+
 	// Root context is canceled on SIGINT/SIGTERM or when main server errors.
 	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -78,69 +222,228 @@ func Run(ctx context.Context, opts Options, build func(ctx context.Context, deps
 	defer signal.Stop(sigc)
 
 	// OTEL tracing + metrics.
-	shutdownTrace, err := otel.Init(runCtx, opts.ServiceName, opts.OTELExtraAttrs...)
+	traceOpts := otel.InitOptions{Attributes: opts.OTELExtraAttrs}
+	if len(opts.TraceSamplingRules) > 0 {
+		sampling := otel.SamplingFromEnv()
+		sampling.Rules = opts.TraceSamplingRules
+		traceOpts.Sampling = &sampling
+	}
+	shutdownTrace, err := otel.InitWith(runCtx, opts.ServiceName, traceOpts)
 	if err != nil {
-		return err
+		return stop(err)
 	}
-	metricsH, shutdownMetrics, err := otel.InitMetricsPrometheus(runCtx, opts.ServiceName, opts.OTELExtraAttrs...)
+	down.Register(shutdown.Flush, "traces", shutdown.Closer(shutdownTrace))
+	metricsOpts, err := otel.MetricsOptionsFromEnv()
 	if err != nil {
-		_ = shutdownTrace(context.Background())
-		return err
+		return stop(err)
+	}
+	metricsOpts.Attributes = opts.OTELExtraAttrs
+	metricsOpts.HistogramBuckets = opts.HistogramBuckets
+	metricsH, shutdownMetrics, err := otel.InitMetricsPrometheusWith(runCtx, opts.ServiceName, metricsOpts)
+	if err != nil {
+		return stop(err)
 	}
+	down.Register(shutdown.Flush, "metrics", shutdown.Closer(shutdownMetrics))
+
+	if err := buildinfo.RegisterMetric(opts.ServiceName); err != nil {
+		log.Warn("build_info metric disabled (init failed)", zap.Error(err))
+	}
+	bi := buildinfo.Get()
+	log.Info("starting",
+		zap.String("version", bi.Version),
+		zap.String("commit", bi.Commit),
+		zap.String("build_time", bi.BuildTime),
+		zap.String("go_version", bi.GoVersion),
+	)
+	log.Debug("environment", zap.Any("settings", env))
 
 	// Readiness graph (admin exposes /readyz using this root).
 	ready := health.NewReadyGraph()
 	ready.Add("otel", health.CheckAlwaysReady())
 	ready.Add("metrics", health.CheckAlwaysReady())
 
+	startup := health.NewStartup()
+	bootDone := startup.Register("boot")
+
 	var serving atomic.Bool
 	serving.Store(true)
 
-	deps := Deps{
-		Log:       log,
-		Metrics:   metricsH,
-		ReadyRoot: ready,
-		Serving:   &serving,
+	// Manual drain via admin POST /drain and /serve; refused once shutdown starts.
+	var (
+		servingMu    sync.Mutex
+		shuttingDown bool
+		mainServing  func(bool)
+		drainHooks   []Hook // set once build has returned
+	)
+	setServing := func(v bool) error {
+		servingMu.Lock()
+		if shuttingDown {
+			servingMu.Unlock()
+			return errors.New("shutting down")
+		}
+		was := serving.Swap(v)
+		if mainServing != nil {
+			mainServing(v)
+		}
+		hooks := drainHooks
+		servingMu.Unlock()
+
+		if was && !v {
+			if err := runHooks(runCtx, hooks); err != nil {
+				log.Error("drain hook failed", zap.Error(err))
+			}
+		}
+		return nil
 	}
 
 	// Admin server.
 	adminEnv := opts.AdminAddrEnv
 	if adminEnv == "" {
-		adminEnv = upperServiceEnvPrefix(opts.ServiceName) + "_ADMIN_ADDR"
+		adminEnv = envPrefix + "_ADMIN_ADDR"
 	}
 	adminAddr := config.Getenv(adminEnv, ":8081")
 	if opts.AdminAddrFallback != "" {
 		adminAddr = config.Getenv(adminEnv, opts.AdminAddrFallback)
 	}
 
+	// Local resources: fd headroom and memory against GOMEMLIMIT always; free disk
+	// space when <SERVICE>_READY_DISK_PATH is set.
+	local := ready.Add("local", nil)
+	local.Add("fds", health.FileDescriptors(cfg.ReadyFDsMaxUsed))
+	local.Add("memory", health.MemoryPressure(cfg.ReadyMemoryMaxUsed))
+	if path := cfg.ReadyDiskPath; path != "" {
+		local.Add("disk", health.DiskSpace(path, cfg.ReadyDiskMinFreeMB<<20))
+	}
+
+	// Readiness is evaluated in the background every <SERVICE>_READY_INTERVAL and
+	// probes are served from cache.
+	var readyEval *health.Evaluator
+	if cfg.ReadyInterval > 0 {
+		readyEval = health.NewEvaluator(ready, health.EvaluatorOptions{
+			Interval: cfg.ReadyInterval,
+			Service:  opts.ServiceName,
+			Log:      log,
+		})
+	}
+
+	hooks := opts.Hooks.clone()
+	deps := Deps{
+		Log:            log,
+		LogLevel:       logLevel,
+		Hooks:          hooks,
+		Shutdown:       down,
+		Metrics:        metricsH,
+		ReadyRoot:      ready,
+		ReadyEvaluator: readyEval,
+		Startup:        startup,
+		Serving:        &serving,
+	}
+
 	adminSrv, err := admin.Start(log, admin.Options{
-		Addr:        adminAddr,
-		ServiceName: opts.ServiceName,
-		Metrics:     metricsH,
-		ReadyRoot:   ready,
-		ServingFn:   serving.Load,
+		Addr:           adminAddr,
+		ServiceName:    opts.ServiceName,
+		Metrics:        metricsH,
+		ReadyRoot:      ready,
+		ReadyEvaluator: readyEval,
+		ServingFn:      serving.Load,
+		SetServing:     setServing,
+		ConfigPrefixes: []string{envPrefix + "_", "OTEL_", "LOG_", "SENTRY_"},
+		Startup:        startup,
+		Pprof:          cfg.Pprof,
+		PprofToken:     cfg.PprofToken,
+		ExtraHandlers:  opts.AdminHandlers,
+		Middleware:     opts.AdminMiddleware,
 	})
 	if err != nil {
-		_ = shutdownMetrics(context.Background())
-		_ = shutdownTrace(context.Background())
-		return err
+		return stop(err)
 	}
-	defer func() {
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
-		defer shutdownCancel()
-		_ = adminSrv.Shutdown(shutdownCtx)
-	}()
+	// Admin (probes, metrics) stays up until the very end.
+	down.Register(shutdown.Close, "admin server", adminSrv.Shutdown)
+	deps.Admin = adminSrv
 
+	// Registered before build, so they run after build's own Drain closers (and
+	// after a failed start too).
+	down.Register(shutdown.Drain, "shutdown hooks", func(ctx context.Context) error {
+		return runShutdownHooks(ctx, hooks.OnShutdown)
+	})
 	main, err := build(runCtx, deps)
 	if err != nil {
-		return err
+		return stop(err)
+	}
+	servers, err := main.servers()
+	if err != nil {
+		return stop(err)
+	}
+	if err := runHooks(runCtx, hooks.OnStart); err != nil {
+		return stop(err)
 	}
-	if main.Serve == nil || main.Shutdown == nil {
-		return errors.New("boot: Main.Serve and Main.Shutdown are required")
+	var warmup *warmupGate
+	warmupDone := func() {}
+	if main.Warmup != nil {
+		warmup = &warmupGate{}
+		ready.Add("warmup", warmup.check)
+		warmupDone = startup.Register("warmup")
 	}
 
-	errCh := make(chan error, 1)
-	go func() { errCh <- main.Serve() }()
+	// Start refreshing only once the service has added its dependencies.
+	if readyEval != nil {
+		readyEval.Start(runCtx)
+	}
+
+	group := newServerGroup(servers)
+	servingMu.Lock()
+	mainServing = group.setServing
+	drainHooks = hooks.OnDrain
+	if !serving.Load() {
+		// Drained before the servers existed.
+		group.setServing(false)
+	}
+	servingMu.Unlock()
+	group.start()
+
+	var wasServing bool
+	down.Register(shutdown.StopIntake, "drain hooks", func(ctx context.Context) error {
+		if !wasServing {
+			return nil // already run by an operator drain
+		}
+		return runHooks(ctx, hooks.OnDrain)
+	})
+	down.Register(shutdown.Drain, "servers", group.shutdown)
+
+	var errs []error
+	// Self-registration, once the servers listen; deregistered first thing on
+	// shutdown so clients stop picking this instance before it drains.
+	reg, inst, err := cfg.registration(opts.ServiceName, adminSrv.Addr())
+	if err == nil && reg != nil {
+		if err = reg.Register(runCtx, inst); err == nil {
+			log.Info("registered", zap.String("registry", cfg.Registry), zap.String("id", inst.ID))
+			down.Register(shutdown.StopIntake, "registry", func(ctx context.Context) error {
+				return reg.Deregister(ctx, inst.ID)
+			})
+		}
+	}
+	if err != nil {
+		log.Error("service registration failed", zap.Error(err))
+		errs = append(errs, err)
+		cancel()
+	} else if err := runHooks(runCtx, hooks.AfterStart); err != nil {
+		log.Error("after-start hook failed", zap.Error(err))
+		errs = append(errs, err)
+		cancel()
+	} else {
+		bootDone()
+	}
+	if opts.Config != nil {
+		go watchConfig(runCtx, log, opts.Config, hup, cfg.ConfigWatch)
+	}
+	if warmup != nil {
+		go warmup.run(runCtx, log, main.Warmup, cfg.WarmupTimeout, func() {
+			warmupDone()
+			if readyEval != nil {
+				readyEval.Refresh(runCtx)
+			}
+		})
+	}
 
 	select {
 	case <-runCtx.Done():
@@ -148,38 +451,85 @@ func Run(ctx context.Context, opts Options, build func(ctx context.Context, deps
 	case sig := <-sigc:
 		log.Info("shutdown signal", zap.String("signal", sig.String()))
 		cancel()
-	case err := <-errCh:
-		if err != nil {
-			log.Error("main server exited", zap.Error(err))
+	case exit := <-group.exited:
+		if exit.err != nil {
+			log.Error("server exited", zap.String("server", exit.name), zap.Error(exit.err))
+		} else {
+			log.Info("server exited", zap.String("server", exit.name))
 		}
 		cancel()
 	}
 
 	// Stop advertising readiness before shutdown.
-	serving.Store(false)
+	servingMu.Lock()
+	shuttingDown = true
+	wasServing = serving.Swap(false)
+	servingMu.Unlock()
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
-	defer shutdownCancel()
+	return stop(errors.Join(errs...))
+}
 
-	var errs []error
-	if err := main.Shutdown(shutdownCtx); err != nil {
-		errs = append(errs, err)
-	}
-	if err := adminSrv.Shutdown(shutdownCtx); err != nil {
-		errs = append(errs, err)
-	}
-	if err := shutdownMetrics(shutdownCtx); err != nil {
-		errs = append(errs, err)
+// LoadConfig loads the service's config (config.NewReloader) and checks
+// boot's own settings under the same prefix in the same pass, so a daemon's
+// main reports every missing or invalid variable, the service's and the
+// platform's, before exiting.
+func LoadConfig[T any](opts config.LoadOptions) (*config.Reloader[T], error) {
+	conf, _, err := config.NewReloader[T](opts)
+	var platform settings
+	_, perr := config.Load(&platform, config.LoadOptions{EnvPrefix: opts.EnvPrefix, LookupEnv: opts.LookupEnv})
+	if err := errors.Join(err, perr); err != nil {
+		return nil, err
 	}
-	if err := shutdownTrace(shutdownCtx); err != nil {
-		errs = append(errs, err)
+	return conf, nil
+}
+
+// settings are the platform's own <SERVICE>_* variables.
+type settings struct {
+	SentryDSN         string `env:"SENTRY_DSN,noprefix,secret"`
+	SentryEnvironment string `env:"SENTRY_ENVIRONMENT,noprefix"`
+
+	// Profiling is opt-in, optionally guarded by a bearer token.
+	Pprof      bool   `env:"PPROF"`
+	PprofToken string `env:"PPROF_TOKEN,secret"`
+
+	ReadyFDsMaxUsed    float64 `env:"READY_FDS_MAX_USED" default:"0.9"`
+	ReadyMemoryMaxUsed float64 `env:"READY_MEMORY_MAX_USED" default:"0.95"`
+	ReadyDiskPath      string  `env:"READY_DISK_PATH"`
+	ReadyDiskMinFreeMB uint64  `env:"READY_DISK_MIN_FREE_MB" default:"100"`
+	// ReadyInterval 0 evaluates readiness on every probe.
+	ReadyInterval time.Duration `env:"READY_INTERVAL" default:"5s"`
+	// ConfigWatch polls Options.Config's file for changes (0: SIGHUP only).
+	ConfigWatch time.Duration `env:"CONFIG_WATCH"`
+	// WarmupTimeout bounds Main.Warmup (0: no limit).
+	WarmupTimeout time.Duration `env:"WARMUP_TIMEOUT" default:"30s"`
+
+	// Registry self-registers the instance, reachable at AdvertiseAddr, once
+	// the servers are up and deregisters it when shutdown begins. The
+	// registry's health check polls the admin /readyz.
+	Registry                string        `env:"REGISTRY" oneof:"consul"`
+	AdvertiseAddr           string        `env:"ADVERTISE_ADDR"`
+	RegistryTags            []string      `env:"REGISTRY_TAGS"`
+	RegistryCheckInterval   time.Duration `env:"REGISTRY_CHECK_INTERVAL" default:"10s"`
+	RegistryDeregisterAfter time.Duration `env:"REGISTRY_DEREGISTER_AFTER" default:"1m"`
+	ConsulAddr              string        `env:"CONSUL_HTTP_ADDR,noprefix"`
+	ConsulToken             string        `env:"CONSUL_HTTP_TOKEN,noprefix,secret"`
+}
+
+func (s *settings) Validate() error {
+	if s.Registry != "" {
+		if _, _, err := splitHostPort(s.AdvertiseAddr); err != nil {
+			return fmt.Errorf("ADVERTISE_ADDR is required with REGISTRY=%s: %w", s.Registry, err)
+		}
 	}
-	return errors.Join(errs...)
+	return nil
 }
 
-func upperServiceEnvPrefix(service string) string {
-	// "gateway" -> "GATEWAY", "authd" -> "AUTH" (strip trailing d), etc.
-	// Be conservative: uppercase and replace '-' with '_'.
+// ServiceEnvPrefix is the env prefix boot derives from a service name when
+// Options.EnvPrefix is unset: the name upper-cased, with '-' and ' ' as '_'
+// ("api-gateway" -> "API_GATEWAY"). A trailing 'd' is dropped on the guess
+// that the name is a daemon binary's ("authd" -> "AUTH"); names that really
+// end in 'd' ("dashboard" -> "DASHBOAR") need an explicit EnvPrefix.
+func ServiceEnvPrefix(service string) string {
 	s := service
 	if len(s) > 1 && s[len(s)-1] == 'd' {
 		s = s[:len(s)-1]
@@ -0,0 +1,65 @@
+package boot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sdk-microservices/internal/platform/config"
+)
+
+func TestServiceEnvPrefix(t *testing.T) {
+	for service, want := range map[string]string{
+		"gateway":         "GATEWAY",
+		"authd":           "AUTH",
+		"api-gateway":     "API_GATEWAY",
+		"Hello World-api": "HELLO_WORLD_API",
+		"d":               "D",
+		"dashboard":       "DASHBOAR", // the heuristic's known miss; see Options.EnvPrefix
+	} {
+		if got := ServiceEnvPrefix(service); got != want {
+			t.Errorf("ServiceEnvPrefix(%q) = %q, want %q", service, got, want)
+		}
+	}
+}
+
+func TestRunEnvPrefix(t *testing.T) {
+	build := func(context.Context, Deps) (Main, error) {
+		t.Fatal("build called with an invalid setting")
+		return Main{}, nil
+	}
+	t.Setenv("DASHBOARD_READY_INTERVAL", "soon")
+
+	for _, prefix := range []string{"DASHBOARD", "DASHBOARD_"} {
+		err := Run(context.Background(), Options{ServiceName: "dashboard", EnvPrefix: prefix}, build)
+		if err == nil || !strings.Contains(err.Error(), "DASHBOARD_READY_INTERVAL") {
+			t.Fatalf("EnvPrefix %q: err = %v, want DASHBOARD_READY_INTERVAL rejected", prefix, err)
+		}
+	}
+
+	// Without EnvPrefix the heuristic reads DASHBOAR_*, so the bad value is
+	// not even seen.
+	err := Run(context.Background(), Options{ServiceName: "dashboard", Flags: Flags{ValidateConfig: true}}, build)
+	if err != nil {
+		t.Fatalf("fallback prefix: err = %v", err)
+	}
+}
+
+func TestLoadConfigReportsServiceAndPlatformErrors(t *testing.T) {
+	type serviceConfig struct {
+		JWTSecret string `env:"JWT_SECRET,required,secret"`
+	}
+	env := map[string]string{"SVC_READY_INTERVAL": "soon"}
+	_, err := LoadConfig[serviceConfig](config.LoadOptions{
+		EnvPrefix: "SVC_",
+		LookupEnv: func(k string) (string, bool) { v, ok := env[k]; return v, ok },
+	})
+	if err == nil {
+		t.Fatal("LoadConfig succeeded")
+	}
+	for _, want := range []string{"SVC_JWT_SECRET", "SVC_READY_INTERVAL"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
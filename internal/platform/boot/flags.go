@@ -0,0 +1,177 @@
+package boot
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"sdk-microservices/internal/platform/buildinfo"
+	"sdk-microservices/internal/platform/config"
+)
+
+// Flags are the command-line flags every daemon accepts, so operators and CI
+// can sanity-check a binary without starting it:
+//
+//	-version          print the build info and exit
+//	-config <path>    config file (overrides <SERVICE>_CONFIG_FILE)
+//	-validate-config  load and validate the configuration, then exit
+//	-print-env        print the effective settings (redacted), then exit
+//	-print-routes     print the service's routes (Options.Routes), then exit
+//
+// Both -flag and --flag work. Any other argument is left in Args for the
+// config flag layer (e.g. -addr=:9090, see config.LoadOptions.Args).
+type Flags struct {
+	Version        bool
+	ConfigFile     string
+	ValidateConfig bool
+	PrintEnv       bool
+	PrintRoutes    bool
+
+	// Args are the arguments left after the flags above.
+	Args []string
+}
+
+// ParseFlags extracts Flags from args (usually os.Args[1:]). It returns
+// flag.ErrHelp for -h/-help.
+func ParseFlags(args []string) (Flags, error) {
+	f := Flags{Args: []string{}}
+	bools := map[string]*bool{
+		"version":         &f.Version,
+		"validate-config": &f.ValidateConfig,
+		"print-env":       &f.PrintEnv,
+		"print-routes":    &f.PrintRoutes,
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			f.Args = append(f.Args, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			f.Args = append(f.Args, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		switch {
+		case name == "h" || name == "help":
+			return Flags{}, flag.ErrHelp
+		case name == "config":
+			if !hasValue {
+				if i+1 == len(args) {
+					return Flags{}, errors.New("flag needs an argument: -config")
+				}
+				i++
+				value = args[i]
+			}
+			f.ConfigFile = value
+		case bools[name] != nil:
+			v := true
+			if hasValue {
+				var err error
+				if v, err = strconv.ParseBool(value); err != nil {
+					return Flags{}, fmt.Errorf("invalid boolean value %q for -%s", value, name)
+				}
+			}
+			*bools[name] = v
+		default:
+			f.Args = append(f.Args, arg)
+		}
+	}
+	return f, nil
+}
+
+// MustParseFlags is ParseFlags on os.Args[1:] for a daemon's main: it prints
+// the usage or the version and exits when asked to, and exits with status 2
+// on a bad flag.
+func MustParseFlags(name string) Flags {
+	f, err := ParseFlags(os.Args[1:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		printUsage(os.Stdout, name)
+		os.Exit(0)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		printUsage(os.Stderr, name)
+		os.Exit(2)
+	case f.Version:
+		printVersion(os.Stdout, name)
+		os.Exit(0)
+	}
+	return f
+}
+
+// ConfigFileOr returns the -config path, or the value of the env var env when
+// the flag is absent.
+func (f Flags) ConfigFileOr(env string) string {
+	if f.ConfigFile != "" {
+		return f.ConfigFile
+	}
+	return os.Getenv(env)
+}
+
+// inspecting reports whether f asks Run to print something and exit instead
+// of starting the service.
+func (f Flags) inspecting() bool {
+	return f.ValidateConfig || f.PrintEnv || f.PrintRoutes
+}
+
+// inspect answers -print-env, -print-routes and -validate-config. It runs
+// once the configuration has loaded, so reaching it means the config is valid.
+func (f Flags) inspect(w io.Writer, env config.Report, routes []string) error {
+	if f.PrintEnv {
+		if _, err := env.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	if f.PrintRoutes {
+		if len(routes) == 0 {
+			return errors.New("boot: -print-routes: the service declares no routes (Options.Routes)")
+		}
+		for _, r := range routes {
+			fmt.Fprintln(w, r)
+		}
+	}
+	if f.ValidateConfig {
+		fmt.Fprintln(w, "config ok")
+	}
+	return nil
+}
+
+func printVersion(w io.Writer, name string) {
+	bi := buildinfo.Get()
+	version := bi.Version
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Fprintf(w, "%s %s", name, version)
+	if bi.Commit != "" {
+		fmt.Fprintf(w, " (commit %s", bi.Commit)
+		if bi.Modified {
+			fmt.Fprint(w, ", modified")
+		}
+		fmt.Fprint(w, ")")
+	}
+	if bi.BuildTime != "" {
+		fmt.Fprintf(w, " built %s", bi.BuildTime)
+	}
+	fmt.Fprintf(w, " %s\n", bi.GoVersion)
+}
+
+func printUsage(w io.Writer, name string) {
+	fmt.Fprintf(w, `Usage: %s [flags] [-<setting>=<value> ...]
+
+Flags:
+  -version          print the build info and exit
+  -config <path>    config file (YAML or JSON)
+  -validate-config  load and validate the configuration, then exit
+  -print-env        print the effective settings (secrets redacted), then exit
+  -print-routes     print the service's routes, then exit
+
+Settings are read from the environment and the config file; -<setting>=<value>
+overrides one, e.g. -addr=:9090 for ADDR.
+`, name)
+}
@@ -0,0 +1,69 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+
+	hellov1 "sdk-microservices/gen/api/proto/hello/v1"
+)
+
+func TestParseFlags(t *testing.T) {
+	f, err := ParseFlags([]string{"--version", "-config", "hello.yaml", "-addr=:9090", "--print-env=false", "-validate-config", "-xds", "--", "-print-routes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Version || f.ConfigFile != "hello.yaml" || f.PrintEnv || !f.ValidateConfig || f.PrintRoutes {
+		t.Fatalf("flags = %+v", f)
+	}
+	if want := []string{"-addr=:9090", "-xds", "--", "-print-routes"}; !slices.Equal(f.Args, want) {
+		t.Fatalf("Args = %q, want %q", f.Args, want)
+	}
+
+	if f, _ := ParseFlags([]string{"--config=/etc/hello.yaml"}); f.ConfigFile != "/etc/hello.yaml" {
+		t.Fatalf("ConfigFile = %q", f.ConfigFile)
+	}
+	if _, err := ParseFlags([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("-h: err = %v", err)
+	}
+	if _, err := ParseFlags([]string{"-config"}); err == nil {
+		t.Fatal("-config without a value accepted")
+	}
+	if _, err := ParseFlags([]string{"-version=maybe"}); err == nil {
+		t.Fatal("-version=maybe accepted")
+	}
+}
+
+func TestRunInspectDoesNotStart(t *testing.T) {
+	t.Setenv("BOOTTEST_ADMIN_ADDR", "127.0.0.1:0")
+
+	err := Run(context.Background(), Options{
+		ServiceName: "boottest",
+		Flags:       Flags{ValidateConfig: true, PrintRoutes: true},
+		Routes:      GRPCRoutes(&hellov1.HelloService_ServiceDesc),
+	}, func(context.Context, Deps) (Main, error) {
+		t.Fatal("build called with -validate-config")
+		return Main{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	err = Run(context.Background(), Options{ServiceName: "boottest", Flags: Flags{PrintRoutes: true}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "no routes") {
+		t.Fatalf("-print-routes without routes: err = %v", err)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	if got := GRPCRoutes(&hellov1.HelloService_ServiceDesc); !slices.Equal(got, []string{"/hello.v1.HelloService/Hello"}) {
+		t.Fatalf("GRPCRoutes = %q", got)
+	}
+	got := HTTPRoutes(hellov1.File_api_proto_hello_v1_hello_proto.Services().ByName("HelloService"))
+	if want := []string{"GET /v1/hello/{name} -> hello.v1.HelloService/Hello"}; !slices.Equal(got, want) {
+		t.Fatalf("HTTPRoutes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"sdk-microservices/internal/platform/grpcutil"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpc_health "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCOptions configures RunGRPC.
+type GRPCOptions struct {
+	Options
+
+	// Addr is the gRPC listen address.
+	Addr string
+	// XDS serves through an xDS-managed server (see grpcutil.NewServer).
+	XDS bool
+	// PreStopDelay keeps serving after health flips to NOT_SERVING (see
+	// grpcutil.GracefulOptions).
+	PreStopDelay time.Duration
+}
+
+// GRPCService is what a RunGRPC build returns.
+type GRPCService struct {
+	// Limits (timeouts, backpressure, identity) for the platform interceptors;
+	// see grpcutil.ServerOptionsWithNameAndLimits.
+	Limits grpcutil.Limits
+	// ServerOptions are added after the platform's, e.g. service-specific
+	// interceptors.
+	ServerOptions []grpc.ServerOption
+	// Register registers the service implementations.
+	Register func(s grpc.ServiceRegistrar)
+	// Health maps each gRPC service name to the readiness nodes it needs (see
+	// grpcutil.SyncHealth); the health service reports each one.
+	Health map[string][]string
+	// Servers run alongside the gRPC server (see Main.Servers).
+	Servers []Server
+	// Warmup holds readiness until it returns (see Main.Warmup).
+	Warmup func(ctx context.Context) error
+}
+
+// RunGRPC is Run for a gRPC service: it builds the server with the platform
+// interceptors, registers the gRPC health service driven by readiness, listens
+// on Addr and drains gracefully on shutdown. build registers cleanup of what
+// it creates on Deps.Shutdown; the server stops first in the Drain phase.
+func RunGRPC(ctx context.Context, opts GRPCOptions, build func(ctx context.Context, deps Deps) (GRPCService, error)) error {
+	return Run(ctx, opts.Options, func(ctx context.Context, deps Deps) (Main, error) {
+		svc, err := build(ctx, deps)
+		if err != nil {
+			return Main{}, err
+		}
+		if svc.Register == nil {
+			return Main{}, errors.New("boot: GRPCService.Register is required")
+		}
+
+		serverOpts := append(grpcutil.ServerOptionsWithNameAndLimits(opts.ServiceName, deps.Log, svc.Limits), svc.ServerOptions...)
+		gs, err := grpcutil.NewServer(opts.XDS, serverOpts...)
+		if err != nil {
+			return Main{}, err
+		}
+		svc.Register(gs)
+
+		hs := grpc_health.NewServer()
+		for name := range svc.Health {
+			hs.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+		}
+		healthpb.RegisterHealthServer(gs, hs)
+		if deps.ReadyEvaluator != nil {
+			deps.ReadyEvaluator.Subscribe(grpcutil.SyncHealth(hs, svc.Health))
+		}
+
+		lis, err := net.Listen("tcp", opts.Addr)
+		if err != nil {
+			return Main{}, err
+		}
+		gsrv := grpcutil.ServeWithGracefulShutdown(lis, gs, hs, grpcutil.GracefulOptions{
+			PreStopDelay: opts.PreStopDelay,
+		})
+
+		return Main{
+			Serve: func() error {
+				deps.Log.Info("grpc listening", zap.String("addr", lis.Addr().String()))
+				return gsrv.Serve()
+			},
+			Shutdown:   gsrv.Shutdown,
+			SetServing: gsrv.SetServing,
+			Servers:    svc.Servers,
+			Warmup:     svc.Warmup,
+		}, nil
+	})
+}
@@ -0,0 +1,52 @@
+package boot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestRunGRPCLifecycle(t *testing.T) {
+	t.Setenv("BOOTTEST_ADMIN_ADDR", "127.0.0.1:0")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var order []string
+	record := func(name string) Hook {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	opts := GRPCOptions{
+		Options: Options{
+			ServiceName: "boottest",
+			Hooks: Hooks{
+				OnStart:    []Hook{record("start")},
+				OnShutdown: []Hook{record("shutdown (options)")},
+			},
+		},
+		Addr: "127.0.0.1:0",
+	}
+	err := RunGRPC(ctx, opts, func(ctx context.Context, deps Deps) (GRPCService, error) {
+		deps.Hooks.AfterStart = append(deps.Hooks.AfterStart, func(context.Context) error {
+			order = append(order, "after start")
+			cancel()
+			return nil
+		})
+		deps.Hooks.OnDrain = append(deps.Hooks.OnDrain, record("drain"))
+		deps.Hooks.OnShutdown = append(deps.Hooks.OnShutdown, record("shutdown (build)"))
+		return GRPCService{Register: func(grpc.ServiceRegistrar) {}}, nil
+	})
+	if err != nil {
+		t.Fatalf("RunGRPC: %v", err)
+	}
+	want := "start,after start,drain,shutdown (build),shutdown (options)"
+	if got := strings.Join(order, ","); got != want {
+		t.Fatalf("hooks ran %s, want %s", got, want)
+	}
+}
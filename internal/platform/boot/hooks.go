@@ -0,0 +1,64 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Hook runs at one point of the service lifecycle (see Hooks).
+type Hook func(ctx context.Context) error
+
+// Hooks are run by Run at well-defined points, in registration order unless
+// noted:
+//
+//   - OnStart after build returns, before the servers start (e.g. cache warmup).
+//     The first error aborts boot.
+//   - AfterStart once the servers are started (e.g. background jobs). ctx is
+//     canceled when shutdown begins. An error shuts the service down.
+//   - OnDrain when the instance stops serving: an operator drain via the admin
+//     server, or the start of shutdown before the servers stop. Errors are logged.
+//   - OnShutdown after the servers have stopped, in reverse order (like defer),
+//     at the end of the shutdown.Drain phase. They also run when boot fails
+//     after registering them. Errors are returned by Run. Prefer registering
+//     on Deps.Shutdown, which places closers in the right phase.
+//
+// Set them in Options, or append to Deps.Hooks from build for resources build
+// creates.
+type Hooks struct {
+	OnStart    []Hook
+	AfterStart []Hook
+	OnDrain    []Hook
+	OnShutdown []Hook
+}
+
+// clone copies h so that appends from build don't touch the caller's Options.
+func (h Hooks) clone() *Hooks {
+	return &Hooks{
+		OnStart:    slices.Clone(h.OnStart),
+		AfterStart: slices.Clone(h.AfterStart),
+		OnDrain:    slices.Clone(h.OnDrain),
+		OnShutdown: slices.Clone(h.OnShutdown),
+	}
+}
+
+// runHooks runs hooks in order, stopping at the first error.
+func runHooks(ctx context.Context, hooks []Hook) error {
+	for _, h := range hooks {
+		if err := h(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runShutdownHooks runs every hook in reverse order and returns all errors.
+func runShutdownHooks(ctx context.Context, hooks []Hook) error {
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
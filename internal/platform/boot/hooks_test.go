@@ -0,0 +1,44 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var order []string
+	hook := func(name string, err error) Hook {
+		return func(context.Context) error {
+			order = append(order, name)
+			return err
+		}
+	}
+	boom := errors.New("boom")
+
+	if err := runHooks(context.Background(), []Hook{hook("a", nil), hook("b", boom), hook("c", nil)}); !errors.Is(err, boom) {
+		t.Fatalf("runHooks err = %v", err)
+	}
+	if got := strings.Join(order, ","); got != "a,b" {
+		t.Fatalf("runHooks ran %s, want a,b", got)
+	}
+
+	order = nil
+	err := runShutdownHooks(context.Background(), []Hook{hook("a", nil), hook("b", boom), hook("c", nil)})
+	if !errors.Is(err, boom) {
+		t.Fatalf("runShutdownHooks err = %v", err)
+	}
+	if got := strings.Join(order, ","); got != "c,b,a" {
+		t.Fatalf("runShutdownHooks ran %s, want c,b,a", got)
+	}
+}
+
+func TestHooksCloneIsolatesOptions(t *testing.T) {
+	opts := Options{Hooks: Hooks{OnShutdown: make([]Hook, 1, 4)}}
+	h := opts.Hooks.clone()
+	h.OnShutdown = append(h.OnShutdown, func(context.Context) error { return nil })
+	if got := opts.Hooks.OnShutdown[:2][1]; got != nil {
+		t.Fatal("append through Deps.Hooks wrote into Options.Hooks")
+	}
+}
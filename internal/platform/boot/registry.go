@@ -0,0 +1,66 @@
+package boot
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"sdk-microservices/internal/platform/buildinfo"
+	"sdk-microservices/internal/platform/registry"
+)
+
+// registration returns the registrar and instance for <SERVICE>_REGISTRY, or a
+// nil registrar when self-registration is off. adminAddr is where the
+// registry's health check reaches /readyz.
+func (s *settings) registration(service string, adminAddr net.Addr) (registry.Registrar, registry.Instance, error) {
+	if s.Registry == "" {
+		return nil, registry.Instance{}, nil
+	}
+	host, port, err := splitHostPort(s.AdvertiseAddr)
+	if err != nil {
+		return nil, registry.Instance{}, err
+	}
+	_, adminPort, err := net.SplitHostPort(adminAddr.String())
+	if err != nil {
+		return nil, registry.Instance{}, err
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = host
+	}
+	bi := buildinfo.Get()
+	inst := registry.Instance{
+		ID:      fmt.Sprintf("%s-%s-%d", service, hostname, port),
+		Name:    service,
+		Address: host,
+		Port:    port,
+		Tags:    s.RegistryTags,
+		Meta: map[string]string{
+			"version": bi.Version,
+			"commit":  bi.Commit,
+		},
+		HealthURL:       "http://" + net.JoinHostPort(host, adminPort) + "/readyz",
+		HealthInterval:  s.RegistryCheckInterval,
+		DeregisterAfter: s.RegistryDeregisterAfter,
+	}
+	// Validate restricts Registry to the registrars below.
+	return registry.NewConsul(registry.ConsulOptions{Addr: s.ConsulAddr, Token: s.ConsulToken}), inst, nil
+}
+
+// splitHostPort parses an advertised host:port; both parts are required.
+func splitHostPort(addr string) (string, int, error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port in %q", addr)
+	}
+	if host == "" {
+		return "", 0, errors.New("host is required (the address clients dial)")
+	}
+	return host, port, nil
+}
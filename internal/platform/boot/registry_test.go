@@ -0,0 +1,83 @@
+package boot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunRegistersWithConsul(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+		check string
+	)
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.URL.Path)
+		if r.URL.Path == "/v1/agent/service/register" {
+			var reg struct {
+				Name  string
+				Port  int
+				Check struct{ HTTP string }
+			}
+			_ = json.NewDecoder(r.Body).Decode(&reg)
+			if reg.Name != "boottest" || reg.Port != 50051 {
+				http.Error(w, "bad registration", http.StatusBadRequest)
+				return
+			}
+			check = reg.Check.HTTP
+		}
+	}))
+	defer consul.Close()
+
+	t.Setenv("BOOTTEST_ADMIN_ADDR", "127.0.0.1:0")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("BOOTTEST_REGISTRY", "consul")
+	t.Setenv("BOOTTEST_ADVERTISE_ADDR", "10.0.0.1:50051")
+	t.Setenv("CONSUL_HTTP_ADDR", consul.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := Run(ctx, Options{ServiceName: "boottest"}, func(ctx context.Context, deps Deps) (Main, error) {
+		deps.Hooks.AfterStart = append(deps.Hooks.AfterStart, func(context.Context) error {
+			cancel()
+			return nil
+		})
+		stop := make(chan struct{})
+		return Main{
+			Serve:    func() error { <-stop; return nil },
+			Shutdown: func(context.Context) error { close(stop); return nil },
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 2 || calls[0] != "/v1/agent/service/register" || !strings.HasPrefix(calls[1], "/v1/agent/service/deregister/boottest-") {
+		t.Fatalf("consul calls = %v", calls)
+	}
+	if !strings.HasPrefix(check, "http://10.0.0.1:") || !strings.HasSuffix(check, "/readyz") {
+		t.Fatalf("health check = %q", check)
+	}
+}
+
+func TestRegistryRequiresAdvertiseAddr(t *testing.T) {
+	t.Setenv("BOOTTEST_REGISTRY", "consul")
+	t.Setenv("BOOTTEST_ADVERTISE_ADDR", ":50051")
+
+	err := Run(context.Background(), Options{ServiceName: "boottest"}, func(context.Context, Deps) (Main, error) {
+		t.Fatal("build called with an invalid registry config")
+		return Main{}, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "ADVERTISE_ADDR") {
+		t.Fatalf("err = %v", err)
+	}
+}
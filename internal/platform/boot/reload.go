@@ -0,0 +1,93 @@
+package boot
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"sdk-microservices/internal/platform/config"
+
+	"go.uber.org/zap"
+)
+
+// Reloadable is configuration that boot logs at startup and reloads on
+// SIGHUP, e.g. a *config.Reloader.
+type Reloadable interface {
+	// Report lists the current settings, secrets redacted.
+	Report() config.Report
+	Reload() (changed config.Report, err error)
+	// File is the config file, also watched for changes when
+	// <SERVICE>_CONFIG_WATCH is set ("" if none).
+	File() string
+}
+
+// notifyHUP starts queueing SIGHUP on the returned channel (one pending signal
+// at most), replacing the default action of killing the process. Run calls it
+// before anything else so a reload signal sent while the service is still
+// starting is handled by watchConfig once it runs rather than fatal.
+func notifyHUP() (hup chan os.Signal, stop func()) {
+	hup = make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	return hup, func() { signal.Stop(hup) }
+}
+
+// watchConfig reloads c on each signal from hup and, with every > 0, whenever
+// its file's modification time or size changes, until ctx is done.
+func watchConfig(ctx context.Context, log *zap.Logger, c Reloadable, hup <-chan os.Signal, every time.Duration) {
+	var tick <-chan time.Time
+	file := c.File()
+	var last fileStamp
+	if every > 0 && file != "" {
+		t := time.NewTicker(every)
+		defer t.Stop()
+		tick = t.C
+		last = stampOf(file)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reloadConfig(log, c, "SIGHUP")
+		case <-tick:
+			if s := stampOf(file); s != last {
+				last = s
+				reloadConfig(log, c, "file changed")
+			}
+		}
+	}
+}
+
+func reloadConfig(log *zap.Logger, c Reloadable, reason string) {
+	changed, err := c.Reload()
+	if err != nil {
+		log.Error("config reload failed; keeping the current config", zap.String("reason", reason), zap.Error(err))
+		return
+	}
+	if len(changed) == 0 {
+		log.Info("config reloaded; nothing changed", zap.String("reason", reason))
+		return
+	}
+	fields := make([]zap.Field, 0, len(changed)+1)
+	fields = append(fields, zap.String("reason", reason))
+	for _, s := range changed {
+		fields = append(fields, zap.String(s.Env, s.Value))
+	}
+	log.Info("config reloaded", fields...)
+}
+
+// fileStamp identifies a version of a file; the zero value means it is missing.
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+func stampOf(path string) fileStamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{mod: fi.ModTime(), size: fi.Size()}
+}
@@ -0,0 +1,97 @@
+package boot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/config"
+
+	"go.uber.org/zap"
+)
+
+type countingConfig struct {
+	file    string
+	reloads chan struct{}
+}
+
+func (c *countingConfig) Reload() (config.Report, error) {
+	c.reloads <- struct{}{}
+	return nil, nil
+}
+
+func (c *countingConfig) Report() config.Report { return nil }
+
+func (c *countingConfig) File() string { return c.file }
+
+func TestWatchConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "svc.yaml")
+	if err := os.WriteFile(file, []byte("a: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := &countingConfig{file: file, reloads: make(chan struct{}, 4)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hup := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		watchConfig(ctx, zap.NewNop(), c, hup, 5*time.Millisecond)
+		close(done)
+	}()
+	defer func() { cancel(); <-done }()
+
+	wait := func(what string) {
+		t.Helper()
+		select {
+		case <-c.reloads:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no reload on %s", what)
+		}
+	}
+
+	hup <- syscall.SIGHUP
+	wait("SIGHUP")
+
+	if err := os.WriteFile(file, []byte("a: 22\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wait("file change")
+}
+
+// A SIGHUP sent while the service is still starting is queued for the config
+// watcher instead of killing the process.
+func TestRunHandlesSIGHUPDuringStartup(t *testing.T) {
+	t.Setenv("BOOTTEST_ADMIN_ADDR", "127.0.0.1:0")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	c := &countingConfig{reloads: make(chan struct{}, 4)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := Run(ctx, Options{ServiceName: "boottest", Config: c}, func(ctx context.Context, deps Deps) (Main, error) {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			return Main{}, err
+		}
+		deps.Hooks.AfterStart = append(deps.Hooks.AfterStart, func(context.Context) error {
+			go func() {
+				select {
+				case <-c.reloads:
+				case <-time.After(2 * time.Second):
+					t.Error("SIGHUP sent during build was not handled")
+				}
+				cancel()
+			}()
+			return nil
+		})
+		stop := make(chan struct{})
+		return Main{
+			Serve:    func() error { <-stop; return nil },
+			Shutdown: func(context.Context) error { close(stop); return nil },
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package boot
+
+import (
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// GRPCRoutes lists the full method names of services ("/hello.v1.HelloService/SayHello"),
+// for Options.Routes.
+func GRPCRoutes(services ...*grpc.ServiceDesc) []string {
+	var routes []string
+	for _, s := range services {
+		for _, m := range s.Methods {
+			routes = append(routes, "/"+s.ServiceName+"/"+m.MethodName)
+		}
+		for _, m := range s.Streams {
+			routes = append(routes, "/"+s.ServiceName+"/"+m.StreamName+" (stream)")
+		}
+	}
+	return routes
+}
+
+// HTTPRoutes lists the google.api.http bindings of services, as served by
+// grpc-gateway ("POST /v1/auth/login -> auth.v1.AuthService/Login"), for
+// Options.Routes.
+func HTTPRoutes(services ...protoreflect.ServiceDescriptor) []string {
+	var routes []string
+	for _, s := range services {
+		methods := s.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			m := methods.Get(i)
+			rule, _ := proto.GetExtension(m.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if rule == nil {
+				continue
+			}
+			target := " -> " + string(s.FullName()) + "/" + string(m.Name())
+			for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				if method, path := httpPattern(r); path != "" {
+					routes = append(routes, method+" "+path+target)
+				}
+			}
+		}
+	}
+	return routes
+}
+
+func httpPattern(r *annotations.HttpRule) (method, path string) {
+	switch p := r.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "GET", p.Get
+	case *annotations.HttpRule_Put:
+		return "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		return "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		return "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		return "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	}
+	return "", ""
+}
@@ -0,0 +1,81 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Server is one listener run by boot, e.g. the gRPC API or a debug HTTP server.
+type Server struct {
+	// Name identifies the server in logs and errors.
+	Name     string
+	Serve    func() error
+	Shutdown func(context.Context) error
+	// SetServing, if set, follows operator drain/resume (see Main.SetServing).
+	SetServing func(serving bool)
+}
+
+// servers returns m's servers: Serve/Shutdown (as "main") followed by Servers.
+func (m Main) servers() ([]Server, error) {
+	var out []Server
+	if m.Serve != nil || m.Shutdown != nil {
+		out = append(out, Server{Name: "main", Serve: m.Serve, Shutdown: m.Shutdown, SetServing: m.SetServing})
+	}
+	out = append(out, m.Servers...)
+	if len(out) == 0 {
+		return nil, errors.New("boot: Main needs Serve and Shutdown, or Servers")
+	}
+	for i, s := range out {
+		if s.Name == "" {
+			out[i].Name = fmt.Sprintf("server %d", i)
+		}
+		if s.Serve == nil || s.Shutdown == nil {
+			return nil, fmt.Errorf("boot: %s: Serve and Shutdown are required", out[i].Name)
+		}
+	}
+	return out, nil
+}
+
+// serverExit is a server's Serve result.
+type serverExit struct {
+	name string
+	err  error
+}
+
+// serverGroup runs servers together: all start at once, the first to exit
+// (normally or not) is reported on exited, and shutdown stops them in order.
+type serverGroup struct {
+	servers []Server
+	exited  chan serverExit
+}
+
+func newServerGroup(servers []Server) *serverGroup {
+	return &serverGroup{servers: servers, exited: make(chan serverExit, len(servers))}
+}
+
+func (g *serverGroup) start() {
+	for _, s := range g.servers {
+		go func(s Server) { g.exited <- serverExit{name: s.Name, err: s.Serve()} }(s)
+	}
+}
+
+func (g *serverGroup) setServing(v bool) {
+	for _, s := range g.servers {
+		if s.SetServing != nil {
+			s.SetServing(v)
+		}
+	}
+}
+
+// shutdown stops the servers in declaration order, each with what remains
+// of ctx, and returns every error.
+func (g *serverGroup) shutdown(ctx context.Context) error {
+	var errs []error
+	for _, s := range g.servers {
+		if err := s.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
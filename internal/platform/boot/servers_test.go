@@ -0,0 +1,73 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeServer blocks in Serve until Shutdown, recording the shutdown order.
+func fakeServer(name string, order *[]string, serveErr error) Server {
+	stop := make(chan struct{})
+	return Server{
+		Name: name,
+		Serve: func() error {
+			if serveErr != nil {
+				return serveErr
+			}
+			<-stop
+			return nil
+		},
+		Shutdown: func(context.Context) error {
+			*order = append(*order, name)
+			close(stop)
+			return nil
+		},
+	}
+}
+
+func TestMainServers(t *testing.T) {
+	var order []string
+	m := Main{
+		Serve:    func() error { return nil },
+		Shutdown: func(context.Context) error { return nil },
+		Servers:  []Server{fakeServer("debug", &order, nil), {Serve: func() error { return nil }, Shutdown: func(context.Context) error { return nil }}},
+	}
+	servers, err := m.servers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 3 || servers[0].Name != "main" || servers[1].Name != "debug" || servers[2].Name != "server 2" {
+		t.Fatalf("servers = %+v", servers)
+	}
+
+	if _, err := (Main{}).servers(); err == nil {
+		t.Fatal("empty Main accepted")
+	}
+	if _, err := (Main{Servers: []Server{{Name: "x", Serve: func() error { return nil }}}}).servers(); err == nil || !strings.Contains(err.Error(), "x") {
+		t.Fatalf("server without Shutdown: err = %v", err)
+	}
+}
+
+func TestServerGroup_FirstExitThenOrderedShutdown(t *testing.T) {
+	var order []string
+	boom := errors.New("bind: address already in use")
+	g := newServerGroup([]Server{
+		fakeServer("grpc", &order, nil),
+		fakeServer("https", &order, boom),
+		fakeServer("debug", &order, nil),
+	})
+	g.start()
+
+	exit := <-g.exited
+	if exit.name != "https" || !errors.Is(exit.err, boom) {
+		t.Fatalf("first exit = %+v", exit)
+	}
+	if err := g.shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "grpc,https,debug" {
+		t.Fatalf("shutdown order = %v", order)
+	}
+}
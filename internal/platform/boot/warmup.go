@@ -0,0 +1,48 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// errWarmingUp is the "warmup" readiness node's error until warmup ends.
+var errWarmingUp = errors.New("warming up")
+
+// warmupGate holds readiness down while Main.Warmup runs.
+type warmupGate struct {
+	open atomic.Bool
+}
+
+func (g *warmupGate) check(context.Context) error {
+	if !g.open.Load() {
+		return errWarmingUp
+	}
+	return nil
+}
+
+// run calls warmup bounded by timeout (if positive), then opens the gate and
+// calls done. Warmup is best effort: after a failure or timeout the instance
+// serves cold rather than not at all.
+func (g *warmupGate) run(ctx context.Context, log *zap.Logger, warmup func(context.Context) error, timeout time.Duration, done func()) {
+	start := time.Now()
+	wctx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		wctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	err := warmup(wctx)
+	cancel()
+	if ctx.Err() != nil {
+		return // shutting down
+	}
+	if err != nil {
+		log.Warn("warmup failed; serving cold", zap.Duration("elapsed", time.Since(start)), zap.Error(err))
+	} else {
+		log.Info("warmup done", zap.Duration("elapsed", time.Since(start)))
+	}
+	g.open.Store(true)
+	done()
+}
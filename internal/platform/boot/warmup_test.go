@@ -0,0 +1,43 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWarmupGate(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name   string
+		warmup func(context.Context) error
+	}{
+		{"ok", func(context.Context) error { return nil }},
+		{"failed", func(context.Context) error { return errors.New("downstream unreachable") }},
+		{"timed out", func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var g warmupGate
+			if err := g.check(ctx); !errors.Is(err, errWarmingUp) {
+				t.Fatalf("before warmup: check = %v", err)
+			}
+			done := false
+			g.run(ctx, zap.NewNop(), tc.warmup, 10*time.Millisecond, func() { done = true })
+			if err := g.check(ctx); err != nil || !done {
+				t.Fatalf("after warmup: check = %v, done = %v", err, done)
+			}
+		})
+	}
+
+	// Shutdown during warmup leaves the gate closed.
+	var g warmupGate
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	g.run(cctx, zap.NewNop(), func(ctx context.Context) error { return ctx.Err() }, 0, func() { t.Error("done called") })
+	if err := g.check(ctx); err == nil {
+		t.Fatal("gate opened during shutdown")
+	}
+}
@@ -0,0 +1,106 @@
+// Package buildinfo reports what binary is running: version, git commit, build
+// time and Go version, from -ldflags when set, else from debug.ReadBuildInfo.
+//
+// Release builds stamp the variables below, e.g.:
+//
+//	go build -ldflags "-X sdk-microservices/internal/platform/buildinfo.Version=v1.4.0 \
+//	  -X sdk-microservices/internal/platform/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X sdk-microservices/internal/platform/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Set via -ldflags -X; empty values fall back to the Go build info.
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	// Modified is true when the binary was built from a dirty worktree.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build info of the running binary.
+func Get() Info {
+	once.Do(func() {
+		info = Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+				info.Version = bi.Main.Version
+			}
+			for _, s := range bi.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					if info.Commit == "" {
+						info.Commit = s.Value
+					}
+				case "vcs.time":
+					if info.BuildTime == "" {
+						info.BuildTime = s.Value
+					}
+				case "vcs.modified":
+					info.Modified = s.Value == "true"
+				}
+			}
+		}
+		if info.Version == "" {
+			info.Version = "dev"
+		}
+	})
+	return info
+}
+
+// Handler serves Get() as JSON (mounted on the admin server at /buildinfo).
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(Get())
+	})
+}
+
+// RegisterMetric publishes the constant build_info gauge (always 1) labeled with
+// version, commit and Go version, so dashboards can mark deploys and join them
+// against other series.
+func RegisterMetric(service string) error {
+	bi := Get()
+	attrs := metric.WithAttributes(
+		attribute.String("service.name", service),
+		attribute.String("version", bi.Version),
+		attribute.String("commit", bi.Commit),
+		attribute.String("go_version", bi.GoVersion),
+	)
+	_, err := otel.Meter("sdk-microservices/"+service).Int64ObservableGauge(
+		"build_info",
+		metric.WithDescription("Build information of the running binary (always 1)"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(1, attrs)
+			return nil
+		}),
+	)
+	return err
+}
@@ -0,0 +1,22 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/buildinfo", nil))
+
+	var got Info
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version == "" || got.GoVersion != runtime.Version() {
+		t.Fatalf("unexpected build info: %+v", got)
+	}
+}
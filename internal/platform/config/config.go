@@ -2,12 +2,58 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
+// Getenv returns the env var key, or fallback if it is empty. The effective value
+// is recorded for /configz (see Effective). Service settings belong in a
+// struct loaded with Load; Getenv suits one-off lookups with computed names.
 func Getenv(key, fallback string) string {
 	v := os.Getenv(key)
 	if v == "" {
-		return fallback
+		v = fallback
 	}
+	Record(key, v)
+	return v
+}
+
+// Int is Getenv for integers; unparsable values fall back to d.
+func Int(key string, d int) int {
+	v := d
+	if i, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		v = i
+	}
+	Record(key, v)
+	return v
+}
+
+// Float is Getenv for floats; unparsable values fall back to d.
+func Float(key string, d float64) float64 {
+	v := d
+	if f, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		v = f
+	}
+	Record(key, v)
+	return v
+}
+
+// Bool is Getenv for booleans; unparsable values fall back to d.
+func Bool(key string, d bool) bool {
+	v := d
+	if b, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		v = b
+	}
+	Record(key, v)
+	return v
+}
+
+// Duration is Getenv for durations ("1.5s"); unparsable values fall back to d.
+func Duration(key string, d time.Duration) time.Duration {
+	v := d
+	if dur, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		v = dur
+	}
+	Record(key, v)
 	return v
 }
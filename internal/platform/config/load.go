@@ -0,0 +1,441 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Source says which layer a setting's value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Validator is implemented by config structs with cross-field rules; Load calls
+// Validate after every field is set.
+type Validator interface {
+	Validate() error
+}
+
+// LoadOptions configures Load.
+type LoadOptions struct {
+	// EnvPrefix (e.g. "AUTH_") is prepended to every env tag not marked noprefix.
+	EnvPrefix string
+	// File is a YAML or JSON config file; "" skips the file layer.
+	File string
+	// Args are command-line arguments (usually os.Args[1:]); nil skips the flag layer.
+	Args []string
+	// LookupEnv defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+// Setting is one field of a loaded config, as shown by Report. Type is the
+// value type ("string", "duration", "[]string", ...) followed by the field's
+// tag options, e.g. "string,required,secret".
+type Setting struct {
+	Env    string `json:"env"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+
+	raw string // unredacted, to detect changes on reload
+}
+
+// Report lists the effective settings after Load, sorted by env name, with
+// secrets redacted.
+type Report []Setting
+
+// WriteTo prints r as an aligned ENV/TYPE/VALUE/SOURCE table.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENV\tTYPE\tVALUE\tSOURCE")
+	for _, s := range r {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Env, s.Type, s.Value, s.Source)
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	_, _ = r.WriteTo(&b)
+	return b.String()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Load fills the struct dst points to. Fields are bound with tags:
+//
+//	Addr   string        `env:"ADDR" default:":50051"`
+//	Secret string        `env:"JWT_SECRET,required,secret"`
+//	Sink   string        `env:"AUDIT_SINK" default:"log" oneof:"log file"`
+//	Region string        `env:"AWS_REGION,noprefix"`
+//	TTL    time.Duration `env:"ACCESS_TTL" default:"15m"`
+//
+// Layers apply in increasing precedence: the default tag, the config file, the
+// environment (EnvPrefix+name) and flags. File keys and flag names derive from
+// the env name without EnvPrefix: JWT_SECRET is jwt_secret in the file (nested
+// maps join with "_", so jwt: {secret: ...} works too) and -jwt-secret on the
+// command line. Struct-typed fields without an env tag are loaded recursively.
+//
+// Supported field types are strings, bools, numbers, time.Duration, []string
+// (comma-separated) and encoding.TextUnmarshaler. Every problem (bad value,
+// missing required field, unknown file key, failed Validate) is reported in one
+// joined error. Effective values are recorded for /configz.
+func Load(dst any, opts LoadOptions) (Report, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config: Load needs a pointer to a struct")
+	}
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+
+	var fields []*field
+	if err := collect(rv.Elem(), opts.EnvPrefix, &fields); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	set := func(f *field, raw string, src Source) {
+		if err := f.set(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", f.env, src, err))
+			return
+		}
+		f.source = src
+	}
+
+	for _, f := range fields {
+		if f.def != "" {
+			set(f, f.def, SourceDefault)
+		} else {
+			f.source = SourceDefault
+		}
+	}
+
+	if opts.File != "" {
+		values, err := readFile(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[string]*field, len(fields))
+		for _, f := range fields {
+			byKey[f.key] = f
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f, ok := byKey[k]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown key %q", opts.File, k))
+				continue
+			}
+			set(f, values[k], SourceFile)
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := opts.LookupEnv(f.env); ok && v != "" {
+			set(f, v, SourceEnv)
+		}
+	}
+
+	if opts.Args != nil {
+		fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		flags := make(map[string]*field, len(fields))
+		for _, f := range fields {
+			name := strings.ReplaceAll(f.key, "_", "-")
+			flags[name] = f
+			fs.String(name, "", "overrides "+f.env)
+		}
+		if err := fs.Parse(opts.Args); err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		fs.Visit(func(fl *flag.Flag) {
+			set(flags[fl.Name], fl.Value.String(), SourceFlag)
+		})
+	}
+
+	for _, f := range fields {
+		if f.required && f.v.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", f.env))
+		}
+		if len(f.oneof) > 0 && !f.v.IsZero() {
+			if s := f.String(); !contains(f.oneof, s) {
+				errs = append(errs, fmt.Errorf("%s: %q is not one of %s", f.env, s, strings.Join(f.oneof, ", ")))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		if v, ok := dst.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	report := make(Report, 0, len(fields))
+	for _, f := range fields {
+		raw := f.String()
+		value := raw
+		if f.secret && value != "" {
+			value = Redacted
+		}
+		// /configz shows recorded values, so secrets are recorded redacted.
+		Record(f.env, value)
+		report = append(report, Setting{Env: f.env, Type: f.typ(), Value: Redact(f.env, value), Source: f.source, raw: raw})
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Env < report[j].Env })
+	return report, nil
+}
+
+type field struct {
+	v        reflect.Value
+	env      string // full variable name
+	key      string // file key; flags use it with '-' for '_'
+	def      string
+	oneof    []string
+	required bool
+	secret   bool
+	source   Source
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func collect(v reflect.Value, prefix string, out *[]*field) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("env")
+		if !ok {
+			if sf.Type.Kind() == reflect.Struct {
+				if err := collect(v.Field(i), prefix, out); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, optsStr, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return fmt.Errorf("config: %s.%s: empty env tag", t.Name(), sf.Name)
+		}
+		f := &field{v: v.Field(i), env: prefix + name, key: strings.ToLower(name), def: sf.Tag.Get("default")}
+		for _, o := range strings.Split(optsStr, ",") {
+			switch o {
+			case "":
+			case "required":
+				f.required = true
+			case "secret":
+				f.secret = true
+			case "noprefix":
+				f.env = name
+			default:
+				return fmt.Errorf("config: %s.%s: unknown env tag option %q", t.Name(), sf.Name, o)
+			}
+		}
+		if o := sf.Tag.Get("oneof"); o != "" {
+			f.oneof = strings.Fields(o)
+		}
+		if !supported(f.v) {
+			return fmt.Errorf("config: %s.%s: unsupported type %s", t.Name(), sf.Name, sf.Type)
+		}
+		*out = append(*out, f)
+	}
+	return nil
+}
+
+func supported(v reflect.Value) bool {
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func (f *field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if tu, ok := f.v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(raw))
+	}
+	switch {
+	case f.v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.v.SetInt(int64(d))
+		return nil
+	case f.v.Kind() == reflect.Slice:
+		var out []string
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+		f.v.Set(reflect.ValueOf(out).Convert(f.v.Type()))
+		return nil
+	}
+	switch f.v.Kind() {
+	case reflect.String:
+		f.v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, f.v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, f.v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(raw, f.v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.v.SetFloat(x)
+	}
+	return nil
+}
+
+// typ describes the field's type and tag options for Report.
+func (f *field) typ() string {
+	t := f.v.Type().String()
+	switch {
+	case f.v.Type() == durationType:
+		t = "duration"
+	case f.v.Kind() == reflect.Slice:
+		t = "[]string"
+	case !f.v.Addr().Type().Implements(textUnmarshalerType):
+		t = f.v.Kind().String()
+	}
+	if f.required {
+		t += ",required"
+	}
+	if f.secret {
+		t += ",secret"
+	}
+	return t
+}
+
+// String formats the field's current value the way set parses it.
+func (f *field) String() string {
+	if m, ok := f.v.Addr().Interface().(encoding.TextMarshaler); ok {
+		b, _ := m.MarshalText()
+		return string(b)
+	}
+	if f.v.Kind() == reflect.Slice {
+		return strings.Join(f.v.Convert(reflect.TypeOf([]string(nil))).Interface().([]string), ",")
+	}
+	return fmt.Sprint(f.v.Interface())
+}
+
+// readFile parses a YAML (or JSON, a YAML subset) file into flat keys.
+func readFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	out := map[string]string{}
+	flatten("", doc, out)
+	return out, nil
+}
+
+func flatten(prefix string, v any, out map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			flatten(join(prefix, k), child, out)
+		}
+	case map[any]any:
+		for k, child := range v {
+			flatten(join(prefix, fmt.Sprint(k)), child, out)
+		}
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fmt.Sprint(item)
+		}
+		out[prefix] = strings.Join(parts, ",")
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+func join(prefix, k string) string {
+	k = strings.ToLower(k)
+	if prefix == "" {
+		return k
+	}
+	return prefix + "_" + k
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Addr    string        `env:"ADDR" default:":8080"`
+	Secret  string        `env:"JWT_SECRET,required,secret"`
+	Timeout time.Duration `env:"TIMEOUT" default:"10s"`
+	Max     int           `env:"MAX_CONNS" default:"4"`
+	Ratio   float64       `env:"RATIO"`
+	Debug   bool          `env:"DEBUG"`
+	Origins []string      `env:"CORS_ORIGINS"`
+	Sink    string        `env:"AUDIT_SINK" default:"log" oneof:"log file"`
+	Region  string        `env:"AWS_REGION,noprefix"`
+	DB      struct {
+		DSN      string   `env:"DB_DSN,secret"`
+		Replicas []string `env:"DB_REPLICA_DSNS,secret"`
+	}
+}
+
+func (c *testConfig) Validate() error {
+	if c.Max > 100 {
+		return errors.New("MAX_CONNS must be at most 100")
+	}
+	return nil
+}
+
+func envMap(m map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := m[k]
+		return v, ok
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "svc.yaml")
+	if err := os.WriteFile(file, []byte("addr: \":9000\"\nmax_conns: 8\ncors_origins: [a.example, b.example]\ndb:\n  dsn: postgres://file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg testConfig
+	rep, err := Load(&cfg, LoadOptions{
+		EnvPrefix: "SVC_",
+		File:      file,
+		Args:      []string{"-max-conns=16"},
+		LookupEnv: envMap(map[string]string{
+			"SVC_JWT_SECRET": "s3cr3t",
+			"SVC_ADDR":       ":9100",
+			"AWS_REGION":     "eu-west-1",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Addr != ":9100" || cfg.Max != 16 || cfg.Timeout != 10*time.Second || cfg.Secret != "s3cr3t" || cfg.Region != "eu-west-1" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if cfg.DB.DSN != "postgres://file" || strings.Join(cfg.Origins, " ") != "a.example b.example" {
+		t.Fatalf("file values: %+v", cfg)
+	}
+
+	sources := map[string]Source{}
+	types := map[string]string{}
+	for _, s := range rep {
+		sources[s.Env] = s.Source
+		types[s.Env] = s.Type
+		if s.Env == "SVC_JWT_SECRET" && s.Value != Redacted {
+			t.Errorf("secret shown as %q", s.Value)
+		}
+	}
+	for env, typ := range map[string]string{
+		"SVC_JWT_SECRET": "string,required,secret", "SVC_TIMEOUT": "duration",
+		"SVC_MAX_CONNS": "int", "SVC_CORS_ORIGINS": "[]string",
+	} {
+		if types[env] != typ {
+			t.Errorf("%s type = %q, want %q", env, types[env], typ)
+		}
+	}
+	want := map[string]Source{
+		"SVC_ADDR": SourceEnv, "SVC_MAX_CONNS": SourceFlag, "SVC_DB_DSN": SourceFile,
+		"SVC_TIMEOUT": SourceDefault, "AWS_REGION": SourceEnv,
+	}
+	for k, v := range want {
+		if sources[k] != v {
+			t.Errorf("%s source = %q, want %q", k, sources[k], v)
+		}
+	}
+	if out := rep.String(); !strings.Contains(out, "SVC_MAX_CONNS") || !strings.Contains(out, "flag") {
+		t.Errorf("report:\n%s", out)
+	}
+}
+
+func TestLoad_SecretDSN(t *testing.T) {
+	var cfg testConfig
+	rep, err := Load(&cfg, LoadOptions{
+		EnvPrefix: "SVC_",
+		LookupEnv: envMap(map[string]string{
+			"SVC_JWT_SECRET":      "x",
+			"SVC_DB_DSN":          "postgres://app:hunter2@db:5432/svc?sslmode=disable",
+			"SVC_DB_REPLICA_DSNS": "postgres://app:hunter2@r1/svc,postgres://app:hunter2@r2/svc",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DB.DSN != "postgres://app:hunter2@db:5432/svc?sslmode=disable" || len(cfg.DB.Replicas) != 2 {
+		t.Fatalf("cfg = %+v", cfg.DB)
+	}
+	if out := rep.String(); strings.Contains(out, "hunter2") {
+		t.Errorf("report leaks the password:\n%s", out)
+	}
+	for k, v := range Effective() {
+		if strings.Contains(v, "hunter2") {
+			t.Errorf("Effective()[%s] = %q leaks the password", k, v)
+		}
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	var cfg testConfig
+	_, err := Load(&cfg, LoadOptions{
+		EnvPrefix: "SVC_",
+		LookupEnv: envMap(map[string]string{
+			"SVC_TIMEOUT":    "soon",
+			"SVC_AUDIT_SINK": "kafka",
+		}),
+	})
+	if err == nil {
+		t.Fatal("Load succeeded")
+	}
+	for _, want := range []string{"SVC_TIMEOUT", "SVC_JWT_SECRET is required", `"kafka" is not one of log, file`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoad_Validate(t *testing.T) {
+	var cfg testConfig
+	_, err := Load(&cfg, LoadOptions{
+		EnvPrefix: "SVC_",
+		LookupEnv: envMap(map[string]string{"SVC_JWT_SECRET": "x", "SVC_MAX_CONNS": "500"}),
+	})
+	if err == nil || !strings.Contains(err.Error(), "at most 100") {
+		t.Fatalf("err = %v, want Validate error", err)
+	}
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "svc.json")
+	if err := os.WriteFile(file, []byte(`{"jwt_secret": "x", "max_con": 3}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var cfg testConfig
+	_, err := Load(&cfg, LoadOptions{EnvPrefix: "SVC_", File: file, LookupEnv: envMap(nil)})
+	if err == nil || !strings.Contains(err.Error(), `unknown key "max_con"`) {
+		t.Fatalf("err = %v, want unknown key", err)
+	}
+}
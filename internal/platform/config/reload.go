@@ -0,0 +1,103 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Reloader keeps a config struct loaded with Load current: Reload (which boot
+// calls on SIGHUP) loads it again with the same options and notifies
+// subscribers when settings changed. Each reload fills a fresh copy, so values
+// handed out earlier are never modified, and a failed reload keeps the current
+// config.
+type Reloader[T any] struct {
+	opts LoadOptions
+
+	mu     sync.Mutex // serializes Reload, and so the callbacks
+	cur    atomic.Pointer[T]
+	report Report
+	subs   []func(cfg *T, changed Report)
+}
+
+// NewReloader loads a T with opts and returns its Reloader along with the
+// initial Report.
+func NewReloader[T any](opts LoadOptions) (*Reloader[T], Report, error) {
+	cfg := new(T)
+	report, err := Load(cfg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := &Reloader[T]{opts: opts, report: report}
+	r.cur.Store(cfg)
+	return r, report, nil
+}
+
+// Current returns the latest successfully loaded config. Callers must not
+// modify it.
+func (r *Reloader[T]) Current() *T { return r.cur.Load() }
+
+// Report returns the settings of the current config, secrets redacted.
+func (r *Reloader[T]) Report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report
+}
+
+// File returns the config file being loaded ("" if none).
+func (r *Reloader[T]) File() string { return r.opts.File }
+
+// OnChange registers fn to be called with the new config and the settings that
+// changed (secrets redacted) after each reload that changed something.
+func (r *Reloader[T]) OnChange(fn func(cfg *T, changed Report)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, fn)
+}
+
+// Reload loads the config again and, if any setting changed, publishes it and
+// notifies subscribers. It returns the changed settings.
+func (r *Reloader[T]) Reload() (Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := new(T)
+	report, err := Load(next, r.opts)
+	if err != nil {
+		return nil, err
+	}
+	changed := diff(r.report, report)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	r.report = report
+	r.cur.Store(next)
+	for _, fn := range r.subs {
+		fn(next, changed)
+	}
+	return changed, nil
+}
+
+// diff returns the settings of next whose value differs from prev.
+func diff(prev, next Report) Report {
+	old := make(map[string]string, len(prev))
+	for _, s := range prev {
+		old[s.Env] = s.raw
+	}
+	var changed Report
+	for _, s := range next {
+		if v, ok := old[s.Env]; !ok || v != s.raw {
+			changed = append(changed, s)
+		}
+	}
+	return changed
+}
+
+// Has reports whether r includes the setting env.
+func (r Report) Has(env string) bool {
+	for _, s := range r {
+		if s.Env == env {
+			return true
+		}
+	}
+	return false
+}
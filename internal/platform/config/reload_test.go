@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "svc.yaml")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("max_conns: 8\n")
+
+	r, _, err := NewReloader[testConfig](LoadOptions{
+		EnvPrefix: "SVC_",
+		File:      file,
+		LookupEnv: envMap(map[string]string{"SVC_JWT_SECRET": "s3cr3t"}),
+	})
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	first := r.Current()
+
+	var got []Report
+	r.OnChange(func(cfg *testConfig, changed Report) {
+		if cfg != r.Current() {
+			t.Error("subscriber got a config other than Current")
+		}
+		got = append(got, changed)
+	})
+
+	if changed, err := r.Reload(); err != nil || len(changed) != 0 || len(got) != 0 {
+		t.Fatalf("unchanged reload: changed %v, err %v, notified %d times", changed, err, len(got))
+	}
+
+	write("max_conns: 12\n")
+	changed, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || !changed.Has("SVC_MAX_CONNS") || changed[0].Value != "12" {
+		t.Fatalf("changed = %v", changed)
+	}
+	if r.Current().Max != 12 || first.Max != 8 || len(got) != 1 {
+		t.Fatalf("current %d, first %d, notified %d times", r.Current().Max, first.Max, len(got))
+	}
+
+	// Invalid values keep the current config.
+	write("max_conns: 500\n")
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("invalid reload succeeded")
+	}
+	if r.Current().Max != 12 || len(got) != 1 {
+		t.Fatalf("failed reload applied: max %d, notified %d times", r.Current().Max, len(got))
+	}
+}
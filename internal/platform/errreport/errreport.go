@@ -0,0 +1,134 @@
+// Package errreport forwards panics and severe errors to an external error tracker
+// (Sentry or a compatible service).
+//
+// Reporting is optional: until SetReporter is called every Capture is a no-op.
+// httpmw.Recover and the grpcutil recovery interceptors report panics; Core reports
+// log entries at or above a level.
+package errreport
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/buildinfo"
+	"sdk-microservices/internal/platform/logging"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Report is one captured error or panic.
+type Report struct {
+	Time    time.Time
+	Level   string // "error", "fatal", ...
+	Message string
+	Err     error
+	// Panic is the recovered value, if this report comes from a panic.
+	Panic any
+	Stack []byte
+
+	RequestID string
+	UserID    string
+	TraceID   string
+	Release   string
+	Service   string
+	// Tags are indexed and searchable; keep them low-cardinality and free of
+	// personal data. Extra is shown on the event only.
+	Tags  map[string]string
+	Extra map[string]string
+}
+
+// Reporter delivers reports. Implementations must not block for long; drop
+// reports rather than stall the request path.
+type Reporter interface {
+	Report(r Report)
+}
+
+var current atomic.Pointer[reporterBox]
+
+type reporterBox struct {
+	r       Reporter
+	service string
+}
+
+// SetReporter installs the process-wide reporter (nil disables reporting).
+func SetReporter(r Reporter, service string) {
+	if r == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&reporterBox{r: r, service: service})
+}
+
+// Enabled reports whether a reporter is installed.
+func Enabled() bool { return current.Load() != nil }
+
+// Capture completes r from ctx (request id, user id, trace id) plus the release
+// and service, and hands it to the installed reporter.
+func Capture(ctx context.Context, r Report) {
+	box := current.Load()
+	if box == nil {
+		return
+	}
+	if ctx != nil {
+		if r.RequestID == "" {
+			r.RequestID, _ = logging.RequestID(ctx)
+		}
+		if r.UserID == "" {
+			r.UserID, _ = authctx.UserID(ctx)
+		}
+		if sc := trace.SpanContextFromContext(ctx); r.TraceID == "" && sc.HasTraceID() {
+			r.TraceID = sc.TraceID().String()
+		}
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	if r.Level == "" {
+		r.Level = "error"
+	}
+	if r.Release == "" {
+		r.Release = Release()
+	}
+	if r.Service == "" {
+		r.Service = box.service
+	}
+	box.r.Report(r)
+}
+
+// CapturePanic reports a recovered panic value with its stack.
+func CapturePanic(ctx context.Context, v any, stack []byte) {
+	r := Report{Level: "fatal", Message: "panic recovered", Panic: v, Stack: stack}
+	if err, ok := v.(error); ok {
+		r.Err = err
+	}
+	Capture(ctx, r)
+}
+
+var (
+	releaseOnce sync.Once
+	release     string
+)
+
+// Release identifies the running build: RELEASE_VERSION or SENTRY_RELEASE if set,
+// else the build version (see buildinfo), else the git commit.
+func Release() string {
+	releaseOnce.Do(func() {
+		for _, k := range []string{"RELEASE_VERSION", "SENTRY_RELEASE"} {
+			if v := os.Getenv(k); v != "" {
+				release = v
+				return
+			}
+		}
+		bi := buildinfo.Get()
+		if bi.Version != "dev" {
+			release = bi.Version
+			return
+		}
+		release = bi.Commit
+	})
+	return release
+}
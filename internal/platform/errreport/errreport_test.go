@@ -0,0 +1,121 @@
+package errreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"sdk-microservices/internal/platform/logging"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeReporter struct {
+	mu      sync.Mutex
+	reports []Report
+}
+
+func (f *fakeReporter) Report(r Report) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reports = append(f.reports, r)
+}
+
+func TestCoreReportsErrorLogs(t *testing.T) {
+	rep := &fakeReporter{}
+	SetReporter(rep, "test")
+	t.Cleanup(func() { SetReporter(nil, "") })
+
+	obs, _ := observer.New(zap.DebugLevel)
+	log := zap.New(Core(obs, zapcore.ErrorLevel)).With(zap.String("request_id", "rid-1"))
+
+	log.Warn("not reported")
+	log.Error("db down", zap.Error(errors.New("dial tcp: refused")), zap.String("user_id", "u1"))
+	log.Error("already captured", Reported)
+
+	if len(rep.reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(rep.reports))
+	}
+	r := rep.reports[0]
+	if r.Message != "db down" || r.RequestID != "rid-1" || r.UserID != "u1" || r.Service != "test" || r.Err == nil {
+		t.Fatalf("report = %+v", r)
+	}
+}
+
+func TestCoreTagsOnlyAllowlistedFields(t *testing.T) {
+	rep := &fakeReporter{}
+	SetReporter(rep, "test")
+	t.Cleanup(func() { SetReporter(nil, "") })
+
+	obs, _ := observer.New(zap.DebugLevel)
+	log := zap.New(Core(obs, zapcore.ErrorLevel))
+	log.Error("login failed",
+		zap.String("rpc.method", "/auth.v1.AuthService/Login"),
+		zap.String("email", "a@example.com"),
+		zap.String("user_agent", "curl/8.0"),
+		zap.String("client.addr", "203.0.113.7"),
+		zap.String("token", "abc"),
+		zap.String("reason", "user a@example.com from 203.0.113.7:4321 and [2001:db8::1]:443 at 12:30:45"),
+	)
+
+	if len(rep.reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(rep.reports))
+	}
+	r := rep.reports[0]
+	if len(r.Tags) != 1 || r.Tags["rpc.method"] != "/auth.v1.AuthService/Login" {
+		t.Fatalf("tags = %v, want only rpc.method", r.Tags)
+	}
+	for _, k := range []string{"email", "user_agent", "client.addr", "token"} {
+		if r.Extra[k] != logging.Redacted {
+			t.Errorf("extra[%s] = %q, want redacted", k, r.Extra[k])
+		}
+	}
+	want := "user [REDACTED] from [REDACTED] and [REDACTED] at 12:30:45"
+	if r.Extra["reason"] != want {
+		t.Errorf("extra[reason] = %q, want %q", r.Extra["reason"], want)
+	}
+}
+
+func TestCapturePanicUsesContext(t *testing.T) {
+	rep := &fakeReporter{}
+	SetReporter(rep, "test")
+	t.Cleanup(func() { SetReporter(nil, "") })
+
+	CapturePanic(logging.WithRequestID(context.Background(), "rid-2"), "boom", []byte("stack"))
+	if len(rep.reports) != 1 || rep.reports[0].RequestID != "rid-2" || rep.reports[0].Panic != "boom" {
+		t.Fatalf("reports = %+v", rep.reports)
+	}
+}
+
+func TestSentryReporterSendsEvent(t *testing.T) {
+	got := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/store/" || !strings.Contains(r.Header.Get("X-Sentry-Auth"), "sentry_key=pub") {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		var ev map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		got <- ev
+	}))
+	defer srv.Close()
+
+	rep, err := NewSentryReporter(SentryOptions{DSN: strings.Replace(srv.URL, "://", "://pub@", 1) + "/42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Report(Report{Message: "boom", UserID: "u1", Err: errors.New("boom")})
+	if err := rep.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-got
+	if ev["message"] != "boom" || ev["user"].(map[string]any)["id"] != "u1" {
+		t.Fatalf("event = %v", ev)
+	}
+}
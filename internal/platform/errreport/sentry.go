@@ -0,0 +1,213 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SentryOptions configures NewSentryReporter.
+type SentryOptions struct {
+	// DSN is the project DSN: https://<public_key>@<host>/<project_id>.
+	DSN         string
+	Environment string
+	// QueueSize bounds reports waiting to be sent (default 100); extra reports are dropped.
+	QueueSize int
+	// Timeout bounds each HTTP request (default 5s).
+	Timeout time.Duration
+	// Log receives delivery failures (optional).
+	Log *zap.Logger
+}
+
+// SentryReporter sends reports to the Sentry store API (also implemented by
+// compatible services such as GlitchTip) from a background goroutine.
+type SentryReporter struct {
+	endpoint string
+	auth     string
+	env      string
+	client   *http.Client
+	log      *zap.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Report
+	done   chan struct{}
+}
+
+// NewSentryReporter parses opts.DSN and starts the delivery goroutine; call Close
+// to flush and stop it.
+func NewSentryReporter(opts SentryOptions) (*SentryReporter, error) {
+	u, err := url.Parse(opts.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("sentry dsn: %w", err)
+	}
+	key := u.User.Username()
+	project := strings.Trim(u.Path, "/")
+	if key == "" || project == "" || u.Host == "" {
+		return nil, fmt.Errorf("sentry dsn: want scheme://key@host/project")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Log == nil {
+		opts.Log = zap.NewNop()
+	}
+
+	s := &SentryReporter{
+		endpoint: fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		auth:     "Sentry sentry_version=7, sentry_client=sdk-microservices/1.0, sentry_key=" + key,
+		env:      opts.Environment,
+		client:   &http.Client{Timeout: opts.Timeout},
+		log:      opts.Log,
+		queue:    make(chan Report, opts.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Report enqueues r, dropping it if the queue is full.
+func (s *SentryReporter) Report(r Report) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- r:
+	default:
+	}
+}
+
+// Close stops accepting reports and waits (until ctx ends) for queued ones to be sent.
+func (s *SentryReporter) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SentryReporter) run() {
+	defer close(s.done)
+	for r := range s.queue {
+		if err := s.send(r); err != nil {
+			s.log.Warn("error report delivery failed", zap.Error(err))
+		}
+	}
+}
+
+func (s *SentryReporter) send(r Report) error {
+	body, err := json.Marshal(sentryEvent(r, s.env))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// sentryEvent maps r to the store API event payload.
+func sentryEvent(r Report, env string) map[string]any {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	tags := map[string]string{}
+	for k, v := range r.Tags {
+		tags[k] = v
+	}
+	for k, v := range map[string]string{"request_id": r.RequestID, "trace_id": r.TraceID, "service": r.Service} {
+		if v != "" {
+			tags[k] = v
+		}
+	}
+
+	ev := map[string]any{
+		"event_id":  hex.EncodeToString(id),
+		"timestamp": r.Time.UTC().Format(time.RFC3339Nano),
+		"level":     sentryLevel(r.Level),
+		"platform":  "go",
+		"logger":    r.Service,
+		"message":   r.Message,
+		"tags":      tags,
+	}
+	if host, err := os.Hostname(); err == nil {
+		ev["server_name"] = host
+	}
+	if r.Release != "" {
+		ev["release"] = r.Release
+	}
+	if env != "" {
+		ev["environment"] = env
+	}
+	if r.UserID != "" {
+		ev["user"] = map[string]string{"id": r.UserID}
+	}
+
+	typ, value := "error", r.Message
+	switch {
+	case r.Panic != nil:
+		typ, value = "panic", fmt.Sprint(r.Panic)
+	case r.Err != nil:
+		typ, value = reflect.TypeOf(r.Err).String(), r.Err.Error()
+	}
+	ev["exception"] = map[string]any{"values": []map[string]string{{"type": typ, "value": value}}}
+	extra := map[string]string{}
+	for k, v := range r.Extra {
+		extra[k] = v
+	}
+	if len(r.Stack) > 0 {
+		extra["stack"] = string(r.Stack)
+	}
+	if len(extra) > 0 {
+		ev["extra"] = extra
+	}
+	return ev
+}
+
+func sentryLevel(l string) string {
+	switch l {
+	case "warn":
+		return "warning"
+	case "dpanic", "panic", "fatal":
+		return "fatal"
+	case "":
+		return "error"
+	}
+	return l
+}
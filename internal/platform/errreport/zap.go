@@ -0,0 +1,152 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sdk-microservices/internal/platform/logging"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Reported marks a log entry whose error was already captured directly (e.g. a
+// recovered panic), so Core does not report it twice. Encoders ignore it.
+var Reported = zapcore.Field{Key: "errreport.reported", Type: zapcore.SkipType}
+
+// Core wraps c so entries at or above level are also captured. request_id,
+// user_id and trace_id fields become the report's ids and an "error" field its
+// error. String fields in tagKeys become tags; the rest go to Extra, redacted
+// (see redactExtra), since they may carry emails, IPs or credentials. Entries
+// c drops (e.g. deduplicated ones) are not reported.
+func Core(c zapcore.Core, level zapcore.Level) zapcore.Core {
+	return &reportCore{Core: c, level: level}
+}
+
+type reportCore struct {
+	zapcore.Core
+	level  zapcore.Level
+	fields []zapcore.Field
+}
+
+func (c *reportCore) With(fields []zapcore.Field) zapcore.Core {
+	return &reportCore{
+		Core:   c.Core.With(fields),
+		level:  c.level,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *reportCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	downstream := c.Core.Check(ent, ce)
+	if downstream == nil || ent.Level < c.level || !Enabled() {
+		return downstream
+	}
+	return downstream.AddCore(ent, reportOnly{c})
+}
+
+// reportOnly is added next to the wrapped core so Write only reports; the wrapped
+// core already writes the entry itself.
+type reportOnly struct{ c *reportCore }
+
+func (r reportOnly) Enabled(zapcore.Level) bool                                            { return true }
+func (r reportOnly) With([]zapcore.Field) zapcore.Core                                     { return r }
+func (r reportOnly) Sync() error                                                           { return nil }
+func (r reportOnly) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry { return ce }
+
+func (r reportOnly) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	rep := Report{
+		Time:    ent.Time,
+		Level:   ent.Level.String(),
+		Message: ent.Message,
+		Stack:   []byte(ent.Stack),
+		Tags:    map[string]string{},
+		Extra:   map[string]string{},
+	}
+	if ent.Caller.Defined {
+		rep.Tags["caller"] = ent.Caller.TrimmedPath()
+	}
+	for _, fs := range [][]zapcore.Field{r.c.fields, fields} {
+		for _, f := range fs {
+			switch {
+			case f.Equals(Reported):
+				return nil
+			case f.Key == "request_id" && f.Type == zapcore.StringType:
+				rep.RequestID = f.String
+			case f.Key == "user_id" && f.Type == zapcore.StringType:
+				rep.UserID = f.String
+			case f.Key == "trace_id" && f.Type == zapcore.StringType:
+				rep.TraceID = f.String
+			case f.Type == zapcore.ErrorType:
+				if err, ok := f.Interface.(error); ok && rep.Err == nil {
+					rep.Err = err
+				}
+			case f.Type == zapcore.StringType && tagKeys[f.Key]:
+				rep.Tags[f.Key] = f.String
+			case f.Type == zapcore.StringType:
+				rep.Extra[f.Key] = redactExtra(f.Key, f.String)
+			}
+		}
+	}
+	if rep.Err == nil {
+		rep.Err = errors.New(ent.Message)
+	}
+	Capture(context.Background(), rep)
+	return nil
+}
+
+// tagKeys are the string fields reported as tags: low-cardinality values that
+// identify where an error happened, never who it happened to.
+var tagKeys = map[string]bool{
+	"caller":      true,
+	"component":   true,
+	"job":         true,
+	"health.node": true,
+	"audit.type":  true,
+	"http.method": true,
+	"http.route":  true,
+	"rpc.system":  true,
+	"rpc.method":  true,
+	"rpc.code":    true,
+}
+
+// personalKeys name fields whose whole value identifies a person or client.
+var personalKeys = logging.NewRedactor(slices.Concat(logging.DefaultSensitiveKeys, []string{
+	"email",
+	"user_agent",
+	"addr",
+	"remote",
+	"remote_addr",
+	"client.addr",
+	"client_ip",
+	"x-forwarded-for",
+	"x-real-ip",
+})...)
+
+var emailPattern = regexp.MustCompile(`[^\s@<>"']+@[^\s@<>"']+\.[A-Za-z]{2,}`)
+
+// ipCandidate matches runs that may hold an IP address, with an optional port.
+var ipCandidate = regexp.MustCompile(`\[?[0-9A-Fa-f:.]*[:.][0-9A-Fa-f:.]*\]?(:\d+)?`)
+
+// redactExtra masks v entirely when key names a credential or personal field,
+// and otherwise masks any email or IP address inside it.
+func redactExtra(key, v string) string {
+	if personalKeys.Sensitive(key) {
+		return logging.Redacted
+	}
+	v = emailPattern.ReplaceAllString(v, logging.Redacted)
+	return ipCandidate.ReplaceAllStringFunc(v, func(s string) string {
+		host := s
+		if h, _, err := net.SplitHostPort(s); err == nil {
+			host = h
+		}
+		if _, err := netip.ParseAddr(strings.Trim(host, "[]")); err != nil {
+			return s
+		}
+		return logging.Redacted
+	})
+}
@@ -0,0 +1,149 @@
+// Package errs defines typed domain errors that translate consistently to gRPC
+// statuses and HTTP problem+json responses.
+//
+// Handlers return *Error values (or wrap them); transport layers translate them
+// with ToStatus / ToProblem. Anything that is not an *Error is treated as internal
+// and its message is never exposed to clients.
+package errs
+
+import (
+	"errors"
+	"time"
+)
+
+// Domain is the ErrorInfo domain attached to translated errors.
+const Domain = "sdk-microservices"
+
+// Kind classifies a domain error.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindUnauthenticated
+	KindPermissionDenied
+	KindRateLimited
+	KindValidation
+	KindPayloadTooLarge
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindUnauthenticated:
+		return "unauthenticated"
+	case KindPermissionDenied:
+		return "permission_denied"
+	case KindRateLimited:
+		return "rate_limited"
+	case KindValidation:
+		return "validation"
+	case KindPayloadTooLarge:
+		return "payload_too_large"
+	default:
+		return "internal"
+	}
+}
+
+// FieldViolation describes one invalid request field.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Error is a typed domain error.
+type Error struct {
+	Kind Kind
+	// Reason is a stable, machine-readable UPPER_SNAKE_CASE identifier (e.g. "EMAIL_TAKEN").
+	Reason string
+	// Message is safe to show to clients.
+	Message string
+	// Fields lists violations for KindValidation.
+	Fields []FieldViolation
+	// RetryAfter is a hint for KindRateLimited.
+	RetryAfter time.Duration
+	// Err is the underlying cause. It is logged, never sent to clients.
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Kind.String()
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is a domain error of the same Kind and Reason, so
+// sentinel values keep matching after Wrap.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Reason == e.Reason
+}
+
+// Wrap returns a copy of e with err attached as the cause.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+func NotFound(reason, msg string) *Error {
+	return &Error{Kind: KindNotFound, Reason: reason, Message: msg}
+}
+
+func Conflict(reason, msg string) *Error {
+	return &Error{Kind: KindConflict, Reason: reason, Message: msg}
+}
+
+func Unauthenticated(reason, msg string) *Error {
+	return &Error{Kind: KindUnauthenticated, Reason: reason, Message: msg}
+}
+
+func PermissionDenied(reason, msg string) *Error {
+	return &Error{Kind: KindPermissionDenied, Reason: reason, Message: msg}
+}
+
+func RateLimited(reason, msg string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindRateLimited, Reason: reason, Message: msg, RetryAfter: retryAfter}
+}
+
+// Validation returns a KindValidation error listing the offending fields.
+func Validation(fields ...FieldViolation) *Error {
+	return &Error{Kind: KindValidation, Reason: "INVALID_ARGUMENT", Message: "invalid request", Fields: fields}
+}
+
+func PayloadTooLarge(reason, msg string) *Error {
+	return &Error{Kind: KindPayloadTooLarge, Reason: reason, Message: msg}
+}
+
+// Internal wraps an unexpected error. Clients only ever see "internal error".
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Reason: "INTERNAL", Message: "internal error", Err: err}
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf returns the Kind of err (KindInternal if err is not a domain error).
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
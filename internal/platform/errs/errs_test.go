@@ -0,0 +1,69 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestToStatus_WrappedValidationCarriesDetails(t *testing.T) {
+	err := fmt.Errorf("register: %w", Validation(FieldViolation{Field: "email", Description: "invalid email"}))
+
+	st := ToStatus(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %s", st.Code())
+	}
+
+	var gotInfo, gotBadRequest bool
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			gotInfo = d.GetDomain() == Domain && d.GetReason() == "INVALID_ARGUMENT"
+		case *errdetails.BadRequest:
+			gotBadRequest = len(d.GetFieldViolations()) == 1 && d.GetFieldViolations()[0].GetField() == "email"
+		}
+	}
+	if !gotInfo || !gotBadRequest {
+		t.Fatalf("missing details: info=%v badRequest=%v", gotInfo, gotBadRequest)
+	}
+}
+
+func TestToStatus_UnknownErrorIsInternalWithoutLeaking(t *testing.T) {
+	st := ToStatus(errors.New("pq: connection refused to 10.0.0.5"))
+	if st.Code() != codes.Internal {
+		t.Fatalf("expected Internal, got %s", st.Code())
+	}
+	if st.Message() != "internal error" {
+		t.Fatalf("unexpected message %q", st.Message())
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/register", nil)
+
+	WriteProblem(rr, req, Conflict("EMAIL_TAKEN", "email already registered"))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+}
+
+func TestIs_MatchesWrappedSentinel(t *testing.T) {
+	sentinel := Conflict("EMAIL_TAKEN", "email already registered")
+	err := fmt.Errorf("create user: %w", sentinel.Wrap(errors.New("duplicate key")))
+	if !errors.Is(err, sentinel) {
+		t.Fatal("expected wrapped sentinel to match")
+	}
+	if errors.Is(err, NotFound("USER_NOT_FOUND", "")) {
+		t.Fatal("unexpected match on different kind")
+	}
+}
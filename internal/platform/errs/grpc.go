@@ -0,0 +1,101 @@
+package errs
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Code maps a Kind to its gRPC status code.
+func (k Kind) Code() codes.Code {
+	switch k {
+	case KindNotFound:
+		return codes.NotFound
+	case KindConflict:
+		return codes.AlreadyExists
+	case KindUnauthenticated:
+		return codes.Unauthenticated
+	case KindPermissionDenied:
+		return codes.PermissionDenied
+	case KindRateLimited, KindPayloadTooLarge:
+		return codes.ResourceExhausted
+	case KindValidation:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// ToStatus translates err into a gRPC status.
+//
+//   - nil -> OK
+//   - existing gRPC status errors and context errors pass through unchanged
+//   - *Error -> mapped code with ErrorInfo (plus BadRequest / RetryInfo where relevant)
+//   - anything else -> Internal with a generic message
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	e, ok := As(err)
+	if !ok {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err)
+		}
+		e = Internal(err)
+	}
+
+	st := status.New(e.Kind.Code(), e.Message)
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: e.Reason, Domain: Domain},
+	}
+	if len(e.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range e.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Description,
+			})
+		}
+		details = append(details, br)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// GRPCError is shorthand for ToStatus(err).Err().
+func GRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToStatus(err).Err()
+}
+
+// UnaryServerInterceptor translates domain errors returned by handlers into gRPC statuses.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, GRPCError(err)
+	}
+}
+
+// StreamServerInterceptor translates domain errors returned by stream handlers into gRPC statuses.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return GRPCError(handler(srv, ss))
+	}
+}
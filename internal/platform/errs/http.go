@@ -0,0 +1,102 @@
+package errs
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sdk-microservices/internal/platform/logging"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Problem is an RFC 9457 (problem+json) response body.
+type Problem struct {
+	Type     string           `json:"type"`
+	Title    string           `json:"title"`
+	Status   int              `json:"status"`
+	Detail   string           `json:"detail,omitempty"`
+	Instance string           `json:"instance,omitempty"`
+	Reason   string           `json:"reason,omitempty"`
+	Errors   []FieldViolation `json:"errors,omitempty"`
+	// RequestID / TraceID let clients and support correlate the response with logs and traces.
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// HTTPStatus maps a Kind to its HTTP status code.
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthenticated:
+		return http.StatusUnauthorized
+	case KindPermissionDenied:
+		return http.StatusForbidden
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindPayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ToProblem translates err into a problem+json body. Non-domain errors become a
+// generic 500 without leaking their message.
+func ToProblem(err error) Problem {
+	e, ok := As(err)
+	if !ok {
+		e = Internal(err)
+	}
+	code := e.Kind.HTTPStatus()
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: e.Message,
+		Reason: e.Reason,
+		Errors: e.Fields,
+	}
+}
+
+// WriteProblem writes err as an application/problem+json response.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	if e, ok := As(err); ok && e.RetryAfter > 0 {
+		secs := int(e.RetryAfter.Seconds())
+		if secs < 1 {
+			secs = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+	WriteProblemBody(w, r, ToProblem(err))
+}
+
+// WriteProblemBody writes p as an application/problem+json response, filling in
+// the instance and correlation ids from r.
+func WriteProblemBody(w http.ResponseWriter, r *http.Request, p Problem) {
+	if r != nil {
+		p.Instance = r.URL.Path
+		p.RequestID, p.TraceID = correlationIDs(r)
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// correlationIDs returns the request id (context, else X-Request-Id header) and the
+// active trace id, if any.
+func correlationIDs(r *http.Request) (requestID, traceID string) {
+	requestID, ok := logging.RequestID(r.Context())
+	if !ok {
+		requestID = r.Header.Get("X-Request-Id")
+	}
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	}
+	return requestID, traceID
+}
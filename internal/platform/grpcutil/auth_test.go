@@ -0,0 +1,90 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/authjwt"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	claims := func(issuer string, ttl time.Duration) *authjwt.Claims {
+		return &authjwt.Claims{
+			TenantID: "acme",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Subject:   "u1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			},
+		}
+	}
+	cases := []struct {
+		name   string
+		method string
+		md     metadata.MD
+		code   codes.Code
+	}{
+		{name: "valid", md: metadata.Pairs("authorization", "Bearer "+signedToken(t, "u1", "acme"))},
+		{name: "lowercase scheme", md: metadata.Pairs("authorization", "bearer "+signedToken(t, "u1", "acme"))},
+		{name: "missing", md: metadata.MD{}, code: codes.Unauthenticated},
+		{name: "not bearer", md: metadata.Pairs("authorization", "Basic dTE6cHc="), code: codes.Unauthenticated},
+		{name: "malformed", md: metadata.Pairs("authorization", "Bearer not-a-jwt"), code: codes.Unauthenticated},
+		{name: "expired", md: metadata.Pairs("authorization", "Bearer "+signToken(t, "secret", claims("test", -time.Minute))), code: codes.Unauthenticated},
+		{name: "wrong issuer", md: metadata.Pairs("authorization", "Bearer "+signToken(t, "secret", claims("other", time.Minute))), code: codes.Unauthenticated},
+		{name: "wrong key", md: metadata.Pairs("authorization", "Bearer "+signToken(t, "other", claims("test", time.Minute))), code: codes.Unauthenticated},
+		{name: "skipped method", method: "/grpc.health.v1.Health/Check", md: metadata.MD{}},
+	}
+
+	icpt := AuthUnaryInterceptor(authjwt.New([]byte("secret"), "test", 0), "/grpc.health.v1.Health/Check")
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = "/svc.v1.Svc/Get"
+			}
+			var called bool
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			_, err := icpt(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+				called = true
+				if tc.method == "" {
+					if uid, _ := authctx.UserID(ctx); uid != "u1" {
+						t.Errorf("user = %q, want u1", uid)
+					}
+					if tid, _ := authctx.TenantID(ctx); tid != "acme" {
+						t.Errorf("tenant = %q, want acme", tid)
+					}
+					if _, ok := authjwt.ClaimsFrom(ctx); !ok {
+						t.Error("claims not stored in context")
+					}
+				}
+				return nil, nil
+			})
+			if got := status.Code(err); got != tc.code {
+				t.Fatalf("code = %v, want %v (err %v)", got, tc.code, err)
+			}
+			if called != (tc.code == codes.OK) {
+				t.Fatalf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestAuthUnaryInterceptorFailsClosedWithoutVerifier(t *testing.T) {
+	icpt := AuthUnaryInterceptor(nil)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signedToken(t, "u1", "")))
+	_, err := icpt(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc.v1.Svc/Get"}, func(context.Context, any) (any, error) {
+		t.Fatal("handler called")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+}
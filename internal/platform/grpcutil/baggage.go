@@ -0,0 +1,31 @@
+package grpcutil
+
+import (
+	"context"
+
+	platformotel "sdk-microservices/internal/platform/otel"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerBaggage puts the caller's user id, tenant (authctx) and
+// x-request-priority metadata into OpenTelemetry baggage. Chain it after
+// UnaryServerIdentity so the identity is known.
+func UnaryServerBaggage() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(callerBaggage(ctx), req)
+	}
+}
+
+// StreamServerBaggage is the streaming variant of UnaryServerBaggage.
+func StreamServerBaggage() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: callerBaggage(ss.Context())})
+	}
+}
+
+func callerBaggage(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return platformotel.WithCallerBaggage(ctx, first(md, platformotel.PriorityHeader))
+}
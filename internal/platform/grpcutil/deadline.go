@@ -0,0 +1,109 @@
+package grpcutil
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineBudget controls how much of the caller's remaining time is handed to a
+// downstream call, so a chain like gateway -> auth -> db never has a downstream call
+// outliving the caller's deadline. Clients apply it to outgoing calls
+// (UnaryClientDeadlineBudget); servers apply it to the work they do for a call,
+// such as database queries (UnaryServerDeadlineBudget).
+type DeadlineBudget struct {
+	// Fraction of the remaining time given to the downstream call (default 0.9).
+	Fraction float64
+	// Overhead is subtracted after applying Fraction, reserving time for the caller
+	// to handle the response. Nil means the default of 5ms; zero means none.
+	Overhead *time.Duration
+}
+
+// DefaultDeadlineOverhead is the Overhead used when DeadlineBudget.Overhead is nil.
+const DefaultDeadlineOverhead = 5 * time.Millisecond
+
+func (b DeadlineBudget) withDefaults() (fraction float64, overhead time.Duration) {
+	fraction = b.Fraction
+	if fraction <= 0 || fraction > 1 {
+		fraction = 0.9
+	}
+	overhead = DefaultDeadlineOverhead
+	if b.Overhead != nil {
+		overhead = max(0, *b.Overhead)
+	}
+	return fraction, overhead
+}
+
+// ShrinkDeadline returns a child context whose deadline is
+// now + remaining*Fraction - Overhead. Contexts without a deadline are returned as-is.
+//
+// If no budget is left, the returned context is already expired and ok is false;
+// callers should fail fast rather than start work that cannot finish.
+func ShrinkDeadline(ctx context.Context, b DeadlineBudget) (c context.Context, cancel context.CancelFunc, ok bool) {
+	dl, has := ctx.Deadline()
+	if !has {
+		return ctx, func() {}, true
+	}
+	fraction, overhead := b.withDefaults()
+
+	remaining := time.Until(dl)
+	budget := time.Duration(float64(remaining)*fraction) - overhead
+	if budget <= 0 {
+		c, cancel = context.WithDeadline(ctx, time.Now())
+		return c, cancel, false
+	}
+	c, cancel = context.WithTimeout(ctx, budget)
+	return c, cancel, true
+}
+
+// UnaryClientDeadlineBudget shrinks the outgoing deadline of each unary call per b.
+func UnaryClientDeadlineBudget(b DeadlineBudget) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c, cancel, ok := ShrinkDeadline(ctx, b)
+		defer cancel()
+		if !ok {
+			return status.Error(codes.DeadlineExceeded, "deadline budget exhausted before call")
+		}
+		return invoker(c, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerDeadlineBudget shrinks the deadline of the handler's context per b,
+// so work done on the caller's behalf (e.g. auth's database queries) ends before
+// the caller gives up. Install it after the platform's default-timeout interceptor
+// so calls without a deadline get one first.
+func UnaryServerDeadlineBudget(b DeadlineBudget) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		c, cancel, ok := ShrinkDeadline(ctx, b)
+		defer cancel()
+		if !ok {
+			return nil, status.Error(codes.DeadlineExceeded, "deadline budget exhausted before handling call")
+		}
+		return handler(c, req)
+	}
+}
+
+// StreamClientDeadlineBudget shrinks the outgoing deadline of each stream per b.
+// The derived context is released when the stream's own context ends.
+func StreamClientDeadlineBudget(b DeadlineBudget) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c, cancel, ok := ShrinkDeadline(ctx, b)
+		if !ok {
+			cancel()
+			return nil, status.Error(codes.DeadlineExceeded, "deadline budget exhausted before call")
+		}
+		cs, err := streamer(c, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		go func() {
+			<-cs.Context().Done()
+			cancel()
+		}()
+		return cs, nil
+	}
+}
@@ -0,0 +1,102 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShrinkDeadline(t *testing.T) {
+	zero := time.Duration(0)
+	overhead := 100 * time.Millisecond
+	cases := []struct {
+		name   string
+		b      DeadlineBudget
+		wantLo time.Duration
+		wantHi time.Duration
+	}{
+		{"defaults", DeadlineBudget{}, 900*time.Millisecond - DefaultDeadlineOverhead - 50*time.Millisecond, 900*time.Millisecond - DefaultDeadlineOverhead},
+		{"explicit zero overhead", DeadlineBudget{Fraction: 0.5, Overhead: &zero}, 450 * time.Millisecond, 500 * time.Millisecond},
+		{"explicit overhead", DeadlineBudget{Fraction: 1, Overhead: &overhead}, 850 * time.Millisecond, 900 * time.Millisecond},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			c, cancelC, ok := ShrinkDeadline(ctx, tc.b)
+			defer cancelC()
+			if !ok {
+				t.Fatal("expected budget left")
+			}
+			dl, _ := c.Deadline()
+			if got := time.Until(dl); got < tc.wantLo || got > tc.wantHi {
+				t.Fatalf("budget = %v, want in [%v, %v]", got, tc.wantLo, tc.wantHi)
+			}
+		})
+	}
+}
+
+func TestShrinkDeadline_NoDeadline(t *testing.T) {
+	c, cancel, ok := ShrinkDeadline(context.Background(), DeadlineBudget{})
+	defer cancel()
+	if _, has := c.Deadline(); has || !ok {
+		t.Fatalf("deadline added to a context without one (ok=%v)", ok)
+	}
+}
+
+func TestUnaryClientDeadlineBudget_Exhausted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Millisecond)
+	defer cancel()
+	icpt := UnaryClientDeadlineBudget(DeadlineBudget{})
+	err := icpt(ctx, "/svc.v1.Svc/Get", nil, nil, nil, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		t.Fatal("invoker called with no budget left")
+		return nil
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+// The gateway shrinks its call to auth, and auth shrinks again before the
+// handler's database work, so each hop ends before the one above it.
+func TestDeadlineBudget_GatewayAuthDB(t *testing.T) {
+	b := DeadlineBudget{Fraction: 0.9}
+	client := UnaryClientDeadlineBudget(b)
+	server := UnaryServerDeadlineBudget(b)
+
+	gatewayCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	gatewayDL, _ := gatewayCtx.Deadline()
+
+	var authDL, dbDL time.Time
+	err := client(gatewayCtx, "/auth.v1.AuthService/Validate", nil, nil, nil, func(ctx context.Context, _ string, req, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		authDL, _ = ctx.Deadline()
+		_, err := server(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/auth.v1.AuthService/Validate"}, func(ctx context.Context, _ any) (any, error) {
+			dbDL, _ = ctx.Deadline()
+			return nil, nil
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !authDL.Before(gatewayDL) || !dbDL.Before(authDL) {
+		t.Fatalf("deadlines not nested: gateway %v, auth %v, db %v", gatewayDL, authDL, dbDL)
+	}
+}
+
+func TestUnaryServerDeadlineBudget_Exhausted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := UnaryServerDeadlineBudget(DeadlineBudget{})(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		t.Fatal("handler called with no budget left")
+		return nil, nil
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,92 @@
+package grpcutil
+
+import (
+	"context"
+	"net"
+	"time"
+
+	grpc_health "google.golang.org/grpc/health"
+)
+
+// GracefulOptions configures ServeWithGracefulShutdown.
+type GracefulOptions struct {
+	// PreStopDelay is how long to keep serving after flipping health to NOT_SERVING,
+	// giving load balancers / kube-proxy time to stop routing new traffic here.
+	// It is cut short if the shutdown context ends first.
+	PreStopDelay time.Duration
+
+	// OnStop runs after the server has stopped (e.g. closing DB pools).
+	OnStop func()
+}
+
+// GracefulServer pairs a gRPC server with its listener and health service.
+// Serve and Shutdown match boot.Main's fields.
+type GracefulServer struct {
+	lis  net.Listener
+	gs   Server
+	hs   *grpc_health.Server
+	opts GracefulOptions
+}
+
+// ServeWithGracefulShutdown wraps the drain sequence shared by gRPC daemons:
+// NOT_SERVING -> pre-stop delay -> GracefulStop bounded by the shutdown context ->
+// force Stop -> OnStop.
+//
+// hs may be nil if the server does not register the gRPC health service.
+func ServeWithGracefulShutdown(lis net.Listener, gs Server, hs *grpc_health.Server, opts GracefulOptions) *GracefulServer {
+	return &GracefulServer{lis: lis, gs: gs, hs: hs, opts: opts}
+}
+
+// Serve blocks serving gRPC on the listener.
+func (g *GracefulServer) Serve() error {
+	return g.gs.Serve(g.lis)
+}
+
+// SetServing flips every registered health service to SERVING or NOT_SERVING
+// without stopping the server, for manually draining an instance (see boot.Main).
+func (g *GracefulServer) SetServing(serving bool) {
+	if g.hs == nil {
+		return
+	}
+	if serving {
+		g.hs.Resume()
+	} else {
+		g.hs.Shutdown()
+	}
+}
+
+// Shutdown drains the server. It always returns nil; a context timeout escalates to Stop.
+func (g *GracefulServer) Shutdown(ctx context.Context) error {
+	if g.hs != nil {
+		// Marks every registered service NOT_SERVING and ignores later updates.
+		g.hs.Shutdown()
+	}
+
+	if g.opts.PreStopDelay > 0 {
+		t := time.NewTimer(g.opts.PreStopDelay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+
+	// GracefulStop does not take context; emulate with a deadline + Stop fallback.
+	done := make(chan struct{})
+	go func() {
+		g.gs.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.gs.Stop()
+		<-done
+	}
+	_ = g.lis.Close()
+
+	if g.opts.OnStop != nil {
+		g.opts.OnStop()
+	}
+	return nil
+}
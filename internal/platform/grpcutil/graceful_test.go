@@ -0,0 +1,32 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+
+	grpc_health "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGracefulServer_SetServing(t *testing.T) {
+	hs := grpc_health.NewServer()
+	hs.SetServingStatus("svc", healthpb.HealthCheckResponse_SERVING)
+	g := ServeWithGracefulShutdown(nil, nil, hs, GracefulOptions{})
+
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "svc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+
+	g.SetServing(false)
+	if s := status(); s != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("after drain: %v", s)
+	}
+	g.SetServing(true)
+	if s := status(); s != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("after resume: %v", s)
+	}
+}
@@ -0,0 +1,50 @@
+package grpcutil
+
+import (
+	"sdk-microservices/internal/platform/health"
+
+	grpc_health "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// SyncHealth returns a health.Evaluator subscriber that mirrors readiness into hs,
+// so gRPC clients (Check and Watch) see real dependency health instead of a static
+// SERVING.
+//
+// The overall status ("") follows the whole graph. services maps each gRPC service
+// name to the top-level graph nodes it needs; a nil list means the whole graph.
+// Soft dependencies never make a service NOT_SERVING (see health.Soft).
+//
+//	deps.ReadyEvaluator.Subscribe(grpcutil.SyncHealth(hs, map[string][]string{
+//		"auth.v1.AuthService": nil,
+//	}))
+func SyncHealth(hs *grpc_health.Server, services map[string][]string) func(health.Result) {
+	return func(res health.Result) {
+		hs.SetServingStatus("", servingStatus(res.Healthy))
+		for svc, nodes := range services {
+			hs.SetServingStatus(svc, servingStatus(nodesHealthy(res, nodes)))
+		}
+	}
+}
+
+// nodesHealthy reports whether every named top-level node is healthy; nodes the
+// graph does not contain count as unhealthy.
+func nodesHealthy(res health.Result, nodes []string) bool {
+	if nodes == nil {
+		return res.Healthy
+	}
+	for _, n := range nodes {
+		dr, ok := res.Deps[n]
+		if !ok || (!dr.Healthy && !dr.Soft) {
+			return false
+		}
+	}
+	return true
+}
+
+func servingStatus(ok bool) healthpb.HealthCheckResponse_ServingStatus {
+	if ok {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,45 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+
+	"sdk-microservices/internal/platform/health"
+
+	grpc_health "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestSyncHealth(t *testing.T) {
+	hs := grpc_health.NewServer()
+	sync := SyncHealth(hs, map[string][]string{
+		"svc.Full":  nil,
+		"svc.Cache": {"cache"},
+	})
+	status := func(svc string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: svc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+
+	sync(health.Result{Healthy: false, Deps: map[string]health.Result{
+		"db":    {Name: "db", Healthy: false},
+		"cache": {Name: "cache", Healthy: true},
+	}})
+	if s := status(""); s != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("overall = %v", s)
+	}
+	if s := status("svc.Full"); s != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("svc.Full = %v", s)
+	}
+	if s := status("svc.Cache"); s != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("svc.Cache = %v, want SERVING (its only dependency is healthy)", s)
+	}
+
+	sync(health.Result{Healthy: true})
+	if s := status("svc.Full"); s != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("svc.Full after recovery = %v", s)
+	}
+}
@@ -0,0 +1,212 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/idempotency"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// IdempotencyKeyHeader is the metadata key clients set to make a unary call retry-safe.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// IdempotencyRecord is a stored response for a completed idempotent call.
+type IdempotencyRecord struct {
+	// RequestHash guards against reusing a key for a different request.
+	RequestHash string `json:"request_hash"`
+	// Type is the response's protobuf full name; Body is its wire encoding.
+	Type string `json:"type"`
+	Body []byte `json:"body"`
+}
+
+// idempotencyLockTTL bounds how long an in-progress call blocks duplicates on
+// other instances if the instance running it dies.
+const idempotencyLockTTL = time.Minute
+
+// UnaryIdempotency dedupes calls to methods (full method names, e.g.
+// "/auth.v1.AuthService/Register") carrying an idempotency-key metadata entry:
+// the first successful response is stored for ttl and replayed for retries with the
+// same key, so retried writes don't repeat their side effects. Other methods pass
+// through; list only writes whose replay is harmless, never logins or anything
+// whose side effects (sessions, audit records) must happen on every call.
+//
+// Keys are scoped by method and authenticated user (authctx). Reusing a key with a
+// different request payload fails with FailedPrecondition. Error responses are not
+// stored, so failed calls can be retried, and neither are responses carrying
+// credentials (a populated field named like *token*, *secret* or *password*).
+// Concurrent duplicates are serialized within an instance; a duplicate arriving
+// while another instance runs the call fails with Aborted.
+func UnaryIdempotency(store idempotency.Store, ttl time.Duration, methods ...string) grpc.UnaryServerInterceptor {
+	allowed := methodSet(methods)
+	if store == nil || len(allowed) == 0 {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	locks := newKeyedMutex()
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := allowed[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		idemKey := first(md, IdempotencyKeyHeader)
+		reqMsg, ok := req.(proto.Message)
+		if idemKey == "" || !ok {
+			return handler(ctx, req)
+		}
+
+		uid, _ := authctx.UserID(ctx)
+		key := info.FullMethod + "|" + uid + "|" + idemKey
+		hash, err := requestHash(reqMsg)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		unlock := locks.lock(key)
+		defer unlock()
+
+		b, reserved, err := store.Reserve(ctx, key, idempotencyLockTTL)
+		if err != nil {
+			// Store down: serve without deduplication rather than failing writes.
+			return handler(ctx, req)
+		}
+		if b != nil {
+			var rec IdempotencyRecord
+			if err := json.Unmarshal(b, &rec); err == nil {
+				if rec.RequestHash != hash {
+					return nil, status.Error(codes.FailedPrecondition, "idempotency key reused with a different request")
+				}
+				if resp, err := decodeRecord(&rec); err == nil {
+					return resp, nil
+				}
+			}
+			return handler(ctx, req)
+		}
+		if !reserved {
+			return nil, status.Error(codes.Aborted, "a call with this idempotency key is still in progress")
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				// Failed, uncacheable or panicked: let a retry run again.
+				_ = store.Release(context.WithoutCancel(ctx), key)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		if respMsg, ok := resp.(proto.Message); ok && !carriesCredentials(respMsg.ProtoReflect()) {
+			if body, mErr := proto.Marshal(respMsg); mErr == nil {
+				rec, mErr := json.Marshal(&IdempotencyRecord{
+					RequestHash: hash,
+					Type:        string(respMsg.ProtoReflect().Descriptor().FullName()),
+					Body:        body,
+				})
+				if mErr == nil {
+					// Best effort: a failed Complete only means a retry re-executes.
+					completed = store.Complete(context.WithoutCancel(ctx), key, rec, ttl) == nil
+				}
+			}
+		}
+		return resp, nil
+	}
+}
+
+// carriesCredentials reports whether m (or a message nested in it) has a
+// populated field whose name suggests a credential.
+func carriesCredentials(m protoreflect.Message) bool {
+	found := false
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := strings.ToLower(string(fd.Name()))
+		for _, part := range []string{"token", "secret", "password", "credential"} {
+			if strings.Contains(name, part) {
+				found = true
+				return false
+			}
+		}
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() && carriesCredentials(v.Message()) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func requestHash(m proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func decodeRecord(rec *IdempotencyRecord) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(rec.Type))
+	if err != nil {
+		return nil, err
+	}
+	m := mt.New().Interface()
+	if err := proto.Unmarshal(rec.Body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// keyedMutex serializes work per key without holding a global lock.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
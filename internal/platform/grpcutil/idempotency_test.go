@@ -0,0 +1,108 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authv1 "sdk-microservices/gen/api/proto/auth/v1"
+	"sdk-microservices/internal/platform/idempotency"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryIdempotency_ReplaysStoredResponse(t *testing.T) {
+	icpt := UnaryIdempotency(idempotency.NewMemoryStore(), time.Minute, authv1.AuthService_Register_FullMethodName)
+	info := &grpc.UnaryServerInfo{FullMethod: authv1.AuthService_Register_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "k1"))
+
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return &authv1.RegisterResponse{UserId: "u-1"}, nil
+	}
+	req := &authv1.RegisterRequest{Email: "a@example.com", Password: "correct horse battery"}
+
+	for i := 0; i < 2; i++ {
+		resp, err := icpt(ctx, req, info, handler)
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if got := resp.(*authv1.RegisterResponse).GetUserId(); got != "u-1" {
+			t.Fatalf("call %d: unexpected user id %q", i, got)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+
+	other := &authv1.RegisterRequest{Email: "b@example.com", Password: "correct horse battery"}
+	if _, err := icpt(ctx, other, info, handler); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for reused key, got %v", err)
+	}
+}
+
+func TestUnaryIdempotency_OnlyAllowlistedMethods(t *testing.T) {
+	icpt := UnaryIdempotency(idempotency.NewMemoryStore(), time.Minute, authv1.AuthService_Register_FullMethodName)
+	info := &grpc.UnaryServerInfo{FullMethod: authv1.AuthService_Login_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "k1"))
+
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return &authv1.LoginResponse{UserId: "u-1"}, nil
+	}
+	req := &authv1.LoginRequest{Email: "a@example.com", Password: "correct horse battery"}
+	for i := 0; i < 2; i++ {
+		if _, err := icpt(ctx, req, info, handler); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("Login replayed from the store: handler ran %d times, want 2", calls)
+	}
+}
+
+func TestUnaryIdempotency_NeverStoresCredentials(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	icpt := UnaryIdempotency(store, time.Minute, authv1.AuthService_Login_FullMethodName)
+	info := &grpc.UnaryServerInfo{FullMethod: authv1.AuthService_Login_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "k1"))
+
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return &authv1.LoginResponse{UserId: "u-1", AccessToken: "tok", RefreshToken: "ref"}, nil
+	}
+	req := &authv1.LoginRequest{Email: "a@example.com", Password: "correct horse battery"}
+	for i := 0; i < 2; i++ {
+		if _, err := icpt(ctx, req, info, handler); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("credentials replayed from the store: handler ran %d times, want 2", calls)
+	}
+}
+
+func TestUnaryIdempotency_InProgressElsewhere(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	icpt := UnaryIdempotency(store, time.Minute, authv1.AuthService_Register_FullMethodName)
+	info := &grpc.UnaryServerInfo{FullMethod: authv1.AuthService_Register_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "k1"))
+
+	// Another instance holds the key.
+	if _, reserved, _ := store.Reserve(ctx, info.FullMethod+"||k1", time.Minute); !reserved {
+		t.Fatal("setup: reserve failed")
+	}
+	_, err := icpt(ctx, &authv1.RegisterRequest{Email: "a@example.com"}, info, func(context.Context, any) (any, error) {
+		t.Fatal("handler ran while the key was held")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("err = %v, want Aborted", err)
+	}
+}
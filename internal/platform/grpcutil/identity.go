@@ -0,0 +1,101 @@
+package grpcutil
+
+import (
+	"context"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/authjwt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Identity metadata keys carried between services.
+const (
+	UserIDHeader   = "x-user-id"
+	TenantIDHeader = "x-tenant-id"
+	// ClientIPHeader carries the end-user IP resolved at the edge (httpmw.RealIP).
+	ClientIPHeader = "x-client-ip"
+)
+
+// IdentityOptions controls how servers derive the caller identity (authctx) from
+// incoming metadata. The zero value trusts nothing.
+type IdentityOptions struct {
+	// Verifier, if set, validates `authorization: Bearer` metadata and uses the
+	// token subject and tenant claim as the identity. Invalid tokens leave the
+	// identity empty; rejecting unauthenticated calls is AuthUnaryInterceptor's job.
+	Verifier *authjwt.Service
+
+	// TrustForwarded accepts x-user-id / x-tenant-id metadata as-is when the
+	// verified token (if any) doesn't carry them. Only enable it when every caller that can reach the port is
+	// itself trusted (e.g. mesh mTLS plus an authorization policy).
+	TrustForwarded bool
+}
+
+// UnaryServerIdentity populates authctx from incoming metadata per opts.
+// It is the server half of UnaryClientIdentity.
+func UnaryServerIdentity(opts IdentityOptions) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(identityFromMetadata(ctx, opts), req)
+	}
+}
+
+// StreamServerIdentity is the streaming variant of UnaryServerIdentity.
+func StreamServerIdentity(opts IdentityOptions) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: identityFromMetadata(ss.Context(), opts)})
+	}
+}
+
+func identityFromMetadata(ctx context.Context, opts IdentityOptions) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if opts.Verifier != nil {
+		if claims, err := verifyBearer(md, opts.Verifier); err == nil {
+			ctx = withClaims(ctx, claims)
+		}
+	}
+
+	if opts.TrustForwarded {
+		if _, ok := authctx.UserID(ctx); !ok {
+			ctx = authctx.WithUserID(ctx, first(md, UserIDHeader))
+		}
+		if _, ok := authctx.TenantID(ctx); !ok {
+			ctx = authctx.WithTenantID(ctx, first(md, TenantIDHeader))
+		}
+	}
+	return ctx
+}
+
+// ClientIP returns the end-user IP forwarded by the edge in x-client-ip metadata.
+// It is informational (audit, logs) and must not be used for authorization.
+func ClientIP(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ip := first(md, ClientIPHeader)
+	return ip, ip != ""
+}
+
+// UnaryClientIdentity re-injects authctx (user id, tenant id) into outgoing metadata
+// so downstream servers running UnaryServerIdentity see the same caller.
+// Values already present in the outgoing metadata win.
+func UnaryClientIdentity() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(injectIdentity(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientIdentity is the streaming variant of UnaryClientIdentity.
+func StreamClientIdentity() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectIdentity(ctx), desc, cc, method, opts...)
+	}
+}
+
+func injectIdentity(ctx context.Context) context.Context {
+	uid, _ := authctx.UserID(ctx)
+	tid, _ := authctx.TenantID(ctx)
+	return appendMissingOutgoing(ctx, UserIDHeader, uid, TenantIDHeader, tid)
+}
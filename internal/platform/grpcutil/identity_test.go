@@ -0,0 +1,88 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/authjwt"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+func signedToken(t *testing.T, subject, tenant string) string {
+	t.Helper()
+	return signToken(t, "secret", &authjwt.Claims{
+		TenantID: tenant,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "test",
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+}
+
+func signToken(t *testing.T, secret string, claims *authjwt.Claims) string {
+	t.Helper()
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return tok
+}
+
+func TestIdentityFromMetadata_Tenant(t *testing.T) {
+	verifier := authjwt.New([]byte("secret"), "test", 0)
+	cases := []struct {
+		name   string
+		opts   IdentityOptions
+		md     metadata.MD
+		user   string
+		tenant string
+	}{
+		{
+			name:   "token tenant",
+			opts:   IdentityOptions{Verifier: verifier},
+			md:     metadata.Pairs("authorization", "Bearer "+signedToken(t, "u1", "acme"), TenantIDHeader, "evil"),
+			user:   "u1",
+			tenant: "acme",
+		},
+		{
+			name:   "token tenant wins over trusted metadata",
+			opts:   IdentityOptions{Verifier: verifier, TrustForwarded: true},
+			md:     metadata.Pairs("authorization", "Bearer "+signedToken(t, "u1", "acme"), TenantIDHeader, "evil"),
+			user:   "u1",
+			tenant: "acme",
+		},
+		{
+			name: "metadata ignored unless trusted",
+			opts: IdentityOptions{Verifier: verifier},
+			md:   metadata.Pairs(UserIDHeader, "u2", TenantIDHeader, "evil"),
+		},
+		{
+			name:   "trusted metadata without token",
+			opts:   IdentityOptions{Verifier: verifier, TrustForwarded: true},
+			md:     metadata.Pairs(UserIDHeader, "u2", TenantIDHeader, "acme"),
+			user:   "u2",
+			tenant: "acme",
+		},
+		{
+			name: "invalid token",
+			opts: IdentityOptions{Verifier: verifier},
+			md:   metadata.Pairs("authorization", "Bearer not-a-jwt"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := identityFromMetadata(metadata.NewIncomingContext(context.Background(), tc.md), tc.opts)
+			if got, _ := authctx.UserID(ctx); got != tc.user {
+				t.Fatalf("user = %q, want %q", got, tc.user)
+			}
+			if got, _ := authctx.TenantID(ctx); got != tc.tenant {
+				t.Fatalf("tenant = %q, want %q", got, tc.tenant)
+			}
+		})
+	}
+}
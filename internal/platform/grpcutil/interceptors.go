@@ -5,10 +5,14 @@ import (
 	"time"
 
 	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/errs"
 	"sdk-microservices/internal/platform/logging"
 	"sdk-microservices/internal/platform/metrics"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -33,12 +37,39 @@ type Limits struct {
 	DefaultTimeout time.Duration
 	// MaxInFlight bounds concurrent unary requests and streams.
 	MaxInFlight int
+	// MaxQueue lets up to this many unary requests wait for an in-flight slot
+	// (for at most MaxQueueWait) instead of failing immediately. Zero means fail-fast.
+	MaxQueue     int
+	MaxQueueWait time.Duration
+	// MaxRecvMsgSize / MaxSendMsgSize bound message sizes in bytes.
+	// Zero keeps the gRPC defaults (4MB receive, unlimited send).
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+	// Identity controls how caller identity (authctx) is derived from incoming metadata.
+	// The zero value trusts nothing; see IdentityOptions.
+	Identity IdentityOptions
+	// SlowThreshold logs RPCs that take longer at Warn (with extra detail) and counts
+	// them in rpc.server.slow. Zero disables slow-RPC reporting.
+	SlowThreshold time.Duration
+	// Redactor masks sensitive metadata/fields in request logs.
+	// Defaults to logging.DefaultRedactor.
+	Redactor *logging.Redactor
 }
 
 // ServerOptionsWithNameAndLimits adds keepalives + OTel tracing/metrics + structured request logging,
 // plus optional timeout/backpressure limits.
 func ServerOptionsWithNameAndLimits(service string, log *zap.Logger, lim Limits) []grpc.ServerOption {
 	opts := ServerOptions()
+	if lim.Redactor == nil {
+		lim.Redactor = logging.DefaultRedactor
+	}
+	log = lim.Redactor.Logger(log)
+	if lim.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(lim.MaxRecvMsgSize))
+	}
+	if lim.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(lim.MaxSendMsgSize))
+	}
 
 	// OTel tracing instrumentation (newer contrib uses StatsHandler, not interceptors).
 	opts = append(opts,
@@ -58,15 +89,25 @@ func ServerOptionsWithNameAndLimits(service string, log *zap.Logger, lim Limits)
 	var unary []grpc.UnaryServerInterceptor
 	// Apply backpressure/timeouts as early as possible.
 	if lim.MaxInFlight > 0 {
-		unary = append(unary, UnaryInFlightLimit(lim.MaxInFlight))
+		unary = append(unary, UnaryInFlightLimitQueued(service, lim.MaxInFlight, QueueOptions{
+			MaxQueue: lim.MaxQueue,
+			MaxWait:  lim.MaxQueueWait,
+		}))
 	}
 	if lim.DefaultTimeout > 0 {
-		unary = append(unary, UnaryTimeout(lim.DefaultTimeout))
+		unary = append(unary, UnaryTimeoutObserved(service, log, lim.DefaultTimeout))
 	}
 	if mu != nil {
 		unary = append(unary, mu)
 	}
-	unary = append(unary, requestLogUnary(log))
+	// Identity before logging so request logs carry the (validated) user id.
+	unary = append(unary, UnaryServerIdentity(lim.Identity))
+	unary = append(unary, UnaryServerBaggage())
+	slow := newSlowRPCReporter(service, lim.SlowThreshold)
+	unary = append(unary, requestLogUnary(log, slow))
+	unary = append(unary, UnaryServerRecover(log))
+	// Innermost: translate domain errors (errs.*) so logs/metrics above see the final code.
+	unary = append(unary, errs.UnaryServerInterceptor())
 
 	var stream []grpc.StreamServerInterceptor
 	if lim.MaxInFlight > 0 {
@@ -75,7 +116,11 @@ func ServerOptionsWithNameAndLimits(service string, log *zap.Logger, lim Limits)
 	if ms != nil {
 		stream = append(stream, ms)
 	}
-	stream = append(stream, requestLogStream(log))
+	stream = append(stream, StreamServerIdentity(lim.Identity))
+	stream = append(stream, StreamServerBaggage())
+	stream = append(stream, requestLogStream(log, slow))
+	stream = append(stream, StreamServerRecover(log))
+	stream = append(stream, errs.StreamServerInterceptor())
 
 	opts = append(opts,
 		grpc.ChainUnaryInterceptor(unary...),
@@ -85,7 +130,7 @@ func ServerOptionsWithNameAndLimits(service string, log *zap.Logger, lim Limits)
 	return opts
 }
 
-func requestLogUnary(base *zap.Logger) grpc.UnaryServerInterceptor {
+func requestLogUnary(base *zap.Logger, slow *slowRPCReporter) grpc.UnaryServerInterceptor {
 	if base == nil {
 		base = zap.NewNop()
 	}
@@ -100,13 +145,13 @@ func requestLogUnary(base *zap.Logger) grpc.UnaryServerInterceptor {
 		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
 			lg = lg.With(zap.String("client.addr", p.Addr.String()))
 		}
+		if uid, ok := authctx.UserID(ctx); ok {
+			lg = lg.With(zap.String("user_id", uid))
+		}
 		if md, ok := metadata.FromIncomingContext(ctx); ok {
 			if rid := first(md, "x-request-id"); rid != "" {
 				lg = lg.With(zap.String("request_id", rid))
-			}
-			if uid := first(md, "x-user-id"); uid != "" {
-				lg = lg.With(zap.String("user_id", uid))
-				ctx = authctx.WithUserID(ctx, uid)
+				ctx = logging.WithRequestID(ctx, rid)
 			}
 			if ua := first(md, "user-agent"); ua != "" {
 				lg = lg.With(zap.String("user_agent", ua))
@@ -116,17 +161,13 @@ func requestLogUnary(base *zap.Logger) grpc.UnaryServerInterceptor {
 		ctx = logging.With(ctx, lg)
 		resp, err := handler(ctx, req)
 
-		st := status.Convert(err)
-		lg.Info("rpc",
-			zap.String("rpc.code", st.Code().String()),
-			zap.Duration("duration", time.Since(start)),
-		)
+		slow.log(ctx, lg, info.FullMethod, start, err)
 
 		return resp, err
 	}
 }
 
-func requestLogStream(base *zap.Logger) grpc.StreamServerInterceptor {
+func requestLogStream(base *zap.Logger, slow *slowRPCReporter) grpc.StreamServerInterceptor {
 	if base == nil {
 		base = zap.NewNop()
 	}
@@ -143,9 +184,13 @@ func requestLogStream(base *zap.Logger) grpc.StreamServerInterceptor {
 		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
 			lg = lg.With(zap.String("client.addr", p.Addr.String()))
 		}
+		if uid, ok := authctx.UserID(ctx); ok {
+			lg = lg.With(zap.String("user_id", uid))
+		}
 		if md, ok := metadata.FromIncomingContext(ctx); ok {
 			if rid := first(md, "x-request-id"); rid != "" {
 				lg = lg.With(zap.String("request_id", rid))
+				ctx = logging.WithRequestID(ctx, rid)
 			}
 			if ua := first(md, "user-agent"); ua != "" {
 				lg = lg.With(zap.String("user_agent", ua))
@@ -155,16 +200,68 @@ func requestLogStream(base *zap.Logger) grpc.StreamServerInterceptor {
 		wrapped := &wrappedStream{ServerStream: ss, ctx: logging.With(ctx, lg)}
 		err := handler(srv, wrapped)
 
-		st := status.Convert(err)
-		lg.Info("rpc",
-			zap.String("rpc.code", st.Code().String()),
-			zap.Duration("duration", time.Since(start)),
-		)
+		slow.log(ctx, lg, info.FullMethod, start, err)
 
 		return err
 	}
 }
 
+// slowRPCReporter writes the per-RPC log line, escalating to Warn (and counting)
+// RPCs that exceed the slow threshold. A nil reporter logs at Info only.
+type slowRPCReporter struct {
+	threshold time.Duration
+	service   string
+	counter   metric.Int64Counter
+}
+
+func newSlowRPCReporter(service string, threshold time.Duration) *slowRPCReporter {
+	if threshold <= 0 {
+		return nil
+	}
+	r := &slowRPCReporter{threshold: threshold, service: service}
+	c, err := otel.Meter("sdk-microservices/"+service).Int64Counter(
+		"rpc.server.slow",
+		metric.WithDescription("RPCs slower than the configured slow threshold"),
+		metric.WithUnit("{request}"),
+	)
+	if err == nil {
+		r.counter = c
+	}
+	return r
+}
+
+func (r *slowRPCReporter) log(ctx context.Context, lg *zap.Logger, fullMethod string, start time.Time, err error) {
+	dur := time.Since(start)
+	st := status.Convert(err)
+	fields := []zap.Field{
+		zap.String("rpc.code", st.Code().String()),
+		zap.Duration("duration", dur),
+	}
+
+	if r == nil || dur < r.threshold {
+		lg.Info("rpc", fields...)
+		return
+	}
+
+	fields = append(fields, zap.Duration("slow_threshold", r.threshold))
+	if dl, ok := ctx.Deadline(); ok {
+		// Negative means the RPC overran its deadline.
+		fields = append(fields, zap.Duration("deadline_remaining", time.Until(dl)))
+	}
+	if err != nil {
+		fields = append(fields, zap.String("rpc.error", st.Message()))
+	}
+	lg.Warn("slow rpc", fields...)
+
+	if r.counter != nil {
+		r.counter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("service.name", r.service),
+			attribute.String("rpc.method", fullMethod),
+			attribute.String("rpc.code", st.Code().String()),
+		))
+	}
+}
+
 type wrappedStream struct {
 	grpc.ServerStream
 	ctx context.Context
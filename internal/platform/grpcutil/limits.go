@@ -2,8 +2,15 @@ package grpcutil
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"sdk-microservices/internal/platform/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -12,19 +19,57 @@ import (
 // UnaryTimeout applies a default timeout to unary RPCs that do not already
 // have a deadline.
 func UnaryTimeout(d time.Duration) grpc.UnaryServerInterceptor {
+	return UnaryTimeoutObserved("", nil, d)
+}
+
+// UnaryTimeoutObserved is UnaryTimeout that also counts (rpc.server.timeouts, by
+// method) and logs RPCs cut off by the injected deadline, so server-imposed
+// timeouts can be told apart from upstream slowness. An empty service disables the
+// counter and a nil log the Warn.
+func UnaryTimeoutObserved(service string, log *zap.Logger, d time.Duration) grpc.UnaryServerInterceptor {
 	if d <= 0 {
 		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 			return handler(ctx, req)
 		}
 	}
 
+	var counter metric.Int64Counter
+	if service != "" {
+		counter, _ = otel.Meter("sdk-microservices/"+service).Int64Counter(
+			"rpc.server.timeouts",
+			metric.WithDescription("RPCs cut off by the server-imposed deadline"),
+			metric.WithUnit("{request}"),
+		)
+	}
+
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		if _, ok := ctx.Deadline(); ok {
 			return handler(ctx, req)
 		}
 		c, cancel := context.WithTimeout(ctx, d)
 		defer cancel()
-		return handler(c, req)
+		resp, err := handler(c, req)
+
+		// Only our deadline firing counts; a client cancel ends the parent instead.
+		if errors.Is(c.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			if counter != nil {
+				counter.Add(ctx, 1, metric.WithAttributes(
+					attribute.String("service.name", service),
+					attribute.String("rpc.method", info.FullMethod),
+				))
+			}
+			if log != nil {
+				lg := logging.WithTrace(ctx, log)
+				if rid, ok := logging.RequestID(ctx); ok {
+					lg = lg.With(zap.String("request_id", rid))
+				}
+				lg.Warn("rpc timed out",
+					zap.String("rpc.method", info.FullMethod),
+					zap.Duration("timeout", d),
+				)
+			}
+		}
+		return resp, err
 	}
 }
 
@@ -71,3 +116,129 @@ func StreamInFlightLimit(max int) grpc.StreamServerInterceptor {
 		}
 	}
 }
+
+// QueueOptions configures a bounded waiting queue in front of an in-flight limit.
+type QueueOptions struct {
+	// MaxQueue bounds how many requests may wait for a slot once the limit is reached.
+	// Zero disables queueing (fail-fast).
+	MaxQueue int
+	// MaxWait bounds how long a queued request waits for a slot before it is rejected.
+	// Defaults to 100ms when queueing is enabled.
+	MaxWait time.Duration
+}
+
+// UnaryInFlightLimitQueued bounds concurrent in-flight unary RPCs like UnaryInFlightLimit,
+// but lets short bursts wait in a bounded queue instead of failing immediately.
+//
+// Requests that find the queue full, or that wait longer than q.MaxWait, get
+// ResourceExhausted. Requests whose context ends while queued get the matching
+// context status (Canceled / DeadlineExceeded).
+//
+// Queue depth and wait time are recorded as OTel metrics under the given service name.
+func UnaryInFlightLimitQueued(service string, max int, q QueueOptions) grpc.UnaryServerInterceptor {
+	if max <= 0 {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+	if q.MaxQueue <= 0 {
+		return UnaryInFlightLimit(max)
+	}
+	if q.MaxWait <= 0 {
+		q.MaxWait = 100 * time.Millisecond
+	}
+
+	sem := make(chan struct{}, max)
+	queue := make(chan struct{}, q.MaxQueue)
+	qm := newQueueMetrics(service)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		// Fast path: a slot is free.
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			return handler(ctx, req)
+		default:
+		}
+
+		// Slow path: take a queue slot (or reject if the queue is full too).
+		select {
+		case queue <- struct{}{}:
+		default:
+			return nil, status.Error(codes.ResourceExhausted, "too many in-flight requests")
+		}
+
+		start := time.Now()
+		qm.enter(ctx)
+		timer := time.NewTimer(q.MaxWait)
+
+		var err error
+		select {
+		case sem <- struct{}{}:
+		case <-timer.C:
+			err = status.Error(codes.ResourceExhausted, "timed out waiting for in-flight slot")
+		case <-ctx.Done():
+			err = status.FromContextError(ctx.Err()).Err()
+		}
+
+		timer.Stop()
+		<-queue
+		qm.leave(ctx, time.Since(start), err == nil)
+
+		if err != nil {
+			return nil, err
+		}
+		defer func() { <-sem }()
+		return handler(ctx, req)
+	}
+}
+
+type queueMetrics struct {
+	depth metric.Int64UpDownCounter
+	wait  metric.Float64Histogram
+	attrs metric.MeasurementOption
+}
+
+func newQueueMetrics(service string) *queueMetrics {
+	m := otel.Meter("sdk-microservices/" + service)
+
+	depth, err := m.Int64UpDownCounter(
+		"rpc.server.queue.depth",
+		metric.WithDescription("RPCs waiting for an in-flight slot"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil
+	}
+	wait, err := m.Float64Histogram(
+		"rpc.server.queue.wait",
+		metric.WithDescription("Time spent waiting for an in-flight slot"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil
+	}
+
+	return &queueMetrics{
+		depth: depth,
+		wait:  wait,
+		attrs: metric.WithAttributes(attribute.String("service.name", service)),
+	}
+}
+
+func (q *queueMetrics) enter(ctx context.Context) {
+	if q == nil {
+		return
+	}
+	q.depth.Add(ctx, 1, q.attrs)
+}
+
+func (q *queueMetrics) leave(ctx context.Context, waited time.Duration, admitted bool) {
+	if q == nil {
+		return
+	}
+	q.depth.Add(ctx, -1, q.attrs)
+	q.wait.Record(ctx, waited.Seconds(), q.attrs,
+		metric.WithAttributes(attribute.Bool("admitted", admitted)),
+	)
+}
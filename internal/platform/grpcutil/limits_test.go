@@ -0,0 +1,91 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInFlightLimitQueued_WaitsForSlot(t *testing.T) {
+	icpt := UnaryInFlightLimitQueued("test", 1, QueueOptions{MaxQueue: 1, MaxWait: time.Second})
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Svc/Do"}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = icpt(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	_, err := icpt(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("expected queued request to succeed, got %v", err)
+	}
+}
+
+func TestUnaryInFlightLimitQueued_RejectsAfterMaxWait(t *testing.T) {
+	icpt := UnaryInFlightLimitQueued("test", 1, QueueOptions{MaxQueue: 1, MaxWait: 10 * time.Millisecond})
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Svc/Do"}
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	go func() {
+		_, _ = icpt(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+
+	_, err := icpt(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		t.Fatalf("handler should not run")
+		return nil, nil
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+}
+
+func TestUnaryTimeoutObserved_LogsOwnDeadlineOnly(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	icpt := UnaryTimeoutObserved("test", zap.New(core), 10*time.Millisecond)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Svc/Do"}
+	slow := func(ctx context.Context, req any) (any, error) {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	if _, err := icpt(context.Background(), nil, info, slow); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if n := logs.FilterMessage("rpc timed out").Len(); n != 1 {
+		t.Fatalf("expected 1 timeout log, got %d", n)
+	}
+
+	// A caller-supplied deadline is not ours to report.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _ = icpt(ctx, nil, info, slow)
+	if n := logs.FilterMessage("rpc timed out").Len(); n != 1 {
+		t.Fatalf("expected upstream deadline not to be logged, got %d logs", n)
+	}
+}
@@ -0,0 +1,50 @@
+package grpcutil
+
+import (
+	"context"
+
+	"sdk-microservices/internal/platform/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientPropagation copies the request id (logging.RequestID) from the calling
+// context into outgoing x-request-id metadata on every call. Caller identity is
+// propagated separately by UnaryClientIdentity.
+//
+// Values already present in the outgoing metadata (e.g. set by the grpc-gateway
+// metadata annotator) win, so explicit call sites keep full control.
+func UnaryClientPropagation() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(propagateOutgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientPropagation is the streaming variant of UnaryClientPropagation.
+func StreamClientPropagation() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(propagateOutgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+func propagateOutgoing(ctx context.Context) context.Context {
+	rid, _ := logging.RequestID(ctx)
+	return appendMissingOutgoing(ctx, "x-request-id", rid)
+}
+
+// appendMissingOutgoing appends key/value pairs to the outgoing metadata, skipping
+// empty values and keys the outgoing metadata already carries.
+func appendMissingOutgoing(ctx context.Context, kv ...string) context.Context {
+	out, _ := metadata.FromOutgoingContext(ctx)
+	var add []string
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" && len(out.Get(kv[i])) == 0 {
+			add = append(add, kv[i], kv[i+1])
+		}
+	}
+	if len(add) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, add...)
+}
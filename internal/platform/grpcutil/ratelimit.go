@@ -0,0 +1,171 @@
+package grpcutil
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/ratelimit"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimiter decides whether one more request for key may proceed.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
+
+// UnaryRateLimit rejects unary RPCs with ResourceExhausted once the caller's
+// budget is spent. Callers are keyed by authenticated user id (authctx), then
+// the x-api-key metadata value, then the peer IP.
+//
+// Install it after AuthUnaryInterceptor so authctx is populated. If the limiter
+// backend errors, the request is allowed (fail-open): a limiter outage should
+// not take the service down with it.
+func UnaryRateLimit(l RateLimiter) grpc.UnaryServerInterceptor {
+	if l == nil {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if ok, err := l.Allow(ctx, rateLimitKey(ctx)); err == nil && !ok {
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimit is the streaming variant of UnaryRateLimit (one token per stream).
+func StreamRateLimit(l RateLimiter) grpc.StreamServerInterceptor {
+	if l == nil {
+		return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, ss)
+		}
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		if ok, err := l.Allow(ctx, rateLimitKey(ctx)); err == nil && !ok {
+			return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(srv, ss)
+	}
+}
+
+func rateLimitKey(ctx context.Context) string {
+	if uid, ok := authctx.UserID(ctx); ok {
+		return "user:" + uid
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if key := first(md, "x-api-key"); key != "" {
+			return "apikey:" + key
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "unknown"
+}
+
+// LocalRateLimiter is an in-memory per-key token bucket.
+// NOTE: limits are per instance; use RedisRateLimiter to share a budget across replicas.
+type LocalRateLimiter struct {
+	buckets *ratelimit.Buckets
+}
+
+func NewLocalRateLimiter(r rate.Limit, burst int, ttl time.Duration) *LocalRateLimiter {
+	return &LocalRateLimiter{buckets: ratelimit.NewBuckets(r, burst, ttl)}
+}
+
+func (l *LocalRateLimiter) Allow(_ context.Context, key string) (bool, error) {
+	return l.buckets.Allow(key), nil
+}
+
+// SetLimit changes the rate and burst for every key, including those already
+// tracked (e.g. on a config reload).
+func (l *LocalRateLimiter) SetLimit(r rate.Limit, burst int) {
+	l.buckets.SetLimit(r, burst)
+}
+
+// RedisRateLimiter is a token bucket stored in Redis, shared by all replicas.
+type RedisRateLimiter struct {
+	client redis.UniversalClient
+	prefix string
+
+	mu    sync.RWMutex
+	rate  rate.Limit
+	burst int
+}
+
+func NewRedisRateLimiter(client redis.UniversalClient, prefix string, r rate.Limit, burst int) *RedisRateLimiter {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisRateLimiter{client: client, prefix: prefix, rate: r, burst: burst}
+}
+
+// tokenBucketScript refills the bucket based on Redis server time, then tries to take one token.
+// KEYS[1]=bucket key, ARGV[1]=tokens/sec, ARGV[2]=burst. Returns 1 if allowed.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(b[1])
+local ts = tonumber(b[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return allowed
+`)
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	l.mu.RLock()
+	r, burst := l.rate, l.burst
+	l.mu.RUnlock()
+	if r <= 0 {
+		return false, nil
+	}
+	n, err := tokenBucketScript.Run(ctx, l.client,
+		[]string{l.prefix + key},
+		strconv.FormatFloat(float64(r), 'f', -1, 64),
+		burst,
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// SetLimit changes the rate and burst; buckets refill at the new rate from
+// their next request.
+func (l *RedisRateLimiter) SetLimit(r rate.Limit, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate, l.burst = r, burst
+}
@@ -0,0 +1,147 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	base := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	cases := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"user wins", authctx.WithUserID(metadata.NewIncomingContext(base, metadata.Pairs("x-api-key", "k1")), "u1"), "user:u1"},
+		{"api key", metadata.NewIncomingContext(base, metadata.Pairs("x-api-key", "k1")), "apikey:k1"},
+		{"peer ip", base, "ip:10.0.0.1"},
+		{"unknown", context.Background(), "unknown"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := rateLimitKey(tc.ctx); got != tc.want {
+				t.Fatalf("key = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+type limiterFunc func(ctx context.Context, key string) (bool, error)
+
+func (f limiterFunc) Allow(ctx context.Context, key string) (bool, error) { return f(ctx, key) }
+
+func TestUnaryRateLimit(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc.v1.Svc/Get"}
+	ok := func(context.Context, any) (any, error) { return "ok", nil }
+	ctx := authctx.WithUserID(context.Background(), "u1")
+
+	icpt := UnaryRateLimit(NewLocalRateLimiter(1, 2, time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := icpt(ctx, nil, info, ok); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if _, err := icpt(ctx, nil, info, ok); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v, want ResourceExhausted", err)
+	}
+	if _, err := icpt(authctx.WithUserID(context.Background(), "u2"), nil, info, ok); err != nil {
+		t.Fatalf("other caller: %v", err)
+	}
+
+	// A failing backend lets requests through.
+	failing := UnaryRateLimit(limiterFunc(func(context.Context, string) (bool, error) {
+		return false, errors.New("backend down")
+	}))
+	if _, err := failing(ctx, nil, info, ok); err != nil {
+		t.Fatalf("fail-open: %v", err)
+	}
+}
+
+// redisReply short-circuits every command on rdb with val (or err) and records
+// the last command's arguments.
+func redisReply(rdb *redis.Client, val any, err error, args *[]any) {
+	rdb.AddHook(replyHook{val: val, err: err, args: args})
+}
+
+type replyHook struct {
+	val  any
+	err  error
+	args *[]any
+}
+
+func (replyHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h replyHook) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		*h.args = cmd.Args()
+		if h.err != nil {
+			cmd.SetErr(h.err)
+			return h.err
+		}
+		cmd.(*redis.Cmd).SetVal(h.val)
+		return nil
+	}
+}
+
+func (replyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(val any, err error, args *[]any) *redis.Client {
+		rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+		t.Cleanup(func() { rdb.Close() })
+		redisReply(rdb, val, err, args)
+		return rdb
+	}
+
+	var args []any
+	l := NewRedisRateLimiter(newClient(int64(1), nil, &args), "", 2.5, 5)
+	if ok, err := l.Allow(ctx, "user:u1"); err != nil || !ok {
+		t.Fatalf("Allow = %v, %v; want allowed", ok, err)
+	}
+	// EVALSHA sha 1 key rate burst
+	if len(args) != 6 || args[3] != "ratelimit:user:u1" || args[4] != "2.5" || args[5] != 5 {
+		t.Fatalf("script args = %v", args)
+	}
+
+	l = NewRedisRateLimiter(newClient(int64(0), nil, &args), "hello:", 1, 1)
+	if ok, err := l.Allow(ctx, "ip:10.0.0.1"); err != nil || ok {
+		t.Fatalf("Allow = %v, %v; want denied", ok, err)
+	}
+	if args[3] != "hello:ip:10.0.0.1" {
+		t.Fatalf("key = %v", args[3])
+	}
+
+	l.SetLimit(10, 20)
+	l.Allow(ctx, "k")
+	if args[4] != "10" || args[5] != 20 {
+		t.Fatalf("after SetLimit args = %v", args)
+	}
+
+	backendErr := errors.New("connection refused")
+	l = NewRedisRateLimiter(newClient(nil, backendErr, &args), "", 1, 1)
+	if _, err := l.Allow(ctx, "k"); !errors.Is(err, backendErr) {
+		t.Fatalf("err = %v, want backend error", err)
+	}
+
+	args = nil
+	l = NewRedisRateLimiter(newClient(int64(1), nil, &args), "", 0, 1)
+	if ok, err := l.Allow(ctx, "k"); ok || err != nil || args != nil {
+		t.Fatalf("zero rate: Allow = %v, %v (redis called: %v)", ok, err, args != nil)
+	}
+}
@@ -0,0 +1,52 @@
+package grpcutil
+
+import (
+	"context"
+	"runtime/debug"
+
+	"sdk-microservices/internal/platform/errreport"
+	"sdk-microservices/internal/platform/logging"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerRecover turns handler panics into codes.Internal, logs them with the
+// request-scoped logger (falling back to log) and sends them to the error reporter.
+// It is the gRPC counterpart of httpmw.Recover.
+func UnaryServerRecover(log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = recovered(ctx, log, info.FullMethod, v)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecover is the streaming variant of UnaryServerRecover.
+func StreamServerRecover(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, v)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, log *zap.Logger, method string, v any) error {
+	stack := debug.Stack()
+	errreport.CapturePanic(ctx, v, stack)
+	logging.From(ctx, log).Error("panic recovered",
+		zap.String("rpc.method", method),
+		zap.Any("panic", v),
+		zap.ByteString("stack", stack),
+		errreport.Reported,
+	)
+	return status.Error(codes.Internal, "internal error")
+}
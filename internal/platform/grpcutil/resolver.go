@@ -0,0 +1,232 @@
+package grpcutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/balancer/pickfirst"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/resolver"
+)
+
+// Load-balancing policies accepted by ClientOptions.LoadBalancing.
+const (
+	LBPickFirst    = "pick_first"
+	LBRoundRobin   = "round_robin"
+	LBLeastRequest = "least_request"
+)
+
+// lbServiceConfig returns a default service config selecting the given policy.
+func lbServiceConfig(policy string) (string, error) {
+	var name string
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case "", LBPickFirst:
+		name = pickfirst.Name
+	case LBRoundRobin:
+		name = roundrobin.Name
+	case LBLeastRequest:
+		name = leastrequest.Name
+	default:
+		return "", fmt.Errorf("grpcutil: unsupported load balancing policy %q", policy)
+	}
+	return fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, name), nil
+}
+
+// DNSTarget returns a dns:/// target. The DNS resolver returns every A/AAAA record,
+// so combined with round_robin each replica gets its own subchannel.
+func DNSTarget(host string, port int) string {
+	return "dns:///" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// KubernetesTarget returns a dns:/// target for a Kubernetes headless service
+// (clusterIP: None), which resolves to one record per ready pod.
+// An empty namespace defaults to "default".
+func KubernetesTarget(service, namespace string, port int) string {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return DNSTarget(service+"."+namespace+".svc.cluster.local", port)
+}
+
+// ConsulScheme is the resolver scheme for Consul-based discovery.
+//
+// Target format:
+//
+//	consul://<agent-host:port>/<service>?tag=<tag>&dc=<datacenter>
+//
+// Only instances passing their health checks are returned.
+const ConsulScheme = "consul"
+
+// ConsulTarget returns a consul:// target for service via the given agent address.
+func ConsulTarget(agent, service string) string {
+	return ConsulScheme + "://" + agent + "/" + service
+}
+
+// NewConsulResolverBuilder returns a resolver.Builder for consul:// targets.
+// Dial registers it automatically; pass it via grpc.WithResolvers when dialing manually.
+func NewConsulResolverBuilder() resolver.Builder {
+	return consulBuilder{}
+}
+
+type consulBuilder struct{}
+
+func (consulBuilder) Scheme() string { return ConsulScheme }
+
+func (consulBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	service := strings.TrimPrefix(target.URL.Path, "/")
+	if target.URL.Host == "" || service == "" {
+		return nil, fmt.Errorf("grpcutil: invalid consul target %q", target.URL.String())
+	}
+
+	q := url.Values{}
+	q.Set("passing", "true")
+	if tag := target.URL.Query().Get("tag"); tag != "" {
+		q.Set("tag", tag)
+	}
+	if dc := target.URL.Query().Get("dc"); dc != "" {
+		q.Set("dc", dc)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &consulResolver{
+		endpoint: "http://" + target.URL.Host + "/v1/health/service/" + url.PathEscape(service),
+		query:    q,
+		cc:       cc,
+		client:   &http.Client{Timeout: 6 * time.Minute},
+		cancel:   cancel,
+	}
+	go r.watch(ctx)
+	return r, nil
+}
+
+type consulResolver struct {
+	endpoint string
+	query    url.Values
+	cc       resolver.ClientConn
+	client   *http.Client
+	cancel   context.CancelFunc
+}
+
+// ResolveNow is a no-op: watch uses Consul blocking queries and pushes every change.
+func (r *consulResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *consulResolver) Close() { r.cancel() }
+
+const (
+	consulMinBackoff = 100 * time.Millisecond
+	consulMaxBackoff = 10 * time.Second
+)
+
+// watch follows Consul's blocking-query rules: an index that goes backwards
+// (agent restart, snapshot restore) resets to 0, and a query that returns
+// without a new index (missing X-Consul-Index, or an agent ignoring the wait)
+// backs off instead of re-querying immediately.
+func (r *consulResolver) watch(ctx context.Context) {
+	var index uint64
+	published := false
+	errBackoff, idleBackoff := consulMinBackoff, consulMinBackoff
+
+	for ctx.Err() == nil {
+		addrs, next, err := r.fetch(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.cc.ReportError(err)
+			if !sleepCtx(ctx, errBackoff) {
+				return
+			}
+			errBackoff = min(2*errBackoff, consulMaxBackoff)
+			continue
+		}
+		errBackoff = consulMinBackoff
+
+		if !published || next != index {
+			published = true
+			_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+		}
+
+		switch {
+		case next > index:
+			index = next
+			idleBackoff = consulMinBackoff
+		case next < index:
+			index = 0
+		default:
+			if !sleepCtx(ctx, idleBackoff) {
+				return
+			}
+			idleBackoff = min(2*idleBackoff, consulMaxBackoff)
+		}
+	}
+}
+
+// sleepCtx waits for d, reporting false if ctx ends first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+type consulEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		Address string `json:"Address"`
+		Port    int    `json:"Port"`
+	} `json:"Service"`
+}
+
+// fetch queries the agent, blocking until the index changes when index is set.
+// It returns the response's X-Consul-Index, or 0 if the header is missing.
+func (r *consulResolver) fetch(ctx context.Context, index uint64) ([]resolver.Address, uint64, error) {
+	q := url.Values{}
+	for k, v := range r.query {
+		q[k] = v
+	}
+	if index > 0 {
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", "5m")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul: unexpected status %d", resp.StatusCode)
+	}
+
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("consul: decode: %w", err)
+	}
+
+	addrs := make([]resolver.Address, 0, len(entries))
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(host, strconv.Itoa(e.Service.Port))})
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return addrs, next, nil
+}
@@ -0,0 +1,166 @@
+package grpcutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+type fakeClientConn struct {
+	resolver.ClientConn
+	states chan resolver.State
+}
+
+func (f *fakeClientConn) UpdateState(s resolver.State) error {
+	f.states <- s
+	return nil
+}
+
+func (f *fakeClientConn) ReportError(error) {}
+
+func TestConsulResolver_UpdatesAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/hello" || r.URL.Query().Get("passing") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("index") != "" {
+			// Block like Consul until the client goes away.
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("X-Consul-Index", "7")
+		_, _ = w.Write([]byte(`[
+			{"Node":{"Address":"10.0.0.1"},"Service":{"Address":"","Port":50051}},
+			{"Node":{"Address":"10.0.0.2"},"Service":{"Address":"10.1.0.2","Port":50051}}
+		]`))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(ConsulTarget(srv.Listener.Addr().String(), "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &fakeClientConn{states: make(chan resolver.State, 1)}
+	r, err := NewConsulResolverBuilder().Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	select {
+	case st := <-cc.states:
+		if len(st.Addresses) != 2 {
+			t.Fatalf("expected 2 addresses, got %d", len(st.Addresses))
+		}
+		if st.Addresses[0].Addr != "10.0.0.1:50051" || st.Addresses[1].Addr != "10.1.0.2:50051" {
+			t.Fatalf("unexpected addresses: %+v", st.Addresses)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for resolver update")
+	}
+}
+
+func buildConsulResolver(t *testing.T, srv *httptest.Server) *fakeClientConn {
+	t.Helper()
+	u, err := url.Parse(ConsulTarget(srv.Listener.Addr().String(), "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &fakeClientConn{states: make(chan resolver.State, 16)}
+	r, err := NewConsulResolverBuilder().Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+	return cc
+}
+
+// An agent that answers without X-Consul-Index must not be polled in a tight loop.
+func TestConsulResolver_BacksOffWithoutIndex(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`[{"Node":{"Address":"10.0.0.1"},"Service":{"Port":50051}}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cc := buildConsulResolver(t, srv)
+	select {
+	case <-cc.states:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for resolver update")
+	}
+	time.Sleep(500 * time.Millisecond)
+	// 100ms, 200ms, 400ms backoffs fit at most ~4 requests into 500ms.
+	if n := requests.Load(); n > 5 {
+		t.Fatalf("agent polled %d times in 500ms", n)
+	}
+}
+
+// When the index goes backwards (e.g. the agent restarted) the resolver starts
+// over with a non-blocking query rather than waiting on the stale index.
+func TestConsulResolver_ResetsIndexThatGoesBackwards(t *testing.T) {
+	indexes := []string{"10", "5", "6"}
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n := len(seen)
+		seen = append(seen, r.URL.Query().Get("index"))
+		mu.Unlock()
+		if n >= len(indexes) {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("X-Consul-Index", indexes[n])
+		fmt.Fprintf(w, `[{"Node":{"Address":"10.0.0.%d"},"Service":{"Port":50051}}]`, n+1)
+	}))
+	t.Cleanup(srv.Close) // runs after the resolver's Close ends the blocked query
+
+	cc := buildConsulResolver(t, srv)
+	for i := 0; i < len(indexes); i++ {
+		select {
+		case <-cc.states:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for update %d", i)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		got := append([]string(nil), seen...)
+		mu.Unlock()
+		if len(got) >= 4 {
+			want := []string{"", "10", "", "6"}
+			for i, w := range want {
+				if got[i] != w {
+					t.Fatalf("index params = %q, want %q", got[:4], want)
+				}
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("index params = %q", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLBServiceConfig_RejectsUnknownPolicy(t *testing.T) {
+	if _, err := lbServiceConfig("random"); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+	if _, err := lbServiceConfig(LBLeastRequest); err != nil {
+		t.Fatalf("least_request: %v", err)
+	}
+}
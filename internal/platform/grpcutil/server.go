@@ -0,0 +1,29 @@
+package grpcutil
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// Server is the subset of *grpc.Server shared with xDS-enabled servers, so daemons
+// can register services and drain the same way regardless of which one they run.
+type Server interface {
+	grpc.ServiceRegistrar
+	Serve(lis net.Listener) error
+	Stop()
+	GracefulStop()
+}
+
+// NewServer returns a plain *grpc.Server, or an xDS-managed server when useXDS is set.
+//
+// xDS servers get their listener configuration (routing, mTLS, RBAC) from the control
+// plane named in the bootstrap file (GRPC_XDS_BOOTSTRAP / GRPC_XDS_BOOTSTRAP_CONFIG)
+// and fall back to plaintext when the control plane sends no security config.
+// xDS support is only compiled in with the "xds" build tag.
+func NewServer(useXDS bool, opts ...grpc.ServerOption) (Server, error) {
+	if useXDS {
+		return newXDSServer(opts...)
+	}
+	return grpc.NewServer(opts...), nil
+}
@@ -0,0 +1,22 @@
+//go:build !xds
+
+package grpcutil
+
+import (
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+// XDSEnabled reports whether this binary was built with xDS support.
+const XDSEnabled = false
+
+var errXDSDisabled = errors.New("grpcutil: xDS requested but binary was built without the xds build tag")
+
+func newXDSServer(...grpc.ServerOption) (Server, error) {
+	return nil, errXDSDisabled
+}
+
+func xdsDialOptions() ([]grpc.DialOption, error) {
+	return nil, errXDSDisabled
+}
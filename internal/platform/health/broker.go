@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ConnState is implemented by broker connections that track their own connection
+// state, e.g. *nats.Conn.
+type ConnState interface {
+	IsConnected() bool
+}
+
+// Pinger is implemented by clients that can round-trip to the broker, e.g. a
+// franz-go *kgo.Client.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// NATSConnected checks that a NATS connection is currently connected. It does not
+// touch the network: the client reconnects on its own and reports its state.
+func NATSConnected(conn ConnState) Check {
+	return func(context.Context) error {
+		if conn == nil {
+			return fmt.Errorf("nats conn is nil")
+		}
+		if !conn.IsConnected() {
+			return fmt.Errorf("nats not connected")
+		}
+		return nil
+	}
+}
+
+// KafkaPing checks that at least one Kafka broker answers.
+func KafkaPing(client Pinger) Check {
+	return func(ctx context.Context) error {
+		if client == nil {
+			return fmt.Errorf("kafka client is nil")
+		}
+		ctx2, cancel := context.WithTimeout(ctx, 1*time.Second)
+		defer cancel()
+		return client.Ping(ctx2)
+	}
+}
+
+// Heartbeat records the last successful publish or consume, so readiness can tell
+// a connected-but-stuck producer or consumer from a working one. Safe for
+// concurrent use; the zero value has never beaten.
+type Heartbeat struct {
+	last atomic.Int64 // unix nanos
+}
+
+// Beat records a successful publish/consume now.
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Last returns the time of the last Beat (zero if none).
+func (h *Heartbeat) Last() time.Time {
+	n := h.last.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// Check fails if there has been no Beat within maxAge. Before the first Beat it
+// passes for maxAge after the check is created, giving the client time to start.
+func (h *Heartbeat) Check(maxAge time.Duration) Check {
+	created := time.Now()
+	return func(context.Context) error {
+		last := h.Last()
+		if last.IsZero() {
+			if time.Since(created) <= maxAge {
+				return nil
+			}
+			return fmt.Errorf("no heartbeat since start (%s)", time.Since(created).Round(time.Second))
+		}
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last heartbeat %s ago", age.Round(time.Second))
+		}
+		return nil
+	}
+}
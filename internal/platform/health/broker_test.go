@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeConn bool
+
+func (c fakeConn) IsConnected() bool { return bool(c) }
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(context.Context) error { return p.err }
+
+func TestBrokerChecks(t *testing.T) {
+	ctx := context.Background()
+	if err := NATSConnected(fakeConn(true))(ctx); err != nil {
+		t.Fatalf("connected: %v", err)
+	}
+	if err := NATSConnected(fakeConn(false))(ctx); err == nil {
+		t.Fatal("expected error when disconnected")
+	}
+	if err := KafkaPing(fakePinger{})(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if err := KafkaPing(fakePinger{err: errors.New("no brokers")})(ctx); err == nil {
+		t.Fatal("expected ping error")
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	var hb Heartbeat
+	check := hb.Check(30 * time.Millisecond)
+	if err := check(ctx); err != nil {
+		t.Fatalf("grace period: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if err := check(ctx); err == nil {
+		t.Fatal("expected error with no heartbeat after grace period")
+	}
+	hb.Beat()
+	if err := check(ctx); err != nil {
+		t.Fatalf("after beat: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if err := check(ctx); err == nil {
+		t.Fatal("expected stale heartbeat error")
+	}
+}
@@ -3,9 +3,12 @@ package health
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -21,6 +24,50 @@ func SQLPing(db *pgxpool.Pool) Check {
 	}
 }
 
+// RedisPing checks a Redis client (single node, cluster or sentinel) with PING.
+func RedisPing(client redis.UniversalClient) Check {
+	return func(ctx context.Context) error {
+		if client == nil {
+			return fmt.Errorf("redis client is nil")
+		}
+		ctx2, cancel := context.WithTimeout(ctx, 1*time.Second)
+		defer cancel()
+		return client.Ping(ctx2).Err()
+	}
+}
+
+// HTTPCheck GETs url and expects expectStatus (any 2xx if 0), for dependencies
+// that only expose an HTTP status page (third-party APIs, S3-compatible storage).
+// timeout defaults to 1s.
+func HTTPCheck(url string, expectStatus int, timeout time.Duration) Check {
+	if timeout <= 0 {
+		timeout = 1 * time.Second
+	}
+	return func(ctx context.Context) error {
+		ctx2, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx2, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+		ok := resp.StatusCode == expectStatus
+		if expectStatus == 0 {
+			ok = resp.StatusCode >= 200 && resp.StatusCode < 300
+		}
+		if !ok {
+			return fmt.Errorf("http status: %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
 // GRPCHealthCheck checks downstream readiness using the standard gRPC health service.
 func GRPCHealthCheck(conn *grpc.ClientConn, service string) Check {
 	return func(ctx context.Context) error {
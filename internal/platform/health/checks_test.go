@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisPing(t *testing.T) {
+	if err := RedisPing(nil)(context.Background()); err == nil || err.Error() != "redis client is nil" {
+		t.Fatalf("nil client: err = %v", err)
+	}
+
+	// Nothing listens on port 1; the check must fail rather than hang.
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	if err := RedisPing(rdb)(context.Background()); err == nil {
+		t.Fatal("expected error for unreachable redis")
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusNoContent)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if err := HTTPCheck(srv.URL+"/ok", 0, 0)(ctx); err != nil {
+		t.Fatalf("2xx: %v", err)
+	}
+	if err := HTTPCheck(srv.URL+"/ok", http.StatusOK, 0)(ctx); err == nil || err.Error() != "http status: 204" {
+		t.Fatalf("expect 200: err = %v", err)
+	}
+	if err := HTTPCheck(srv.URL+"/down", 0, 0)(ctx); err == nil {
+		t.Fatal("expected error for 503")
+	}
+	if err := HTTPCheck(srv.URL+"/slow", 0, 20*time.Millisecond)(ctx); err == nil {
+		t.Fatal("expected timeout")
+	}
+}
@@ -0,0 +1,242 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// EvaluatorOptions configures NewEvaluator.
+type EvaluatorOptions struct {
+	// Interval is how often the graph is evaluated in the background (default 5s).
+	Interval time.Duration
+	// Timeout bounds one evaluation of the whole graph (default 2s).
+	Timeout time.Duration
+	// MaxStaleness is how old a cached result may be before a probe re-evaluates
+	// synchronously instead (default 3*Interval). It only matters if the background
+	// loop is not running or falls behind (e.g. a check hangs past Timeout).
+	MaxStaleness time.Duration
+	// Parallelism bounds concurrent checks per evaluation (default DefaultParallelism).
+	Parallelism int
+
+	// Service, if set, exports a health.node.status gauge per node (1 healthy,
+	// 0 unhealthy) under the service's meter.
+	Service string
+	// Log receives a line per node state transition (optional).
+	Log *zap.Logger
+}
+
+// Evaluator evaluates a health graph in the background and serves probes from the
+// cached result, so aggressive kubelet probing doesn't turn into a query against
+// every dependency per request.
+type Evaluator struct {
+	root *Node
+	opts EvaluatorOptions
+
+	evalMu sync.Mutex // serializes evaluations
+
+	mu     sync.RWMutex
+	last   Result
+	at     time.Time
+	states map[string]nodeState // by node path, e.g. "ready/db"
+	subs   []func(Result)
+}
+
+type nodeState struct {
+	healthy bool
+	status  string
+	since   time.Time
+}
+
+// NewEvaluator returns an Evaluator for root. Call Start to begin background
+// refreshes; until then probes evaluate on demand (at most once per MaxStaleness).
+func NewEvaluator(root *Node, opts EvaluatorOptions) *Evaluator {
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	if opts.MaxStaleness <= 0 {
+		opts.MaxStaleness = 3 * opts.Interval
+	}
+	if opts.Log == nil {
+		opts.Log = zap.NewNop()
+	}
+	e := &Evaluator{root: root, opts: opts, states: map[string]nodeState{}}
+	if opts.Service != "" {
+		e.instrument(opts.Service)
+	}
+	return e
+}
+
+// instrument registers the per-node status gauge, read from the latest states.
+func (e *Evaluator) instrument(service string) {
+	m := otel.Meter("sdk-microservices/" + service)
+	svc := attribute.String("service.name", service)
+	_, err := m.Int64ObservableGauge(
+		"health.node.status",
+		metric.WithDescription("Health of each dependency graph node (1 healthy, 0 unhealthy)"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			e.mu.RLock()
+			defer e.mu.RUnlock()
+			for path, st := range e.states {
+				v := int64(0)
+				if st.healthy {
+					v = 1
+				}
+				o.Observe(v, metric.WithAttributes(svc, attribute.String("health.node", path)))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		e.opts.Log.Warn("health metrics disabled (init failed)", zap.Error(err))
+	}
+}
+
+// Start evaluates the graph now and then every Interval until ctx ends. Structural
+// problems in the graph (see Validate) are logged once.
+func (e *Evaluator) Start(ctx context.Context) {
+	if err := Validate(e.root); err != nil {
+		e.opts.Log.Error("invalid health graph", zap.Error(err))
+	}
+	e.refresh(ctx)
+	go func() {
+		t := time.NewTicker(e.opts.Interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				e.refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Result returns the latest cached result and when it was computed; ok is false
+// before the first evaluation.
+func (e *Evaluator) Result() (res Result, at time.Time, ok bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.last, e.at, !e.at.IsZero()
+}
+
+// Evaluate returns the cached result, re-evaluating first if it is missing or
+// older than MaxStaleness. Concurrent callers share one evaluation.
+func (e *Evaluator) Evaluate(ctx context.Context) Result {
+	if res, at, ok := e.Result(); ok && time.Since(at) <= e.opts.MaxStaleness {
+		return res
+	}
+	e.evalMu.Lock()
+	defer e.evalMu.Unlock()
+	// Another caller may have refreshed while we waited.
+	if res, at, ok := e.Result(); ok && time.Since(at) <= e.opts.MaxStaleness {
+		return res
+	}
+	return e.evaluateLocked(ctx)
+}
+
+// Refresh re-evaluates the graph now instead of at the next interval, e.g.
+// when a gate in it opens.
+func (e *Evaluator) Refresh(ctx context.Context) {
+	e.refresh(ctx)
+}
+
+func (e *Evaluator) refresh(ctx context.Context) {
+	e.evalMu.Lock()
+	defer e.evalMu.Unlock()
+	e.evaluateLocked(ctx)
+}
+
+// evaluateLocked runs the graph and caches the result. Callers hold evalMu.
+func (e *Evaluator) evaluateLocked(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.opts.Timeout)
+	defer cancel()
+	res := EvaluateWith(ctx, e.root, EvaluateOptions{Parallelism: e.opts.Parallelism})
+	now := time.Now()
+
+	e.mu.Lock()
+	e.last, e.at = res, now
+	e.track("", res, now)
+	subs := e.subs
+	e.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(res)
+	}
+	return res
+}
+
+// Subscribe calls fn with every new result (e.g. to mirror readiness into the gRPC
+// health service). Calls are serialized; fn must not block.
+func (e *Evaluator) Subscribe(fn func(Result)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subs = append(e.subs[:len(e.subs):len(e.subs)], fn)
+}
+
+// track records the state of res and its deps, logging transitions with how long
+// the node spent in its previous state. Callers hold e.mu.
+func (e *Evaluator) track(parent string, res Result, now time.Time) {
+	path := res.Name
+	if parent != "" {
+		path = parent + "/" + res.Name
+	}
+	prev, seen := e.states[path]
+	switch {
+	case !seen:
+		e.states[path] = nodeState{healthy: res.Healthy, status: res.Status, since: now}
+		if !res.Healthy {
+			e.opts.Log.Warn("health node unhealthy", zap.String("health.node", path), zap.String("error", res.Error))
+		}
+	case prev.status != res.Status:
+		e.states[path] = nodeState{healthy: res.Healthy, status: res.Status, since: now}
+		fields := []zap.Field{
+			zap.String("health.node", path),
+			zap.String("from", prev.status),
+			zap.String("to", res.Status),
+			zap.Duration("previous_duration", now.Sub(prev.since)),
+		}
+		if res.Error != "" {
+			fields = append(fields, zap.String("error", res.Error))
+		}
+		if res.Status == StatusOK {
+			e.opts.Log.Info("health node recovered", fields...)
+		} else {
+			e.opts.Log.Warn("health node state changed", fields...)
+		}
+	}
+	for _, d := range res.Deps {
+		e.track(path, d, now)
+	}
+}
+
+// Handler serves the cached result like health.Handler, adding an Age header with
+// the result's age in seconds. If serving() is provided and returns false, the
+// handler returns 503 immediately.
+func (e *Evaluator) Handler(serving func() bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serving != nil && !serving() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("NOT_SERVING"))
+			return
+		}
+
+		out := e.Evaluate(r.Context())
+		if _, at, ok := e.Result(); ok {
+			w.Header().Set("Age", strconv.FormatInt(int64(time.Since(at)/time.Second), 10))
+		}
+		writeResult(w, out)
+	})
+}
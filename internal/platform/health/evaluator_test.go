@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEvaluator_ServesFromCache(t *testing.T) {
+	var calls atomic.Int32
+	root := NewReadyGraph()
+	root.Add("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	ev := NewEvaluator(root, EvaluatorOptions{Interval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ev.Start(ctx)
+
+	h := ev.Handler(nil)
+	for i := 0; i < 5; i++ {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rr.Code)
+		}
+		if rr.Header().Get("Age") == "" {
+			t.Fatalf("missing Age header")
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("check ran %d times, want 1", n)
+	}
+}
+
+func TestEvaluator_StaleResultReevaluates(t *testing.T) {
+	var fail atomic.Bool
+	root := NewReadyGraph()
+	root.Add("db", func(context.Context) error {
+		if fail.Load() {
+			return errors.New("down")
+		}
+		return nil
+	})
+
+	ev := NewEvaluator(root, EvaluatorOptions{Interval: time.Hour, MaxStaleness: time.Millisecond})
+	if !ev.Evaluate(context.Background()).Healthy {
+		t.Fatalf("expected healthy")
+	}
+	fail.Store(true)
+	time.Sleep(5 * time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	ev.Handler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 after stale re-evaluation", rr.Code)
+	}
+}
+
+func TestEvaluator_LogsTransitions(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	var fail atomic.Bool
+	root := NewReadyGraph()
+	root.Add("db", func(context.Context) error {
+		if fail.Load() {
+			return errors.New("down")
+		}
+		return nil
+	})
+
+	ev := NewEvaluator(root, EvaluatorOptions{Interval: time.Hour, Log: zap.New(core)})
+	ev.refresh(context.Background())
+	fail.Store(true)
+	ev.refresh(context.Background())
+	ev.refresh(context.Background()) // no change, no log
+	fail.Store(false)
+	ev.refresh(context.Background())
+
+	changed := logs.FilterMessage("health node state changed").FilterField(zap.String("health.node", "ready/db")).All()
+	if len(changed) != 1 || changed[0].ContextMap()["to"] != StatusUnhealthy {
+		t.Fatalf("state changed logs = %v", changed)
+	}
+	recovered := logs.FilterMessage("health node recovered").FilterField(zap.String("health.node", "ready/db")).All()
+	if len(recovered) != 1 {
+		t.Fatalf("recovered logs = %d, want 1", len(recovered))
+	}
+	if _, ok := recovered[0].ContextMap()["previous_duration"]; !ok {
+		t.Fatalf("missing previous_duration")
+	}
+}
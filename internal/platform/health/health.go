@@ -3,21 +3,52 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Check func(ctx context.Context) error
 
+// Criticality says whether a node's failure fails its parent.
+type Criticality int
+
+const (
+	// Hard dependencies fail readiness when unhealthy (the default).
+	Hard Criticality = iota
+	// Soft dependencies only mark their parent "degraded" when unhealthy, so a
+	// flaky optional dependency doesn't take pods out of rotation.
+	Soft
+)
+
+// Result statuses.
+const (
+	StatusOK        = "ok"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
 type Node struct {
 	Name  string
 	Check Check
 	Deps  []*Node
+
+	// Timeout bounds Check (zero means only the caller's deadline applies).
+	Timeout time.Duration
+	// Criticality is how this node's failure affects its parent (default Hard).
+	Criticality Criticality
 }
 
 type Result struct {
-	Name     string            `json:"name"`
-	Healthy  bool              `json:"healthy"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Status is StatusOK, StatusDegraded (healthy, but a soft dependency is
+	// failing) or StatusUnhealthy.
+	Status   string            `json:"status"`
+	Soft     bool              `json:"soft,omitempty"`
 	Error    string            `json:"error,omitempty"`
 	Duration time.Duration     `json:"duration"`
 	Deps     map[string]Result `json:"deps,omitempty"`
@@ -30,44 +61,197 @@ func (n *Node) Add(name string, check Check) *Node {
 	return child
 }
 
+// AddSoft is Add for a Soft dependency.
+func (n *Node) AddSoft(name string, check Check) *Node {
+	child := n.Add(name, check)
+	child.Criticality = Soft
+	return child
+}
+
+// DefaultParallelism bounds how many checks Evaluate runs at once.
+const DefaultParallelism = 8
+
+// EvaluateOptions configures EvaluateWith.
+type EvaluateOptions struct {
+	// Parallelism is the maximum number of checks running at once (default
+	// DefaultParallelism; 1 evaluates sequentially).
+	Parallelism int
+}
+
+// Evaluate is EvaluateWith with default options.
 func Evaluate(ctx context.Context, n *Node) Result {
+	return EvaluateWith(ctx, n, EvaluateOptions{})
+}
+
+// EvaluateWith runs n's check and then its dependencies', siblings concurrently.
+// n is healthy if its check passes and all Hard dependencies are healthy; failing
+// Soft dependencies only degrade it. A node reachable along several paths is
+// checked once per evaluation. A graph with a cycle is reported unhealthy without
+// running any checks.
+func EvaluateWith(ctx context.Context, n *Node, opts EvaluateOptions) Result {
+	if err := findCycle(n); err != nil {
+		return Result{Name: n.Name, Status: StatusUnhealthy, Error: err.Error()}
+	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = DefaultParallelism
+	}
+	r := &evalRun{sem: make(chan struct{}, opts.Parallelism), done: map[*Node]*evalOnce{}}
+	return r.eval(ctx, n)
+}
+
+// evalRun is the state of one evaluation: the check semaphore and per-node results.
+type evalRun struct {
+	sem  chan struct{}
+	mu   sync.Mutex
+	done map[*Node]*evalOnce
+}
+
+type evalOnce struct {
+	once sync.Once
+	res  Result
+}
+
+func (r *evalRun) eval(ctx context.Context, n *Node) Result {
+	r.mu.Lock()
+	o, ok := r.done[n]
+	if !ok {
+		o = &evalOnce{}
+		r.done[n] = o
+	}
+	r.mu.Unlock()
+	o.once.Do(func() { o.res = r.evalNode(ctx, n) })
+	return o.res
+}
+
+func (r *evalRun) evalNode(ctx context.Context, n *Node) Result {
 	start := time.Now()
 	res := Result{
-		Name: n.Name,
-		Deps: map[string]Result{},
+		Name:    n.Name,
+		Healthy: true,
+		Status:  StatusOK,
+		Soft:    n.Criticality == Soft,
+		Deps:    map[string]Result{},
 	}
 	if n.Check != nil {
-		if err := n.Check(ctx); err != nil {
+		// Only the check holds a slot, so parents waiting on children can't starve them.
+		var err error
+		select {
+		case r.sem <- struct{}{}:
+			err = runCheck(ctx, n)
+			<-r.sem
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
 			res.Healthy = false
+			res.Status = StatusUnhealthy
 			res.Error = err.Error()
 			res.Duration = time.Since(start)
 			return res
 		}
 	}
-	for _, d := range n.Deps {
-		dr := Evaluate(ctx, d)
+
+	results := make([]Result, len(n.Deps))
+	var wg sync.WaitGroup
+	for i, d := range n.Deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.eval(ctx, d)
+		}()
+	}
+	wg.Wait()
+
+	degraded := false
+	for i, d := range n.Deps {
+		dr := results[i]
 		res.Deps[dr.Name] = dr
-		if !dr.Healthy {
+		switch {
+		case !dr.Healthy && d.Criticality == Soft:
+			degraded = true
+		case !dr.Healthy:
 			res.Healthy = false
+		case dr.Status == StatusDegraded:
+			degraded = true
 		}
 	}
-	// If we made it here and didn't fail checks, it's healthy.
-	if res.Error == "" && res.Healthy == false && len(res.Deps) == 0 {
-		res.Healthy = true
+	switch {
+	case !res.Healthy:
+		res.Status = StatusUnhealthy
+	case degraded:
+		res.Status = StatusDegraded
+	}
+	res.Duration = time.Since(start)
+	return res
+}
+
+// Validate reports structural problems in the graph under root: dependency cycles
+// (which Evaluate refuses to run) and sibling nodes sharing a name (only the last
+// one's result is visible in Result.Deps).
+func Validate(root *Node) error {
+	var errs []error
+	if err := findCycle(root); err != nil {
+		errs = append(errs, err)
 	}
-	if res.Error == "" && (len(res.Deps) > 0) {
-		// healthy if all deps healthy
-		all := true
-		for _, dr := range res.Deps {
-			if !dr.Healthy {
-				all = false
-				break
+	seen := map[*Node]bool{}
+	var walk func(path string, n *Node)
+	walk = func(path string, n *Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		names := map[string]bool{}
+		for _, d := range n.Deps {
+			if names[d.Name] {
+				errs = append(errs, fmt.Errorf("health: duplicate dependency %s/%s", path, d.Name))
 			}
+			names[d.Name] = true
+			walk(path+"/"+d.Name, d)
 		}
-		res.Healthy = all
 	}
-	res.Duration = time.Since(start)
-	return res
+	if errs == nil {
+		walk(root.Name, root)
+	}
+	return errors.Join(errs...)
+}
+
+// findCycle returns an error naming the first dependency cycle under root.
+func findCycle(root *Node) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*Node]int{}
+	var stack []string
+	var visit func(n *Node) error
+	visit = func(n *Node) error {
+		stack = append(stack, n.Name)
+		defer func() { stack = stack[:len(stack)-1] }()
+		switch state[n] {
+		case visiting:
+			return fmt.Errorf("health: dependency cycle %s", strings.Join(stack, " -> "))
+		case visited:
+			return nil
+		}
+		state[n] = visiting
+		for _, d := range n.Deps {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		state[n] = visited
+		return nil
+	}
+	return visit(root)
+}
+
+func runCheck(ctx context.Context, n *Node) error {
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+	return n.Check(ctx)
 }
 
 // Handler returns an http.Handler that evaluates the dependency graph.
@@ -83,17 +267,21 @@ func Handler(root *Node, serving func() bool) http.Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 		defer cancel()
 
-		out := Evaluate(ctx, root)
-		if !out.Healthy {
-			w.WriteHeader(http.StatusServiceUnavailable)
-		}
-		w.Header().Set("content-type", "application/json")
-		enc := json.NewEncoder(w)
-		enc.SetIndent("", "  ")
-		_ = enc.Encode(out)
+		writeResult(w, Evaluate(ctx, root))
 	})
 }
 
+// writeResult writes out as indented JSON, with 503 if it is unhealthy.
+func writeResult(w http.ResponseWriter, out Result) {
+	w.Header().Set("content-type", "application/json")
+	if !out.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
+
 // Livez is a simple liveness handler.
 func Livez() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
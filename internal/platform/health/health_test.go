@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvaluate_SoftDependencyDegrades(t *testing.T) {
+	root := NewReadyGraph()
+	root.Add("db", CheckAlwaysReady())
+	root.AddSoft("cache", func(context.Context) error { return errors.New("connection refused") })
+
+	res := Evaluate(context.Background(), root)
+	if !res.Healthy || res.Status != StatusDegraded {
+		t.Fatalf("root = healthy %v, status %q; want healthy, degraded", res.Healthy, res.Status)
+	}
+	if c := res.Deps["cache"]; c.Healthy || c.Status != StatusUnhealthy || !c.Soft {
+		t.Fatalf("cache = %+v", c)
+	}
+
+	root.Add("queue", func(context.Context) error { return errors.New("down") })
+	if res := Evaluate(context.Background(), root); res.Healthy || res.Status != StatusUnhealthy {
+		t.Fatalf("hard failure: healthy %v, status %q", res.Healthy, res.Status)
+	}
+}
+
+func TestEvaluate_NodeTimeout(t *testing.T) {
+	root := NewReadyGraph()
+	slow := root.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	slow.Timeout = 10 * time.Millisecond
+
+	start := time.Now()
+	res := Evaluate(context.Background(), root)
+	if res.Healthy {
+		t.Fatalf("expected timed-out check to fail")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("evaluation took %v; node timeout not applied", d)
+	}
+}
+
+func TestEvaluate_SiblingsRunConcurrently(t *testing.T) {
+	root := NewReadyGraph()
+	slow := func(context.Context) error { time.Sleep(100 * time.Millisecond); return nil }
+	for _, name := range []string{"a", "b", "c", "d"} {
+		root.Add(name, slow)
+	}
+
+	start := time.Now()
+	if res := Evaluate(context.Background(), root); !res.Healthy {
+		t.Fatalf("res = %+v", res)
+	}
+	if d := time.Since(start); d > 300*time.Millisecond {
+		t.Fatalf("took %v; siblings should run in parallel", d)
+	}
+
+	start = time.Now()
+	EvaluateWith(context.Background(), root, EvaluateOptions{Parallelism: 1})
+	if d := time.Since(start); d < 400*time.Millisecond {
+		t.Fatalf("took %v with Parallelism 1; want sequential", d)
+	}
+}
+
+func TestEvaluate_SharedNodeCheckedOnce(t *testing.T) {
+	var calls atomic.Int32
+	shared := &Node{Name: "db", Check: func(context.Context) error { calls.Add(1); return nil }}
+	root := NewReadyGraph()
+	root.Add("users", nil).Deps = []*Node{shared}
+	root.Add("orders", nil).Deps = []*Node{shared}
+
+	if res := Evaluate(context.Background(), root); !res.Healthy {
+		t.Fatalf("res = %+v", res)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("shared check ran %d times, want 1", n)
+	}
+	if err := Validate(root); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestEvaluate_Cycle(t *testing.T) {
+	root := NewReadyGraph()
+	a := root.Add("a", CheckAlwaysReady())
+	b := a.Add("b", CheckAlwaysReady())
+	b.Deps = append(b.Deps, a)
+
+	res := Evaluate(context.Background(), root)
+	if res.Healthy || !strings.Contains(res.Error, "ready -> a -> b -> a") {
+		t.Fatalf("res = %+v", res)
+	}
+	if err := Validate(root); err == nil {
+		t.Fatal("Validate: expected cycle error")
+	}
+}
+
+func TestValidate_DuplicateNames(t *testing.T) {
+	root := NewReadyGraph()
+	root.Add("db", CheckAlwaysReady())
+	root.Add("db", CheckAlwaysReady())
+	if err := Validate(root); err == nil || !strings.Contains(err.Error(), "ready/db") {
+		t.Fatalf("err = %v", err)
+	}
+}
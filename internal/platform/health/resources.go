@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+)
+
+// DiskSpace fails when the filesystem holding path has less than minFree bytes
+// available to unprivileged users.
+func DiskSpace(path string, minFree uint64) Check {
+	return func(context.Context) error {
+		free, total, err := diskUsage(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("disk %s: %d MiB free of %d MiB (want >= %d MiB)", path, free>>20, total>>20, minFree>>20)
+		}
+		return nil
+	}
+}
+
+// FileDescriptors fails when more than maxUsed (0-1, default 0.9) of the soft
+// RLIMIT_NOFILE is in use.
+func FileDescriptors(maxUsed float64) Check {
+	if maxUsed <= 0 {
+		maxUsed = 0.9
+	}
+	return func(context.Context) error {
+		open, limit, err := fdUsage()
+		if err != nil {
+			return err
+		}
+		if limit > 0 && float64(open) > maxUsed*float64(limit) {
+			return fmt.Errorf("file descriptors: %d of %d in use", open, limit)
+		}
+		return nil
+	}
+}
+
+// MemoryPressure fails when the memory the Go runtime has mapped (what GOMEMLIMIT
+// governs) exceeds maxUsed (0-1, default 0.9) of the limit. It always passes when
+// no limit is set.
+func MemoryPressure(maxUsed float64) Check {
+	if maxUsed <= 0 {
+		maxUsed = 0.9
+	}
+	return func(context.Context) error {
+		limit := debug.SetMemoryLimit(-1)
+		if limit <= 0 || limit == math.MaxInt64 {
+			return nil
+		}
+		used := runtimeMemory()
+		if float64(used) > maxUsed*float64(limit) {
+			return fmt.Errorf("memory: %d MiB of %d MiB limit in use", used>>20, limit>>20)
+		}
+		return nil
+	}
+}
+
+// runtimeMemory returns the bytes mapped by the runtime minus those released back
+// to the OS, the quantity the GC compares against the memory limit.
+func runtimeMemory() uint64 {
+	s := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(s)
+	return s[0].Value.Uint64() - s[1].Value.Uint64()
+}
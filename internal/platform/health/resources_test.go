@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"math"
+	"runtime/debug"
+	"testing"
+)
+
+func TestDiskSpace(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := DiskSpace(dir, 1)(ctx); err != nil {
+		t.Fatalf("1 byte free: %v", err)
+	}
+	if err := DiskSpace(dir, math.MaxUint64)(ctx); err == nil {
+		t.Fatal("expected error for impossible free space")
+	}
+}
+
+func TestFileDescriptors(t *testing.T) {
+	ctx := context.Background()
+	if err := FileDescriptors(1)(ctx); err != nil {
+		t.Fatalf("fds: %v", err)
+	}
+	if err := FileDescriptors(1e-9)(ctx); err == nil {
+		t.Skip("no fd accounting on this platform")
+	}
+}
+
+func TestMemoryPressure(t *testing.T) {
+	ctx := context.Background()
+	prev := debug.SetMemoryLimit(math.MaxInt64)
+	defer debug.SetMemoryLimit(prev)
+
+	if err := MemoryPressure(0.01)(ctx); err != nil {
+		t.Fatalf("no limit set: %v", err)
+	}
+	debug.SetMemoryLimit(1 << 20) // far below what any Go process maps
+	if err := MemoryPressure(0.9)(ctx); err == nil {
+		t.Fatal("expected error over limit")
+	}
+	debug.SetMemoryLimit(math.MaxInt64 - 1)
+	if err := MemoryPressure(0.9)(ctx); err != nil {
+		t.Fatalf("huge limit: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build linux || darwin
+
+package health
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func diskUsage(path string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	bsize := uint64(st.Bsize)
+	return uint64(st.Bavail) * bsize, uint64(st.Blocks) * bsize, nil
+}
+
+func fdUsage() (open, limit uint64, err error) {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, 0, fmt.Errorf("getrlimit: %w", err)
+	}
+	entries, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return 0, 0, fmt.Errorf("count fds: %w", err)
+	}
+	return uint64(len(entries)), uint64(rl.Cur), nil
+}
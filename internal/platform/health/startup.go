@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Startup tracks one-time initialization steps (migrations applied, caches warmed,
+// first successful DB ping) for a Kubernetes startupProbe. Unlike readiness it
+// latches: once every registered step is done, startup stays complete.
+type Startup struct {
+	mu    sync.Mutex
+	steps []*startupStep
+	since time.Time
+}
+
+type startupStep struct {
+	name string
+	done time.Time
+	err  string // last failure reported by WaitFor
+}
+
+// StartupStatus is the JSON served by Startup.Handler.
+type StartupStatus struct {
+	Started bool                `json:"started"`
+	Elapsed time.Duration       `json:"elapsed"`
+	Steps   []StartupStepStatus `json:"steps"`
+}
+
+type StartupStepStatus struct {
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewStartup returns a Startup with no steps (i.e. already started).
+func NewStartup() *Startup {
+	return &Startup{since: time.Now()}
+}
+
+// Register adds a required step and returns the function that marks it done.
+// Calling done more than once is harmless.
+func (s *Startup) Register(name string) (done func()) {
+	st := &startupStep{name: name}
+	s.mu.Lock()
+	s.steps = append(s.steps, st)
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if st.done.IsZero() {
+			st.done = time.Now()
+			st.err = ""
+		}
+	}
+}
+
+// WaitFor registers name and runs check every interval (default 1s) in the
+// background until it first succeeds or ctx ends, e.g. the first successful DB ping.
+func (s *Startup) WaitFor(ctx context.Context, name string, interval time.Duration, check Check) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	done := s.Register(name)
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			err := check(ctx)
+			if err == nil {
+				done()
+				return
+			}
+			s.setError(name, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+func (s *Startup) setError(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.steps {
+		if st.name == name && st.done.IsZero() {
+			st.err = err.Error()
+		}
+	}
+}
+
+// Started reports whether every registered step is done.
+func (s *Startup) Started() bool {
+	return s.Status().Started
+}
+
+// Status returns the state of every step.
+func (s *Startup) Status() StartupStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := StartupStatus{Started: true, Elapsed: time.Since(s.since), Steps: make([]StartupStepStatus, 0, len(s.steps))}
+	end := s.since
+	for _, st := range s.steps {
+		done := !st.done.IsZero()
+		if !done {
+			out.Started = false
+		} else if st.done.After(end) {
+			end = st.done
+		}
+		out.Steps = append(out.Steps, StartupStepStatus{Name: st.name, Done: done, Error: st.err})
+	}
+	if out.Started {
+		out.Elapsed = end.Sub(s.since)
+	}
+	return out
+}
+
+// Handler serves the startup status as JSON: 200 once started, else 503.
+func (s *Startup) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		st := s.Status()
+		w.Header().Set("content-type", "application/json")
+		if !st.Started {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(st)
+	})
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartup_LatchesOnceAllStepsDone(t *testing.T) {
+	s := NewStartup()
+	migrate := s.Register("migrations")
+
+	var up atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.WaitFor(ctx, "db", 5*time.Millisecond, func(context.Context) error {
+		if !up.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d before init, want 503", rr.Code)
+	}
+
+	migrate()
+	up.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for !s.Started() {
+		if time.Now().After(deadline) {
+			t.Fatalf("startup never completed: %+v", s.Status())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Later failures don't un-start the service; that is readiness' job.
+	up.Store(false)
+	rr = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d after init, want 200", rr.Code)
+	}
+}
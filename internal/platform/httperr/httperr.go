@@ -0,0 +1,38 @@
+// Package httperr writes transport-level HTTP errors (auth failures, overload,
+// bad admin input) in the same problem+json envelope as domain errors (see
+// errs.WriteProblem), so every error body a client sees has one shape.
+package httperr
+
+import (
+	"net/http"
+
+	"sdk-microservices/internal/platform/errs"
+)
+
+// WriteError writes a problem+json response with the given status. code is a
+// stable UPPER_SNAKE_CASE reason (may be empty), msg is safe to show to clients
+// and defaults to the status text.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, msg string, fields []errs.FieldViolation) {
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	errs.WriteProblemBody(w, r, errs.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: msg,
+		Reason: code,
+		Errors: fields,
+	})
+}
+
+// Unauthorized writes a 401 with reason code.
+func Unauthorized(w http.ResponseWriter, r *http.Request, code string) {
+	WriteError(w, r, http.StatusUnauthorized, code, "", nil)
+}
+
+// Unavailable writes a 503 asking the client to retry after a second.
+func Unavailable(w http.ResponseWriter, r *http.Request, code string) {
+	w.Header().Set("Retry-After", "1")
+	WriteError(w, r, http.StatusServiceUnavailable, code, "", nil)
+}
@@ -0,0 +1,35 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/platform/logging"
+)
+
+func TestWriteError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/x", nil)
+	req = req.WithContext(logging.WithRequestID(req.Context(), "rid-1"))
+	rec := httptest.NewRecorder()
+	Unavailable(rec, req, "OVERLOADED")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("content-type = %q", ct)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("missing Retry-After")
+	}
+	var p errs.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Reason != "OVERLOADED" || p.Detail != "Service Unavailable" || p.Instance != "/v1/x" || p.RequestID != "rid-1" {
+		t.Fatalf("problem = %+v", p)
+	}
+}
@@ -6,42 +6,94 @@ import (
 
 	"sdk-microservices/internal/platform/authctx"
 	"sdk-microservices/internal/platform/authjwt"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/platform/httperr"
 )
 
-// AuthBearer validates an Authorization: Bearer <token> header and stores user id in context.
+// AuthBearer validates an Authorization: Bearer <token> header and stores the user id
+// (authctx) and the verified claims (authjwt.ClaimsFrom) in context.
 // It does NOT enforce any specific audience; keep that in the JWT issuer/claims as needed.
 func AuthBearer(jwtSvc *authjwt.Service, next http.Handler) http.Handler {
 	if jwtSvc == nil {
 		// If misconfigured, fail closed.
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			httperr.Unavailable(w, r, "AUTH_UNAVAILABLE")
 		})
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		h := r.Header.Get("Authorization")
 		if h == "" {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			httperr.Unauthorized(w, r, "MISSING_CREDENTIALS")
 			return
 		}
 		const prefix = "Bearer "
 		if !strings.HasPrefix(h, prefix) {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			httperr.Unauthorized(w, r, "INVALID_TOKEN")
 			return
 		}
 		tok := strings.TrimSpace(strings.TrimPrefix(h, prefix))
 		if tok == "" {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			httperr.Unauthorized(w, r, "INVALID_TOKEN")
 			return
 		}
 
 		claims, err := jwtSvc.Parse(tok)
 		if err != nil {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			httperr.Unauthorized(w, r, "INVALID_TOKEN")
 			return
 		}
 
 		ctx := authctx.WithUserID(r.Context(), claims.Subject)
+		ctx = authjwt.WithClaims(ctx, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+var (
+	errNoClaims     = errs.Unauthenticated("UNAUTHENTICATED", "authentication required")
+	errMissingScope = errs.PermissionDenied("INSUFFICIENT_SCOPE", "missing required scope")
+	errMissingRole  = errs.PermissionDenied("INSUFFICIENT_ROLE", "missing required role")
+)
+
+// RequireScopes rejects requests whose claims (set by AuthBearer) lack any of scopes:
+// 401 without claims, 403 on a missing scope. Use it in route-scoped chains after AuthBearer.
+func RequireScopes(scopes ...string) Middleware {
+	return requireClaims(errMissingScope, func(c *authjwt.Claims) bool {
+		for _, s := range scopes {
+			if !c.HasScope(s) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// RequireRole rejects requests whose claims carry none of roles (401 without claims, 403 otherwise).
+func RequireRole(roles ...string) Middleware {
+	return requireClaims(errMissingRole, func(c *authjwt.Claims) bool {
+		for _, r := range roles {
+			if c.HasRole(r) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func requireClaims(denied error, ok func(*authjwt.Claims) bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, found := authjwt.ClaimsFrom(r.Context())
+			if !found {
+				errs.WriteProblem(w, r, errNoClaims)
+				return
+			}
+			if !ok(claims) {
+				errs.WriteProblem(w, r, denied)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
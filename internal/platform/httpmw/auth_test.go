@@ -0,0 +1,57 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authjwt"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+func TestRequireScopesAndRole(t *testing.T) {
+	svc := authjwt.New([]byte("secret"), "test", 0)
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &authjwt.Claims{
+		Scope: "hello:read orders:write",
+		Roles: []string{"support"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "test",
+			Subject:   "u1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	cases := []struct {
+		name string
+		mw   Middleware
+		auth bool
+		want int
+	}{
+		{"scopes granted", RequireScopes("hello:read", "orders:write"), true, http.StatusOK},
+		{"scope missing", RequireScopes("admin"), true, http.StatusForbidden},
+		{"role granted", RequireRole("admin", "support"), true, http.StatusOK},
+		{"role missing", RequireRole("admin"), true, http.StatusForbidden},
+		{"no claims", RequireScopes("hello:read"), false, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := tc.mw(ok)
+			if tc.auth {
+				h = AuthBearer(svc, h)
+			}
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/v1/x", nil)
+			req.Header.Set("Authorization", "Bearer "+tok)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rr.Code)
+			}
+		})
+	}
+}
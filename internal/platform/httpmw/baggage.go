@@ -0,0 +1,17 @@
+package httpmw
+
+import (
+	"net/http"
+
+	"sdk-microservices/internal/platform/otel"
+)
+
+// Baggage puts the caller's user id, tenant (authctx) and X-Request-Priority into
+// OpenTelemetry baggage (see otel.WithCallerBaggage). Place it after authentication
+// so the identity is known.
+func Baggage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.WithCallerBaggage(r.Context(), r.Header.Get(otel.PriorityHeader))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
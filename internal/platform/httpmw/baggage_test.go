@@ -0,0 +1,50 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/otel"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestBaggage(t *testing.T) {
+	var got baggage.Baggage
+	h := Baggage(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = baggage.FromContext(r.Context())
+	}))
+
+	// A client-supplied user_id must be replaced by the authenticated one.
+	spoofed, _ := baggage.Parse("user_id=admin,other=kept")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(otel.PriorityHeader, "high")
+	ctx := authctx.WithTenantID(authctx.WithUserID(req.Context(), "u1"), "t1")
+	req = req.WithContext(baggage.ContextWithBaggage(ctx, spoofed))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	for key, want := range map[string]string{
+		otel.BaggageUserID:   "u1",
+		otel.BaggageTenantID: "t1",
+		otel.BaggagePriority: "high",
+		"other":              "kept",
+	} {
+		if v := got.Member(key).Value(); v != want {
+			t.Errorf("%s = %q, want %q", key, v, want)
+		}
+	}
+
+	// Anonymous callers carry no identity members, even if they send some.
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(otel.PriorityHeader, "not a priority!")
+	req = req.WithContext(baggage.ContextWithBaggage(req.Context(), spoofed))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if v := got.Member(otel.BaggageUserID).Value(); v != "" {
+		t.Errorf("user_id = %q, want dropped", v)
+	}
+	if v := got.Member(otel.BaggagePriority).Value(); v != "" {
+		t.Errorf("priority = %q, want rejected", v)
+	}
+}
@@ -0,0 +1,70 @@
+package httpmw
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheNoStore forbids any cache (browser or intermediary) from storing the response.
+// Use it for anything carrying credentials or tokens.
+const CacheNoStore = "no-store"
+
+// CacheMaxAge returns a Cache-Control value allowing caching for d.
+// Private responses may only be cached by the client, never by shared caches.
+func CacheMaxAge(d time.Duration, private bool) string {
+	scope := "public"
+	if private {
+		scope = "private"
+	}
+	return scope + ", max-age=" + strconv.Itoa(int(d.Seconds()))
+}
+
+// CacheRule applies Value to requests whose path starts with Prefix.
+type CacheRule struct {
+	Prefix string
+	Value  string
+}
+
+// CachePolicy configures CacheControl.
+type CachePolicy struct {
+	// Rules are matched by longest Prefix.
+	Rules []CacheRule
+	// Default applies when no rule matches (default CacheNoStore).
+	Default string
+}
+
+// CacheControl sets a Cache-Control header per policy before calling next, so
+// handlers can still override it. Only GET/HEAD responses are ever marked
+// cacheable; every other method gets no-store.
+func CacheControl(p CachePolicy, next http.Handler) http.Handler {
+	if p.Default == "" {
+		p.Default = CacheNoStore
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := CacheNoStore
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			v = p.match(r.URL.Path)
+		}
+		w.Header().Set("Cache-Control", v)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p CachePolicy) match(path string) string {
+	best, bestLen := p.Default, -1
+	for _, rule := range p.Rules {
+		if strings.HasPrefix(path, rule.Prefix) && len(rule.Prefix) > bestLen {
+			best, bestLen = rule.Value, len(rule.Prefix)
+		}
+	}
+	return best
+}
+
+// WithCacheControl adapts CacheControl(p, next) into a Middleware.
+func WithCacheControl(p CachePolicy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return CacheControl(p, next)
+	}
+}
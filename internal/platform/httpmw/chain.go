@@ -40,6 +40,13 @@ func WithWrap(service string, log *zap.Logger) Middleware {
 	}
 }
 
+// WithWrapSampled adapts WrapSampled(service, log, sampling, next) into a Middleware.
+func WithWrapSampled(service string, log *zap.Logger, sampling LogSampling) Middleware {
+	return func(next http.Handler) http.Handler {
+		return WrapSampled(service, log, sampling, next)
+	}
+}
+
 // WithRecover adapts Recover(log, next) into a Middleware.
 func WithRecover(log *zap.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
@@ -60,3 +67,10 @@ func WithInFlightLimit(max int) Middleware {
 		return InFlightLimit(max, next)
 	}
 }
+
+// WithInFlightLimitQueued adapts InFlightLimitQueued(service, max, q, next) into a Middleware.
+func WithInFlightLimitQueued(service string, max int, q QueueOptions) Middleware {
+	return func(next http.Handler) http.Handler {
+		return InFlightLimitQueued(service, max, q, next)
+	}
+}
@@ -0,0 +1,134 @@
+package httpmw
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CoalesceOptions configures Coalesce.
+type CoalesceOptions struct {
+	// Key identifies identical requests; "" opts a request out. The default keys on
+	// path, query, Accept and caller (authctx user, else a hash of Authorization).
+	Key func(r *http.Request) string
+}
+
+var (
+	// errCoalesceAbandoned means the leading request's client went away, so its
+	// (likely partial) response must not be fanned out.
+	errCoalesceAbandoned = errors.New("coalesce: leader canceled")
+	errCoalescePanic     = errors.New("coalesce: leader panicked")
+)
+
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Coalesce merges identical concurrent GET requests into a single call to next and
+// fans the response out to every waiter, protecting downstreams from stampedes on
+// hot keys (e.g. right after a cache expiry).
+//
+// Responses are buffered in memory, so only use it in front of handlers with
+// modest, non-streaming GET responses. Waiters whose context ends stop waiting; if
+// the leading request is canceled, waiters call next themselves.
+func Coalesce(opts CoalesceOptions) Middleware {
+	if opts.Key == nil {
+		opts.Key = coalesceKey
+	}
+	var group singleflight.Group
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := opts.Key(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// fn only runs for the leading request (on its own goroutine), so only the
+			// leader ever sees panicked set.
+			var panicked any
+			ch := group.DoChan(key, func() (_ any, err error) {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked, err = p, errCoalescePanic
+					}
+				}()
+				rec := &coalesceRecorder{header: make(http.Header), status: http.StatusOK}
+				next.ServeHTTP(rec, r)
+				if r.Context().Err() != nil {
+					return nil, errCoalesceAbandoned
+				}
+				return &coalescedResponse{status: rec.status, header: rec.header, body: rec.body.Bytes()}, nil
+			})
+
+			var res singleflight.Result
+			select {
+			case res = <-ch:
+			case <-r.Context().Done():
+				return
+			}
+			if panicked != nil {
+				// Re-panic on the request goroutine so Recover sees it.
+				panic(panicked)
+			}
+			if r.Context().Err() != nil {
+				return
+			}
+			resp, _ := res.Val.(*coalescedResponse)
+			if res.Err != nil || resp == nil {
+				// The leader failed; serve this waiter on its own.
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			for k, vs := range resp.header {
+				h[k] = append([]string(nil), vs...)
+			}
+			w.WriteHeader(resp.status)
+			_, _ = w.Write(resp.body)
+		})
+	}
+}
+
+// coalesceKey keys on everything that may change a GET response for the caller.
+// The Authorization hash keeps callers apart even before authctx is populated.
+func coalesceKey(r *http.Request) string {
+	uid, _ := authctx.UserID(r.Context())
+	sum := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+	return r.URL.RequestURI() + "|" + r.Header.Get("Accept") + "|" + uid + "|" + hex.EncodeToString(sum[:])
+}
+
+// coalesceRecorder buffers the leader's response for fan-out.
+type coalesceRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *coalesceRecorder) Header() http.Header { return w.header }
+
+func (w *coalesceRecorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+	}
+}
+
+func (w *coalesceRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
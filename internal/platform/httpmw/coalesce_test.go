@@ -0,0 +1,63 @@
+package httpmw
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce_SharesConcurrentGETs(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := Coalesce(CoalesceOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("X-Test", "1")
+		_, _ = io.WriteString(w, "hello")
+	}))
+	const n = 5
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, n)
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/hello/x?a=1", nil)
+		req.Header.Set("Authorization", "Bearer same")
+		wg.Add(1)
+		go func(rec *httptest.ResponseRecorder) {
+			defer wg.Done()
+			h.ServeHTTP(rec, req)
+		}(recs[i])
+	}
+	// Give every request time to join the in-flight call.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("downstream calls = %d, want 1", got)
+	}
+	for i, rec := range recs {
+		if rec.Body.String() != "hello" || rec.Header().Get("X-Test") != "1" {
+			t.Fatalf("response[%d] = %q %v", i, rec.Body.String(), rec.Header())
+		}
+	}
+}
+
+func TestCoalesce_KeysByCaller(t *testing.T) {
+	r1 := httptest.NewRequest(http.MethodGet, "/v1/x", nil)
+	r1.Header.Set("Authorization", "Bearer a")
+	r2 := httptest.NewRequest(http.MethodGet, "/v1/x", nil)
+	r2.Header.Set("Authorization", "Bearer b")
+	if coalesceKey(r1) == coalesceKey(r2) {
+		t.Fatal("different callers must not share a response")
+	}
+	r3 := httptest.NewRequest(http.MethodGet, "/v1/x?page=2", nil)
+	r3.Header.Set("Authorization", "Bearer a")
+	if coalesceKey(r1) == coalesceKey(r3) {
+		t.Fatal("different queries must not share a response")
+	}
+}
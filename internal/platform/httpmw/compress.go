@@ -0,0 +1,195 @@
+package httpmw
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressOptions configures Compress.
+type CompressOptions struct {
+	// Level is the gzip level (default gzip.DefaultCompression).
+	Level int
+	// MinSize skips compression when the response declares a smaller Content-Length
+	// (default 1024). Responses without Content-Length are always compressed.
+	MinSize int
+	// ContentTypes is the allowlist of media types to compress
+	// (default JSON, problem+json, text/*, JavaScript).
+	ContentTypes []string
+}
+
+var defaultCompressTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"text/*",
+}
+
+// Compress gzips responses for clients that send Accept-Encoding: gzip.
+//
+// gzip writers are pooled, only allowlisted content types are compressed, and
+// Vary: Accept-Encoding is always set so caches keep encodings apart. Responses
+// that already carry a Content-Encoding are left alone.
+func Compress(opts CompressOptions, next http.Handler) http.Handler {
+	if opts.Level == 0 {
+		opts.Level = gzip.DefaultCompression
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1024
+	}
+	if len(opts.ContentTypes) == 0 {
+		opts.ContentTypes = defaultCompressTypes
+	}
+
+	level := opts.Level
+	pool := &sync.Pool{New: func() any {
+		zw, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			zw = gzip.NewWriter(io.Discard)
+		}
+		return zw
+	}}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, opts: &opts, pool: pool}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// WithCompress adapts Compress(opts, next) into a Middleware.
+func WithCompress(opts CompressOptions) Middleware {
+	return func(next http.Handler) http.Handler {
+		return Compress(opts, next)
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding value allows gzip. An explicit
+// gzip entry wins over "*", whatever their order.
+func acceptsGzip(ae string) bool {
+	wildcard := false
+	for _, part := range strings.Split(ae, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		switch {
+		case strings.EqualFold(coding, "gzip"):
+			return acceptableQ(params)
+		case coding == "*":
+			wildcard = acceptableQ(params)
+		}
+	}
+	return wildcard
+}
+
+// acceptableQ reports whether a coding's parameters leave it acceptable (q > 0).
+func acceptableQ(params string) bool {
+	if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+		if f, err := strconv.ParseFloat(q, 64); err == nil && f == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	opts *CompressOptions
+	pool *sync.Pool
+
+	decided bool
+	zw      *gzip.Writer
+}
+
+// decide picks gzip vs passthrough once, right before headers are sent.
+func (w *gzipResponseWriter) decide(status int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	h := w.Header()
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified {
+		return
+	}
+	if h.Get("Content-Encoding") != "" || !w.allowedType(h.Get("Content-Type")) {
+		return
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.Atoi(cl); err == nil && n < w.opts.MinSize {
+			return
+		}
+	}
+
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", "gzip")
+	w.zw = w.pool.Get().(*gzip.Writer)
+	w.zw.Reset(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) allowedType(ct string) bool {
+	if ct == "" {
+		// Unknown until sniffed by net/http; don't guess.
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range w.opts.ContentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mt, prefix+"/") {
+				return true
+			}
+		} else if mt == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	w.decide(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes buffered compressed data before flushing the underlying writer,
+// so streaming responses keep working through the gzip layer.
+func (w *gzipResponseWriter) Flush() {
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *gzipResponseWriter) close() {
+	if w.zw == nil {
+		return
+	}
+	_ = w.zw.Close()
+	w.zw.Reset(io.Discard)
+	w.pool.Put(w.zw)
+	w.zw = nil
+}
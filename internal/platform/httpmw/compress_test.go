@@ -0,0 +1,90 @@
+package httpmw
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCompress_GzipsJSONThroughWrap(t *testing.T) {
+	body := strings.Repeat(`{"message":"hello"}`, 100)
+	h := Chain{WithCompress(CompressOptions{}), WithWrap("testsvc", zap.NewNop())}.Then(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, body)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/v1/hello", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, rr.Code)
+	}
+	if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Fatalf("expected Vary: Accept-Encoding, got %q", got)
+	}
+	zr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Fatalf("decompressed body mismatch")
+	}
+}
+
+func TestCompress_SkipsDisallowedContentType(t *testing.T) {
+	h := Compress(CompressOptions{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(make([]byte, 4096))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/logo.png", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected no encoding, got %q", got)
+	}
+	if rr.Body.Len() != 4096 {
+		t.Fatalf("expected raw body, got %d bytes", rr.Body.Len())
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for _, tc := range []struct {
+		ae   string
+		want bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP;q=0.5", true},
+		{"deflate, br", false},
+		{"gzip;q=0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"*;q=0, gzip", true},
+		{"gzip;q=0, *", false},
+		{"br, *;q=0.1", true},
+	} {
+		if got := acceptsGzip(tc.ae); got != tc.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tc.ae, got, tc.want)
+		}
+	}
+}
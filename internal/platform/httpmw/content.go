@@ -0,0 +1,99 @@
+package httpmw
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sdk-microservices/internal/platform/httperr"
+)
+
+// ContentPolicy configures ContentNegotiation.
+type ContentPolicy struct {
+	// ContentTypes are the media types accepted for POST/PUT/PATCH bodies
+	// (default application/json).
+	ContentTypes []string
+	// Produces are the media types the handler can respond with, checked against
+	// Accept (default application/json).
+	Produces []string
+}
+
+// ContentNegotiation rejects request bodies whose Content-Type is not in
+// p.ContentTypes with 415, and requests whose Accept header admits none of
+// p.Produces with 406, so malformed clients fail clearly at the edge instead of
+// with confusing unmarshal errors downstream.
+//
+// Bodiless requests and requests without an Accept header pass. Use Routes to
+// apply different policies per route.
+func ContentNegotiation(p ContentPolicy, next http.Handler) http.Handler {
+	if len(p.ContentTypes) == 0 {
+		p.ContentTypes = []string{"application/json"}
+	}
+	if len(p.Produces) == 0 {
+		p.Produces = []string{"application/json"}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hasBody(r) {
+			mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || !slices.Contains(p.ContentTypes, mt) {
+				httperr.WriteError(w, r, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+					"Content-Type must be one of: "+strings.Join(p.ContentTypes, ", "), nil)
+				return
+			}
+		}
+		if accept := r.Header.Values("Accept"); len(accept) > 0 && !acceptsAny(accept, p.Produces) {
+			httperr.WriteError(w, r, http.StatusNotAcceptable, "NOT_ACCEPTABLE",
+				"Accept must allow one of: "+strings.Join(p.Produces, ", "), nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithContentNegotiation adapts ContentNegotiation(p, next) into a Middleware.
+func WithContentNegotiation(p ContentPolicy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return ContentNegotiation(p, next)
+	}
+}
+
+func hasBody(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return r.ContentLength > 0 || (r.ContentLength < 0 && r.Body != nil && r.Body != http.NoBody)
+	}
+	return false
+}
+
+// acceptsAny reports whether the Accept header values admit any of produces.
+// Media ranges with q=0 are explicit refusals.
+func acceptsAny(accept, produces []string) bool {
+	for _, v := range accept {
+		for _, rng := range strings.Split(v, ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(rng))
+			if err != nil {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			for _, p := range produces {
+				if mediaMatch(mt, p) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func mediaMatch(rng, mt string) bool {
+	if rng == "*/*" || rng == mt {
+		return true
+	}
+	typ, ok := strings.CutSuffix(rng, "/*")
+	return ok && strings.HasPrefix(mt, typ+"/")
+}
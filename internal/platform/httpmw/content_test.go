@@ -0,0 +1,47 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	h := ContentNegotiation(ContentPolicy{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		name        string
+		method      string
+		body        string
+		contentType string
+		accept      string
+		want        int
+	}{
+		{"json body", http.MethodPost, "{}", "application/json; charset=utf-8", "", http.StatusOK},
+		{"form body", http.MethodPost, "a=1", "application/x-www-form-urlencoded", "", http.StatusUnsupportedMediaType},
+		{"missing content type", http.MethodPut, "{}", "", "", http.StatusUnsupportedMediaType},
+		{"empty post", http.MethodPost, "", "", "", http.StatusOK},
+		{"get ignores content type", http.MethodGet, "", "text/plain", "", http.StatusOK},
+		{"accept wildcard", http.MethodGet, "", "", "text/html, */*;q=0.8", http.StatusOK},
+		{"accept type range", http.MethodGet, "", "", "application/*", http.StatusOK},
+		{"accept html only", http.MethodGet, "", "", "text/html", http.StatusNotAcceptable},
+		{"accept json refused", http.MethodGet, "", "", "application/json;q=0", http.StatusNotAcceptable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/v1/x", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package httpmw
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures CORS.
+type CORSOptions struct {
+	// AllowOrigin decides whether a request Origin is allowed. Use MatchOrigins for
+	// the common exact / wildcard-subdomain cases. Nil rejects every origin.
+	AllowOrigin func(origin string) bool
+
+	// AllowedMethods defaults to GET, POST, PUT, PATCH, DELETE.
+	AllowedMethods []string
+	// AllowedHeaders defaults to Authorization, Content-Type, X-Request-Id, Idempotency-Key.
+	AllowedHeaders []string
+	// ExposedHeaders defaults to X-Request-Id and X-Trace-Id.
+	ExposedHeaders []string
+
+	// AllowCredentials sets Access-Control-Allow-Credentials (cookies / auth headers).
+	// Never combine it with an AllowOrigin that admits any origin: every site
+	// could then make credentialed requests (see CheckCORSOrigins).
+	AllowCredentials bool
+
+	// MaxAge controls how long browsers may cache preflight results (default 10m).
+	MaxAge time.Duration
+}
+
+// MatchOrigins returns an origin matcher for the given patterns:
+//
+//   - "*" matches any origin
+//   - "https://*.example.com" matches any subdomain of example.com over https
+//   - anything else must match exactly (case-insensitive)
+func MatchOrigins(patterns ...string) func(origin string) bool {
+	var exact []string
+	var suffixes [][2]string // {scheme prefix, host suffix}
+	anyOrigin := false
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+		case p == "*":
+			anyOrigin = true
+		case strings.Contains(p, "://*."):
+			i := strings.Index(p, "*.")
+			suffixes = append(suffixes, [2]string{p[:i], p[i+1:]})
+		default:
+			exact = append(exact, p)
+		}
+	}
+
+	return func(origin string) bool {
+		if anyOrigin {
+			return true
+		}
+		o := strings.ToLower(origin)
+		for _, e := range exact {
+			if o == e {
+				return true
+			}
+		}
+		for _, s := range suffixes {
+			if strings.HasPrefix(o, s[0]) && strings.HasSuffix(o, s[1]) && len(o) > len(s[0])+len(s[1]) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// CheckCORSOrigins rejects MatchOrigins patterns that cannot be used with
+// allowCredentials: "*" would reflect every origin with credentials allowed.
+// Call it when loading config.
+func CheckCORSOrigins(patterns []string, allowCredentials bool) error {
+	if !allowCredentials {
+		return nil
+	}
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "*" {
+			return errors.New(`CORS origin "*" cannot be combined with credentials`)
+		}
+	}
+	return nil
+}
+
+// CORS returns a Middleware that applies CORS headers and answers preflight requests.
+// Requests without an Origin header (server-to-server, curl) pass through untouched.
+func CORS(opts CORSOptions) Middleware {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = []string{"Authorization", "Content-Type", "X-Request-Id", "Idempotency-Key"}
+	}
+	if len(opts.ExposedHeaders) == 0 {
+		opts.ExposedHeaders = []string{"X-Request-Id", TraceIDHeader}
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 10 * time.Minute
+	}
+
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if opts.AllowOrigin == nil || !opts.AllowOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				// Let the request through without CORS headers; the browser blocks the response.
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Set("Access-Control-Allow-Origin", origin)
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if preflight {
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				h.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			h.Set("Access-Control-Expose-Headers", exposed)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
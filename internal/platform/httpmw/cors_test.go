@@ -0,0 +1,61 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS_PreflightAllowedOrigin(t *testing.T) {
+	h := CORS(CORSOptions{AllowOrigin: MatchOrigins("https://*.example.com")})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("preflight should not reach the handler")
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "http://api.example.com/v1/hello", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got %d", http.StatusNoContent, rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Fatalf("unexpected Access-Control-Allow-Origin %q", got)
+	}
+}
+
+func TestCORS_DisallowedOriginGetsNoHeaders(t *testing.T) {
+	h := CORS(CORSOptions{AllowOrigin: MatchOrigins("https://app.example.com")})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/v1/hello", nil)
+	req.Header.Set("Origin", "https://evil.example.org")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no Access-Control-Allow-Origin, got %q", got)
+	}
+	if MatchOrigins("https://*.example.com")("https://.example.com") {
+		t.Fatalf("empty subdomain should not match")
+	}
+}
+
+func TestCheckCORSOrigins(t *testing.T) {
+	for _, tc := range []struct {
+		origins     []string
+		credentials bool
+		wantErr     bool
+	}{
+		{[]string{"*"}, false, false},
+		{[]string{"https://app.example.com", " * "}, true, true},
+		{[]string{"https://*.example.com"}, true, false},
+		{nil, true, false},
+	} {
+		if err := CheckCORSOrigins(tc.origins, tc.credentials); (err != nil) != tc.wantErr {
+			t.Errorf("CheckCORSOrigins(%q, %v) = %v, want error %v", tc.origins, tc.credentials, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+func TestDenyList(t *testing.T) {
+	d := NewDenyList(nil)
+	h := d.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "u-bad"}).SignedString([]byte("k"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	put := httptest.NewRequest(http.MethodPut, "http://admin/denylist", strings.NewReader(
+		`{"ips":["203.0.113.0/24"],"subjects":["u-bad"],"headers":[{"name":"User-Agent","value":"evilbot"}]}`))
+	rr := httptest.NewRecorder()
+	d.Handler().ServeHTTP(rr, put)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 from admin PUT, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	cases := []struct {
+		name string
+		mod  func(*http.Request)
+		want int
+	}{
+		{"allowed", func(r *http.Request) {}, http.StatusOK},
+		{"ip", func(r *http.Request) { r.RemoteAddr = "203.0.113.7:1" }, http.StatusForbidden},
+		{"subject", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+tok) }, http.StatusForbidden},
+		{"header", func(r *http.Request) { r.Header.Set("User-Agent", "evilbot") }, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/v1/hello/x", nil)
+			req.RemoteAddr = "198.51.100.1:1"
+			tc.mod(req)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rr.Code)
+			}
+		})
+	}
+
+	rr = httptest.NewRecorder()
+	d.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "http://admin/denylist", strings.NewReader(`{"ips":["nope"]}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid rules to be rejected, got %d", rr.Code)
+	}
+	if got := d.Rules().Subjects; len(got) != 1 {
+		t.Fatalf("expected previous rules to be kept, got %v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package httpmw
 
 import (
+	"math/rand/v2"
 	"net/http"
 	"time"
 
@@ -11,10 +12,48 @@ import (
 )
 
 // Wrap adds OpenTelemetry spans + structured access logging.
+// Sensitive fields are redacted with logging.DefaultRedactor unless log already redacts.
 func Wrap(service string, log *zap.Logger, next http.Handler) http.Handler {
-	if log == nil {
-		log = zap.NewNop()
+	return WrapSampled(service, log, LogSampling{}, next)
+}
+
+// SampleRule sets the fraction of successful requests whose access log line is kept
+// for matching requests. Method/Pattern match like Route.
+type SampleRule struct {
+	Method  string
+	Pattern string
+	// Rate is in [0, 1]: 0.01 keeps 1% of lines, 0 drops all of them.
+	Rate float64
+}
+
+// LogSampling configures access log sampling per route.
+// Responses with status >= 400 are always logged; unmatched routes are always logged.
+type LogSampling struct {
+	Rules []SampleRule
+}
+
+// rate returns the sampling rate for r (1 when no rule matches).
+func (s LogSampling) rate(r *http.Request) float64 {
+	rate, best := 1.0, -1
+	for _, rule := range s.Rules {
+		if score := (Route{Method: rule.Method, Pattern: rule.Pattern}).score(r); score > best {
+			rate, best = rule.Rate, score
+		}
 	}
+	return rate
+}
+
+func (s LogSampling) keep(r *http.Request, status int) bool {
+	if status >= 400 || len(s.Rules) == 0 {
+		return true
+	}
+	rate := s.rate(r)
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// WrapSampled is Wrap with per-route access log sampling (spans are unaffected).
+func WrapSampled(service string, log *zap.Logger, sampling LogSampling, next http.Handler) http.Handler {
+	log = logging.DefaultRedactor.Logger(log)
 
 	accessLog := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -22,6 +61,10 @@ func Wrap(service string, log *zap.Logger, next http.Handler) http.Handler {
 		sw := &respWriter{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(sw, r)
 
+		if !sampling.keep(r, sw.status) {
+			return
+		}
+
 		lg := logging.WithTrace(r.Context(), log).With(
 			zap.String("http.method", r.Method),
 			zap.String("http.path", r.URL.Path),
@@ -35,8 +78,8 @@ func Wrap(service string, log *zap.Logger, next http.Handler) http.Handler {
 		if ua := r.Header.Get("user-agent"); ua != "" {
 			lg = lg.With(zap.String("user_agent", ua))
 		}
-		if r.RemoteAddr != "" {
-			lg = lg.With(zap.String("client.addr", r.RemoteAddr))
+		if ip := clientIP(r); ip != "" {
+			lg = lg.With(zap.String("client.addr", ip))
 		}
 
 		lg.Info("http")
@@ -55,3 +98,6 @@ func (w *respWriter) WriteHeader(code int) {
 	w.status = code
 	w.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController (Flush, deadlines).
+func (w *respWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
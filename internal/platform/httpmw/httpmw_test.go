@@ -0,0 +1,40 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWrapSampled_DropsSuccessesKeepsErrors(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	status := http.StatusOK
+	h := WrapSampled("testsvc", zap.New(core), LogSampling{
+		Rules: []SampleRule{{Pattern: "/healthz", Rate: 0}},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	serve := func(path string) {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+	}
+
+	serve("/healthz")
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("expected sampled-out success to be dropped, got %d lines", n)
+	}
+
+	serve("/v1/hello")
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("expected unmatched route to be logged, got %d lines", n)
+	}
+
+	status = http.StatusServiceUnavailable
+	serve("/healthz")
+	if n := logs.Len(); n != 2 {
+		t.Fatalf("expected error to be logged, got %d lines", n)
+	}
+}
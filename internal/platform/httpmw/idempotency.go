@@ -0,0 +1,266 @@
+package httpmw
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/platform/idempotency"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a write retry-safe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentResponse is a stored response for a completed idempotent request.
+type IdempotentResponse struct {
+	// RequestHash guards against reusing a key for a different request.
+	RequestHash string      `json:"request_hash"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// IdempotencyOptions configures Idempotency.
+type IdempotencyOptions struct {
+	// TTL is how long completed responses are replayed (default 24h).
+	TTL time.Duration
+	// LockTTL bounds how long an in-progress reservation blocks duplicates if the
+	// instance holding it dies (default 1m).
+	LockTTL time.Duration
+	// Wait lets a concurrent duplicate wait up to this long for the first request to
+	// finish and then replay its response. Zero rejects it immediately with 409.
+	Wait time.Duration
+	// MaxBodyBytes bounds recorded response bodies; larger responses are not stored
+	// (default 1MB).
+	MaxBodyBytes int
+}
+
+var (
+	errIdempotencyInUse = errs.Conflict("IDEMPOTENCY_KEY_IN_USE", "a request with this idempotency key is still in progress")
+	errIdempotencyReuse = errs.Validation(errs.FieldViolation{
+		Field:       IdempotencyKeyHeader,
+		Description: "idempotency key reused with a different request",
+	})
+)
+
+// Idempotency dedupes unsafe requests (POST, PUT, PATCH, DELETE) carrying an
+// Idempotency-Key header: the first successful response is stored and replayed
+// (with Idempotent-Replayed: true) for retries with the same key, so retried
+// writes don't repeat their side effects.
+//
+// Keys are scoped by method, path and authenticated user (authctx), so mount it
+// after authentication: requests without a user are served without
+// deduplication, since any caller could otherwise replay another's response by
+// guessing its key. Reusing a key with a different body is a 400. A duplicate that arrives while the first request
+// is still running waits up to opts.Wait for it, else gets 409. Error responses
+// (>= 400) are not stored, so failed calls can be retried.
+//
+// This is the HTTP counterpart of grpcutil.UnaryIdempotency.
+func Idempotency(store idempotency.Store, opts IdempotencyOptions) Middleware {
+	if opts.TTL <= 0 {
+		opts.TTL = 24 * time.Hour
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = time.Minute
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 1 << 20
+	}
+
+	return func(next http.Handler) http.Handler {
+		if store == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idemKey := r.Header.Get(IdempotencyKeyHeader)
+			uid, _ := authctx.UserID(r.Context())
+			if idemKey == "" || uid == "" || !unsafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				// Typically MaxBody tripping; let the handler see the same error.
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := r.Method + "|" + r.URL.Path + "|" + uid + "|" + idemKey
+			sum := sha256.Sum256(body)
+			hash := hex.EncodeToString(sum[:])
+
+			ctx := r.Context()
+			prev, reserved, err := reserveIdempotent(ctx, store, key, opts.LockTTL)
+			if err != nil {
+				// Store down: serve without deduplication rather than failing writes.
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !reserved && prev == nil && opts.Wait > 0 {
+				prev, reserved, err = waitIdempotent(ctx, store, key, opts)
+				if err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			switch {
+			case prev != nil && prev.RequestHash != hash:
+				errs.WriteProblem(w, r, errIdempotencyReuse)
+				return
+			case prev != nil:
+				replayIdempotent(w, prev)
+				return
+			case !reserved:
+				errs.WriteProblem(w, r, errIdempotencyInUse)
+				return
+			}
+
+			rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK, max: opts.MaxBodyBytes}
+			completed := false
+			defer func() {
+				if !completed {
+					// Handler failed or panicked: let a retry run again.
+					_ = store.Release(context.WithoutCancel(ctx), key)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			if rec.status < 400 && !rec.overflow {
+				b, err := json.Marshal(&IdempotentResponse{
+					RequestHash: hash,
+					Status:      rec.status,
+					Header:      rec.header,
+					Body:        rec.body.Bytes(),
+				})
+				if err == nil {
+					err = store.Complete(context.WithoutCancel(ctx), key, b, opts.TTL)
+				}
+				// Best effort: a failed Complete only means a retry re-executes.
+				completed = err == nil
+			}
+		})
+	}
+}
+
+func unsafeMethod(m string) bool {
+	switch m {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// reserveIdempotent is store.Reserve with the stored response decoded.
+func reserveIdempotent(ctx context.Context, store idempotency.Store, key string, lockTTL time.Duration) (*IdempotentResponse, bool, error) {
+	b, reserved, err := store.Reserve(ctx, key, lockTTL)
+	if err != nil || b == nil {
+		return nil, reserved, err
+	}
+	var resp IdempotentResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, false, nil
+}
+
+// waitIdempotent polls until the in-progress request completes (or is released, in
+// which case this request takes over the key), the wait elapses, or ctx ends.
+func waitIdempotent(ctx context.Context, store idempotency.Store, key string, opts IdempotencyOptions) (*IdempotentResponse, bool, error) {
+	deadline := time.NewTimer(opts.Wait)
+	defer deadline.Stop()
+	tick := time.NewTicker(25 * time.Millisecond)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, nil
+		case <-deadline.C:
+			return nil, false, nil
+		case <-tick.C:
+			prev, reserved, err := reserveIdempotent(ctx, store, key, opts.LockTTL)
+			if err != nil || reserved || prev != nil {
+				return prev, reserved, err
+			}
+		}
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, resp *IdempotentResponse) {
+	h := w.Header()
+	for k, vs := range resp.Header {
+		h[k] = append([]string(nil), vs...)
+	}
+	h.Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
+
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) { return 0, e.err }
+
+// requestScopedHeaders describe the request that produced a response (often
+// set by outer middleware) rather than the response, so they are not stored: a
+// replay keeps the retry's own request id, trace id and rate-limit state.
+var requestScopedHeaders = []string{
+	"Date",
+	"X-Request-Id",
+	TraceIDHeader,
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"Retry-After",
+}
+
+// idempotencyRecorder passes the response through while keeping a copy to store.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	header      http.Header
+	body        bytes.Buffer
+	max         int
+	overflow    bool
+}
+
+func (w *idempotencyRecorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+		w.header = w.Header().Clone()
+		for _, k := range requestScopedHeaders {
+			w.header.Del(k)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.overflow {
+		if w.body.Len()+len(b) > w.max {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController (Flush, deadlines).
+func (w *idempotencyRecorder) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,139 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/idempotency"
+)
+
+func idemRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/v1/orders", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	return req.WithContext(authctx.WithUserID(req.Context(), "u1"))
+}
+
+func TestIdempotency_ReplaysAndRejectsReuse(t *testing.T) {
+	var calls atomic.Int32
+	h := Idempotency(idempotency.NewMemoryStore(), IdempotencyOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, idemRequest("k1", `{"a":1}`))
+		if rr.Code != http.StatusCreated || rr.Body.String() != `{"id":"1"}` {
+			t.Fatalf("attempt %d: unexpected response %d %q", i, rr.Code, rr.Body.String())
+		}
+		if replayed := rr.Header().Get("Idempotent-Replayed") == "true"; replayed != (i == 1) {
+			t.Fatalf("attempt %d: unexpected Idempotent-Replayed %v", i, replayed)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", n)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, idemRequest("k1", `{"a":2}`))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for reused key, got %d", rr.Code)
+	}
+}
+
+func TestIdempotency_ReplayKeepsRequestScopedHeaders(t *testing.T) {
+	h := Idempotency(idempotency.NewMemoryStore(), IdempotencyOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/v1/orders/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	// Outer middleware stamps every request with its own id and trace.
+	outer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+		w.Header().Set(TraceIDHeader, "trace-"+r.Header.Get("X-Request-Id"))
+		h.ServeHTTP(w, r)
+	})
+
+	for _, rid := range []string{"rid-1", "rid-2"} {
+		req := idemRequest("k1", `{"a":1}`)
+		req.Header.Set("X-Request-Id", rid)
+		rr := httptest.NewRecorder()
+		outer.ServeHTTP(rr, req)
+		if got := rr.Header().Get("X-Request-Id"); got != rid {
+			t.Fatalf("X-Request-Id = %q, want %q", got, rid)
+		}
+		if got := rr.Header().Get(TraceIDHeader); got != "trace-"+rid {
+			t.Fatalf("%s = %q, want %q", TraceIDHeader, got, "trace-"+rid)
+		}
+		if got := rr.Header().Get("Location"); got != "/v1/orders/1" {
+			t.Fatalf("Location = %q, want the stored value", got)
+		}
+	}
+}
+
+func TestIdempotency_SkipsRequestsWithoutUser(t *testing.T) {
+	var calls atomic.Int32
+	h := Idempotency(idempotency.NewMemoryStore(), IdempotencyOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	// Two anonymous callers sending the same key must not share a response.
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/v1/orders", strings.NewReader(`{"a":1}`))
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("attempt %d: anonymous request was replayed", i)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", n)
+	}
+}
+
+func TestIdempotency_ConcurrentDuplicate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		wait time.Duration
+		want int
+	}{
+		{"rejected", 0, http.StatusConflict},
+		{"waits and replays", time.Second, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			h := Idempotency(idempotency.NewMemoryStore(), IdempotencyOptions{Wait: tc.wait})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-release
+				_, _ = w.Write([]byte("done"))
+			}))
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				h.ServeHTTP(httptest.NewRecorder(), idemRequest("k", "x"))
+			}()
+			<-started
+
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				close(release)
+			}()
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, idemRequest("k", "x"))
+			<-done
+			if rr.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rr.Code)
+			}
+		})
+	}
+}
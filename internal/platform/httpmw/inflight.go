@@ -1,7 +1,15 @@
 package httpmw
 
 import (
+	"context"
 	"net/http"
+	"time"
+
+	"sdk-microservices/internal/platform/httperr"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // InFlightLimit applies backpressure by bounding the number of concurrent
@@ -10,6 +18,10 @@ import (
 // When the limit is reached, it returns 503 immediately (fail-fast) rather than
 // queueing unbounded work and risking OOM / tail-latency blowups.
 func InFlightLimit(max int, next http.Handler) http.Handler {
+	return inFlightLimit(nil, max, next)
+}
+
+func inFlightLimit(lm *limitMetrics, max int, next http.Handler) http.Handler {
 	if max <= 0 {
 		return next
 	}
@@ -20,12 +32,203 @@ func InFlightLimit(max int, next http.Handler) http.Handler {
 		select {
 		case sem <- struct{}{}:
 			defer func() { <-sem }()
+			defer lm.acquire(r.Context())()
 			next.ServeHTTP(w, r)
 			return
 		default:
-			w.Header().Set("Retry-After", "1")
-			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			lm.reject(r.Context(), "limit")
+			writeUnavailable(w, r)
 			return
 		}
 	})
 }
+
+// QueueOptions configures a bounded waiting queue in front of an in-flight limit.
+type QueueOptions struct {
+	// MaxQueue bounds how many requests may wait for a slot once the limit is reached.
+	// Zero disables queueing (fail-fast).
+	MaxQueue int
+	// MaxWait bounds how long a queued request waits for a slot before it is rejected.
+	// Defaults to 100ms when queueing is enabled.
+	MaxWait time.Duration
+}
+
+// InFlightLimitQueued bounds concurrent in-flight requests like InFlightLimit,
+// but lets short bursts wait in a bounded queue instead of failing immediately.
+//
+// Requests that find the queue full, or that wait longer than q.MaxWait, get 503.
+// Requests whose context ends while queued (client gone, edge Timeout) are dropped
+// without running next.
+//
+// Slots in use, rejections, queue depth and wait time are recorded as OTel metrics
+// under the given service name.
+func InFlightLimitQueued(service string, max int, q QueueOptions, next http.Handler) http.Handler {
+	if max <= 0 {
+		return next
+	}
+	lm := newLimitMetrics(service)
+	if q.MaxQueue <= 0 {
+		return inFlightLimit(lm, max, next)
+	}
+	if q.MaxWait <= 0 {
+		q.MaxWait = 100 * time.Millisecond
+	}
+
+	sem := make(chan struct{}, max)
+	queue := make(chan struct{}, q.MaxQueue)
+	qm := newQueueMetrics(service)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fast path: a slot is free.
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			defer lm.acquire(r.Context())()
+			next.ServeHTTP(w, r)
+			return
+		default:
+		}
+
+		// Slow path: take a queue slot (or reject if the queue is full too).
+		select {
+		case queue <- struct{}{}:
+		default:
+			lm.reject(r.Context(), "queue_full")
+			writeUnavailable(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		start := time.Now()
+		qm.enter(ctx)
+		timer := time.NewTimer(q.MaxWait)
+
+		admitted := false
+		select {
+		case sem <- struct{}{}:
+			admitted = true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+
+		timer.Stop()
+		<-queue
+		qm.leave(ctx, time.Since(start), admitted)
+
+		if !admitted {
+			if ctx.Err() == nil {
+				lm.reject(ctx, "queue_timeout")
+				writeUnavailable(w, r)
+			}
+			return
+		}
+		defer func() { <-sem }()
+		defer lm.acquire(ctx)()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeUnavailable(w http.ResponseWriter, r *http.Request) {
+	httperr.Unavailable(w, r, "OVERLOADED")
+}
+
+// limitMetrics makes 503 shedding visible: slots in use and rejections by reason
+// ("limit", "queue_full", "queue_timeout").
+type limitMetrics struct {
+	active     metric.Int64UpDownCounter
+	rejections metric.Int64Counter
+	attrs      metric.MeasurementOption
+}
+
+func newLimitMetrics(service string) *limitMetrics {
+	m := otel.Meter("sdk-microservices/" + service)
+
+	active, err := m.Int64UpDownCounter(
+		"http.server.limit.active",
+		metric.WithDescription("Requests holding an in-flight slot"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil
+	}
+	rejections, err := m.Int64Counter(
+		"http.server.limit.rejections",
+		metric.WithDescription("Requests rejected by the in-flight limit"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil
+	}
+
+	return &limitMetrics{
+		active:     active,
+		rejections: rejections,
+		attrs:      metric.WithAttributes(attribute.String("service.name", service)),
+	}
+}
+
+// acquire counts a held slot and returns the matching release.
+func (l *limitMetrics) acquire(ctx context.Context) func() {
+	if l == nil {
+		return func() {}
+	}
+	l.active.Add(ctx, 1, l.attrs)
+	return func() { l.active.Add(ctx, -1, l.attrs) }
+}
+
+func (l *limitMetrics) reject(ctx context.Context, reason string) {
+	if l == nil {
+		return
+	}
+	l.rejections.Add(ctx, 1, l.attrs, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+type queueMetrics struct {
+	depth metric.Int64UpDownCounter
+	wait  metric.Float64Histogram
+	attrs metric.MeasurementOption
+}
+
+func newQueueMetrics(service string) *queueMetrics {
+	m := otel.Meter("sdk-microservices/" + service)
+
+	depth, err := m.Int64UpDownCounter(
+		"http.server.queue.depth",
+		metric.WithDescription("HTTP requests waiting for an in-flight slot"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil
+	}
+	wait, err := m.Float64Histogram(
+		"http.server.queue.wait",
+		metric.WithDescription("Time spent waiting for an in-flight slot"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil
+	}
+
+	return &queueMetrics{
+		depth: depth,
+		wait:  wait,
+		attrs: metric.WithAttributes(attribute.String("service.name", service)),
+	}
+}
+
+func (q *queueMetrics) enter(ctx context.Context) {
+	if q == nil {
+		return
+	}
+	q.depth.Add(ctx, 1, q.attrs)
+}
+
+func (q *queueMetrics) leave(ctx context.Context, waited time.Duration, admitted bool) {
+	if q == nil {
+		return
+	}
+	q.depth.Add(ctx, -1, q.attrs)
+	q.wait.Record(ctx, waited.Seconds(), q.attrs,
+		metric.WithAttributes(attribute.Bool("admitted", admitted)),
+	)
+}
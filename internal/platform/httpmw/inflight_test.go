@@ -0,0 +1,58 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestInFlightLimitQueued_WaitsForSlot(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := InFlightLimitQueued("test", 1, QueueOptions{MaxQueue: 1, MaxWait: time.Second}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+	}))
+
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/slow", nil))
+	<-started
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/fast", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected queued request to succeed, got %d", rr.Code)
+	}
+}
+
+func TestInFlightLimitQueued_RejectsAfterMaxWait(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	h := InFlightLimitQueued("test", 1, QueueOptions{MaxQueue: 1, MaxWait: 10 * time.Millisecond}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/slow" {
+			t.Fatalf("handler should not run")
+		}
+		close(started)
+		<-release
+	}))
+
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/slow", nil))
+	<-started
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/fast", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("expected Retry-After 1, got %q", got)
+	}
+}
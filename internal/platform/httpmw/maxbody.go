@@ -0,0 +1,38 @@
+package httpmw
+
+import (
+	"net/http"
+
+	"sdk-microservices/internal/platform/errs"
+)
+
+// DefaultMaxBodyBytes is the request body limit applied by DefaultEdge.
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+var errBodyTooLarge = errs.PayloadTooLarge("BODY_TOO_LARGE", "request body too large")
+
+// MaxBody rejects request bodies larger than n bytes with 413 (problem+json).
+//
+// Requests that declare a larger Content-Length are rejected up front; chunked or
+// lying bodies are cut off by http.MaxBytesReader, so handlers see a read error
+// instead of buffering unbounded input.
+func MaxBody(n int64, next http.Handler) http.Handler {
+	if n <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > n {
+			errs.WriteProblem(w, r, errBodyTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithMaxBody adapts MaxBody(n, next) into a Middleware.
+func WithMaxBody(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return MaxBody(n, next)
+	}
+}
@@ -2,8 +2,11 @@ package httpmw
 
 import (
 	"net/http"
+	"net/netip"
 	"time"
 
+	"sdk-microservices/internal/platform/logging"
+
 	"go.uber.org/zap"
 )
 
@@ -20,6 +23,35 @@ type EdgePolicy struct {
 	// MaxInFlight limits concurrent requests processed by the server handler.
 	MaxInFlight int
 
+	// MaxQueue lets up to this many requests wait for an in-flight slot (for at most
+	// MaxQueueWait) instead of getting 503 immediately. Zero means fail-fast.
+	MaxQueue     int
+	MaxQueueWait time.Duration
+
+	// MaxBodyBytes bounds request body size (default DefaultMaxBodyBytes).
+	MaxBodyBytes int64
+
+	// RequestID configures X-Request-Id validation/generation.
+	RequestID RequestIDOptions
+
+	// Security configures response security headers (default DefaultSecurityPolicy()).
+	Security *SecurityPolicy
+
+	// CORS, if set, applies CORS headers and answers preflight requests before
+	// Timeout/InFlightLimit and any Leaf middleware (e.g. auth).
+	CORS *CORSOptions
+
+	// Redactor masks sensitive headers/fields in every log written by the edge chain
+	// (access log, panics). Defaults to logging.DefaultRedactor.
+	Redactor *logging.Redactor
+
+	// LogSampling thins access logs for high-QPS routes; errors are always logged.
+	LogSampling LogSampling
+
+	// TrustedProxies are the proxy ranges whose X-Forwarded-For is honored when
+	// resolving the client IP (see RealIP). Empty means "use the direct peer".
+	TrustedProxies []netip.Prefix
+
 	// Outer is applied outside the default edge chain (i.e., even before RequestID/Recover).
 	// Use sparingly.
 	Outer Chain
@@ -27,45 +59,78 @@ type EdgePolicy struct {
 	// Leaf is applied closest to the business handler, inside the default edge chain.
 	// Typical examples: rate limiting, auth, request validation, etc.
 	Leaf Chain
+
+	// Routes adds route-scoped middleware inside Leaf (e.g. auth everywhere except
+	// /v1/auth/ and health endpoints).
+	Routes Routes
 }
 
 // DefaultEdge returns the default "edge" chain, excluding Wrap() and excluding any leaf middleware.
 func DefaultEdge(log *zap.Logger, timeout time.Duration, maxInFlight int) Chain {
-	if timeout <= 0 {
-		timeout = 30 * time.Second
+	return edgeChain(log, EdgePolicy{Timeout: timeout, MaxInFlight: maxInFlight}, nil)
+}
+
+// edgeChain builds the default edge chain from p, inserting policy-driven middleware
+// right after the security headers so it runs before timeouts/in-flight limits.
+func edgeChain(log *zap.Logger, p EdgePolicy, early Chain) Chain {
+	if p.Timeout <= 0 {
+		p.Timeout = 30 * time.Second
+	}
+	if p.MaxInFlight <= 0 {
+		p.MaxInFlight = 512
 	}
-	if maxInFlight <= 0 {
-		maxInFlight = 512
+	if p.MaxBodyBytes <= 0 {
+		p.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	sec := DefaultSecurityPolicy()
+	if p.Security != nil {
+		sec = *p.Security
 	}
 
-	return Chain{
-		RequestID,
+	c := Chain{
+		WithRequestID(p.RequestID),
+		TraceID,
 		WithRecover(log),
-		SecurityHeaders,
-		WithTimeout(timeout),
-		WithInFlightLimit(maxInFlight),
+		WithSecurity(sec),
 	}
+	c = c.Append(early...)
+	return c.Append(
+		WithMaxBody(p.MaxBodyBytes),
+		WithTimeoutOptions(TimeoutOptions{Timeout: p.Timeout, Service: p.ServiceName, Log: log}),
+		WithInFlightLimitQueued(p.ServiceName, p.MaxInFlight, QueueOptions{MaxQueue: p.MaxQueue, MaxWait: p.MaxQueueWait}),
+	)
 }
 
 // BuildEdgeHandler composes a policy-driven middleware stack around next.
 //
 // Final order (outer -> inner):
 //
-//	Outer..., Wrap, RequestID, Recover, SecurityHeaders, Timeout, InFlightLimit, Leaf..., next
+//	Outer..., RealIP, Wrap, RequestID, TraceID, Recover, Security, [CORS], MaxBody, Timeout, InFlightLimit, Leaf..., Routes, next
 func BuildEdgeHandler(log *zap.Logger, p EdgePolicy, next http.Handler) http.Handler {
 	if p.ServiceName == "" {
 		p.ServiceName = "service"
 	}
+	if p.Redactor == nil {
+		p.Redactor = logging.DefaultRedactor
+	}
+	log = p.Redactor.Logger(log)
 
-	leaf := p.Leaf.Then(next)
+	leaf := p.Leaf.Then(p.Routes.Then(next))
 
-	core := DefaultEdge(log, p.Timeout, p.MaxInFlight).
-		Append() // no-op; keeps style consistent
+	var early Chain
+	if p.CORS != nil {
+		// Preflights never count against timeouts or in-flight limits.
+		early = append(early, CORS(*p.CORS))
+	}
+	core := edgeChain(log, p, early)
 
 	h := core.Then(leaf)
 
 	// Add standard tracing + access logging outside of the default policy chain.
-	h = WithWrap(p.ServiceName, log)(h)
+	h = WithWrapSampled(p.ServiceName, log, p.LogSampling)(h)
+
+	// Resolve the client IP once, before access logging and rate limiting read it.
+	h = RealIP(p.TrustedProxies)(h)
 
 	// Finally apply any outer middleware.
 	h = p.Outer.Then(h)
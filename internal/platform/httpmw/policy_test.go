@@ -1,8 +1,10 @@
 package httpmw
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,9 +31,22 @@ func TestBuildEdgeHandler_PanicRecoveredAndHeadersPresent(t *testing.T) {
 		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, rr.Code)
 	}
 
-	if rr.Header().Get("X-Request-Id") == "" {
+	rid := rr.Header().Get("X-Request-Id")
+	if rid == "" {
 		t.Fatalf("expected X-Request-Id header to be set")
 	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected problem+json, got %q", ct)
+	}
+	var body struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.RequestID != rid {
+		t.Fatalf("expected request_id %q in body, got %q", rid, body.RequestID)
+	}
 
 	// Security headers should be present even on errors.
 	if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
@@ -44,3 +59,56 @@ func TestBuildEdgeHandler_PanicRecoveredAndHeadersPresent(t *testing.T) {
 		t.Fatalf("expected Referrer-Policy=no-referrer, got %q", got)
 	}
 }
+
+func TestBuildEdgeHandler_RejectsOversizedBody(t *testing.T) {
+	h := BuildEdgeHandler(zap.NewNop(), EdgePolicy{
+		ServiceName:  "testsvc",
+		MaxBodyBytes: 16,
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("handler should not run")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/v1/test", strings.NewReader(strings.Repeat("x", 32)))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected problem+json, got %q", ct)
+	}
+}
+
+func TestRoutes_ScopesChainsByPattern(t *testing.T) {
+	tag := func(v string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Chain", v)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := Routes{
+		Routes: []Route{
+			{Pattern: "/v1/auth/", Chain: Chain{tag("auth")}},
+			{Pattern: "/v1/auth/logout", Chain: Chain{tag("logout")}},
+			{Method: http.MethodGet, Pattern: "/v1/", Chain: Chain{tag("api-get")}},
+		},
+		Default: Chain{tag("default")},
+	}.Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct{ method, path, want string }{
+		{http.MethodPost, "/v1/auth/login", "auth"},
+		{http.MethodPost, "/v1/auth/logout", "logout"},
+		{http.MethodGet, "/v1/hello", "api-get"},
+		{http.MethodPost, "/v1/hello", "default"},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(tc.method, "http://example.com"+tc.path, nil))
+		if got := rr.Header().Get("X-Chain"); got != tc.want {
+			t.Fatalf("%s %s: expected chain %q, got %q", tc.method, tc.path, tc.want, got)
+		}
+	}
+}
@@ -1,88 +1,215 @@
 package httpmw
 
 import (
-	"net"
+	"context"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"golang.org/x/time/rate"
+
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/platform/ratelimit"
 )
 
-// In-memory per-IP rate limiter.
-// NOTE: For multi-instance deployments, back this with Redis (sliding window / token bucket).
-type IPLimiter struct {
-	rate    rate.Limit
-	burst   int
-	ttl     time.Duration
-	mu      sync.Mutex
-	clients map[string]*ipClient
-}
+// KeyFunc extracts the rate-limit key from a request. An empty key means
+// "no opinion"; see FirstKey.
+type KeyFunc func(*http.Request) string
 
-type ipClient struct {
-	lim  *rate.Limiter
-	last time.Time
+// KeyByIP keys requests by client IP ("unknown" if it cannot be determined).
+func KeyByIP(r *http.Request) string {
+	if ip := clientIP(r); ip != "" {
+		return "ip:" + ip
+	}
+	return "ip:unknown"
 }
 
-func NewIPLimiter(r rate.Limit, burst int, ttl time.Duration) *IPLimiter {
-	return &IPLimiter{
-		rate:    r,
-		burst:   burst,
-		ttl:     ttl,
-		clients: make(map[string]*ipClient),
+// KeyByUser keys requests by the authenticated user id from authctx.
+// It returns "" for anonymous requests, so combine it with FirstKey.
+func KeyByUser(r *http.Request) string {
+	if uid, ok := authctx.UserID(r.Context()); ok {
+		return "user:" + uid
 	}
+	return ""
 }
 
-func (l *IPLimiter) get(ip string) *rate.Limiter {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// KeyByHeader keys requests by the value of header (e.g. "X-API-Key").
+func KeyByHeader(header string) KeyFunc {
+	return func(r *http.Request) string {
+		if v := r.Header.Get(header); v != "" {
+			return "hdr:" + header + ":" + v
+		}
+		return ""
+	}
+}
 
-	now := time.Now()
-	// opportunistic cleanup
-	for k, c := range l.clients {
-		if now.Sub(c.last) > l.ttl {
-			delete(l.clients, k)
+// FirstKey returns the first non-empty key from fns, falling back to KeyByIP.
+func FirstKey(fns ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		for _, fn := range fns {
+			if k := fn(r); k != "" {
+				return k
+			}
 		}
+		return KeyByIP(r)
 	}
+}
+
+// KeyedLimiter is an in-memory token-bucket rate limiter, one bucket per key.
+// NOTE: For multi-instance deployments, back this with Redis (sliding window / token bucket).
+type KeyedLimiter struct {
+	key     KeyFunc
+	metrics *rateLimitMetrics
+	buckets *ratelimit.Buckets
+}
+
+// IPLimiter is a KeyedLimiter keyed by client IP.
+type IPLimiter = KeyedLimiter
 
-	c, ok := l.clients[ip]
-	if !ok {
-		c = &ipClient{lim: rate.NewLimiter(l.rate, l.burst), last: now}
-		l.clients[ip] = c
-		return c.lim
+// NewKeyedLimiter creates a limiter keyed by key (KeyByIP if nil).
+func NewKeyedLimiter(key KeyFunc, r rate.Limit, burst int, ttl time.Duration) *KeyedLimiter {
+	if key == nil {
+		key = KeyByIP
 	}
-	c.last = now
-	return c.lim
+	return &KeyedLimiter{key: key, buckets: ratelimit.NewBuckets(r, burst, ttl)}
+}
+
+func NewIPLimiter(r rate.Limit, burst int, ttl time.Duration) *IPLimiter {
+	return NewKeyedLimiter(KeyByIP, r, burst, ttl)
+}
+
+// Instrument records rejections and the tracked client count as OTel metrics under
+// service (see rateLimitMetrics). Call it before serving; it returns l.
+func (l *KeyedLimiter) Instrument(service string) *KeyedLimiter {
+	l.metrics = newRateLimitMetrics(service, l.buckets.Len)
+	return l
 }
 
-func (l *IPLimiter) Middleware(next http.Handler) http.Handler {
+// SetLimit changes the rate and burst for every key, including those already
+// tracked (e.g. on a config reload).
+func (l *KeyedLimiter) SetLimit(r rate.Limit, burst int) {
+	l.buckets.SetLimit(r, burst)
+}
+
+func (l *KeyedLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := clientIP(r)
-		if ip == "" {
-			ip = "unknown"
-		}
-		if !l.get(ip).Allow() {
-			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		key := l.key(r)
+		if !l.allow(key, w) {
+			l.metrics.reject(r.Context(), key)
+			writeRateLimited(w, r)
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
-func clientIP(r *http.Request) string {
-	// Prefer RFC 7239 Forwarded? We'll keep this minimal.
-	// If you run behind a trusted proxy, terminate and set X-Forwarded-For there.
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err == nil {
-		return host
+// allow consumes a token for key and sets the RateLimit-* headers on w.
+func (l *KeyedLimiter) allow(key string, w http.ResponseWriter) bool {
+	now := time.Now()
+	lim := l.buckets.Get(key, now)
+	r, burst := l.buckets.Limit()
+
+	res := lim.ReserveN(now, 1)
+	delay := res.DelayFrom(now)
+	if delay > 0 {
+		res.CancelAt(now)
 	}
-	// RemoteAddr may already be a host.
-	if net.ParseIP(r.RemoteAddr) != nil {
-		return r.RemoteAddr
+
+	remaining := int(lim.TokensAt(now))
+	if remaining < 0 {
+		remaining = 0
 	}
-	return ""
+	// Reset is the time until the bucket is full again.
+	var reset time.Duration
+	if r > 0 {
+		missing := float64(burst) - lim.TokensAt(now)
+		reset = time.Duration(missing / float64(r) * float64(time.Second))
+	}
+	setRateLimitHeaders(w, burst, remaining, reset, delay)
+	return delay == 0
+}
+
+var errRateLimited = errs.RateLimited("RATE_LIMITED", "too many requests", 0)
+
+// setRateLimitHeaders emits the draft IETF RateLimit-Limit/-Remaining/-Reset headers,
+// plus Retry-After when the request is rejected (retryAfter > 0).
+func setRateLimitHeaders(w http.ResponseWriter, limit, remaining int, reset, retryAfter time.Duration) {
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+	if retryAfter > 0 {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+	}
+}
+
+// writeRateLimited writes a 429 problem; Retry-After is already set by setRateLimitHeaders.
+func writeRateLimited(w http.ResponseWriter, r *http.Request) {
+	errs.WriteProblem(w, r, errRateLimited)
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// clientIP prefers the IP resolved by RealIP and falls back to the direct peer.
+func clientIP(r *http.Request) string {
+	if ip, ok := ClientIP(r.Context()); ok {
+		return ip
+	}
+	return remoteIP(r)
 }
 
-func (l *IPLimiter) Wrap(next http.Handler) http.Handler {
+func (l *KeyedLimiter) Wrap(next http.Handler) http.Handler {
 	return l.Middleware(next)
 }
+
+// rateLimitMetrics makes 429 shedding visible: rejections by key class (the key
+// prefix: "ip", "user", "hdr") and the number of clients currently tracked.
+type rateLimitMetrics struct {
+	rejections metric.Int64Counter
+	service    attribute.KeyValue
+}
+
+func newRateLimitMetrics(service string, clients func() int) *rateLimitMetrics {
+	m := otel.Meter("sdk-microservices/" + service)
+	svc := attribute.String("service.name", service)
+
+	rejections, err := m.Int64Counter(
+		"http.server.ratelimit.rejections",
+		metric.WithDescription("Requests rejected by the rate limiter"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil
+	}
+	_, err = m.Int64ObservableGauge(
+		"http.server.ratelimit.clients",
+		metric.WithDescription("Rate limiter keys currently tracked"),
+		metric.WithUnit("{client}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(clients()), metric.WithAttributes(svc))
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil
+	}
+	return &rateLimitMetrics{rejections: rejections, service: svc}
+}
+
+func (m *rateLimitMetrics) reject(ctx context.Context, key string) {
+	if m == nil {
+		return
+	}
+	class, _, _ := strings.Cut(key, ":")
+	m.rejections.Add(ctx, 1, metric.WithAttributes(m.service, attribute.String("key.class", class)))
+}
@@ -0,0 +1,167 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSlidingWindowLimiter_NoBoundaryBurst(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewSlidingWindowLimiter(10, time.Second, 0)
+	l.now = func() time.Time { return now }
+
+	// Fill the window right before the boundary.
+	now = now.Add(900 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		if !l.Allow("ip") {
+			t.Fatalf("request %d: expected allowed", i)
+		}
+	}
+	if l.Allow("ip") {
+		t.Fatalf("expected limit reached")
+	}
+
+	// Just after the boundary, a token bucket would admit a fresh burst; the
+	// sliding window still counts ~90% of the previous window.
+	now = now.Add(200 * time.Millisecond)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if l.Allow("ip") {
+			allowed++
+		}
+	}
+	if allowed > 2 {
+		t.Fatalf("expected at most 2 requests after boundary, got %d", allowed)
+	}
+
+	// Two full windows later the previous count no longer applies.
+	now = now.Add(2 * time.Second)
+	if !l.Allow("ip") {
+		t.Fatalf("expected allowed after windows elapsed")
+	}
+}
+
+func TestSlidingWindowLimiter_SetLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewSlidingWindowLimiter(2, time.Second, 0)
+	l.now = func() time.Time { return now }
+
+	for range 2 {
+		l.Allow("ip")
+	}
+	if l.Allow("ip") {
+		t.Fatal("expected limit reached")
+	}
+
+	// A higher limit applies to the key already tracked.
+	l.SetLimit(3, time.Second)
+	if !l.Allow("ip") {
+		t.Fatal("expected allowed after raising the limit")
+	}
+	if l.Allow("ip") {
+		t.Fatal("expected the raised limit reached")
+	}
+
+	// A new window starts every key afresh.
+	l.SetLimit(1, time.Minute)
+	if !l.Allow("ip") {
+		t.Fatal("expected allowed after changing the window")
+	}
+	if l.Allow("ip") {
+		t.Fatal("expected the new limit reached")
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	l := NewIPLimiter(1, 2, time.Minute)
+	h := l.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var rr *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	}
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("RateLimit-Limit"); got != "2" {
+		t.Fatalf("expected RateLimit-Limit 2, got %q", got)
+	}
+	if got := rr.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Fatalf("expected RateLimit-Remaining 0, got %q", got)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("expected Retry-After 1, got %q", got)
+	}
+}
+
+func TestFirstKey(t *testing.T) {
+	key := FirstKey(KeyByUser, KeyByHeader("X-API-Key"))
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	if got := key(r); got != "ip:10.0.0.1" {
+		t.Fatalf("expected ip key, got %q", got)
+	}
+
+	r.Header.Set("X-API-Key", "k1")
+	if got := key(r); got != "hdr:X-API-Key:k1" {
+		t.Fatalf("expected api key, got %q", got)
+	}
+
+	r = r.WithContext(authctx.WithUserID(r.Context(), "u1"))
+	if got := key(r); got != "user:u1" {
+		t.Fatalf("expected user key, got %q", got)
+	}
+}
+
+func TestKeyedLimiter_InstrumentCountsRejections(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(prev) })
+
+	l := NewKeyedLimiter(KeyByIP, 1, 1, time.Minute).Instrument("test")
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range d.DataPoints {
+					got[m.Name] += dp.Value
+					if class, _ := dp.Attributes.Value("key.class"); class.AsString() != "ip" {
+						t.Errorf("key.class = %q, want ip", class.AsString())
+					}
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range d.DataPoints {
+					got[m.Name] = dp.Value
+				}
+			}
+		}
+	}
+	if got["http.server.ratelimit.rejections"] != 2 {
+		t.Fatalf("rejections = %d, want 2", got["http.server.ratelimit.rejections"])
+	}
+	if got["http.server.ratelimit.clients"] != 1 {
+		t.Fatalf("clients = %d, want 1", got["http.server.ratelimit.clients"])
+	}
+}
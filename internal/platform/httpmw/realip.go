@@ -0,0 +1,110 @@
+package httpmw
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// ClientIP returns the client IP resolved by RealIP, if any.
+func ClientIP(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(string)
+	return ip, ok && ip != ""
+}
+
+// ParseCIDRs parses trusted proxy ranges; bare IPs are treated as single-host prefixes.
+func ParseCIDRs(cidrs ...string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !strings.Contains(c, "/") {
+			addr, err := netip.ParseAddr(c)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p.Masked())
+	}
+	return out, nil
+}
+
+// RealIP resolves the client IP once per request and stores it in the context
+// (see ClientIP) for the rate limiter, access logs and downstream metadata.
+//
+// X-Forwarded-For is only honored when the direct peer is a trusted proxy; the
+// client is then the right-most address not in trusted, so clients cannot spoof
+// their IP by prepending entries. With no trusted proxies the peer address is used.
+func RealIP(trusted []netip.Prefix) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := resolveClientIP(r, trusted); ip != "" {
+				r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peerIP := remoteIP(r)
+	if len(trusted) == 0 || !isTrusted(peerIP, trusted) {
+		return peerIP
+	}
+
+	hops := r.Header.Values("X-Forwarded-For")
+	for i := len(hops) - 1; i >= 0; i-- {
+		parts := strings.Split(hops[i], ",")
+		for j := len(parts) - 1; j >= 0; j-- {
+			ip := strings.TrimSpace(parts[j])
+			if _, err := netip.ParseAddr(ip); err != nil {
+				// Garbage in the chain: stop trusting it and fall back to the last good hop.
+				return peerIP
+			}
+			if !isTrusted(ip, trusted) {
+				return ip
+			}
+			peerIP = ip
+		}
+	}
+	return peerIP
+}
+
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the host part of r.RemoteAddr ("" if it cannot be parsed).
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err == nil {
+		return host
+	}
+	// RemoteAddr may already be a host.
+	if net.ParseIP(r.RemoteAddr) != nil {
+		return r.RemoteAddr
+	}
+	return ""
+}
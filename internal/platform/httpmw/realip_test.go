@@ -0,0 +1,44 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseCIDRs("10.0.0.0/8", "192.168.1.1")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{"untrusted peer ignores XFF", "203.0.113.9:1234", []string{"1.2.3.4"}, "203.0.113.9"},
+		{"trusted peer uses XFF", "10.1.2.3:1234", []string{"198.51.100.7"}, "198.51.100.7"},
+		{"spoofed left entries ignored", "10.1.2.3:1234", []string{"6.6.6.6, 198.51.100.7, 192.168.1.1"}, "198.51.100.7"},
+		{"multiple headers", "10.1.2.3:1234", []string{"6.6.6.6", "198.51.100.7"}, "198.51.100.7"},
+		{"garbage falls back", "10.1.2.3:1234", []string{"not-an-ip"}, "10.1.2.3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			h := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = ClientIP(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			req.RemoteAddr = tc.remote
+			for _, v := range tc.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
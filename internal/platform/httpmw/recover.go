@@ -4,9 +4,18 @@ import (
 	"net/http"
 	"runtime/debug"
 
+	"sdk-microservices/internal/platform/errreport"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/platform/logging"
+
 	"go.uber.org/zap"
 )
 
+var errPanic = errs.Internal(nil)
+
+// Recover turns handler panics into a problem+json 500 carrying the request id and
+// trace id, and logs the panic tagged with the same ids so support can correlate them.
+// The panic is also sent to the error reporter, if one is installed (errreport).
 func Recover(log *zap.Logger, next http.Handler) http.Handler {
 	if log == nil {
 		log = zap.NewNop()
@@ -15,11 +24,18 @@ func Recover(log *zap.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if v := recover(); v != nil {
-				log.Error("panic recovered",
+				lg := logging.WithTrace(r.Context(), log)
+				if rid, ok := logging.RequestID(r.Context()); ok {
+					lg = lg.With(zap.String("request_id", rid))
+				}
+				stack := debug.Stack()
+				errreport.CapturePanic(r.Context(), v, stack)
+				lg.Error("panic recovered",
 					zap.Any("panic", v),
-					zap.ByteString("stack", debug.Stack()),
+					zap.ByteString("stack", stack),
+					errreport.Reported,
 				)
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				errs.WriteProblem(w, r, errPanic)
 			}
 		}()
 
@@ -2,18 +2,95 @@ package httpmw
 
 import (
 	"net/http"
+	"strings"
+
+	"sdk-microservices/internal/platform/logging"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultMaxRequestIDLen bounds inbound X-Request-Id values.
+const DefaultMaxRequestIDLen = 128
+
+// RequestIDOptions configures RequestIDWith.
+type RequestIDOptions struct {
+	// MaxLen bounds accepted inbound ids (default DefaultMaxRequestIDLen).
+	MaxLen int
+	// FromTrace uses the trace id (active span, else the W3C traceparent header) as
+	// the request id when none is supplied, so logs, traces and the id clients see
+	// line up. Otherwise a UUIDv4 is generated.
+	FromTrace bool
+}
+
 // RequestID ensures every request has an X-Request-Id.
-// If absent, it generates a UUIDv4. Always echoes back the header.
+// If absent, it generates a UUIDv4. Always echoes back the header and stores the id
+// in the request context (logging.RequestID) for downstream propagation.
 func RequestID(next http.Handler) http.Handler {
+	return RequestIDWith(RequestIDOptions{}, next)
+}
+
+// RequestIDWith is RequestID with options. Inbound ids that are too long or contain
+// anything but [A-Za-z0-9._:-] are replaced, since they end up verbatim in logs
+// and downstream metadata.
+func RequestIDWith(opts RequestIDOptions, next http.Handler) http.Handler {
+	if opts.MaxLen <= 0 {
+		opts.MaxLen = DefaultMaxRequestIDLen
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("X-Request-Id") == "" {
-			r.Header.Set("X-Request-Id", uuid.NewString())
+		rid := r.Header.Get("X-Request-Id")
+		if !validRequestID(rid, opts.MaxLen) {
+			rid = ""
+			if opts.FromTrace {
+				rid = traceRequestID(r)
+			}
+			if rid == "" {
+				rid = uuid.NewString()
+			}
+			r.Header.Set("X-Request-Id", rid)
 		}
-		w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
-		next.ServeHTTP(w, r)
+		w.Header().Set("X-Request-Id", rid)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), rid)))
 	})
 }
+
+// WithRequestID adapts RequestIDWith(opts, next) into a Middleware.
+func WithRequestID(opts RequestIDOptions) Middleware {
+	return func(next http.Handler) http.Handler {
+		return RequestIDWith(opts, next)
+	}
+}
+
+func validRequestID(id string, maxLen int) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// traceRequestID returns the active trace id, falling back to the traceparent header
+// ("00-<trace-id>-<parent-id>-<flags>") when no span is active.
+func traceRequestID(r *http.Request) string {
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	parts := strings.Split(r.Header.Get("traceparent"), "-")
+	if len(parts) != 4 {
+		return ""
+	}
+	tid, err := trace.TraceIDFromHex(parts[1])
+	if err != nil || !tid.IsValid() {
+		return ""
+	}
+	return tid.String()
+}
@@ -0,0 +1,42 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID_ReplacesMalformed(t *testing.T) {
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, in := range []string{"abc\nforged=1", strings.Repeat("a", DefaultMaxRequestIDLen+1)} {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("X-Request-Id", in)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if got := rr.Header().Get("X-Request-Id"); got == in || got == "" {
+			t.Fatalf("expected %q to be replaced, got %q", in, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("X-Request-Id", "req-123_abc.def:1")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if got := rr.Header().Get("X-Request-Id"); got != "req-123_abc.def:1" {
+		t.Fatalf("expected valid id to be kept, got %q", got)
+	}
+}
+
+func TestRequestID_FromTraceparent(t *testing.T) {
+	h := RequestIDWith(RequestIDOptions{FromTrace: true}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if got := rr.Header().Get("X-Request-Id"); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected trace id, got %q", got)
+	}
+}
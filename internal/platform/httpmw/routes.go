@@ -0,0 +1,89 @@
+package httpmw
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Route scopes a Chain to matching requests.
+type Route struct {
+	// Method restricts the route to one HTTP method; empty matches any method.
+	Method string
+	// Pattern is a path prefix when it ends with "/" (e.g. "/v1/auth/"),
+	// otherwise an exact path (e.g. "/healthz").
+	Pattern string
+	// Chain is applied to matching requests (may be empty for "no extra middleware").
+	Chain Chain
+}
+
+// Routes picks a leaf Chain per request, so route groups (auth vs. API vs. health)
+// get different middleware without hand-written path switches.
+//
+// Matching: exact patterns beat prefixes, longer prefixes beat shorter ones, and
+// method-specific routes beat method-agnostic ones. Unmatched requests use Default.
+type Routes struct {
+	Routes  []Route
+	Default Chain
+}
+
+// Then builds each route's chain around h once and returns a dispatching handler.
+func (rs Routes) Then(h http.Handler) http.Handler {
+	if len(rs.Routes) == 0 {
+		return rs.Default.Then(h)
+	}
+
+	type built struct {
+		Route
+		h http.Handler
+	}
+	routes := make([]built, 0, len(rs.Routes))
+	for _, r := range rs.Routes {
+		routes = append(routes, built{Route: r, h: r.Chain.Then(h)})
+	}
+	def := rs.Default.Then(h)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var best http.Handler
+		bestScore := -1
+		for _, r := range routes {
+			if score := r.score(req); score > bestScore {
+				best, bestScore = r.h, score
+			}
+		}
+		if best == nil {
+			best = def
+		}
+		best.ServeHTTP(w, req)
+	})
+}
+
+// score returns how specifically r matches req, or -1 if it does not match.
+func (r Route) score(req *http.Request) int {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return -1
+	}
+	path := req.URL.Path
+
+	var score int
+	switch {
+	case strings.HasSuffix(r.Pattern, "/"):
+		if !strings.HasPrefix(path, r.Pattern) {
+			return -1
+		}
+		score = 2 * len(r.Pattern)
+	case path == r.Pattern:
+		// Exact matches outrank any prefix of the same path.
+		score = 2*len(r.Pattern) + 1
+	default:
+		return -1
+	}
+	if r.Method != "" {
+		score++
+	}
+	return score * 2
+}
+
+// Middleware adapts Routes into a Middleware.
+func (rs Routes) Middleware() Middleware {
+	return rs.Then
+}
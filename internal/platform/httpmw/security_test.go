@@ -0,0 +1,55 @@
+package httpmw
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurity_HSTSOnlyOverTLS(t *testing.T) {
+	p := DefaultSecurityPolicy()
+	p.HSTSMaxAge = time.Hour
+	p.HSTSIncludeSubdomains = true
+	h := Security(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	if got := rr.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Fatalf("expected no HSTS over plain HTTP, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Security-Policy"); got == "" {
+		t.Fatalf("expected default CSP")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if got := rr.Header().Get("Strict-Transport-Security"); got != "max-age=3600; includeSubDomains" {
+		t.Fatalf("unexpected HSTS %q", got)
+	}
+}
+
+func TestSecurity_TrustForwardedProto(t *testing.T) {
+	p := SecurityPolicy{HSTSMaxAge: time.Hour}
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+
+	rr := httptest.NewRecorder()
+	Security(p, http.NotFoundHandler()).ServeHTTP(rr, req)
+	if got := rr.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Fatalf("expected forwarded proto to be ignored, got %q", got)
+	}
+
+	p.TrustForwardedProto = true
+	rr = httptest.NewRecorder()
+	Security(p, http.NotFoundHandler()).ServeHTTP(rr, req)
+	if got := rr.Header().Get("Strict-Transport-Security"); got != "max-age=3600" {
+		t.Fatalf("unexpected HSTS %q", got)
+	}
+	if got := rr.Header().Get("X-Frame-Options"); got != "" {
+		t.Fatalf("expected empty fields to be omitted, got %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package httpmw
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SlidingWindowLimiter is an in-memory keyed limiter using the approximate
+// sliding-window counter algorithm.
+//
+// Unlike the token bucket in KeyedLimiter, it never admits more than roughly limit
+// requests in any window-length span, so there is no 2x burst at window boundaries.
+// The estimate weights the previous window's count by how much of it still
+// overlaps the sliding window: prev*(1-elapsed/window) + cur.
+type SlidingWindowLimiter struct {
+	key     KeyFunc
+	metrics *rateLimitMetrics
+	limit   int
+	window  time.Duration
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	clients map[string]*windowCounter
+}
+
+type windowCounter struct {
+	start time.Time // start of the current fixed window
+	prev  int
+	cur   int
+}
+
+// NewSlidingWindowLimiter allows at most limit requests per window per IP.
+// Idle clients are forgotten after ttl (at least two windows).
+func NewSlidingWindowLimiter(limit int, window, ttl time.Duration) *SlidingWindowLimiter {
+	return NewKeyedSlidingWindowLimiter(KeyByIP, limit, window, ttl)
+}
+
+// NewKeyedSlidingWindowLimiter is NewSlidingWindowLimiter keyed by key (KeyByIP if nil).
+func NewKeyedSlidingWindowLimiter(key KeyFunc, limit int, window, ttl time.Duration) *SlidingWindowLimiter {
+	if key == nil {
+		key = KeyByIP
+	}
+	if ttl < 2*window {
+		ttl = 2 * window
+	}
+	return &SlidingWindowLimiter{
+		key:     key,
+		limit:   limit,
+		window:  window,
+		ttl:     ttl,
+		now:     time.Now,
+		clients: make(map[string]*windowCounter),
+	}
+}
+
+// Instrument records rejections and the tracked client count as OTel metrics under
+// service, like KeyedLimiter.Instrument. Call it before serving; it returns l.
+func (l *SlidingWindowLimiter) Instrument(service string) *SlidingWindowLimiter {
+	l.metrics = newRateLimitMetrics(service, func() int {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.clients)
+	})
+	return l
+}
+
+// SetLimit changes the limit and window for every key (e.g. on a config
+// reload). A new window drops the tracked counts, which were kept in windows
+// of the old length.
+func (l *SlidingWindowLimiter) SetLimit(limit int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	if window != l.window {
+		l.window = window
+		l.ttl = max(l.ttl, 2*window)
+		clear(l.clients)
+	}
+}
+
+// Allow records a request for key and reports whether it is within the limit.
+func (l *SlidingWindowLimiter) Allow(key string) bool {
+	ok, _ := l.allow(key)
+	return ok
+}
+
+type windowState struct {
+	limit      int
+	remaining  int
+	reset      time.Duration // until the current fixed window ends
+	retryAfter time.Duration // set when rejected
+}
+
+func (l *SlidingWindowLimiter) allow(key string) (bool, windowState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	// opportunistic cleanup
+	for k, c := range l.clients {
+		if now.Sub(c.start) > l.ttl {
+			delete(l.clients, k)
+		}
+	}
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &windowCounter{start: now.Truncate(l.window)}
+		l.clients[key] = c
+	}
+
+	// Roll the fixed windows forward.
+	if elapsed := now.Sub(c.start); elapsed >= l.window {
+		if elapsed < 2*l.window {
+			c.prev = c.cur
+		} else {
+			c.prev = 0
+		}
+		c.cur = 0
+		c.start = now.Truncate(l.window)
+	}
+
+	elapsed := now.Sub(c.start)
+	overlap := 1 - float64(elapsed)/float64(l.window)
+	estimate := float64(c.prev)*overlap + float64(c.cur)
+	st := windowState{limit: l.limit, reset: l.window - elapsed}
+	if estimate >= float64(l.limit) {
+		st.retryAfter = l.retryAfter(c, elapsed)
+		return false, st
+	}
+	c.cur++
+	st.remaining = int(float64(l.limit) - estimate - 1)
+	if st.remaining < 0 {
+		st.remaining = 0
+	}
+	return true, st
+}
+
+// retryAfter estimates when the previous window's weight decays enough to admit
+// one more request; if the current window alone is full, that is the next window.
+func (l *SlidingWindowLimiter) retryAfter(c *windowCounter, elapsed time.Duration) time.Duration {
+	toNext := l.window - elapsed
+	if c.cur >= l.limit || c.prev == 0 {
+		return toNext
+	}
+	// Solve prev*(1-t/window) + cur < limit for t.
+	need := 1 - float64(l.limit-c.cur)/float64(c.prev)
+	wait := time.Duration(need*float64(l.window)) - elapsed
+	if wait <= 0 || wait > toNext {
+		return toNext
+	}
+	return wait
+}
+
+func (l *SlidingWindowLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := l.key(r)
+		ok, st := l.allow(key)
+		setRateLimitHeaders(w, st.limit, st.remaining, st.reset, st.retryAfter)
+		if !ok {
+			l.metrics.reject(r.Context(), key)
+			writeRateLimited(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *SlidingWindowLimiter) Wrap(next http.Handler) http.Handler {
+	return l.Middleware(next)
+}
@@ -2,10 +2,29 @@ package httpmw
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
+
+	"sdk-microservices/internal/platform/logging"
+	"sdk-microservices/internal/platform/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
 )
 
+// TimeoutOptions configures TimeoutWith.
+type TimeoutOptions struct {
+	// Timeout is the injected per-request deadline; zero disables the middleware.
+	Timeout time.Duration
+	// Service names the meter for the http.server.timeouts counter; empty disables it.
+	Service string
+	// Log receives a Warn for every request cut off by the injected deadline.
+	Log *zap.Logger
+}
+
 // Timeout enforces a per-request deadline.
 //
 // It only applies a deadline if the request context does not already have one.
@@ -13,9 +32,22 @@ import (
 //
 // If the deadline is exceeded, a 504 is returned.
 func Timeout(d time.Duration, next http.Handler) http.Handler {
+	return TimeoutWith(TimeoutOptions{Timeout: d}, next)
+}
+
+// TimeoutWith is Timeout with observability: requests cut off by the injected
+// deadline are counted (http.server.timeouts, by method and route) and logged, so
+// gateway-imposed timeouts can be told apart from upstream slowness. Requests
+// running under an upstream deadline are not counted.
+func TimeoutWith(opts TimeoutOptions, next http.Handler) http.Handler {
+	d := opts.Timeout
 	if d <= 0 {
 		return next
 	}
+	var counter metric.Int64Counter
+	if opts.Service != "" {
+		counter = newTimeoutCounter(opts.Service)
+	}
 
 	// Use net/http's TimeoutHandler so we always return a response even if the
 	// downstream handler forgets to check ctx.Done().
@@ -30,5 +62,52 @@ func Timeout(d time.Duration, next http.Handler) http.Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), d)
 		defer cancel()
 		th.ServeHTTP(w, r.WithContext(ctx))
+
+		// Only our deadline firing counts; a client hang-up cancels the parent instead.
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) || r.Context().Err() != nil {
+			return
+		}
+		route, ok := metrics.Route(r.Context())
+		if !ok {
+			route = metrics.UnmatchedRoute
+		}
+		if counter != nil {
+			counter.Add(context.WithoutCancel(ctx), 1, metric.WithAttributes(
+				attribute.String("service.name", opts.Service),
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+			))
+		}
+		if opts.Log != nil {
+			lg := logging.WithTrace(r.Context(), opts.Log)
+			if rid, ok := logging.RequestID(r.Context()); ok {
+				lg = lg.With(zap.String("request_id", rid))
+			}
+			lg.Warn("request timed out",
+				zap.String("http.method", r.Method),
+				zap.String("http.route", route),
+				zap.String("http.path", r.URL.Path),
+				zap.Duration("timeout", d),
+			)
+		}
 	})
 }
+
+// WithTimeoutOptions adapts TimeoutWith(opts, next) into a Middleware.
+func WithTimeoutOptions(opts TimeoutOptions) Middleware {
+	return func(next http.Handler) http.Handler {
+		return TimeoutWith(opts, next)
+	}
+}
+
+func newTimeoutCounter(service string) metric.Int64Counter {
+	c, err := otel.Meter("sdk-microservices/"+service).Int64Counter(
+		"http.server.timeouts",
+		metric.WithDescription("HTTP requests cut off by the server-imposed deadline"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil
+	}
+	return c
+}
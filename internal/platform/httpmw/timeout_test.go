@@ -0,0 +1,42 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimeoutWith_LogsInjectedDeadline(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	h := TimeoutWith(TimeoutOptions{Timeout: 10 * time.Millisecond, Service: "test", Log: zap.New(core)},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	// http.TimeoutHandler answers 503.
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	entries := logs.FilterMessage("request timed out").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 timeout log, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()["http.route"]; got != "unmatched" {
+		t.Fatalf("http.route = %v, want unmatched", got)
+	}
+
+	// Requests under an upstream deadline are not cut off by us.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+	if n := logs.FilterMessage("request timed out").Len(); n != 1 {
+		t.Fatalf("expected upstream deadline not to be logged, got %d logs", n)
+	}
+}
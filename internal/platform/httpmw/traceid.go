@@ -0,0 +1,30 @@
+package httpmw
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Response headers carrying the request's trace and server span ids.
+const (
+	TraceIDHeader = "X-Trace-Id"
+	SpanIDHeader  = "X-Span-Id"
+)
+
+// TraceID echoes the active trace id (and the server span id) in the X-Trace-Id and
+// X-Span-Id response headers, so a user reporting a problem can quote an id that
+// engineers paste straight into the tracing UI. It needs a span in the context, so
+// it must run inside Wrap/WrapSampled; without one it does nothing.
+//
+// The ids are set even when the trace is not sampled: logs still carry trace_id.
+func TraceID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			h := w.Header()
+			h.Set(TraceIDHeader, sc.TraceID().String())
+			h.Set(SpanIDHeader, sc.SpanID().String())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
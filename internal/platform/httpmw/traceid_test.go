@@ -0,0 +1,37 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceID_SetsHeaders(t *testing.T) {
+	tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid})
+
+	h := TraceID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(trace.ContextWithSpanContext(req.Context(), sc))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get(TraceIDHeader); got != tid.String() {
+		t.Fatalf("%s = %q, want %q", TraceIDHeader, got, tid)
+	}
+	if got := rr.Header().Get(SpanIDHeader); got != sid.String() {
+		t.Fatalf("%s = %q, want %q", SpanIDHeader, got, sid)
+	}
+}
+
+func TestTraceID_NoSpan(t *testing.T) {
+	h := TraceID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rr.Header().Get(TraceIDHeader); got != "" {
+		t.Fatalf("unexpected %s %q without a span", TraceIDHeader, got)
+	}
+}
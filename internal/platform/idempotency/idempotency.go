@@ -0,0 +1,173 @@
+// Package idempotency stores the responses replayed by the HTTP
+// (httpmw.Idempotency) and gRPC (grpcutil.UnaryIdempotency) idempotency
+// middleware. Responses are opaque bytes; each middleware encodes its own.
+//
+// A key is first reserved while its request runs, then either completed with the
+// response or released so the request can be retried.
+package idempotency
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store persists responses keyed by idempotency key.
+type Store interface {
+	// Reserve claims key for lockTTL. It returns reserved=true if the caller now owns
+	// the key; otherwise resp is the completed response, or nil while another request
+	// still holds the key.
+	Reserve(ctx context.Context, key string, lockTTL time.Duration) (resp []byte, reserved bool, err error)
+	// Complete stores resp for key until ttl elapses.
+	Complete(ctx context.Context, key string, resp []byte, ttl time.Duration) error
+	// Release drops an in-progress reservation.
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryStore is an in-process Store. Expired entries are dropped in expiry
+// order (a min-heap), so no call scans every key.
+// NOTE: records are per instance; use RedisStore behind a load balancer.
+type MemoryStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	records map[string]memoryRecord
+	expiry  expiryHeap
+}
+
+type memoryRecord struct {
+	resp    []byte // nil while in progress
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{now: time.Now, records: make(map[string]memoryRecord)}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, key string, lockTTL time.Duration) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+	if r, ok := s.records[key]; ok {
+		return r.resp, false, nil
+	}
+	s.set(key, memoryRecord{expires: now.Add(lockTTL)})
+	return nil, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, resp []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resp == nil {
+		resp = []byte{}
+	}
+	s.set(key, memoryRecord{resp: resp, expires: s.now().Add(ttl)})
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[key]; ok && r.resp == nil {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) set(key string, r memoryRecord) {
+	s.records[key] = r
+	heap.Push(&s.expiry, expiryItem{key: key, expires: r.expires})
+}
+
+// expire drops records whose expiry has passed. Heap items left behind by a key
+// that was since rewritten or released no longer match its record and are skipped.
+func (s *MemoryStore) expire(now time.Time) {
+	for len(s.expiry) > 0 && !s.expiry[0].expires.After(now) {
+		it := heap.Pop(&s.expiry).(expiryItem)
+		if r, ok := s.records[it.key]; ok && r.expires.Equal(it.expires) {
+			delete(s.records, it.key)
+		}
+	}
+}
+
+type expiryItem struct {
+	key     string
+	expires time.Time
+}
+
+type expiryHeap []expiryItem
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)        { *h = append(*h, x.(expiryItem)) }
+func (h *expiryHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// RedisStore stores records in Redis so all replicas share them.
+// Reservations use SET NX, so only one replica runs a given key at a time.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "idempotency:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// pendingMarker is stored while a request is in progress. Completed responses
+// are prefixed with doneMarker so no response can be mistaken for it.
+const (
+	pendingMarker = "pending"
+	doneMarker    = "done:"
+)
+
+func (s *RedisStore) Reserve(ctx context.Context, key string, lockTTL time.Duration) ([]byte, bool, error) {
+	ok, err := s.client.SetNX(ctx, s.prefix+key, pendingMarker, lockTTL).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	b, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired between SETNX and GET; the caller polls or retries.
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) < len(doneMarker) || string(b[:len(doneMarker)]) != doneMarker {
+		return nil, false, nil
+	}
+	return b[len(doneMarker):], false, nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, resp []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, append([]byte(doneMarker), resp...), ttl).Err()
+}
+
+// releaseScript deletes the key only while it still holds the pending marker.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return releaseScript.Run(ctx, s.client, []string{s.prefix + key}, pendingMarker).Err()
+}
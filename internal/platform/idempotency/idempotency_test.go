@@ -0,0 +1,60 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	if _, reserved, _ := s.Reserve(ctx, "k", time.Minute); !reserved {
+		t.Fatal("first Reserve should own the key")
+	}
+	if resp, reserved, _ := s.Reserve(ctx, "k", time.Minute); reserved || resp != nil {
+		t.Fatalf("in-progress key: resp=%q reserved=%v", resp, reserved)
+	}
+	if err := s.Release(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, reserved, _ := s.Reserve(ctx, "k", time.Minute); !reserved {
+		t.Fatal("released key should be reservable")
+	}
+	if err := s.Complete(ctx, "k", []byte("resp"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Release(ctx, "k") // completed records survive Release
+	if resp, reserved, _ := s.Reserve(ctx, "k", time.Minute); reserved || string(resp) != "resp" {
+		t.Fatalf("completed key: resp=%q reserved=%v", resp, reserved)
+	}
+}
+
+func TestMemoryStore_ExpiresInOrder(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	_, _, _ = s.Reserve(ctx, "a", time.Second)
+	_ = s.Complete(ctx, "a", []byte("a"), time.Hour) // rewrites the lock's expiry
+	_, _, _ = s.Reserve(ctx, "b", time.Second)
+
+	now = now.Add(2 * time.Second)
+	_, _, _ = s.Reserve(ctx, "c", time.Second)
+	if _, ok := s.records["b"]; ok {
+		t.Fatal("expired lock not dropped")
+	}
+	if resp, _, _ := s.Reserve(ctx, "a", time.Second); string(resp) != "a" {
+		t.Fatal("stale heap entry dropped a completed record")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, reserved, _ := s.Reserve(ctx, "a", time.Second); !reserved {
+		t.Fatal("expired record should be reservable again")
+	}
+	if len(s.records) != 1 || len(s.expiry) != 1 {
+		t.Fatalf("records=%d heap=%d, want 1 each", len(s.records), len(s.expiry))
+	}
+}
@@ -0,0 +1,202 @@
+// Package jobs runs periodic background work (expired-row cleanup, retention,
+// archival) with jitter, per-run timeouts, optional cluster-wide singleton
+// execution and metrics, so services don't each hand-roll ticker goroutines.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// Job is one periodic task.
+type Job struct {
+	// Name identifies the job in logs, metrics and the cluster lock.
+	Name string
+	// Interval between the end of one run and the start of the next.
+	Interval time.Duration
+	// Jitter spreads each wait over this fraction of Interval around it (default
+	// 0.1, i.e. ±5%), so replicas started together don't hit the database in lockstep.
+	Jitter float64
+	// Timeout bounds one run (default Interval).
+	Timeout time.Duration
+	// Singleton runs the job on at most one instance at a time, using the
+	// Runner's Locker. Other instances skip that tick.
+	Singleton bool
+	// RunAtStart runs the job immediately instead of after the first Interval.
+	RunAtStart bool
+	Run        func(ctx context.Context) error
+}
+
+// Locker provides cluster-wide mutual exclusion for Singleton jobs.
+type Locker interface {
+	// TryLock acquires name without waiting. ok is false if another instance holds it.
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
+
+// Options configures NewRunner.
+type Options struct {
+	// Service names the meter (jobs.runs, jobs.duration) like other platform metrics.
+	Service string
+	Log     *zap.Logger
+	// Locker is required for Singleton jobs (e.g. PGLocker).
+	Locker Locker
+}
+
+// Runner runs registered jobs until its context ends.
+type Runner struct {
+	opts Options
+	jobs []Job
+	wg   sync.WaitGroup
+
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+	svc      attribute.KeyValue
+}
+
+// NewRunner returns a Runner; add jobs, then call Start.
+func NewRunner(opts Options) *Runner {
+	if opts.Log == nil {
+		opts.Log = zap.NewNop()
+	}
+	r := &Runner{opts: opts, svc: attribute.String("service.name", opts.Service)}
+	m := otel.Meter("sdk-microservices/" + opts.Service)
+	var err error
+	if r.runs, err = m.Int64Counter("jobs.runs",
+		metric.WithDescription("Background job runs by outcome (success, error, skipped)"),
+		metric.WithUnit("{run}"),
+	); err != nil {
+		opts.Log.Warn("jobs metrics disabled (init failed)", zap.Error(err))
+	}
+	if r.duration, err = m.Float64Histogram("jobs.duration",
+		metric.WithDescription("Background job run duration"),
+		metric.WithUnit("s"),
+	); err != nil {
+		opts.Log.Warn("jobs metrics disabled (init failed)", zap.Error(err))
+	}
+	return r
+}
+
+// Add registers j. It panics on an invalid job, as that is a programming error.
+func (r *Runner) Add(j Job) {
+	switch {
+	case j.Name == "" || j.Run == nil || j.Interval <= 0:
+		panic("jobs: Job needs Name, Run and a positive Interval")
+	case j.Singleton && r.opts.Locker == nil:
+		panic("jobs: Singleton job " + j.Name + " needs Options.Locker")
+	}
+	if j.Jitter <= 0 {
+		j.Jitter = 0.1
+	}
+	if j.Timeout <= 0 {
+		j.Timeout = j.Interval
+	}
+	r.jobs = append(r.jobs, j)
+}
+
+// Start runs every job in its own goroutine until ctx ends. Wait blocks until
+// they have returned.
+func (r *Runner) Start(ctx context.Context) {
+	for _, j := range r.jobs {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.loop(ctx, j)
+		}()
+	}
+}
+
+// Wait blocks until all jobs have stopped (after Start's context ends).
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, j Job) {
+	if j.RunAtStart {
+		r.runOnce(ctx, j)
+	}
+	for {
+		t := time.NewTimer(jittered(j.Interval, j.Jitter))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		r.runOnce(ctx, j)
+	}
+}
+
+// runOnce runs j once, recording its outcome. Panics are recovered and reported
+// as errors so one bad run doesn't kill the loop.
+func (r *Runner) runOnce(ctx context.Context, j Job) {
+	log := r.opts.Log.With(zap.String("job", j.Name))
+	if j.Singleton {
+		unlock, ok, err := r.opts.Locker.TryLock(ctx, j.Name)
+		if err != nil {
+			log.Warn("job lock failed", zap.Error(err))
+			r.record(ctx, j, "error", 0)
+			return
+		}
+		if !ok {
+			r.record(ctx, j, "skipped", 0)
+			return
+		}
+		defer unlock()
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, j.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := safeRun(runCtx, j.Run)
+	d := time.Since(start)
+	switch {
+	case err == nil:
+		r.record(ctx, j, "success", d)
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		// Shutting down; not a job failure.
+	default:
+		log.Warn("job failed", zap.Error(err), zap.Duration("duration", d))
+		r.record(ctx, j, "error", d)
+	}
+}
+
+func safeRun(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &panicError{p}
+		}
+	}()
+	return run(ctx)
+}
+
+type panicError struct{ v any }
+
+func (e *panicError) Error() string { return fmt.Sprintf("jobs: panic: %v", e.v) }
+
+func (r *Runner) record(ctx context.Context, j Job, outcome string, d time.Duration) {
+	attrs := metric.WithAttributes(r.svc, attribute.String("job.name", j.Name), attribute.String("outcome", outcome))
+	if r.runs != nil {
+		r.runs.Add(context.WithoutCancel(ctx), 1, attrs)
+	}
+	if r.duration != nil && outcome != "skipped" {
+		r.duration.Record(context.WithoutCancel(ctx), d.Seconds(), attrs)
+	}
+}
+
+// jittered returns d randomized by ±frac/2 of itself.
+func jittered(d time.Duration, frac float64) time.Duration {
+	span := time.Duration(float64(d) * frac)
+	if span <= 0 {
+		return d
+	}
+	return d - span/2 + rand.N(span)
+}
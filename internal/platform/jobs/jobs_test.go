@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type memLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func (l *memLocker) TryLock(_ context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[name] {
+		return nil, false, nil
+	}
+	l.held[name] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, name)
+	}, true, nil
+}
+
+func TestRunner(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(prev)
+
+	var ok, failing, panicking atomic.Int32
+	r := NewRunner(Options{Service: "test"})
+	r.Add(Job{Name: "ok", Interval: 5 * time.Millisecond, RunAtStart: true, Run: func(context.Context) error {
+		ok.Add(1)
+		return nil
+	}})
+	r.Add(Job{Name: "failing", Interval: 5 * time.Millisecond, Run: func(context.Context) error {
+		failing.Add(1)
+		return errors.New("boom")
+	}})
+	r.Add(Job{Name: "panicking", Interval: 5 * time.Millisecond, Run: func(context.Context) error {
+		panicking.Add(1)
+		panic("bad")
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	r.Wait()
+
+	if ok.Load() < 2 || failing.Load() < 2 || panicking.Load() < 2 {
+		t.Fatalf("runs: ok=%d failing=%d panicking=%d; jobs should keep running after errors and panics",
+			ok.Load(), failing.Load(), panicking.Load())
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	outcomes := map[string]string{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "jobs.runs" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				job, _ := dp.Attributes.Value(attribute.Key("job.name"))
+				outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+				outcomes[job.AsString()] = outcome.AsString()
+			}
+		}
+	}
+	if outcomes["ok"] != "success" || outcomes["failing"] != "error" || outcomes["panicking"] != "error" {
+		t.Fatalf("outcomes = %v", outcomes)
+	}
+}
+
+func TestRunner_Singleton(t *testing.T) {
+	locker := &memLocker{held: map[string]bool{}}
+	var running, maxRunning atomic.Int32
+	job := Job{Name: "sweep", Interval: time.Millisecond, Jitter: 0.01, Singleton: true, RunAtStart: true, Run: func(context.Context) error {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}}
+
+	// Two "replicas" sharing one lock.
+	ctx, cancel := context.WithCancel(context.Background())
+	var runners []*Runner
+	for range 2 {
+		r := NewRunner(Options{Service: "test", Locker: locker})
+		r.Add(job)
+		r.Start(ctx)
+		runners = append(runners, r)
+	}
+	time.Sleep(40 * time.Millisecond)
+	cancel()
+	for _, r := range runners {
+		r.Wait()
+	}
+	if maxRunning.Load() != 1 {
+		t.Fatalf("max concurrent runs = %d, want 1", maxRunning.Load())
+	}
+}
+
+func TestJittered(t *testing.T) {
+	for range 100 {
+		if d := jittered(time.Second, 0.2); d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("jittered = %v", d)
+		}
+	}
+}
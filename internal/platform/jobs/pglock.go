@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PGLocker returns a Locker backed by Postgres session advisory locks on pool, so
+// a Singleton job runs on one replica at a time. The lock is held on a dedicated
+// connection for the duration of the run and released if that connection drops.
+func PGLocker(pool *pgxpool.Pool) Locker {
+	return pgLocker{pool: pool}
+}
+
+type pgLocker struct {
+	pool *pgxpool.Pool
+}
+
+func (l pgLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("jobs: acquire: %w", err)
+	}
+	key := lockKey(name)
+	var ok bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("jobs: lock %s: %w", name, err)
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	return func() {
+		if _, err := conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// Don't return a connection that may still hold the lock to the pool.
+			_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+		conn.Release()
+	}, true, nil
+}
+
+// lockKey maps a job name to an advisory lock key.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("jobs:" + name))
+	return int64(h.Sum64())
+}
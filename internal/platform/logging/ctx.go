@@ -9,6 +9,28 @@ import (
 
 type ctxKey struct{}
 
+type requestIDKey struct{}
+
+// WithRequestID stores the request id in context so it can be propagated to downstream calls.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request id stored by WithRequestID, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	s, ok := ctx.Value(requestIDKey{}).(string)
+	if !ok || s == "" {
+		return "", false
+	}
+	return s, true
+}
+
 func With(ctx context.Context, l *zap.Logger) context.Context {
 	if l == nil {
 		l = zap.NewNop()
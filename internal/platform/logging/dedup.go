@@ -0,0 +1,173 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DedupOptions configures Dedup.
+type DedupOptions struct {
+	// Level selects the entries deduplicated (default ErrorLevel and above).
+	Level zapcore.LevelEnabler
+	// Window is the deduplication period per message (default 10s).
+	Window time.Duration
+	// Burst is how many identical entries are written per Window before the rest
+	// are suppressed (default 5).
+	Burst int
+	// MaxKeys bounds the distinct messages tracked at once (default 1000); entries
+	// beyond it pass through unthrottled.
+	MaxKeys int
+}
+
+// Dedup returns l with repeated identical entries (same level, message and caller)
+// rate-limited, protecting log pipelines during error storms such as a downed
+// database. Once a message's window ends, a Warn summarizes how many duplicates were
+// suppressed; pending summaries are also written on Sync.
+func Dedup(l *zap.Logger, opts DedupOptions) *zap.Logger {
+	if l == nil {
+		return zap.NewNop()
+	}
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return NewDedupCore(c, opts)
+	}))
+}
+
+// NewDedupCore wraps c as described by Dedup.
+func NewDedupCore(c zapcore.Core, opts DedupOptions) zapcore.Core {
+	if _, ok := c.(*dedupCore); ok {
+		return c
+	}
+	if opts.Level == nil {
+		opts.Level = zapcore.ErrorLevel
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Second
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = 5
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = 1000
+	}
+	return &dedupCore{Core: c, state: &dedupState{
+		opts: opts,
+		root: c,
+		now:  time.Now,
+		seen: make(map[dedupKey]*dedupEntry),
+	}}
+}
+
+type dedupKey struct {
+	level   zapcore.Level
+	message string
+	caller  string
+}
+
+type dedupEntry struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// dedupState is shared by a core and every core derived from it via With.
+type dedupState struct {
+	opts DedupOptions
+	root zapcore.Core
+	now  func() time.Time
+
+	nextSweep atomic.Int64 // unix nanos
+
+	mu   sync.Mutex
+	seen map[dedupKey]*dedupEntry
+}
+
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), state: c.state}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	c.state.flush(false)
+	if c.state.opts.Level.Enabled(ent.Level) && !c.state.allow(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *dedupCore) Sync() error {
+	c.state.flush(true)
+	return c.Core.Sync()
+}
+
+// allow records ent and reports whether it is within its key's burst.
+func (s *dedupState) allow(ent zapcore.Entry) bool {
+	key := dedupKey{level: ent.Level, message: ent.Message, caller: ent.Caller.String()}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.seen[key]
+	if !ok {
+		if len(s.seen) >= s.opts.MaxKeys {
+			return true
+		}
+		e = &dedupEntry{start: now}
+		s.seen[key] = e
+	}
+	e.count++
+	if e.count <= s.opts.Burst {
+		return true
+	}
+	e.suppressed++
+	return false
+}
+
+// flush drops expired keys (all keys if force) and writes a summary for each one
+// that suppressed entries. Sweeps run at most once per Window unless forced.
+func (s *dedupState) flush(force bool) {
+	now := s.now()
+	next := s.nextSweep.Load()
+	if !force && now.UnixNano() < next {
+		return
+	}
+	if !s.nextSweep.CompareAndSwap(next, now.Add(s.opts.Window).UnixNano()) && !force {
+		return // another goroutine is sweeping
+	}
+
+	s.mu.Lock()
+	type summary struct {
+		key        dedupKey
+		suppressed int
+	}
+	var out []summary
+	for k, e := range s.seen {
+		if !force && now.Sub(e.start) < s.opts.Window {
+			continue
+		}
+		if e.suppressed > 0 {
+			out = append(out, summary{k, e.suppressed})
+		}
+		delete(s.seen, k)
+	}
+	s.mu.Unlock()
+
+	for _, sum := range out {
+		ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: now, Message: "suppressed duplicate log entries"}
+		if ce := s.root.Check(ent, nil); ce != nil {
+			ce.Write(
+				zap.String("suppressed_message", sum.key.message),
+				zap.String("suppressed_level", sum.key.level.String()),
+				zap.String("suppressed_caller", sum.key.caller),
+				zap.Int("suppressed", sum.suppressed),
+			)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupCore(t *testing.T) {
+	obs, logs := observer.New(zap.DebugLevel)
+	core := NewDedupCore(obs, DedupOptions{Window: time.Minute, Burst: 2}).(*dedupCore)
+	now := time.Unix(1000, 0)
+	core.state.now = func() time.Time { return now }
+	log := zap.New(core).With(zap.String("component", "db"))
+
+	for i := 0; i < 10; i++ {
+		log.Error("db down")
+		log.Info("not deduplicated")
+	}
+	log.Error("other error")
+
+	if n := logs.FilterMessage("db down").Len(); n != 2 {
+		t.Fatalf("db down logged %d times, want 2", n)
+	}
+	if n := logs.FilterMessage("not deduplicated").Len(); n != 10 {
+		t.Fatalf("info logged %d times, want 10", n)
+	}
+	if n := logs.FilterMessage("other error").Len(); n != 1 {
+		t.Fatalf("other error logged %d times, want 1", n)
+	}
+
+	// The next entry after the window triggers the summary.
+	now = now.Add(time.Minute)
+	log.Warn("tick")
+	sums := logs.FilterMessage("suppressed duplicate log entries").All()
+	if len(sums) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(sums))
+	}
+	if got := sums[0].ContextMap()["suppressed"]; got != int64(8) {
+		t.Fatalf("suppressed = %v, want 8", got)
+	}
+	if sums[0].Level != zapcore.WarnLevel {
+		t.Fatalf("summary level = %v", sums[0].Level)
+	}
+
+	// A fresh window logs the message again.
+	log.Error("db down")
+	if n := logs.FilterMessage("db down").Len(); n != 3 {
+		t.Fatalf("db down logged %d times after window, want 3", n)
+	}
+}
@@ -1,9 +1,133 @@
 package logging
 
-import "go.uber.org/zap"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Options configures NewWith. The zero value is zap's production logger.
+type Options struct {
+	// Level is the minimum level: debug, info, warn, error, dpanic, panic or fatal
+	// (default info).
+	Level string
+	// Format is "json" (default) or "console".
+	Format string
+
+	// SampleInitial and SampleThereafter configure zap's per-second sampling: the
+	// first SampleInitial entries with the same level and message are logged, then
+	// every SampleThereafter-th. Zero keeps zap's defaults (100/100); a negative
+	// SampleInitial disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+
+	// DisableCaller omits the caller (file:line) annotation.
+	DisableCaller bool
+	// DisableStacktrace omits stack traces, which are otherwise added at Error and above.
+	DisableStacktrace bool
+
+	// OutputPaths are zap sink URLs or file paths (default stderr).
+	OutputPaths []string
+}
+
+// OptionsFromEnv reads the logger settings:
+//   - LOG_LEVEL: minimum level (default info)
+//   - LOG_FORMAT: json or console
+//   - LOG_SAMPLING_INITIAL / LOG_SAMPLING_THEREAFTER: sampling; LOG_SAMPLING_INITIAL=-1 disables it
+//   - LOG_CALLER / LOG_STACKTRACE: "false" disables caller annotations / stack traces
+//   - LOG_OUTPUT: comma-separated output paths (e.g. "stdout" or "/var/log/svc.log")
+func OptionsFromEnv() Options {
+	o := Options{
+		Level:  strings.TrimSpace(os.Getenv("LOG_LEVEL")),
+		Format: strings.TrimSpace(os.Getenv("LOG_FORMAT")),
+	}
+	o.SampleInitial, _ = strconv.Atoi(strings.TrimSpace(os.Getenv("LOG_SAMPLING_INITIAL")))
+	o.SampleThereafter, _ = strconv.Atoi(strings.TrimSpace(os.Getenv("LOG_SAMPLING_THEREAFTER")))
+	if v, err := strconv.ParseBool(os.Getenv("LOG_CALLER")); err == nil {
+		o.DisableCaller = !v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("LOG_STACKTRACE")); err == nil {
+		o.DisableStacktrace = !v
+	}
+	for _, p := range strings.Split(os.Getenv("LOG_OUTPUT"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			o.OutputPaths = append(o.OutputPaths, p)
+		}
+	}
+	return o
+}
+
+// New returns the logger for service configured from the environment (see
+// OptionsFromEnv). Repeated identical errors are deduplicated (see Dedup).
 func New(service string) (*zap.Logger, error) {
+	return NewWith(service, OptionsFromEnv())
+}
+
+// NewWith returns the logger for service configured by opts. Repeated identical
+// errors are deduplicated (see Dedup).
+func NewWith(service string, opts Options) (*zap.Logger, error) {
+	log, _, err := NewLeveled(service, opts)
+	return log, err
+}
+
+// NewLeveled is NewWith that also returns the logger's level, which can be
+// changed while the service runs (e.g. on a config reload).
+func NewLeveled(service string, opts Options) (*zap.Logger, zap.AtomicLevel, error) {
 	cfg := zap.NewProductionConfig()
 	cfg.InitialFields = map[string]any{"service": service}
-	return cfg.Build()
+
+	if opts.Level != "" {
+		lvl, err := zap.ParseAtomicLevel(opts.Level)
+		if err != nil {
+			return nil, cfg.Level, fmt.Errorf("logging: level: %w", err)
+		}
+		cfg.Level = lvl
+	}
+	switch opts.Format {
+	case "", "json":
+	case "console":
+		cfg.Encoding = "console"
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	default:
+		return nil, cfg.Level, fmt.Errorf("logging: unknown format %q (want json or console)", opts.Format)
+	}
+
+	switch {
+	case opts.SampleInitial < 0:
+		cfg.Sampling = nil
+	case opts.SampleInitial > 0 || opts.SampleThereafter > 0:
+		if opts.SampleInitial > 0 {
+			cfg.Sampling.Initial = opts.SampleInitial
+		}
+		if opts.SampleThereafter > 0 {
+			cfg.Sampling.Thereafter = opts.SampleThereafter
+		}
+	}
+
+	cfg.DisableCaller = opts.DisableCaller
+	cfg.DisableStacktrace = opts.DisableStacktrace
+	if len(opts.OutputPaths) > 0 {
+		cfg.OutputPaths = opts.OutputPaths
+	}
+
+	log, err := cfg.Build(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return NewDedupCore(c, DedupOptions{})
+	}))
+	return log, cfg.Level, err
+}
+
+// SetLevel changes lvl to level (as in Options.Level); "" leaves it unchanged.
+func SetLevel(lvl zap.AtomicLevel, level string) error {
+	if level == "" {
+		return nil
+	}
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("logging: level: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWith_LevelFormatAndOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.log")
+	lg, err := NewWith("svc", Options{Level: "debug", Format: "console", OutputPaths: []string{path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Debug("hello debug")
+	_ = lg.Sync()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	if !strings.Contains(out, "DEBUG") || !strings.Contains(out, "hello debug") {
+		t.Fatalf("console debug entry missing: %q", out)
+	}
+	if strings.HasPrefix(strings.TrimSpace(out), "{") {
+		t.Fatalf("expected console encoding, got JSON: %q", out)
+	}
+}
+
+func TestNewWith_InvalidOptions(t *testing.T) {
+	if _, err := NewWith("svc", Options{Level: "loud"}); err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+	if _, err := NewWith("svc", Options{Format: "xml"}); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "console")
+	t.Setenv("LOG_SAMPLING_INITIAL", "-1")
+	t.Setenv("LOG_CALLER", "false")
+	t.Setenv("LOG_OUTPUT", "stdout, /tmp/x.log")
+
+	o := OptionsFromEnv()
+	if o.Level != "warn" || o.Format != "console" || o.SampleInitial != -1 || !o.DisableCaller || o.DisableStacktrace {
+		t.Fatalf("unexpected options: %+v", o)
+	}
+	if len(o.OutputPaths) != 2 || o.OutputPaths[1] != "/tmp/x.log" {
+		t.Fatalf("OutputPaths = %v", o.OutputPaths)
+	}
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// PII field helpers keep personal data out of plaintext logs while leaving enough
+// to correlate entries: emails and IPs are partially masked, tokens are hashed.
+
+// Email logs email with the local part masked: "jane.doe@example.com" becomes
+// "j***@example.com".
+func Email(key, email string) zap.Field {
+	return zap.String(key, MaskEmail(email))
+}
+
+// IP logs ip truncated to its network: the last octet of IPv4 addresses and all
+// but the /48 prefix of IPv6 addresses are zeroed.
+func IP(key, ip string) zap.Field {
+	return zap.String(key, MaskIP(ip))
+}
+
+// Token logs a short SHA-256 fingerprint of tok ("sha256:1a2b3c4d5e6f"), so the
+// same token can be recognized across entries without being recoverable. Use a
+// key the Redactor doesn't cover (e.g. "token.fingerprint"), or it is redacted.
+func Token(key, tok string) zap.Field {
+	return zap.String(key, HashValue(tok))
+}
+
+// MaskEmail returns email with all but the first character of the local part
+// replaced. Values that are not an email are fully redacted.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(strings.TrimSpace(email), "@")
+	if !ok || local == "" || domain == "" {
+		if email == "" {
+			return ""
+		}
+		return Redacted
+	}
+	return local[:1] + "***@" + domain
+}
+
+// MaskIP returns ip with its host bits zeroed (see IP). Values that are not an IP
+// are fully redacted.
+func MaskIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Redacted
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	p, _ := addr.Prefix(bits)
+	return p.Addr().String()
+}
+
+// HashValue returns a short, stable SHA-256 fingerprint of v ("" for "").
+func HashValue(v string) string {
+	if v == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(v))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
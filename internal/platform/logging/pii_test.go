@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskEmail(t *testing.T) {
+	cases := map[string]string{
+		"jane.doe@example.com": "j***@example.com",
+		"a@b.io":               "a***@b.io",
+		"not-an-email":         Redacted,
+		"":                     "",
+	}
+	for in, want := range cases {
+		if got := MaskEmail(in); got != want {
+			t.Errorf("MaskEmail(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMaskIP(t *testing.T) {
+	cases := map[string]string{
+		"203.0.113.42":        "203.0.113.0",
+		"::ffff:203.0.113.42": "203.0.113.0",
+		"2001:db8:abcd:12::1": "2001:db8:abcd::",
+		"garbage":             Redacted,
+		"":                    "",
+	}
+	for in, want := range cases {
+		if got := MaskIP(in); got != want {
+			t.Errorf("MaskIP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHashValue(t *testing.T) {
+	a, b := HashValue("tok-1"), HashValue("tok-2")
+	if !strings.HasPrefix(a, "sha256:") || a == b || a != HashValue("tok-1") {
+		t.Fatalf("HashValue not a stable fingerprint: %q, %q", a, b)
+	}
+	if strings.Contains(a, "tok-1") {
+		t.Fatalf("HashValue leaks input: %q", a)
+	}
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the value of any sensitive field.
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveKeys are header names and log field keys that never reach a log sink.
+var DefaultSensitiveKeys = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"password",
+	"secret",
+	"token",
+	"access_token",
+	"refresh_token",
+	"id_token",
+}
+
+// DefaultRedactor redacts DefaultSensitiveKeys.
+var DefaultRedactor = NewRedactor(DefaultSensitiveKeys...)
+
+// Redactor masks sensitive log fields by key (a denylist).
+//
+// Keys match case-insensitively, with '_' and '-' treated alike, either exactly
+// or as the last dotted segment, so "authorization" also covers
+// "http.request.header.authorization".
+type Redactor struct {
+	keys map[string]struct{}
+}
+
+// NewRedactor returns a Redactor for the given header names / field keys.
+func NewRedactor(keys ...string) *Redactor {
+	r := &Redactor{keys: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		if k = normalizeKey(k); k != "" {
+			r.keys[k] = struct{}{}
+		}
+	}
+	return r
+}
+
+func normalizeKey(k string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(k)), "_", "-")
+}
+
+// Sensitive reports whether values under key must be redacted.
+func (r *Redactor) Sensitive(key string) bool {
+	if r == nil || len(r.keys) == 0 {
+		return false
+	}
+	k := normalizeKey(key)
+	if _, ok := r.keys[k]; ok {
+		return true
+	}
+	if i := strings.LastIndexByte(k, '.'); i >= 0 {
+		_, ok := r.keys[k[i+1:]]
+		return ok
+	}
+	return false
+}
+
+// Logger returns l with sensitive fields redacted, including fields added later via With.
+// A logger that already redacts keeps its existing Redactor.
+func (r *Redactor) Logger(l *zap.Logger) *zap.Logger {
+	if l == nil {
+		l = zap.NewNop()
+	}
+	if r == nil {
+		return l
+	}
+	return l.WithOptions(zap.WrapCore(r.Core))
+}
+
+// Core wraps c so sensitive fields are replaced before they are encoded.
+func (r *Redactor) Core(c zapcore.Core) zapcore.Core {
+	if _, ok := c.(*redactCore); ok || r == nil {
+		return c
+	}
+	return &redactCore{Core: c, r: r}
+}
+
+type redactCore struct {
+	zapcore.Core
+	r *Redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.fields(fields)), r: c.r}
+}
+
+// Check lets the wrapped core decide (sampling, dedup, error reporting all
+// happen in Check) and hands whatever it selected to a redactingEntry, so the
+// fields are redacted before any of its cores writes them.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	inner := c.Core.Check(ent, nil)
+	if inner == nil {
+		return ce
+	}
+	return ce.AddCore(ent, &redactingEntry{ce: inner, r: c.r})
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.r.fields(fields))
+}
+
+// redactingEntry is a one-shot core writing to the cores the wrapped core's
+// Check selected, with sensitive fields redacted.
+type redactingEntry struct {
+	ce *zapcore.CheckedEntry
+	r  *Redactor
+}
+
+func (e *redactingEntry) Enabled(zapcore.Level) bool        { return true }
+func (e *redactingEntry) With([]zapcore.Field) zapcore.Core { return e }
+func (e *redactingEntry) Sync() error                       { return nil }
+func (e *redactingEntry) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, e)
+}
+
+func (e *redactingEntry) Write(_ zapcore.Entry, fields []zapcore.Field) error {
+	e.ce.Write(e.r.fields(fields)...)
+	return nil
+}
+
+// fields returns fields with sensitive entries replaced, copying only when needed.
+func (r *Redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		if !r.Sensitive(f.Key) {
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+		}
+		out[i] = zap.String(f.Key, Redacted)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
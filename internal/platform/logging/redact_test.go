@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactor_Logger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	lg := DefaultRedactor.Logger(zap.New(core)).With(zap.String("Authorization", "Bearer abc"))
+
+	lg.Info("http",
+		zap.String("http.request.header.cookie", "sid=1"),
+		zap.String("refresh-token", "r1"),
+		zap.String("user_agent", "curl"),
+	)
+
+	got := logs.All()[0].ContextMap()
+	for _, k := range []string{"Authorization", "http.request.header.cookie", "refresh-token"} {
+		if got[k] != Redacted {
+			t.Fatalf("expected %s to be redacted, got %v", k, got[k])
+		}
+	}
+	if got["user_agent"] != "curl" {
+		t.Fatalf("expected user_agent to be kept, got %v", got["user_agent"])
+	}
+}
+
+func TestRedactor_KeepsExistingRedactor(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	custom := NewRedactor("x-tenant-secret")
+	lg := DefaultRedactor.Logger(custom.Logger(zap.New(core)))
+
+	lg.Info("http", zap.String("X-Tenant-Secret", "s"), zap.String("authorization", "a"))
+
+	got := logs.All()[0].ContextMap()
+	if got["X-Tenant-Secret"] != Redacted {
+		t.Fatalf("expected custom key to be redacted, got %v", got["X-Tenant-Secret"])
+	}
+	if got["authorization"] != "a" {
+		t.Fatalf("expected outer redactor to be ignored, got %v", got["authorization"])
+	}
+}
+
+func TestRedactor_KeepsWrappedCoreCheck(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	sampled := zapcore.NewSamplerWithOptions(core, time.Minute, 1, 0)
+	lg := DefaultRedactor.Logger(zap.New(sampled))
+
+	for i := 0; i < 5; i++ {
+		lg.Info("login", zap.String("token", "t1"))
+	}
+
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("sampler bypassed: %d entries written, want 1", n)
+	}
+	if got := logs.All()[0].ContextMap()["token"]; got != Redacted {
+		t.Fatalf("expected token to be redacted, got %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/exp/zapslog"
+	"go.uber.org/zap/zapcore"
+)
+
+// Slog returns a *slog.Logger that writes through the contextual zap logger (see
+// With/From), so code standardized on log/slog shares the same sinks, redaction,
+// dedup and error reporting. Records carry trace_id/span_id from ctx, else from
+// the context they are logged with.
+func Slog(ctx context.Context) *slog.Logger {
+	core := From(ctx, nil).Core()
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.IsValid() {
+		// Bound on the core so they stay top-level under any WithGroup.
+		core = core.With(traceFields(sc))
+	}
+	return slog.New(&traceHandler{Handler: zapslog.NewHandler(core, zapslog.WithCaller(true)), bound: sc.IsValid()})
+}
+
+// NewSlog returns a *slog.Logger writing through l.
+func NewSlog(l *zap.Logger) *slog.Logger {
+	if l == nil {
+		l = zap.NewNop()
+	}
+	return slog.New(&traceHandler{Handler: zapslog.NewHandler(l.Core(), zapslog.WithCaller(true))})
+}
+
+func traceFields(sc trace.SpanContext) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// traceHandler adds the trace of the context a record is logged with, unless
+// the logger was already bound to one by Slog. Those attrs follow any open
+// group, like the record's own.
+type traceHandler struct {
+	slog.Handler
+	bound bool
+}
+
+func (h *traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); !h.bound && sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs), bound: h.bound}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name), bound: h.bound}
+}
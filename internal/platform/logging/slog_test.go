@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlog_WritesThroughContextLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+	ctx = With(ctx, zap.New(core))
+
+	lg := Slog(ctx).With("component", "cache").WithGroup("req")
+	lg.Debug("dropped")
+	lg.Warn("slow lookup", "key", "k1", "attempts", 3, "err", errors.New("timeout"))
+
+	if logs.Len() != 1 {
+		t.Fatalf("got %d entries, want 1 (debug must be filtered)", logs.Len())
+	}
+	e := logs.All()[0]
+	if e.Level != zapcore.WarnLevel || e.Message != "slow lookup" {
+		t.Fatalf("entry = %v %q", e.Level, e.Message)
+	}
+	m := e.ContextMap()
+	if m["trace_id"] != tid.String() || m["component"] != "cache" {
+		t.Fatalf("missing trace/bound fields: %v", m)
+	}
+	req, ok := m["req"].(map[string]any)
+	if !ok || req["key"] != "k1" || req["attempts"] != int64(3) || req["err"] != "timeout" {
+		t.Fatalf("group fields = %v", m["req"])
+	}
+}
+
+func TestSlog_NestsGroupsAndAttrs(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	lg := NewSlog(zap.New(core)).
+		With("a", 1).
+		WithGroup("g1").
+		With("b", 2).
+		WithGroup("g2").
+		WithGroup("empty")
+	lg.Info("nested", "c", 3, slog.Group("inline", "d", 4), slog.Group("", "e", 5))
+	lg.WithGroup("unused").Info("no attrs")
+
+	if logs.Len() != 2 {
+		t.Fatalf("got %d entries, want 2", logs.Len())
+	}
+	m := logs.All()[0].ContextMap()
+	if m["a"] != int64(1) {
+		t.Fatalf("top-level attr = %v", m)
+	}
+	g1, ok := m["g1"].(map[string]any)
+	if !ok || g1["b"] != int64(2) {
+		t.Fatalf("g1 = %v", m["g1"])
+	}
+	g2, ok := g1["g2"].(map[string]any)
+	if !ok {
+		t.Fatalf("g2 missing from %v", g1)
+	}
+	empty, ok := g2["empty"].(map[string]any)
+	if !ok || empty["c"] != int64(3) || empty["e"] != int64(5) {
+		t.Fatalf("innermost group = %v", g2["empty"])
+	}
+	if inline, ok := empty["inline"].(map[string]any); !ok || inline["d"] != int64(4) {
+		t.Fatalf("inline group = %v", empty["inline"])
+	}
+
+	// Groups without attrs leave no empty objects behind.
+	m = logs.All()[1].ContextMap()
+	if g1, ok := m["g1"].(map[string]any); !ok || len(g1) != 1 {
+		t.Fatalf("unused groups = %v", m)
+	}
+}
+
+func TestNewSlog_TraceFromRecordContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+
+	NewSlog(zap.New(core)).InfoContext(ctx, "traced")
+	if m := logs.All()[0].ContextMap(); m["trace_id"] != tid.String() || m["span_id"] != sid.String() {
+		t.Fatalf("fields = %v", m)
+	}
+}
+
+func TestNewSlog_NilLogger(t *testing.T) {
+	NewSlog(nil).Info("no panic", slog.String("k", "v"))
+}
@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"net/netip"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// OverflowValue replaces label values past a LabelGuard's per-key limit.
+const OverflowValue = "other"
+
+// LabelGuardOptions configures NewLabelGuard.
+type LabelGuardOptions struct {
+	// MaxValues caps distinct values per attribute key (default 100). Values first
+	// seen after the cap is reached are reported as OverflowValue.
+	MaxValues int
+	// KeepRaw skips normalization (see Normalize) and only applies the cap.
+	KeepRaw bool
+}
+
+// LabelGuard protects custom metrics from label explosions, e.g. when a raw path,
+// user id or email ends up as an attribute by mistake. Pass attributes through it
+// before recording:
+//
+//	counter.Add(ctx, 1, guard.Option(attribute.String("tenant", tenant)))
+//
+// String values are normalized first, then each key admits at most MaxValues
+// distinct values for the life of the guard. Share one guard per instrument (or
+// per service) so the cap bounds what Prometheus sees.
+type LabelGuard struct {
+	max     int
+	keepRaw bool
+
+	mu   sync.Mutex
+	seen map[attribute.Key]map[string]struct{}
+}
+
+// NewLabelGuard returns a LabelGuard.
+func NewLabelGuard(opts LabelGuardOptions) *LabelGuard {
+	if opts.MaxValues <= 0 {
+		opts.MaxValues = 100
+	}
+	return &LabelGuard{
+		max:     opts.MaxValues,
+		keepRaw: opts.KeepRaw,
+		seen:    make(map[attribute.Key]map[string]struct{}),
+	}
+}
+
+// Attributes returns kvs with string values normalized and capped. Non-string
+// attributes pass through unchanged.
+func (g *LabelGuard) Attributes(kvs ...attribute.KeyValue) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, len(kvs))
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, kv := range kvs {
+		if kv.Value.Type() != attribute.STRING {
+			out[i] = kv
+			continue
+		}
+		v := kv.Value.AsString()
+		if !g.keepRaw {
+			v = Normalize(v)
+		}
+		out[i] = kv.Key.String(g.admit(kv.Key, v))
+	}
+	return out
+}
+
+// Option is Attributes as a measurement option.
+func (g *LabelGuard) Option(kvs ...attribute.KeyValue) metric.MeasurementOption {
+	return metric.WithAttributes(g.Attributes(kvs...)...)
+}
+
+// admit returns v if key may carry it, else OverflowValue. Callers hold g.mu.
+func (g *LabelGuard) admit(key attribute.Key, v string) string {
+	vals := g.seen[key]
+	if vals == nil {
+		vals = make(map[string]struct{})
+		g.seen[key] = vals
+	}
+	if _, ok := vals[v]; ok {
+		return v
+	}
+	if len(vals) >= g.max {
+		return OverflowValue
+	}
+	vals[v] = struct{}{}
+	return v
+}
+
+// Normalize replaces obviously unbounded label values with a placeholder: emails
+// ("{email}"), IP addresses ("{ip}"), numbers ("{id}"), UUIDs ("{uuid}") and long
+// hex strings or tokens ("{hex}"). Paths are normalized per segment, so
+// "/users/42/orders" becomes "/users/{id}/orders"; the query string is dropped.
+func Normalize(v string) string {
+	if strings.HasPrefix(v, "/") {
+		v, _, _ = strings.Cut(v, "?")
+		segs := strings.Split(v, "/")
+		for i, s := range segs {
+			segs[i] = normalizeSegment(s)
+		}
+		return strings.Join(segs, "/")
+	}
+	return normalizeSegment(v)
+}
+
+func normalizeSegment(s string) string {
+	switch {
+	case s == "":
+		return s
+	case strings.Contains(s, "@") && strings.Contains(s[strings.IndexByte(s, '@'):], "."):
+		return "{email}"
+	case isDigits(s):
+		return "{id}"
+	case isUUID(s):
+		return "{uuid}"
+	case len(s) >= 16 && isHex(s):
+		return "{hex}"
+	}
+	if _, err := netip.ParseAddr(s); err == nil {
+		return "{ip}"
+	}
+	return s
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID matches the canonical 8-4-4-4-12 form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i : i+1]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"/users/42/orders", "/users/{id}/orders"},
+		{"/v1/items/3f2b9c1e-8a7d-4c6b-9e5f-0a1b2c3d4e5f?x=1", "/v1/items/{uuid}"},
+		{"jane@example.com", "{email}"},
+		{"10.0.0.7", "{ip}"},
+		{"deadbeefdeadbeefdeadbeef", "{hex}"},
+		{"checkout", "checkout"},
+		{"/healthz", "/healthz"},
+	}
+	for _, c := range cases {
+		if got := Normalize(c.in); got != c.want {
+			t.Errorf("Normalize(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestLabelGuard_CapsDistinctValues(t *testing.T) {
+	g := NewLabelGuard(LabelGuardOptions{MaxValues: 2})
+	value := func(v string) string {
+		return g.Attributes(attribute.String("tenant", v))[0].Value.AsString()
+	}
+
+	if value("a") != "a" || value("b") != "b" {
+		t.Fatalf("values under the cap must pass through")
+	}
+	if got := value("c"); got != OverflowValue {
+		t.Fatalf("value past the cap = %q, want %q", got, OverflowValue)
+	}
+	if value("a") != "a" {
+		t.Fatalf("already admitted values must keep passing through")
+	}
+	// Caps are per key.
+	if got := g.Attributes(attribute.String("region", "eu"))[0].Value.AsString(); got != "eu" {
+		t.Fatalf("other key = %q, want eu", got)
+	}
+	// Non-string attributes are left alone.
+	if got := g.Attributes(attribute.Int("shard", 7))[0].Value.AsInt64(); got != 7 {
+		t.Fatalf("int attribute = %d, want 7", got)
+	}
+}
@@ -19,6 +19,11 @@ type GRPCServerMetrics struct {
 	inflight metric.Int64UpDownCounter
 	errors   metric.Int64Counter
 	latency  metric.Float64Histogram
+
+	// Streams are measured separately: a long-lived stream's lifetime says nothing
+	// about request latency, so it must not land in rpc.server.duration.
+	streamDuration metric.Float64Histogram
+	streamMessages metric.Int64Counter
 }
 
 func NewGRPCServerMetrics(service string) (*GRPCServerMetrics, error) {
@@ -51,11 +56,31 @@ func NewGRPCServerMetrics(service string) (*GRPCServerMetrics, error) {
 		return nil, err
 	}
 
+	streamDuration, err := m.Float64Histogram(
+		"rpc.server.stream.duration",
+		metric.WithDescription("RPC server stream lifetime (open to close)"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	streamMessages, err := m.Int64Counter(
+		"rpc.server.stream.messages",
+		metric.WithDescription("Stream messages by direction (sent/received)"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GRPCServerMetrics{
-		service:  service,
-		inflight: inflight,
-		errors:   errors,
-		latency:  latency,
+		service:        service,
+		inflight:       inflight,
+		errors:         errors,
+		latency:        latency,
+		streamDuration: streamDuration,
+		streamMessages: streamMessages,
 	}, nil
 }
 
@@ -117,13 +142,18 @@ func (g *GRPCServerMetrics) StreamServerInterceptor() grpc.StreamServerIntercept
 		g.inflight.Add(ctx, 1, metric.WithAttributes(base...))
 		defer g.inflight.Add(ctx, -1, metric.WithAttributes(base...))
 
-		err := handler(srv, ss)
+		err := handler(srv, &countingServerStream{
+			ServerStream: ss,
+			counter:      g.streamMessages,
+			sent:         metric.WithAttributes(append(base, attribute.String("rpc.direction", "sent"))...),
+			received:     metric.WithAttributes(append(base, attribute.String("rpc.direction", "received"))...),
+		})
 
 		st := status.Convert(err)
 		code := st.Code().String()
 		attrs := append(base, attribute.String("rpc.code", code))
 
-		g.latency.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+		g.streamDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
 		if st.Code().String() != "OK" {
 			g.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
 		}
@@ -132,6 +162,30 @@ func (g *GRPCServerMetrics) StreamServerInterceptor() grpc.StreamServerIntercept
 	}
 }
 
+// countingServerStream counts successfully sent/received stream messages.
+type countingServerStream struct {
+	grpc.ServerStream
+	counter  metric.Int64Counter
+	sent     metric.AddOption
+	received metric.AddOption
+}
+
+func (s *countingServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.counter.Add(s.Context(), 1, s.sent)
+	}
+	return err
+}
+
+func (s *countingServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.counter.Add(s.Context(), 1, s.received)
+	}
+	return err
+}
+
 // lowCardMethod turns "/pkg.Service/Method" into "Service/Method" to keep labels sane.
 func lowCardMethod(full string) string {
 	full = strings.TrimPrefix(full, "/")
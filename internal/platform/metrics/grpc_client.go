@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCClientMetrics provides low-cardinality gRPC client metrics, labelled by the
+// downstream (target) service so callers' view of their dependencies shows up next
+// to the servers' own view.
+type GRPCClientMetrics struct {
+	service string
+
+	inflight metric.Int64UpDownCounter
+	errors   metric.Int64Counter
+	latency  metric.Float64Histogram
+}
+
+func NewGRPCClientMetrics(service string) (*GRPCClientMetrics, error) {
+	m := otel.Meter("sdk-microservices/" + service)
+
+	inflight, err := m.Int64UpDownCounter(
+		"rpc.client.inflight",
+		metric.WithDescription("In-flight outgoing RPCs"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	errors, err := m.Int64Counter(
+		"rpc.client.errors",
+		metric.WithDescription("Outgoing RPC errors (non-OK)"),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	latency, err := m.Float64Histogram(
+		"rpc.client.duration",
+		metric.WithDescription("RPC client duration"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCClientMetrics{
+		service:  service,
+		inflight: inflight,
+		errors:   errors,
+		latency:  latency,
+	}, nil
+}
+
+// UnaryClientInterceptor records metrics for unary calls to the named target service.
+func (g *GRPCClientMetrics) UnaryClientInterceptor(target string) grpc.UnaryClientInterceptor {
+	if g == nil {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+
+		base := g.baseAttrs(target, method, false)
+		g.inflight.Add(ctx, 1, metric.WithAttributes(base...))
+		defer g.inflight.Add(ctx, -1, metric.WithAttributes(base...))
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		g.record(ctx, base, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor records metrics for streams to the named target service.
+// Duration covers stream establishment only; see StreamServerInterceptor for lifetimes.
+func (g *GRPCClientMetrics) StreamClientInterceptor(target string) grpc.StreamClientInterceptor {
+	if g == nil {
+		return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+	}
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+
+		base := g.baseAttrs(target, method, true)
+		g.inflight.Add(ctx, 1, metric.WithAttributes(base...))
+		defer g.inflight.Add(ctx, -1, metric.WithAttributes(base...))
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		g.record(ctx, base, start, err)
+		return cs, err
+	}
+}
+
+func (g *GRPCClientMetrics) baseAttrs(target, method string, stream bool) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("service.name", g.service),
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.target_service", target),
+		attribute.String("rpc.method", lowCardMethod(method)),
+	}
+	if stream {
+		attrs = append(attrs, attribute.Bool("rpc.stream", true))
+	}
+	return attrs
+}
+
+func (g *GRPCClientMetrics) record(ctx context.Context, base []attribute.KeyValue, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := append(base, attribute.String("rpc.code", code.String()))
+
+	g.latency.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+	if code != codes.OK {
+		g.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
@@ -1,18 +1,36 @@
 package metrics
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// UnmatchedRoute labels requests no router claimed (404s, scanners), so raw paths
+// never become label values.
+const UnmatchedRoute = "unmatched"
+
+// HTTPServerOptions configures HTTPServerMetrics.
+type HTTPServerOptions struct {
+	// Route returns a low-cardinality route template for r (e.g. "/v1/hello/{name}"),
+	// or "" if unknown. It is consulted only when no router called SetRoute.
+	Route func(r *http.Request) string
+}
+
 // HTTPServerMetrics provides low-cardinality HTTP server metrics for a gateway-style HTTP server.
+//
+// Requests are labeled with http.route: the template recorded via SetRoute by the
+// router that matched the request, else HTTPServerOptions.Route, else UnmatchedRoute.
 type HTTPServerMetrics struct {
 	service string
+	route   func(*http.Request) string
 
 	inflight metric.Int64UpDownCounter
 	errors   metric.Int64Counter
@@ -20,6 +38,10 @@ type HTTPServerMetrics struct {
 }
 
 func NewHTTPServerMetrics(service string) (*HTTPServerMetrics, error) {
+	return NewHTTPServerMetricsWithOptions(service, HTTPServerOptions{})
+}
+
+func NewHTTPServerMetricsWithOptions(service string, opts HTTPServerOptions) (*HTTPServerMetrics, error) {
 	m := otel.Meter("sdk-microservices/" + service)
 
 	inflight, err := m.Int64UpDownCounter(
@@ -49,6 +71,7 @@ func NewHTTPServerMetrics(service string) (*HTTPServerMetrics, error) {
 
 	return &HTTPServerMetrics{
 		service:  service,
+		route:    opts.Route,
 		inflight: inflight,
 		errors:   errors,
 		latency:  latency,
@@ -63,6 +86,8 @@ func (h *HTTPServerMetrics) Middleware(next http.Handler) http.Handler {
 		start := time.Now()
 
 		sw := &statusCapturingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		rh := &routeHolder{}
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, rh))
 
 		attrs := []attribute.KeyValue{
 			attribute.String("service.name", h.service),
@@ -75,10 +100,19 @@ func (h *HTTPServerMetrics) Middleware(next http.Handler) http.Handler {
 		next.ServeHTTP(sw, r)
 
 		codeStr := strconv.Itoa(sw.status)
-		attrs = append(attrs, attribute.String("http.status_code", codeStr))
-
+		attrs = append(attrs,
+			attribute.String("http.route", h.routeOf(r, rh)),
+			attribute.String("http.status_code", codeStr),
+		)
+
+		// This middleware runs outside the tracing middleware, so take the span the
+		// router saw (via SetRoute) for the exemplar.
+		ctx := r.Context()
+		if sc, ok := rh.span.Load().(trace.SpanContext); ok {
+			ctx = trace.ContextWithSpanContext(ctx, sc)
+		}
 		dur := time.Since(start).Seconds()
-		h.latency.Record(r.Context(), dur, metric.WithAttributes(attrs...))
+		h.latency.Record(ctx, dur, metric.WithAttributes(attrs...))
 
 		if sw.status >= 500 {
 			h.errors.Add(r.Context(), 1, metric.WithAttributes(attrs...))
@@ -86,6 +120,58 @@ func (h *HTTPServerMetrics) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+func (h *HTTPServerMetrics) routeOf(r *http.Request, rh *routeHolder) string {
+	if v, ok := rh.route.Load().(string); ok && v != "" {
+		return v
+	}
+	if h.route != nil {
+		if v := h.route(r); v != "" {
+			return v
+		}
+	}
+	return UnmatchedRoute
+}
+
+type routeKey struct{}
+
+// routeHolder is written by the router (possibly on a timeout handler's goroutine)
+// and read by the middleware once the request finishes.
+type routeHolder struct {
+	route atomic.Value // string
+	span  atomic.Value // trace.SpanContext, for exemplars
+}
+
+// SetRoute records the matched route template (e.g. "/v1/hello/{name}") for the
+// request in ctx, along with the active span, which becomes the duration exemplar.
+// Routers call it once they have matched; it is a no-op outside
+// HTTPServerMetrics.Middleware.
+func SetRoute(ctx context.Context, route string) {
+	if rh, ok := ctx.Value(routeKey{}).(*routeHolder); ok {
+		rh.route.Store(route)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			rh.span.Store(sc)
+		}
+	}
+}
+
+// Route returns the route template recorded for the request in ctx via SetRoute.
+func Route(ctx context.Context) (string, bool) {
+	rh, ok := ctx.Value(routeKey{}).(*routeHolder)
+	if !ok {
+		return "", false
+	}
+	v, ok := rh.route.Load().(string)
+	return v, ok && v != ""
+}
+
+// RouteHandler labels every request served by h with route.
+func RouteHandler(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), route)
+		h.ServeHTTP(w, r)
+	})
+}
+
 type statusCapturingResponseWriter struct {
 	http.ResponseWriter
 	status int
@@ -96,5 +182,8 @@ func (w *statusCapturingResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController (Flush, deadlines).
+func (w *statusCapturingResponseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
 // Ensure we satisfy interfaces at compile time.
 var _ http.ResponseWriter = (*statusCapturingResponseWriter)(nil)
@@ -0,0 +1,74 @@
+package otel
+
+import (
+	"context"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Baggage member keys for caller context carried across services.
+const (
+	BaggageUserID   = "user_id"
+	BaggageTenantID = "tenant_id"
+	BaggagePriority = "priority"
+)
+
+// PriorityHeader is the HTTP header / gRPC metadata key carrying request priority
+// (e.g. "high", "low") into baggage.
+const PriorityHeader = "x-request-priority"
+
+// WithCallerBaggage puts the authctx user/tenant and priority into ctx's baggage
+// (and onto the active span) so downstream services can read them without custom
+// metadata keys.
+//
+// Identity members are always taken from authctx: inbound values are replaced or
+// dropped, so callers cannot spoof them with a baggage header. An empty priority
+// keeps any inbound priority member.
+func WithCallerBaggage(ctx context.Context, priority string) context.Context {
+	bag := baggage.FromContext(ctx)
+	bag = bag.DeleteMember(BaggageUserID).DeleteMember(BaggageTenantID)
+
+	var attrs []attribute.KeyValue
+	set := func(key, val, attr string) {
+		m, err := baggage.NewMemberRaw(key, val)
+		if err != nil {
+			return
+		}
+		if b, err := bag.SetMember(m); err == nil {
+			bag = b
+			attrs = append(attrs, attribute.String(attr, val))
+		}
+	}
+	if uid, ok := authctx.UserID(ctx); ok {
+		set(BaggageUserID, uid, "enduser.id")
+	}
+	if tid, ok := authctx.TenantID(ctx); ok {
+		set(BaggageTenantID, tid, "tenant.id")
+	}
+	if validPriority(priority) {
+		set(BaggagePriority, priority, "request.priority")
+	}
+
+	if len(attrs) > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// validPriority accepts short lowercase tokens only; priority is a routing hint,
+// never free-form client data.
+func validPriority(p string) bool {
+	if p == "" || len(p) > 16 {
+		return false
+	}
+	for i := 0; i < len(p); i++ {
+		if c := p[i]; (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
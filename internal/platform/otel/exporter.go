@@ -0,0 +1,243 @@
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	stdouttrace "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// ExporterConfig configures the OTLP trace exporter. An empty Endpoint selects the
+// stdout exporter.
+type ExporterConfig struct {
+	// Endpoint is host:port or a URL. A URL's scheme decides TLS ("http" is
+	// plaintext); for http/protobuf its path is used as-is when set.
+	Endpoint string
+	// Protocol is "grpc" (default) or "http/protobuf".
+	Protocol string
+	// Headers are sent with every export, e.g. vendor API keys.
+	Headers map[string]string
+	// Insecure disables TLS.
+	Insecure bool
+	// CAFile is a PEM bundle used to verify the collector instead of system roots.
+	CAFile string
+	// ClientCertFile and ClientKeyFile enable mTLS.
+	ClientCertFile string
+	ClientKeyFile  string
+	// Compression is "gzip" or "none" (default).
+	Compression string
+	// Timeout bounds each export (default 10s).
+	Timeout time.Duration
+}
+
+// ExporterConfigFromEnv reads the standard OTLP exporter variables, preferring the
+// trace-specific form (OTEL_EXPORTER_OTLP_TRACES_*) over the generic one
+// (OTEL_EXPORTER_OTLP_*) for: ENDPOINT, PROTOCOL, HEADERS ("k1=v1,k2=v2", values
+// URL-encoded), INSECURE, CERTIFICATE, CLIENT_CERTIFICATE, CLIENT_KEY, COMPRESSION
+// and TIMEOUT (milliseconds).
+//
+// A generic http/protobuf endpoint gets the /v1/traces path appended; a
+// trace-specific one is used verbatim, as the OTel spec requires.
+func ExporterConfigFromEnv() (ExporterConfig, error) {
+	var c ExporterConfig
+	endpoint, perSignal := otlpEnv("ENDPOINT")
+	c.Endpoint = endpoint
+	c.Protocol, _ = otlpEnv("PROTOCOL")
+	c.CAFile, _ = otlpEnv("CERTIFICATE")
+	c.ClientCertFile, _ = otlpEnv("CLIENT_CERTIFICATE")
+	c.ClientKeyFile, _ = otlpEnv("CLIENT_KEY")
+	c.Compression, _ = otlpEnv("COMPRESSION")
+
+	if v, _ := otlpEnv("INSECURE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("OTEL_EXPORTER_OTLP_INSECURE: %w", err)
+		}
+		c.Insecure = b
+	}
+	if v, _ := otlpEnv("TIMEOUT"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms < 0 {
+			return c, fmt.Errorf("OTEL_EXPORTER_OTLP_TIMEOUT: invalid milliseconds %q", v)
+		}
+		c.Timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	// Generic headers first so trace-specific ones win on conflicts.
+	for _, key := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS"} {
+		h, err := parseHeaders(os.Getenv(key))
+		if err != nil {
+			return c, fmt.Errorf("%s: %w", key, err)
+		}
+		for k, v := range h {
+			if c.Headers == nil {
+				c.Headers = make(map[string]string)
+			}
+			c.Headers[k] = v
+		}
+	}
+
+	if !perSignal && isHTTPProtocol(c.Protocol) {
+		if u, err := url.Parse(c.Endpoint); err == nil && u.Scheme != "" && u.Host != "" {
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/traces"
+			c.Endpoint = u.String()
+		}
+	}
+	return c, nil
+}
+
+// otlpEnv returns OTEL_EXPORTER_OTLP_TRACES_<name>, else OTEL_EXPORTER_OTLP_<name>;
+// perSignal reports which one was used.
+func otlpEnv(name string) (v string, perSignal bool) {
+	if v := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_" + name)); v != "" {
+		return v, true
+	}
+	return strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_" + name)), false
+}
+
+// parseHeaders parses the OTLP header list format: "k1=v1,k2=v2" with URL-encoded values.
+func parseHeaders(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	out := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid header %q", kv)
+		}
+		dv, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %w", k, err)
+		}
+		out[k] = dv
+	}
+	return out, nil
+}
+
+func isHTTPProtocol(p string) bool {
+	switch strings.ToLower(p) {
+	case "http/protobuf", "http":
+		return true
+	}
+	return false
+}
+
+// tlsConfig builds the client TLS config from the CA and client cert files.
+func (c ExporterConfig) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("otlp ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("otlp ca: no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("otlp client cert: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// insecure reports whether to export in plaintext: explicitly, or via an http:// URL.
+func (c ExporterConfig) insecure() bool {
+	return c.Insecure || strings.HasPrefix(strings.ToLower(c.Endpoint), "http://")
+}
+
+func newTraceExporter(ctx context.Context, c ExporterConfig) (sdktrace.SpanExporter, func(context.Context) error, error) {
+	if c.Endpoint == "" {
+		exp, err := stdouttrace.New(
+			stdouttrace.WithPrettyPrint(),
+		)
+		return exp, func(context.Context) error { return nil }, err
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	switch strings.ToLower(c.Compression) {
+	case "", "none", "gzip":
+	default:
+		return nil, nil, errors.New("unsupported OTLP compression: " + c.Compression)
+	}
+	hasScheme := strings.Contains(c.Endpoint, "://")
+
+	var client otlptrace.Client
+	switch strings.ToLower(c.Protocol) {
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithTimeout(c.Timeout)}
+		if hasScheme {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(c.Endpoint))
+		} else {
+			opts = append(opts, otlptracegrpc.WithEndpoint(c.Endpoint))
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(c.Headers))
+		}
+		if strings.EqualFold(c.Compression, "gzip") {
+			opts = append(opts, otlptracegrpc.WithCompressor("gzip"))
+		}
+		if c.insecure() {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			tlsCfg, err := c.tlsConfig()
+			if err != nil {
+				return nil, nil, err
+			}
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+		client = otlptracegrpc.NewClient(opts...)
+	case "http/protobuf", "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithTimeout(c.Timeout)}
+		if hasScheme {
+			opts = append(opts, otlptracehttp.WithEndpointURL(c.Endpoint))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(c.Endpoint))
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(c.Headers))
+		}
+		if strings.EqualFold(c.Compression, "gzip") {
+			opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		if c.insecure() {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			tlsCfg, err := c.tlsConfig()
+			if err != nil {
+				return nil, nil, err
+			}
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		}
+		client = otlptracehttp.NewClient(opts...)
+	default:
+		return nil, nil, errors.New("unsupported OTEL_EXPORTER_OTLP_PROTOCOL: " + c.Protocol)
+	}
+
+	exp, err := otlptrace.New(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exp, exp.Shutdown, nil
+}
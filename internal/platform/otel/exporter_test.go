@@ -0,0 +1,56 @@
+package otel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExporterConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://otlp.vendor.example:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=abc%3D%3D,x-team=core")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "x-team=edge")
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "gzip")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", "2500")
+
+	c, err := ExporterConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Endpoint != "https://otlp.vendor.example:4318/v1/traces" {
+		t.Errorf("Endpoint = %q", c.Endpoint)
+	}
+	if c.Headers["api-key"] != "abc==" || c.Headers["x-team"] != "edge" {
+		t.Errorf("Headers = %v", c.Headers)
+	}
+	if c.Compression != "gzip" || c.Timeout != 2500*time.Millisecond {
+		t.Errorf("Compression = %q, Timeout = %v", c.Compression, c.Timeout)
+	}
+	if c.insecure() {
+		t.Errorf("https endpoint must not be insecure")
+	}
+}
+
+func TestExporterConfigFromEnv_PerSignalEndpointVerbatim(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318/custom/path")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+
+	c, err := ExporterConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Endpoint != "http://traces:4318/custom/path" {
+		t.Errorf("Endpoint = %q", c.Endpoint)
+	}
+	if !c.insecure() {
+		t.Errorf("http:// endpoint should export in plaintext")
+	}
+}
+
+func TestExporterConfigFromEnv_Invalid(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "novalue")
+	if _, err := ExporterConfigFromEnv(); err == nil {
+		t.Fatalf("expected error for malformed headers")
+	}
+}
@@ -2,7 +2,12 @@ package otel
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	prom "github.com/prometheus/client_golang/prometheus"
@@ -14,17 +19,112 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// InitMetricsPrometheus wires an OTEL MeterProvider backed by a Prometheus scrape endpoint.
-// It returns the /metrics handler and a shutdown function.
+// DefaultLatencyBuckets are the histogram boundaries (seconds) for latency
+// instruments: the OTel semantic-convention buckets plus sub-5ms resolution, since
+// the SDK defaults (0, 5, 10, 25, ... 10000) put every fast RPC in one bucket.
+var DefaultLatencyBuckets = []float64{
+	0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.075,
+	0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10,
+}
+
+// MetricsOptions configures InitMetricsPrometheusWith.
+type MetricsOptions struct {
+	// Attributes are added to the metrics resource.
+	Attributes []attribute.KeyValue
+	// LatencyBuckets are the boundaries for every histogram measured in seconds
+	// (unit "s"), e.g. http.server.duration and rpc.server.duration. Defaults to
+	// DefaultLatencyBuckets.
+	LatencyBuckets []float64
+	// HistogramBuckets override the boundaries of individual histograms by
+	// instrument name, taking precedence over LatencyBuckets.
+	HistogramBuckets map[string][]float64
+}
+
+// MetricsOptionsFromEnv reads OTEL_METRICS_LATENCY_BUCKETS, a comma-separated list
+// of ascending boundaries in seconds (e.g. "0.001,0.005,0.01,0.05,0.1,0.5,1").
+func MetricsOptionsFromEnv() (MetricsOptions, error) {
+	var o MetricsOptions
+	if v := strings.TrimSpace(os.Getenv("OTEL_METRICS_LATENCY_BUCKETS")); v != "" {
+		b, err := ParseBuckets(v)
+		if err != nil {
+			return o, fmt.Errorf("OTEL_METRICS_LATENCY_BUCKETS: %w", err)
+		}
+		o.LatencyBuckets = b
+	}
+	return o, nil
+}
+
+// ParseBuckets parses comma-separated, strictly ascending histogram boundaries.
+func ParseBuckets(s string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %q: %w", f, err)
+		}
+		if len(out) > 0 && v <= out[len(out)-1] {
+			return nil, fmt.Errorf("buckets must be strictly ascending at %v", v)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// histogramView applies the configured boundaries. It is a single view so an
+// instrument matching both a per-name and the latency rule yields one stream.
+func (o MetricsOptions) histogramView() sdkmetric.View {
+	latency := o.LatencyBuckets
+	if len(latency) == 0 {
+		latency = DefaultLatencyBuckets
+	}
+	return func(i sdkmetric.Instrument) (sdkmetric.Stream, bool) {
+		if i.Kind != sdkmetric.InstrumentKindHistogram {
+			return sdkmetric.Stream{}, false
+		}
+		b, ok := o.HistogramBuckets[i.Name]
+		if !ok {
+			if i.Unit != "s" {
+				return sdkmetric.Stream{}, false
+			}
+			b = latency
+		}
+		return sdkmetric.Stream{
+			Name:        i.Name,
+			Description: i.Description,
+			Unit:        i.Unit,
+			Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: slices.Clone(b)},
+		}, true
+	}
+}
+
+// InitMetricsPrometheus is InitMetricsPrometheusWith using MetricsOptionsFromEnv.
+// Invalid bucket settings fall back to the defaults.
 func InitMetricsPrometheus(
 	ctx context.Context,
 	serviceName string,
 	extraAttrs ...attribute.KeyValue,
 ) (http.Handler, func(context.Context) error, error) {
+	opts, _ := MetricsOptionsFromEnv()
+	opts.Attributes = extraAttrs
+	return InitMetricsPrometheusWith(ctx, serviceName, opts)
+}
+
+// InitMetricsPrometheusWith wires an OTEL MeterProvider backed by a Prometheus scrape endpoint.
+// It returns the /metrics handler and a shutdown function.
+//
+// Measurements recorded with a sampled span in their context carry that trace id as
+// an exemplar (OpenMetrics format only), so a slow histogram bucket links to an
+// example trace. OTEL_METRICS_EXEMPLAR_FILTER ("always_off", ...) overrides this.
+func InitMetricsPrometheusWith(
+	ctx context.Context,
+	serviceName string,
+	opts MetricsOptions,
+) (http.Handler, func(context.Context) error, error) {
 
 	res, err := resource.New(
 		ctx,
@@ -32,7 +132,7 @@ func InitMetricsPrometheus(
 		resource.WithTelemetrySDK(),
 		resource.WithHost(),
 		resource.WithAttributes(semconv.ServiceName(serviceName)),
-		resource.WithAttributes(extraAttrs...),
+		resource.WithAttributes(opts.Attributes...),
 	)
 	if err != nil {
 		return nil, nil, err
@@ -52,10 +152,15 @@ func InitMetricsPrometheus(
 		return nil, nil, err
 	}
 
-	mp := sdkmetric.NewMeterProvider(
+	mpOpts := []sdkmetric.Option{
 		sdkmetric.WithResource(res),
 		sdkmetric.WithReader(exp),
-	)
+		sdkmetric.WithView(opts.histogramView()),
+	}
+	if os.Getenv("OTEL_METRICS_EXEMPLAR_FILTER") == "" {
+		mpOpts = append(mpOpts, sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter))
+	}
+	mp := sdkmetric.NewMeterProvider(mpOpts...)
 	otel.SetMeterProvider(mp)
 	if err := runtime.Start(
 		runtime.WithMinimumReadMemStatsInterval(10 * time.Second),
@@ -63,8 +168,9 @@ func InitMetricsPrometheus(
 		return nil, nil, err
 	}
 
-	// Expose /metrics from that registry.
-	h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	// Expose /metrics from that registry. Exemplars are only part of the
+	// OpenMetrics exposition, which scrapers negotiate via Accept.
+	h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true})
 
 	return h, mp.Shutdown, nil
 }
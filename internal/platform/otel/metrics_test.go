@@ -0,0 +1,67 @@
+package otel
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestHistogramView(t *testing.T) {
+	opts := MetricsOptions{
+		LatencyBuckets:   []float64{0.001, 0.01, 0.1},
+		HistogramBuckets: map[string][]float64{"payload.size": {1024, 65536}},
+	}
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(opts.histogramView()))
+	m := mp.Meter("test")
+
+	lat, _ := m.Float64Histogram("rpc.server.duration", metric.WithUnit("s"))
+	size, _ := m.Int64Histogram("payload.size", metric.WithUnit("By"))
+	other, _ := m.Float64Histogram("queue.depth")
+	ctx := context.Background()
+	lat.Record(ctx, 0.002)
+	size.Record(ctx, 2048)
+	other.Record(ctx, 3)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	bounds := map[string][]float64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, mt := range sm.Metrics {
+			switch d := mt.Data.(type) {
+			case metricdata.Histogram[float64]:
+				bounds[mt.Name] = d.DataPoints[0].Bounds
+			case metricdata.Histogram[int64]:
+				bounds[mt.Name] = d.DataPoints[0].Bounds
+			}
+		}
+	}
+	if got := bounds["rpc.server.duration"]; !slices.Equal(got, opts.LatencyBuckets) {
+		t.Errorf("latency bounds = %v, want %v", got, opts.LatencyBuckets)
+	}
+	if got := bounds["payload.size"]; !slices.Equal(got, []float64{1024, 65536}) {
+		t.Errorf("payload.size bounds = %v", got)
+	}
+	if got := bounds["queue.depth"]; len(got) == 0 || slices.Equal(got, opts.LatencyBuckets) {
+		t.Errorf("unitless histogram should keep SDK defaults, got %v", got)
+	}
+}
+
+func TestParseBuckets(t *testing.T) {
+	b, err := ParseBuckets("0.001, 0.01,0.1")
+	if err != nil || !slices.Equal(b, []float64{0.001, 0.01, 0.1}) {
+		t.Fatalf("ParseBuckets = %v, %v", b, err)
+	}
+	if _, err := ParseBuckets("0.1,0.01"); err == nil {
+		t.Fatalf("expected error for descending buckets")
+	}
+	if _, err := ParseBuckets("fast"); err == nil {
+		t.Fatalf("expected error for non-numeric bucket")
+	}
+}
@@ -4,15 +4,10 @@ import (
 	"context"
 	"errors"
 	"os"
-	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
-	stdouttrace "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -22,20 +17,36 @@ import (
 // ShutdownFn shuts down the OTEL providers.
 type ShutdownFn func(context.Context) error
 
+// InitOptions configures InitWith.
+type InitOptions struct {
+	// Attributes are added to the tracing resource.
+	Attributes []attribute.KeyValue
+	// Sampling configures the sampler. When nil, SamplingFromEnv is used, unless
+	// OTEL_TRACES_SAMPLER is set, in which case the SDK's own env handling applies.
+	Sampling *Sampling
+	// Exporter configures the trace exporter. When nil, ExporterConfigFromEnv is used.
+	Exporter *ExporterConfig
+}
+
 // Init configures global OpenTelemetry tracing.
 //
 // Behavior:
-//   - If OTEL_EXPORTER_OTLP_ENDPOINT is set, exports traces to that endpoint.
+//   - If OTEL_EXPORTER_OTLP_[TRACES_]ENDPOINT is set, exports traces to that endpoint.
 //   - Otherwise falls back to a dev-friendly stdout exporter.
 //
 // Supported env vars (subset of OTEL standard):
-//   - OTEL_EXPORTER_OTLP_ENDPOINT
-//   - OTEL_EXPORTER_OTLP_PROTOCOL ("grpc" or "http/protobuf")
-//   - OTEL_EXPORTER_OTLP_INSECURE ("true"/"false") for grpc
+//   - OTEL_EXPORTER_OTLP_[TRACES_]{ENDPOINT,PROTOCOL,HEADERS,INSECURE,CERTIFICATE,
+//     CLIENT_CERTIFICATE,CLIENT_KEY,COMPRESSION,TIMEOUT} (see ExporterConfigFromEnv)
 //   - OTEL_RESOURCE_ATTRIBUTES (standard)
-//   - OTEL_TRACES_SAMPLER (standard, handled by SDK)
-//   - OTEL_TRACES_SAMPLER_ARG (standard, handled by SDK)
+//   - OTEL_TRACES_SAMPLER (standard, handled by SDK when set)
+//   - OTEL_TRACES_SAMPLER_ARG (root sampling ratio, see SamplingFromEnv)
+//   - OTEL_TRACES_SAMPLE_ON_ERROR ("true" exports error spans of unsampled traces)
 func Init(ctx context.Context, serviceName string, extraAttrs ...attribute.KeyValue) (ShutdownFn, error) {
+	return InitWith(ctx, serviceName, InitOptions{Attributes: extraAttrs})
+}
+
+// InitWith is Init with explicit options (e.g. per-route sampling rules).
+func InitWith(ctx context.Context, serviceName string, opts InitOptions) (ShutdownFn, error) {
 	res, err := resource.New(
 		ctx,
 		resource.WithFromEnv(),
@@ -44,26 +55,48 @@ func Init(ctx context.Context, serviceName string, extraAttrs ...attribute.KeyVa
 		resource.WithAttributes(
 			semconv.ServiceName(serviceName),
 		),
-		resource.WithAttributes(extraAttrs...),
+		resource.WithAttributes(opts.Attributes...),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	exp, shutdownExp, err := newTraceExporter(ctx)
+	expCfg := opts.Exporter
+	if expCfg == nil {
+		c, err := ExporterConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		expCfg = &c
+	}
+	exp, shutdownExp, err := newTraceExporter(ctx, *expCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithResource(res),
-		// Respect OTEL_TRACES_SAMPLER env automatically.
-		sdktrace.WithBatcher(exp,
-			sdktrace.WithBatchTimeout(5*time.Second),
-			sdktrace.WithMaxQueueSize(2048),
-			sdktrace.WithMaxExportBatchSize(512),
-		),
+	bsp := sdktrace.NewBatchSpanProcessor(exp,
+		sdktrace.WithBatchTimeout(5*time.Second),
+		sdktrace.WithMaxQueueSize(2048),
+		sdktrace.WithMaxExportBatchSize(512),
 	)
+	tpOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSpanProcessor(bsp),
+	}
+
+	sampling := opts.Sampling
+	if sampling == nil && os.Getenv("OTEL_TRACES_SAMPLER") == "" {
+		s := SamplingFromEnv()
+		sampling = &s
+	}
+	if sampling != nil {
+		tpOpts = append(tpOpts, sdktrace.WithSampler(sampling.Sampler()))
+		if sampling.AlwaysOnError {
+			tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(errorSpanProcessor{next: bsp}))
+		}
+	}
+
+	tp := sdktrace.NewTracerProvider(tpOpts...)
 
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
@@ -84,41 +117,3 @@ func Init(ctx context.Context, serviceName string, extraAttrs ...attribute.KeyVa
 		return errors.Join(errs...)
 	}, nil
 }
-
-func newTraceExporter(ctx context.Context) (sdktrace.SpanExporter, func(context.Context) error, error) {
-	endpoint := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
-	if endpoint == "" {
-		exp, err := stdouttrace.New(
-			stdouttrace.WithPrettyPrint(),
-		)
-		return exp, func(context.Context) error { return nil }, err
-	}
-
-	proto := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
-	switch strings.ToLower(proto) {
-	case "", "grpc":
-		opts := []otlptracegrpc.Option{
-			otlptracegrpc.WithEndpoint(endpoint),
-		}
-		if strings.EqualFold(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"), "true") {
-			opts = append(opts, otlptracegrpc.WithInsecure())
-		}
-		client := otlptracegrpc.NewClient(opts...)
-		exp, err := otlptrace.New(ctx, client)
-		if err != nil {
-			return nil, nil, err
-		}
-		return exp, exp.Shutdown, nil
-	case "http/protobuf", "http":
-		client := otlptracehttp.NewClient(
-			otlptracehttp.WithEndpoint(endpoint),
-		)
-		exp, err := otlptrace.New(ctx, client)
-		if err != nil {
-			return nil, nil, err
-		}
-		return exp, exp.Shutdown, nil
-	default:
-		return nil, nil, errors.New("unsupported OTEL_EXPORTER_OTLP_PROTOCOL: " + proto)
-	}
-}
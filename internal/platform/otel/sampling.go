@@ -0,0 +1,175 @@
+package otel
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplingRule overrides the sampling ratio for matching root spans.
+type SamplingRule struct {
+	// Method restricts the rule to one HTTP method; empty matches any method.
+	Method string
+	// Pattern is a path prefix when it ends with "/" (e.g. "/v1/auth/"), otherwise
+	// an exact path (e.g. "/healthz"). gRPC spans match as "/pkg.Service/Method".
+	Pattern string
+	// Ratio is the fraction of matching traces to sample (0 = never, 1 = always).
+	Ratio float64
+}
+
+// Sampling configures the trace sampler installed by InitWith.
+type Sampling struct {
+	// Ratio is the fraction of new (root) traces sampled; children follow their
+	// parent's decision.
+	Ratio float64
+	// Rules override Ratio for root spans by route; the most specific match wins.
+	Rules []SamplingRule
+	// AlwaysOnError also exports spans that end with an error status even when their
+	// trace was not sampled. Unsampled spans are then recorded (not dropped) so their
+	// status is known, which costs some CPU and memory per span.
+	AlwaysOnError bool
+}
+
+// SamplingFromEnv reads the sampler settings:
+//   - OTEL_TRACES_SAMPLER_ARG: root sampling ratio (default 1)
+//   - OTEL_TRACES_SAMPLE_ON_ERROR: "true" enables AlwaysOnError
+func SamplingFromEnv() Sampling {
+	s := Sampling{Ratio: 1}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER_ARG")), 64); err == nil && v >= 0 && v <= 1 {
+		s.Ratio = v
+	}
+	s.AlwaysOnError, _ = strconv.ParseBool(os.Getenv("OTEL_TRACES_SAMPLE_ON_ERROR"))
+	return s
+}
+
+// Sampler returns the head sampler described by s: parent-based, with per-route
+// ratios for root spans.
+func (s Sampling) Sampler() sdktrace.Sampler {
+	rules := make([]samplingRule, 0, len(s.Rules))
+	for _, r := range s.Rules {
+		rules = append(rules, samplingRule{SamplingRule: r, sampler: sdktrace.TraceIDRatioBased(r.Ratio)})
+	}
+	root := &routeSampler{rules: rules, fallback: sdktrace.TraceIDRatioBased(s.Ratio)}
+
+	var sampler sdktrace.Sampler = sdktrace.ParentBased(root)
+	if s.AlwaysOnError {
+		sampler = recordUnsampled{sampler}
+	}
+	return sampler
+}
+
+type samplingRule struct {
+	SamplingRule
+	sampler sdktrace.Sampler
+}
+
+// routeSampler picks a ratio for root spans by the route they serve.
+type routeSampler struct {
+	rules    []samplingRule
+	fallback sdktrace.Sampler
+}
+
+func (s *routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if len(s.rules) > 0 {
+		method, path := spanRoute(p)
+		best, bestScore := s.fallback, -1
+		for _, r := range s.rules {
+			if score := r.score(method, path); score > bestScore {
+				best, bestScore = r.sampler, score
+			}
+		}
+		return best.ShouldSample(p)
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *routeSampler) Description() string { return "RouteSampler" }
+
+// spanRoute returns the HTTP method and path of a server span (url.path from
+// otelhttp), or "/"+span name for gRPC spans ("pkg.Service/Method").
+func spanRoute(p sdktrace.SamplingParameters) (method, path string) {
+	for _, kv := range p.Attributes {
+		switch kv.Key {
+		case "url.path", "http.target":
+			path = kv.Value.AsString()
+		case "http.request.method", "http.method":
+			method = kv.Value.AsString()
+		}
+	}
+	if path == "" && strings.Contains(p.Name, "/") {
+		path = "/" + strings.TrimPrefix(p.Name, "/")
+	}
+	return method, path
+}
+
+// score ranks matches like httpmw.Routes: exact beats prefix, longer prefixes
+// beat shorter ones, method-specific beats method-agnostic. -1 means no match.
+func (r samplingRule) score(method, path string) int {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return -1
+	}
+	var score int
+	switch {
+	case strings.HasSuffix(r.Pattern, "/"):
+		if !strings.HasPrefix(path, r.Pattern) {
+			return -1
+		}
+		score = 2 * len(r.Pattern)
+	case path == r.Pattern:
+		score = 2*len(r.Pattern) + 1
+	default:
+		return -1
+	}
+	if r.Method != "" {
+		score++
+	}
+	return score * 2
+}
+
+// recordUnsampled turns Drop decisions into RecordOnly so errorSpanProcessor can
+// see how unsampled spans end.
+type recordUnsampled struct{ sdktrace.Sampler }
+
+func (s recordUnsampled) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	res := s.Sampler.ShouldSample(p)
+	if res.Decision == sdktrace.Drop {
+		res.Decision = sdktrace.RecordOnly
+	}
+	return res
+}
+
+func (s recordUnsampled) Description() string {
+	return "RecordUnsampled{" + s.Sampler.Description() + "}"
+}
+
+// errorSpanProcessor forwards unsampled spans that ended with an error to next
+// (the exporting processor) as if they had been sampled.
+type errorSpanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+func (p errorSpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	sc := s.SpanContext()
+	if sc.IsSampled() || s.Status().Code != codes.Error {
+		return
+	}
+	p.next.OnEnd(sampledSpan{ReadOnlySpan: s, sc: sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))})
+}
+
+// Shutdown and ForceFlush are no-ops: next is registered (and shut down) separately.
+func (p errorSpanProcessor) Shutdown(context.Context) error   { return nil }
+func (p errorSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+	sc trace.SpanContext
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext { return s.sc }
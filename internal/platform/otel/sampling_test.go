@@ -0,0 +1,72 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSamplingRules(t *testing.T) {
+	s := Sampling{
+		Ratio: 0,
+		Rules: []SamplingRule{
+			{Pattern: "/healthz", Ratio: 0},
+			{Pattern: "/v1/auth/", Ratio: 1},
+			{Pattern: "/auth.v1.AuthService/Login", Ratio: 1},
+		},
+	}.Sampler()
+
+	cases := []struct {
+		name  string
+		span  string
+		attrs []attribute.KeyValue
+		want  sdktrace.SamplingDecision
+	}{
+		{"health never", "GET", []attribute.KeyValue{attribute.String("url.path", "/healthz")}, sdktrace.Drop},
+		{"auth always", "POST", []attribute.KeyValue{attribute.String("url.path", "/v1/auth/login")}, sdktrace.RecordAndSample},
+		{"fallback ratio", "GET", []attribute.KeyValue{attribute.String("url.path", "/v1/hello/x")}, sdktrace.Drop},
+		{"grpc by span name", "auth.v1.AuthService/Login", nil, sdktrace.RecordAndSample},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := s.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       trace.TraceID{1},
+				Name:          tc.span,
+				Kind:          trace.SpanKindServer,
+				Attributes:    tc.attrs,
+			})
+			if res.Decision != tc.want {
+				t.Fatalf("decision = %v, want %v", res.Decision, tc.want)
+			}
+		})
+	}
+}
+
+func TestSamplingAlwaysOnError(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	bsp := sdktrace.NewSimpleSpanProcessor(exp)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(Sampling{Ratio: 0, AlwaysOnError: true}.Sampler()),
+		sdktrace.WithSpanProcessor(bsp),
+		sdktrace.WithSpanProcessor(errorSpanProcessor{next: bsp}),
+	)
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	tr := tp.Tracer("test")
+	_, ok := tr.Start(context.Background(), "ok")
+	ok.End()
+	_, failed := tr.Start(context.Background(), "failed")
+	failed.SetStatus(codes.Error, "boom")
+	failed.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "failed" {
+		t.Fatalf("exported %d spans (%v), want only the failed one", len(spans), spans)
+	}
+}
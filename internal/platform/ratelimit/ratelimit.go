@@ -0,0 +1,92 @@
+// Package ratelimit holds the in-memory, per-key token buckets behind both the
+// HTTP (httpmw.KeyedLimiter) and gRPC (grpcutil.LocalRateLimiter) rate limiters,
+// so the two transports share one bucket and eviction implementation.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Buckets is a set of token buckets, one per key, all with the same rate and
+// burst. Keys idle for longer than the TTL are dropped.
+// NOTE: limits are per instance; share a budget across replicas with Redis.
+type Buckets struct {
+	mu        sync.Mutex
+	rate      rate.Limit
+	burst     int
+	ttl       time.Duration
+	lastSweep time.Time
+	clients   map[string]*client
+}
+
+type client struct {
+	lim  *rate.Limiter
+	last time.Time
+}
+
+// NewBuckets returns buckets refilling at r tokens/sec up to burst, forgetting
+// keys idle for ttl.
+func NewBuckets(r rate.Limit, burst int, ttl time.Duration) *Buckets {
+	return &Buckets{rate: r, burst: burst, ttl: ttl, clients: make(map[string]*client)}
+}
+
+// Get returns the bucket for key, creating it (full) if needed, and marks the
+// key as used at now.
+func (b *Buckets) Get(key string, now time.Time) *rate.Limiter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Sweep idle keys at most once per TTL rather than on every call.
+	if now.Sub(b.lastSweep) >= b.ttl {
+		for k, c := range b.clients {
+			if now.Sub(c.last) > b.ttl {
+				delete(b.clients, k)
+			}
+		}
+		b.lastSweep = now
+	}
+
+	c, ok := b.clients[key]
+	if !ok {
+		c = &client{lim: rate.NewLimiter(b.rate, b.burst)}
+		b.clients[key] = c
+	}
+	c.last = now
+	return c.lim
+}
+
+// Allow takes one token from key's bucket, reporting whether one was available.
+func (b *Buckets) Allow(key string) bool {
+	now := time.Now()
+	return b.Get(key, now).AllowN(now, 1)
+}
+
+// Limit returns the current rate and burst.
+func (b *Buckets) Limit() (rate.Limit, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate, b.burst
+}
+
+// SetLimit changes the rate and burst for every key, including those already
+// tracked (e.g. on a config reload).
+func (b *Buckets) SetLimit(r rate.Limit, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate, b.burst = r, burst
+	now := time.Now()
+	for _, c := range b.clients {
+		c.lim.SetLimitAt(now, r)
+		c.lim.SetBurstAt(now, burst)
+	}
+}
+
+// Len returns the number of keys currently tracked.
+func (b *Buckets) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuckets_PerKeyBurst(t *testing.T) {
+	b := NewBuckets(1, 2, time.Minute)
+	for i := 0; i < 2; i++ {
+		if !b.Allow("a") {
+			t.Fatalf("request %d: expected allowed", i)
+		}
+	}
+	if b.Allow("a") {
+		t.Fatal("expected burst spent")
+	}
+	if !b.Allow("b") {
+		t.Fatal("keys must not share a bucket")
+	}
+}
+
+func TestBuckets_SetLimitAppliesToTrackedKeys(t *testing.T) {
+	b := NewBuckets(1, 1, time.Minute)
+	if !b.Allow("a") || b.Allow("a") {
+		t.Fatal("expected a burst of 1")
+	}
+	b.SetLimit(1000, 5)
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		if !b.Allow("a") {
+			t.Fatalf("request %d after SetLimit: expected allowed", i)
+		}
+	}
+	if r, burst := b.Limit(); r != 1000 || burst != 5 {
+		t.Fatalf("Limit() = %v, %d", r, burst)
+	}
+}
+
+func TestBuckets_EvictsIdleKeys(t *testing.T) {
+	b := NewBuckets(1, 1, time.Minute)
+	now := time.Unix(1000, 0)
+	b.Get("a", now)
+	b.Get("b", now.Add(30*time.Second))
+	if b.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", b.Len())
+	}
+	// "a" has been idle past the TTL; "b" has not.
+	b.Get("c", now.Add(90*time.Second))
+	if b.Len() != 2 {
+		t.Fatalf("Len after sweep = %d, want 2", b.Len())
+	}
+}
@@ -0,0 +1,150 @@
+// Package registry registers a service instance with a service registry so
+// clients outside Kubernetes can discover it (e.g. the gateway dialing
+// consul://agent/hello with round_robin, see grpcutil.ConsulTarget).
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Instance is one running replica of a service.
+type Instance struct {
+	// ID is unique per replica, e.g. "<service>-<hostname>-<port>".
+	ID      string
+	Name    string
+	Address string
+	Port    int
+	Tags    []string
+	// Meta is free-form metadata (version, commit, ...).
+	Meta map[string]string
+
+	// HealthURL is polled by the registry; the instance is only returned to
+	// clients while it answers 2xx (typically the admin /readyz, so draining
+	// takes it out of rotation).
+	HealthURL      string
+	HealthInterval time.Duration
+	// DeregisterAfter removes an instance whose check stays critical this long,
+	// e.g. after a crash that skipped Deregister (0: never).
+	DeregisterAfter time.Duration
+}
+
+// Registrar adds and removes instances in a service registry.
+type Registrar interface {
+	Register(ctx context.Context, inst Instance) error
+	Deregister(ctx context.Context, id string) error
+}
+
+// ConsulOptions configure NewConsul.
+type ConsulOptions struct {
+	// Addr is the local agent's HTTP address, host:port or a URL (as in
+	// CONSUL_HTTP_ADDR). Defaults to 127.0.0.1:8500.
+	Addr string
+	// Token is sent as X-Consul-Token when set.
+	Token string
+	// Client defaults to an http.Client with a 10s timeout.
+	Client *http.Client
+}
+
+// Consul registers instances with the local Consul agent.
+type Consul struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// NewConsul returns a Registrar backed by the Consul agent API.
+func NewConsul(opts ConsulOptions) *Consul {
+	if opts.Addr == "" {
+		opts.Addr = "127.0.0.1:8500"
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	base := opts.Addr
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	return &Consul{base: strings.TrimSuffix(base, "/"), token: opts.Token, client: opts.Client}
+}
+
+type consulRegistration struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address,omitempty"`
+	Port    int               `json:"Port,omitempty"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   *consulCheck      `json:"Check,omitempty"`
+}
+
+type consulCheck struct {
+	HTTP                           string `json:"HTTP"`
+	Interval                       string `json:"Interval"`
+	Timeout                        string `json:"Timeout,omitempty"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+// Register adds inst to the agent, replacing any registration with the same ID.
+func (c *Consul) Register(ctx context.Context, inst Instance) error {
+	reg := consulRegistration{
+		ID:      inst.ID,
+		Name:    inst.Name,
+		Address: inst.Address,
+		Port:    inst.Port,
+		Tags:    inst.Tags,
+		Meta:    inst.Meta,
+	}
+	if inst.HealthURL != "" {
+		interval := inst.HealthInterval
+		if interval <= 0 {
+			interval = 10 * time.Second
+		}
+		reg.Check = &consulCheck{
+			HTTP:     inst.HealthURL,
+			Interval: interval.String(),
+			Timeout:  min(interval, 5*time.Second).String(),
+		}
+		if inst.DeregisterAfter > 0 {
+			reg.Check.DeregisterCriticalServiceAfter = inst.DeregisterAfter.String()
+		}
+	}
+	body, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+	return c.put(ctx, "/v1/agent/service/register", body)
+}
+
+// Deregister removes the instance with the given ID from the agent.
+func (c *Consul) Deregister(ctx context.Context, id string) error {
+	return c.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(id), nil)
+}
+
+func (c *Consul) put(ctx context.Context, path string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.base+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("consul: %s: unexpected status %d: %s", path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsulRegisterDeregister(t *testing.T) {
+	var (
+		got   consulRegistration
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-Consul-Token") != "tok" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/v1/agent/service/register" {
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	c := NewConsul(ConsulOptions{Addr: srv.Listener.Addr().String(), Token: "tok"})
+	inst := Instance{
+		ID:              "hello-host-50051",
+		Name:            "hello",
+		Address:         "10.0.0.1",
+		Port:            50051,
+		Tags:            []string{"grpc"},
+		Meta:            map[string]string{"version": "v1"},
+		HealthURL:       "http://10.0.0.1:8081/readyz",
+		HealthInterval:  2 * time.Second,
+		DeregisterAfter: time.Minute,
+	}
+	if err := c.Register(context.Background(), inst); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if got.ID != inst.ID || got.Name != "hello" || got.Port != 50051 || got.Meta["version"] != "v1" {
+		t.Fatalf("registration = %+v", got)
+	}
+	if got.Check == nil || got.Check.HTTP != inst.HealthURL || got.Check.Interval != "2s" ||
+		got.Check.Timeout != "2s" || got.Check.DeregisterCriticalServiceAfter != "1m0s" {
+		t.Fatalf("check = %+v", got.Check)
+	}
+
+	if err := c.Deregister(context.Background(), inst.ID); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if want := "/v1/agent/service/deregister/hello-host-50051"; len(paths) != 2 || paths[1] != want {
+		t.Fatalf("paths = %v, want deregister at %s", paths, want)
+	}
+}
+
+func TestConsulError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Invalid service address", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := NewConsul(ConsulOptions{Addr: srv.Listener.Addr().String()}).Register(context.Background(), Instance{ID: "x", Name: "x"})
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "Invalid service address") {
+		t.Fatalf("err = %v", err)
+	}
+}
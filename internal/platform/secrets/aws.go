@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// AWSCredentials sign Secrets Manager requests.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSCredentialsFromEnv reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN.
+func AWSCredentialsFromEnv(context.Context) (AWSCredentials, error) {
+	c := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return AWSCredentials{}, errors.New("secrets: AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY not set")
+	}
+	return c, nil
+}
+
+// AWSOptions configure an AWS Secrets Manager provider.
+type AWSOptions struct {
+	Region string
+	// SecretID names the secret (or its ARN) whose SecretString is a JSON
+	// object of the service's secrets; each secret is a key of that object.
+	SecretID string
+	// Credentials defaults to AWSCredentialsFromEnv.
+	Credentials func(context.Context) (AWSCredentials, error)
+	// Endpoint overrides https://secretsmanager.<region>.amazonaws.com.
+	Endpoint string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// AWSSecretsManager reads secrets from one AWS Secrets Manager secret.
+// Rotating the secret (a new AWSCURRENT version) rotates every key it holds.
+type AWSSecretsManager struct {
+	opts AWSOptions
+}
+
+func NewAWSSecretsManager(opts AWSOptions) (*AWSSecretsManager, error) {
+	if opts.Region == "" || opts.SecretID == "" {
+		return nil, errors.New("secrets: AWS Region and SecretID are required")
+	}
+	if opts.Credentials == nil {
+		opts.Credentials = AWSCredentialsFromEnv
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://secretsmanager." + opts.Region + ".amazonaws.com"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &AWSSecretsManager{opts: opts}, nil
+}
+
+func (a *AWSSecretsManager) Get(ctx context.Context, name string) ([]byte, error) {
+	creds, err := a.opts.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload, _ := json.Marshal(map[string]string{"SecretId": a.opts.SecretID})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.opts.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, payload, a.opts.Region, "secretsmanager", creds, time.Now().UTC())
+
+	resp, err := a.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("secretsmanager: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("secretsmanager: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type string `json:"__type"`
+		}
+		_ = json.Unmarshal(body, &e)
+		if e.Type == "ResourceNotFoundException" {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, a.opts.SecretID)
+		}
+		return nil, fmt.Errorf("secretsmanager: status %d %s", resp.StatusCode, e.Type)
+	}
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("secretsmanager: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out.SecretString), &m); err != nil {
+		return nil, fmt.Errorf("secretsmanager: %s is not a JSON object", a.opts.SecretID)
+	}
+	return lookup(m, name)
+}
+
+// signV4 adds SigV4 headers (Authorization, X-Amz-Date, X-Amz-Security-Token)
+// to req for a JSON-protocol AWS API call.
+func signV4(req *http.Request, payload []byte, region, service string, c AWSCredentials, now time.Time) {
+	date := now.Format("20060102")
+	stamp := now.Format("20060102T150405Z")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+
+	req.Header.Set("X-Amz-Date", stamp)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	// Canonical headers, sorted by name.
+	signed := "content-type;host;x-amz-date"
+	headers := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-date:" + stamp + "\n"
+	if c.SessionToken != "" {
+		signed += ";x-amz-security-token"
+		headers += "x-amz-security-token:" + c.SessionToken + "\n"
+	}
+	signed += ";x-amz-target"
+	headers += "x-amz-target:" + req.Header.Get("X-Amz-Target") + "\n"
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	ph := sha256.Sum256(payload)
+	canonical := req.Method + "\n" + path + "\n" + req.URL.Query().Encode() + "\n" + headers + "\n" + signed + "\n" + hex.EncodeToString(ph[:])
+	ch := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(ch[:])
+
+	key := []byte("AWS4" + c.SecretAccessKey)
+	for _, s := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.AccessKeyID+"/"+scope+", SignedHeaders="+signed+", Signature="+sig)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
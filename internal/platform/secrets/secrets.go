@@ -0,0 +1,161 @@
+// Package secrets fetches secrets (signing keys, database credentials, TLS
+// keys) from a backend and keeps them current, notifying consumers when a
+// value rotates so they can pick it up without a restart.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned by providers for secrets they don't hold.
+var ErrNotFound = errors.New("secrets: not found")
+
+// Provider fetches the current value of a named secret.
+type Provider interface {
+	Get(ctx context.Context, name string) ([]byte, error)
+}
+
+// Env serves secrets from environment variables named after the secret. It
+// never rotates; it keeps local development on the same code path.
+type Env struct{}
+
+func (Env) Get(_ context.Context, name string) ([]byte, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return []byte(v), nil
+}
+
+// Dir serves secrets from files named after the secret in a directory, e.g. a
+// mounted Kubernetes Secret (which the kubelet updates in place). One trailing
+// newline is trimmed.
+type Dir string
+
+func (d Dir) Get(_ context.Context, name string) ([]byte, error) {
+	if name != filepath.Base(name) {
+		return nil, fmt.Errorf("secrets: invalid name %q", name)
+	}
+	b, err := os.ReadFile(filepath.Join(string(d), name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r")), nil
+}
+
+// DefaultRefreshInterval is how often Watch polls by default.
+const DefaultRefreshInterval = time.Minute
+
+// WatchOptions configure Watch.
+type WatchOptions struct {
+	// Interval between polls (default DefaultRefreshInterval; negative disables
+	// polling, leaving Refresh to the caller).
+	Interval time.Duration
+	// Log reports failed refreshes (optional).
+	Log *zap.Logger
+}
+
+// Secret is a named secret kept current by polling its Provider. A failed
+// refresh keeps the last good value.
+type Secret struct {
+	name string
+	p    Provider
+	log  *zap.Logger
+
+	refreshMu sync.Mutex // serializes Refresh, and so the callbacks
+	mu        sync.Mutex
+	value     []byte
+	subs      []func(value []byte)
+}
+
+// Watch fetches name from p and, until ctx is done, polls it for changes. It
+// fails if the first fetch does.
+func Watch(ctx context.Context, p Provider, name string, opts WatchOptions) (*Secret, error) {
+	if opts.Interval == 0 {
+		opts.Interval = DefaultRefreshInterval
+	}
+	if opts.Log == nil {
+		opts.Log = zap.NewNop()
+	}
+	s := &Secret{name: name, p: p, log: opts.Log}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	if opts.Interval > 0 {
+		go s.poll(ctx, opts.Interval)
+	}
+	return s, nil
+}
+
+// Name returns the secret's name.
+func (s *Secret) Name() string { return s.name }
+
+// Value returns the current value. Callers must not modify it.
+func (s *Secret) Value() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+// OnChange registers fn to be called with each new value after a rotation.
+// Callbacks run one at a time on the goroutine calling Refresh.
+func (s *Secret) OnChange(fn func(value []byte)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs = append(s.subs, fn)
+}
+
+// Refresh fetches the secret now and notifies subscribers if it changed.
+func (s *Secret) Refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	v, err := s.p.Get(ctx, s.name)
+	if err != nil {
+		return fmt.Errorf("secret %s: %w", s.name, err)
+	}
+	s.mu.Lock()
+	if bytes.Equal(v, s.value) {
+		s.mu.Unlock()
+		return nil
+	}
+	first := s.value == nil
+	s.value = v
+	subs := append([]func([]byte){}, s.subs...)
+	s.mu.Unlock()
+
+	if !first {
+		s.log.Info("secret rotated", zap.String("secret", s.name))
+		for _, fn := range subs {
+			fn(v)
+		}
+	}
+	return nil
+}
+
+func (s *Secret) poll(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				s.log.Warn("secret refresh failed; keeping the current value", zap.String("secret", s.name), zap.Error(err))
+			}
+		}
+	}
+}
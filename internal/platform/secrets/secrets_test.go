@@ -0,0 +1,237 @@
+package secrets
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapProvider is an in-memory Provider for tests.
+type mapProvider struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (p *mapProvider) set(name, v string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.m[name] = v
+}
+
+func (p *mapProvider) Get(_ context.Context, name string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, ok := p.m[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(v), nil
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "AUTH_JWT_SECRET"), []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Dir(dir).Get(context.Background(), "AUTH_JWT_SECRET")
+	if err != nil || string(got) != "s3cr3t" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if _, err := Dir(dir).Get(context.Background(), "MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing: err = %v, want ErrNotFound", err)
+	}
+	if _, err := Dir(dir).Get(context.Background(), "../etc/passwd"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("path traversal: err = %v", err)
+	}
+}
+
+func TestWatchNotifiesOnRotation(t *testing.T) {
+	ctx := context.Background()
+	p := &mapProvider{m: map[string]string{"key": "v1"}}
+	s, err := Watch(ctx, p, "key", WatchOptions{Interval: -1})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	var got []string
+	s.OnChange(func(v []byte) { got = append(got, string(v)) })
+
+	if err := s.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	p.set("key", "v2")
+	if err := s.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if string(s.Value()) != "v2" || len(got) != 1 || got[0] != "v2" {
+		t.Fatalf("value %q, notifications %v", s.Value(), got)
+	}
+
+	delete(p.m, "key")
+	if err := s.Refresh(ctx); err == nil {
+		t.Fatal("Refresh of a deleted secret succeeded")
+	}
+	if string(s.Value()) != "v2" {
+		t.Fatalf("failed refresh replaced the value with %q", s.Value())
+	}
+
+	if _, err := Watch(ctx, p, "missing", WatchOptions{Interval: -1}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Watch missing: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWatchPolls(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := &mapProvider{m: map[string]string{"key": "v1"}}
+	s, err := Watch(ctx, p, "key", WatchOptions{Interval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	changed := make(chan string, 1)
+	s.OnChange(func(v []byte) { changed <- string(v) })
+	p.set("key", "v2")
+	select {
+	case v := <-changed:
+		if v != "v2" {
+			t.Fatalf("notified with %q", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rotation not picked up")
+	}
+}
+
+func TestVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/services/auth" || r.Header.Get("X-Vault-Token") != "root" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"AUTH_JWT_SECRET":"from-vault"},"metadata":{"version":3}}}`))
+	}))
+	defer srv.Close()
+
+	v, err := NewVault(VaultOptions{Addr: srv.URL, Token: "root", Mount: "kv", Path: "services/auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v.Get(context.Background(), "AUTH_JWT_SECRET")
+	if err != nil || string(got) != "from-vault" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if _, err := v.Get(context.Background(), "AUTH_DB_PASSWORD"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing key: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") ||
+			!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target") {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || r.Header.Get("X-Amz-Security-Token") != "tok" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		var in struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in.SecretId != "prod/auth" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Name":"prod/auth","SecretString":"{\"AUTH_DB_PASSWORD\":\"pw\"}"}`))
+	}))
+	defer srv.Close()
+
+	creds := func(context.Context) (AWSCredentials, error) {
+		return AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET", SessionToken: "tok"}, nil
+	}
+	a, err := NewAWSSecretsManager(AWSOptions{Region: "eu-west-1", SecretID: "prod/auth", Credentials: creds, Endpoint: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.Get(context.Background(), "AUTH_DB_PASSWORD")
+	if err != nil || string(got) != "pw" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+
+	missing, _ := NewAWSSecretsManager(AWSOptions{Region: "eu-west-1", SecretID: "other", Credentials: creds, Endpoint: srv.URL})
+	if _, err := missing.Get(context.Background(), "AUTH_DB_PASSWORD"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing secret: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKeyPairFollowsRotation(t *testing.T) {
+	ctx := context.Background()
+	cert1, key1 := selfSigned(t, "one")
+	cert2, key2 := selfSigned(t, "two")
+	p := &mapProvider{m: map[string]string{"cert": cert1, "key": key1}}
+	certS, _ := Watch(ctx, p, "cert", WatchOptions{Interval: -1})
+	keyS, _ := Watch(ctx, p, "key", WatchOptions{Interval: -1})
+	get := KeyPair(certS, keyS)
+
+	subject := func() string {
+		c, err := get()
+		if err != nil {
+			t.Fatalf("KeyPair: %v", err)
+		}
+		leaf, _ := x509.ParseCertificate(c.Certificate[0])
+		return leaf.Subject.CommonName
+	}
+	if got := subject(); got != "one" {
+		t.Fatalf("subject = %q", got)
+	}
+
+	// Certificate rotated, key not yet: keep serving the old pair.
+	p.set("cert", cert2)
+	_ = certS.Refresh(ctx)
+	if got := subject(); got != "one" {
+		t.Fatalf("half-rotated subject = %q", got)
+	}
+	p.set("key", key2)
+	_ = keyS.Refresh(ctx)
+	if got := subject(); got != "two" {
+		t.Fatalf("rotated subject = %q", got)
+	}
+}
+
+func selfSigned(t *testing.T, cn string) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}))
+}
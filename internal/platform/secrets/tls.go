@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/tls"
+	"sync"
+)
+
+// KeyPair returns a function serving the certificate in the PEM secrets cert and
+// key, for tls.Config.GetCertificate / GetClientCertificate hooks. It reparses
+// only after either secret rotates; while a rotation is half done (the new
+// certificate with the old key), it keeps serving the last valid pair.
+func KeyPair(cert, key *Secret) func() (*tls.Certificate, error) {
+	var (
+		mu                sync.Mutex
+		lastCert, lastKey []byte
+		current           *tls.Certificate
+	)
+	return func() (*tls.Certificate, error) {
+		c, k := cert.Value(), key.Value()
+		mu.Lock()
+		defer mu.Unlock()
+		if current != nil && bytes.Equal(c, lastCert) && bytes.Equal(k, lastKey) {
+			return current, nil
+		}
+		pair, err := tls.X509KeyPair(c, k)
+		if err != nil {
+			if current != nil {
+				return current, nil
+			}
+			return nil, err
+		}
+		current, lastCert, lastKey = &pair, c, k
+		return current, nil
+	}
+}
@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VaultOptions configure a Vault KV (version 2) provider.
+type VaultOptions struct {
+	// Addr is the Vault server, e.g. https://vault.internal:8200.
+	Addr  string
+	Token string
+	// Mount is the KV engine's mount path (default "secret").
+	Mount string
+	// Path is the KV entry holding the service's secrets, e.g. "auth"; each
+	// secret is a key of that entry.
+	Path string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Vault reads secrets from one HashiCorp Vault KV v2 entry. Writing a new
+// version of the entry rotates every secret it holds.
+type Vault struct {
+	opts VaultOptions
+}
+
+func NewVault(opts VaultOptions) (*Vault, error) {
+	if opts.Addr == "" || opts.Path == "" {
+		return nil, errors.New("secrets: Vault Addr and Path are required")
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Vault{opts: opts}, nil
+}
+
+func (v *Vault) Get(ctx context.Context, name string) ([]byte, error) {
+	u := strings.TrimRight(v.opts.Addr, "/") + "/v1/" + url.PathEscape(v.opts.Mount) + "/data/" + strings.Trim(v.opts.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.opts.Token)
+	resp, err := v.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: status %d", resp.StatusCode)
+	}
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	return lookup(body.Data.Data, name)
+}
+
+// lookup returns name from a decoded JSON object of secrets.
+func lookup(m map[string]any, name string) ([]byte, error) {
+	switch v := m[name].(type) {
+	case string:
+		if v == "" {
+			break
+		}
+		return []byte(v), nil
+	case nil:
+	default:
+		return nil, fmt.Errorf("secrets: %s is not a string", name)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
@@ -0,0 +1,193 @@
+// Package shutdown stops a service in phases: components register closers
+// with the phase they belong to, and Shutdown runs the phases in order, each
+// bounded by its own timeout, so a slow drain can't eat the time needed to
+// flush telemetry or close the database cleanly.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Phase is a step of shutdown. Phases run in declaration order.
+type Phase int
+
+const (
+	// StopIntake stops accepting new work: drain health, pause consumers.
+	StopIntake Phase = iota
+	// Drain waits for in-flight work: server graceful stop, background jobs.
+	Drain
+	// Flush writes out buffered data: audit logs, traces, metrics.
+	Flush
+	// Close releases connections: database pools, caches, downstream clients.
+	Close
+
+	numPhases
+)
+
+func (p Phase) String() string {
+	switch p {
+	case StopIntake:
+		return "stop intake"
+	case Drain:
+		return "drain"
+	case Flush:
+		return "flush"
+	case Close:
+		return "close"
+	}
+	return fmt.Sprintf("phase %d", int(p))
+}
+
+// DefaultTimeouts bound each phase unless Options override them.
+var DefaultTimeouts = map[Phase]time.Duration{
+	StopIntake: 5 * time.Second,
+	Drain:      10 * time.Second,
+	Flush:      5 * time.Second,
+	Close:      5 * time.Second,
+}
+
+// Closer stops one component. It should return once ctx is done.
+type Closer func(ctx context.Context) error
+
+// Func adapts a close function without context or error (e.g. pgxpool's
+// Close) to a Closer.
+func Func(fn func()) Closer {
+	return func(context.Context) error {
+		fn()
+		return nil
+	}
+}
+
+// Options configure New.
+type Options struct {
+	// Timeouts override DefaultTimeouts per phase.
+	Timeouts map[Phase]time.Duration
+	// Log reports each phase and closer failures (optional).
+	Log *zap.Logger
+}
+
+// Manager collects closers and runs them once, at Shutdown.
+type Manager struct {
+	timeouts [numPhases]time.Duration
+	log      *zap.Logger
+
+	mu      sync.Mutex
+	closers [numPhases][]closer
+	once    sync.Once
+	err     error
+}
+
+type closer struct {
+	name string
+	fn   Closer
+}
+
+// New returns an empty Manager.
+func New(opts Options) *Manager {
+	if opts.Log == nil {
+		opts.Log = zap.NewNop()
+	}
+	m := &Manager{log: opts.Log}
+	for p := Phase(0); p < numPhases; p++ {
+		m.timeouts[p] = DefaultTimeouts[p]
+		if d, ok := opts.Timeouts[p]; ok {
+			m.timeouts[p] = d
+		}
+	}
+	return m
+}
+
+// Register adds fn to phase. Within a phase, closers run one at a time in
+// reverse registration order, like defers, so a component registered after
+// its dependencies stops before them.
+func (m *Manager) Register(phase Phase, name string, fn Closer) {
+	if phase < 0 || phase >= numPhases {
+		panic(fmt.Sprintf("shutdown: invalid phase %d", phase))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers[phase] = append(m.closers[phase], closer{name: name, fn: fn})
+}
+
+// Shutdown runs every phase and returns the closers' errors, joined. A phase
+// that outlives its timeout is abandoned (its remaining closers keep running
+// in the background) and the next phase starts. Only the first call runs the
+// closers; later calls return the same result.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		var errs []error
+		for p := Phase(0); p < numPhases; p++ {
+			m.mu.Lock()
+			cs := m.closers[p]
+			m.mu.Unlock()
+			if len(cs) == 0 {
+				continue
+			}
+			if err := m.runPhase(ctx, p, cs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
+
+func (m *Manager) runPhase(ctx context.Context, p Phase, cs []closer) error {
+	start := time.Now()
+	if d := m.timeouts[p]; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	var (
+		mu      sync.Mutex
+		errs    []error
+		pending = len(cs)
+		current string
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(cs) - 1; i >= 0; i-- {
+			c := cs[i]
+			mu.Lock()
+			current = c.name
+			mu.Unlock()
+			err := c.fn(ctx)
+			mu.Lock()
+			pending--
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			}
+			mu.Unlock()
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		select {
+		case <-done:
+		default:
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("shutdown: %s: gave up after %s waiting for %s (%d closers left)",
+				p, time.Since(start).Round(time.Millisecond), current, pending))
+			mu.Unlock()
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, err := range errs {
+		m.log.Error("shutdown step failed", zap.Stringer("phase", p), zap.Error(err))
+	}
+	m.log.Debug("shutdown phase done", zap.Stringer("phase", p), zap.Duration("elapsed", time.Since(start)))
+	return errors.Join(errs...)
+}
@@ -0,0 +1,93 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	m := New(Options{})
+	var order []string
+	record := func(name string) Closer {
+		return Func(func() { order = append(order, name) })
+	}
+	// Registered out of phase order on purpose.
+	m.Register(Close, "db", record("db"))
+	m.Register(Flush, "traces", record("traces"))
+	m.Register(Drain, "server", record("server"))
+	m.Register(Drain, "jobs", record("jobs"))
+	m.Register(StopIntake, "health", record("health"))
+	m.Register(Close, "cache", record("cache"))
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	want := []string{"health", "jobs", "server", "traces", "cache", "db"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+
+	// Later calls don't run the closers again.
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown: %v", err)
+	}
+	if len(order) != len(want) {
+		t.Fatalf("closers ran again: %v", order)
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	m := New(Options{})
+	boom := errors.New("boom")
+	closed := false
+	m.Register(Flush, "audit", func(context.Context) error { return boom })
+	m.Register(Close, "db", Func(func() { closed = true }))
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "audit: boom") {
+		t.Fatalf("err = %v, want audit: boom", err)
+	}
+	if !closed {
+		t.Fatal("a failing phase must not skip the next")
+	}
+	if err2 := m.Shutdown(context.Background()); err2 != err {
+		t.Fatalf("second Shutdown = %v, want %v", err2, err)
+	}
+}
+
+func TestShutdownPhaseTimeout(t *testing.T) {
+	m := New(Options{Timeouts: map[Phase]time.Duration{Drain: 20 * time.Millisecond}})
+	release := make(chan struct{})
+	defer close(release)
+	m.Register(Drain, "stuck", func(context.Context) error {
+		<-release // ignores ctx
+		return nil
+	})
+	closed := false
+	m.Register(Close, "db", Func(func() { closed = true }))
+
+	start := time.Now()
+	err := m.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "drain: gave up") || !strings.Contains(err.Error(), "stuck") {
+		t.Fatalf("err = %v, want drain timeout naming stuck", err)
+	}
+	if !closed {
+		t.Fatal("Close phase did not run after Drain timed out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Shutdown took %s", elapsed)
+	}
+}
+
+func TestRegisterInvalidPhase(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register with an invalid phase did not panic")
+		}
+	}()
+	New(Options{}).Register(numPhases, "x", Func(func() {}))
+}
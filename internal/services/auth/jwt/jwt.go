@@ -3,6 +3,7 @@ package jwt
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
@@ -13,35 +14,75 @@ var (
 )
 
 type Service struct {
-	secret []byte
+	keys   atomic.Pointer[keys]
 	issuer string
 }
 
 func New(secret, issuer string) *Service {
-	return &Service{secret: []byte(secret), issuer: issuer}
+	s := &Service{issuer: issuer}
+	s.keys.Store(&keys{current: []byte(secret)})
+	return s
+}
+
+// keys are the signing key and the one it replaced.
+type keys struct {
+	current, previous []byte
+}
+
+// Rotate makes secret the signing key. Tokens signed with the key it replaces
+// keep verifying until they expire, so a rotation doesn't invalidate sessions;
+// a second rotation retires that key.
+func (s *Service) Rotate(secret []byte) {
+	old := s.keys.Load()
+	if string(old.current) == string(secret) {
+		return
+	}
+	s.keys.Store(&keys{current: secret, previous: old.current})
 }
 
 type Claims struct {
 	Email string `json:"email,omitempty"`
+	// TenantID is the tenant the token was issued for (authjwt.Claims.TenantID).
+	TenantID string `json:"tid,omitempty"`
+	// SessionID is the login session the token belongs to; Validate rejects the
+	// token once that session is revoked.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Identity is what an access token asserts.
+type Identity struct {
+	UserID string
+	Email  string
+	// TenantID is the tenant the user logged in to ("" for none).
+	TenantID string
+	// SessionID ties the token to a login session ("" for none).
+	SessionID string
+}
+
 func (s *Service) NewAccessToken(userID, email string, ttl time.Duration) (token string, exp time.Time, err error) {
+	return s.IssueAccessToken(Identity{UserID: userID, Email: email}, ttl)
+}
+
+// IssueAccessToken is NewAccessToken with the tenant and session claims.
+func (s *Service) IssueAccessToken(id Identity, ttl time.Duration) (token string, exp time.Time, err error) {
 	now := time.Now().UTC()
 	exp = now.Add(ttl)
 
 	claims := &Claims{
-		Email: email,
+		Email:     id.Email,
+		TenantID:  id.TenantID,
+		SessionID: id.SessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    s.issuer,
-			Subject:   userID,
+			Subject:   id.UserID,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(exp),
 		},
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := t.SignedString(s.secret)
+	signed, err := t.SignedString(s.keys.Load().current)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("sign: %w", err)
 	}
@@ -66,7 +107,7 @@ func (s *Service) NewRefreshToken(userID, email string, ttl time.Duration) (stri
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := t.SignedString(s.secret)
+	signed, err := t.SignedString(s.keys.Load().current)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("sign: %w", err)
 	}
@@ -78,7 +119,11 @@ func (s *Service) Parse(token string) (*Claims, error) {
 		if t.Method != jwt.SigningMethodHS256 {
 			return nil, ErrInvalidToken
 		}
-		return s.secret, nil
+		k := s.keys.Load()
+		if k.previous == nil {
+			return k.current, nil
+		}
+		return jwt.VerificationKeySet{Keys: []jwt.VerificationKey{k.current, k.previous}}, nil
 	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
 	if err != nil {
 		return nil, ErrInvalidToken
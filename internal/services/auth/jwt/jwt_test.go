@@ -48,3 +48,31 @@ func TestParseRejectsGarbage(t *testing.T) {
 		t.Fatalf("expected parse error")
 	}
 }
+
+func TestRotateKeepsPreviousKeyForVerification(t *testing.T) {
+	s := New("old-secret", "issuer")
+	old, _, err := s.NewAccessToken("user-123", "u@example.com", time.Minute)
+	if err != nil {
+		t.Fatalf("NewAccessToken err=%v", err)
+	}
+
+	s.Rotate([]byte("new-secret"))
+	if _, err := s.Parse(old); err != nil {
+		t.Fatalf("token signed before rotation rejected: %v", err)
+	}
+	tok, _, err := s.NewAccessToken("user-123", "u@example.com", time.Minute)
+	if err != nil {
+		t.Fatalf("NewAccessToken err=%v", err)
+	}
+	if _, err := New("old-secret", "issuer").Parse(tok); err == nil {
+		t.Fatal("token signed after rotation verified with the old key")
+	}
+
+	s.Rotate([]byte("newer-secret"))
+	if _, err := s.Parse(old); err == nil {
+		t.Fatal("key retired by a second rotation still verifies")
+	}
+	if _, err := s.Parse(tok); err != nil {
+		t.Fatalf("token signed with the previous key rejected: %v", err)
+	}
+}
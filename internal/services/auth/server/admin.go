@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	authv1 "sdk-microservices/gen/api/proto/auth/v1"
+	"sdk-microservices/internal/db"
+	"sdk-microservices/internal/db/pagination"
+	"sdk-microservices/internal/platform/authjwt"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/services/auth/store"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SessionReadScope grants read access to every user's sessions via AuthAdminService.
+const SessionReadScope = "auth.sessions.read"
+
+// SessionSearcher is the session lookup AdminServer needs, implemented by
+// *store.Store.
+type SessionSearcher interface {
+	SearchSessions(ctx context.Context, f store.SessionFilter, size int, pageToken string) ([]store.Session, string, error)
+}
+
+// AdminServer implements AuthAdminService for abuse investigations. Callers
+// need a verified token carrying SessionReadScope (see grpcutil.IdentityOptions).
+type AdminServer struct {
+	authv1.UnimplementedAuthAdminServiceServer
+
+	log *zap.Logger
+	s   SessionSearcher
+}
+
+func NewAdmin(log *zap.Logger, s SessionSearcher) *AdminServer {
+	return &AdminServer{log: log, s: s}
+}
+
+func (a *AdminServer) SearchSessions(ctx context.Context, req *authv1.SearchSessionsRequest) (*authv1.SearchSessionsResponse, error) {
+	claims, ok := authjwt.ClaimsFrom(ctx)
+	if !ok {
+		return nil, errs.Unauthenticated("MISSING_TOKEN", "missing or invalid bearer token")
+	}
+	if !claims.HasScope(SessionReadScope) {
+		return nil, errs.PermissionDenied("INSUFFICIENT_SCOPE", "missing required scope")
+	}
+
+	f := store.SessionFilter{
+		UserID:            req.GetUserId(),
+		IP:                req.GetIp(),
+		UserAgentContains: req.GetUserAgentContains(),
+	}
+	if req.GetCreatedAfter() != nil {
+		f.CreatedAfter = req.GetCreatedAfter().AsTime()
+	}
+	if req.GetCreatedBefore() != nil {
+		f.CreatedBefore = req.GetCreatedBefore().AsTime()
+	}
+
+	// Investigations tolerate replica lag.
+	sessions, next, err := a.s.SearchSessions(db.WithReadOnly(ctx), f, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidToken) {
+			return nil, errs.Validation(errs.FieldViolation{Field: "page_token", Description: "invalid page token"})
+		}
+		if _, ok := errs.As(err); ok {
+			return nil, err
+		}
+		a.log.Error("search sessions failed", zap.Error(err))
+		return nil, errs.Internal(err)
+	}
+
+	resp := &authv1.SearchSessionsResponse{
+		Sessions:      make([]*authv1.AdminSession, 0, len(sessions)),
+		NextPageToken: next,
+	}
+	for _, s := range sessions {
+		out := &authv1.AdminSession{
+			Id:        s.ID,
+			UserId:    s.UserID,
+			CreatedAt: timestamppb.New(s.CreatedAt),
+			ExpiresAt: timestamppb.New(s.ExpiresAt),
+			UserAgent: s.UserAgent,
+			Ip:        s.IP,
+		}
+		if s.RevokedAt != nil {
+			out.RevokedAt = timestamppb.New(*s.RevokedAt)
+		}
+		resp.Sessions = append(resp.Sessions, out)
+	}
+	return resp, nil
+}
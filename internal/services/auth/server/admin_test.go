@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authv1 "sdk-microservices/gen/api/proto/auth/v1"
+	"sdk-microservices/internal/db/pagination"
+	"sdk-microservices/internal/platform/authjwt"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/services/auth/store"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeSearcher struct {
+	got      store.SessionFilter
+	sessions []store.Session
+	err      error
+}
+
+func (f *fakeSearcher) SearchSessions(_ context.Context, filter store.SessionFilter, _ int, _ string) ([]store.Session, string, error) {
+	f.got = filter
+	return f.sessions, "next", f.err
+}
+
+func withScope(scope string) context.Context {
+	return authjwt.WithClaims(context.Background(), &authjwt.Claims{Scope: scope})
+}
+
+func TestAdminSearchSessionsRequiresScope(t *testing.T) {
+	a := NewAdmin(zap.NewNop(), &fakeSearcher{})
+
+	if _, err := a.SearchSessions(context.Background(), &authv1.SearchSessionsRequest{}); errs.KindOf(err) != errs.KindUnauthenticated {
+		t.Fatalf("no claims: err = %v, want unauthenticated", err)
+	}
+	if _, err := a.SearchSessions(withScope("profile"), &authv1.SearchSessionsRequest{}); errs.KindOf(err) != errs.KindPermissionDenied {
+		t.Fatalf("missing scope: err = %v, want permission denied", err)
+	}
+}
+
+func TestAdminSearchSessions(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	revoked := created.Add(time.Hour)
+	fs := &fakeSearcher{sessions: []store.Session{
+		{ID: "s1", UserID: "u1", CreatedAt: created, ExpiresAt: created.Add(24 * time.Hour), RevokedAt: &revoked, UserAgent: "curl/8.0", IP: "203.0.113.7"},
+		{ID: "s2", UserID: "u1", CreatedAt: created, ExpiresAt: created.Add(24 * time.Hour)},
+	}}
+	a := NewAdmin(zap.NewNop(), fs)
+
+	resp, err := a.SearchSessions(withScope("openid "+SessionReadScope), &authv1.SearchSessionsRequest{
+		Ip:                "203.0.113.0/24",
+		UserAgentContains: "curl",
+		CreatedAfter:      timestamppb.New(created),
+	})
+	if err != nil {
+		t.Fatalf("SearchSessions: %v", err)
+	}
+	if fs.got.IP != "203.0.113.0/24" || fs.got.UserAgentContains != "curl" || !fs.got.CreatedAfter.Equal(created) || !fs.got.CreatedBefore.IsZero() {
+		t.Fatalf("filter = %+v", fs.got)
+	}
+	if resp.GetNextPageToken() != "next" || len(resp.GetSessions()) != 2 {
+		t.Fatalf("resp = %v", resp)
+	}
+	if s := resp.GetSessions()[0]; s.GetRevokedAt().AsTime() != revoked || s.GetIp() != "203.0.113.7" {
+		t.Fatalf("session[0] = %v", s)
+	}
+	if resp.GetSessions()[1].GetRevokedAt() != nil {
+		t.Fatal("active session has revoked_at")
+	}
+
+	fs.err = pagination.ErrInvalidToken
+	if _, err := a.SearchSessions(withScope(SessionReadScope), &authv1.SearchSessionsRequest{PageToken: "bogus"}); errs.KindOf(err) != errs.KindValidation {
+		t.Fatalf("bad token: err = %v, want validation", err)
+	}
+}
@@ -8,46 +8,83 @@ import (
 	"time"
 
 	authv1 "sdk-microservices/gen/api/proto/auth/v1"
+	"sdk-microservices/internal/db"
+	"sdk-microservices/internal/platform/audit"
+	"sdk-microservices/internal/platform/authctx"
+	"sdk-microservices/internal/platform/errs"
+	"sdk-microservices/internal/platform/grpcutil"
+	"sdk-microservices/internal/platform/logging"
 	"sdk-microservices/internal/services/auth/jwt"
 	"sdk-microservices/internal/services/auth/password"
 	"sdk-microservices/internal/services/auth/store"
+	"sdk-microservices/internal/services/auth/tokens"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 var emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
 
+// UserStore is the user and session persistence the server needs, implemented
+// by *store.Store and *store.CachedStore.
+type UserStore interface {
+	CreateUser(ctx context.Context, email, passwordHash string) (*store.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*store.User, error)
+	GetUserByID(ctx context.Context, id string) (*store.User, error)
+	CreateSession(ctx context.Context, in store.NewSession, limit store.SessionLimit) (id string, evicted []string, err error)
+	SessionActive(ctx context.Context, id, userID string) (bool, error)
+}
+
 type Server struct {
 	authv1.UnimplementedAuthServiceServer
 
-	log *zap.Logger
-	s   *store.Store
-	jwt *jwt.Service
+	log   *zap.Logger
+	s     UserStore
+	jwt   *jwt.Service
+	audit *audit.Logger
 
-	accessTTL  time.Duration
-	refreshTTL time.Duration
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+	sessionLimit store.SessionLimit
+
+	evictions metric.Int64Counter
 }
 
 type Options struct {
 	AccessTTL  time.Duration
 	RefreshTTL time.Duration
+	// SessionLimit caps each user's active sessions (default: unlimited).
+	SessionLimit store.SessionLimit
+	// Audit records registrations and logins (optional).
+	Audit *audit.Logger
 }
 
-func New(log *zap.Logger, st *store.Store, jwtSvc *jwt.Service, opt Options) *Server {
+func New(log *zap.Logger, st UserStore, jwtSvc *jwt.Service, opt Options) *Server {
 	if opt.AccessTTL == 0 {
 		opt.AccessTTL = 15 * time.Minute
 	}
 	if opt.RefreshTTL == 0 {
 		opt.RefreshTTL = 7 * 24 * time.Hour
 	}
+	evictions, err := otel.Meter("sdk-microservices/auth").Int64Counter("auth.sessions.evicted",
+		metric.WithDescription("Sessions revoked to keep a user under the session limit"),
+		metric.WithUnit("{session}"))
+	if err != nil {
+		log.Warn("session eviction metric disabled (init failed)", zap.Error(err))
+	}
 	return &Server{
-		log:        log,
-		s:          st,
-		jwt:        jwtSvc,
-		accessTTL:  opt.AccessTTL,
-		refreshTTL: opt.RefreshTTL,
+		log:          log,
+		s:            st,
+		jwt:          jwtSvc,
+		audit:        opt.Audit,
+		accessTTL:    opt.AccessTTL,
+		refreshTTL:   opt.RefreshTTL,
+		sessionLimit: opt.SessionLimit,
+		evictions:    evictions,
 	}
 }
 
@@ -55,29 +92,40 @@ func (s *Server) Register(ctx context.Context, req *authv1.RegisterRequest) (*au
 	email := strings.TrimSpace(strings.ToLower(req.GetEmail()))
 	pw := req.GetPassword()
 
+	var violations []errs.FieldViolation
 	if !emailRe.MatchString(email) {
-		return nil, status.Error(codes.InvalidArgument, "invalid email")
+		violations = append(violations, errs.FieldViolation{Field: "email", Description: "invalid email"})
 	}
 	if len(pw) < 12 {
-		return nil, status.Error(codes.InvalidArgument, "password must be at least 12 characters")
+		violations = append(violations, errs.FieldViolation{Field: "password", Description: "password must be at least 12 characters"})
+	}
+	if len(violations) > 0 {
+		s.record(ctx, "auth.register", audit.Failure, "invalid_request", logging.MaskEmail(email))
+		return nil, errs.Validation(violations...)
 	}
 
 	hash, err := password.Hash(pw)
 	if err != nil {
-		s.log.Error("hash password", zap.Error(err))
+		s.reqLog(ctx).Error("hash password", zap.Error(err))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
 	u, err := s.s.CreateUser(ctx, email, hash)
 	if err != nil {
-		// Postgres unique violation text varies; keep it simple.
-		if strings.Contains(strings.ToLower(err.Error()), "unique") || strings.Contains(strings.ToLower(err.Error()), "duplicate") {
-			return nil, status.Error(codes.AlreadyExists, "email already registered")
+		if errors.Is(err, store.ErrEmailTaken) {
+			s.record(ctx, "auth.register", audit.Failure, "email_taken", logging.MaskEmail(email))
+			return nil, err
 		}
-		s.log.Error("create user", zap.Error(err))
-		return nil, status.Error(codes.Internal, "internal error")
+		s.reqLog(ctx).Error("create user", zap.Error(err), logging.Email("email", email))
+		var connErr *db.ConnectionError
+		if errors.As(db.ClassifyError(err), &connErr) {
+			// Nothing to conflict with yet: the client can safely retry.
+			return nil, status.Error(codes.Unavailable, "database unavailable")
+		}
+		return nil, errs.Internal(err)
 	}
 
+	s.record(ctx, "auth.register", audit.Success, "", u.ID)
 	return &authv1.RegisterResponse{UserId: u.ID}, nil
 }
 
@@ -92,31 +140,68 @@ func (s *Server) Login(ctx context.Context, req *authv1.LoginRequest) (*authv1.L
 		return nil, status.Error(codes.InvalidArgument, "password required")
 	}
 
-	u, err := s.s.GetUserByEmail(ctx, email)
+	u, err := s.s.GetUserByEmail(db.WithReadOnly(ctx), email)
 	if err != nil {
 		// Avoid user enumeration.
+		s.record(ctx, "auth.login", audit.Failure, "unknown_user", logging.MaskEmail(email))
 		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 	}
 
 	if err := password.Verify(pw, u.PasswordHash); err != nil {
 		if errors.Is(err, password.ErrMismatch) {
+			s.record(ctx, "auth.login", audit.Failure, "invalid_credentials", u.ID)
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 		}
-		s.log.Error("verify password", zap.Error(err))
+		s.reqLog(ctx).Error("verify password", zap.Error(err), zap.String("user_id", u.ID))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	access, exp, err := s.jwt.NewAccessToken(u.ID, u.Email, s.accessTTL)
+	// The refresh token is opaque: only its hash in the session row makes it valid.
+	refresh, err := tokens.NewRefreshToken()
+	refreshExp := time.Now().Add(s.refreshTTL)
 	if err != nil {
-		s.log.Error("issue access token", zap.Error(err))
+		s.reqLog(ctx).Error("issue refresh token", zap.Error(err), zap.String("user_id", u.ID))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
-	refresh, _, err := s.jwt.NewRefreshToken(u.ID, u.Email, s.refreshTTL)
+	ip, _ := grpcutil.ClientIP(ctx)
+	sessionID, evicted, err := s.s.CreateSession(ctx, store.NewSession{
+		UserID:           u.ID,
+		RefreshTokenHash: tokens.HashRefreshToken(refresh),
+		ExpiresAt:        refreshExp,
+		UserAgent:        userAgent(ctx),
+		IP:               ip,
+	}, s.sessionLimit)
+	if err != nil {
+		if errors.Is(err, store.ErrTooManySessions) {
+			s.record(ctx, "auth.login", audit.Failure, "too_many_sessions", u.ID)
+			return nil, err
+		}
+		s.reqLog(ctx).Error("create session", zap.Error(err), zap.String("user_id", u.ID))
+		return nil, errs.Internal(err)
+	}
+	// The access token names its session, so Validate stops accepting it once
+	// the session is revoked (e.g. evicted by the session limit). It is bound to
+	// the tenant the user logged in to, so later calls take their tenant from it
+	// rather than from forwarded metadata.
+	tenant, _ := authctx.TenantID(ctx)
+	access, exp, err := s.jwt.IssueAccessToken(jwt.Identity{
+		UserID:    u.ID,
+		Email:     u.Email,
+		TenantID:  tenant,
+		SessionID: sessionID,
+	}, s.accessTTL)
 	if err != nil {
-		s.log.Error("issue refresh token", zap.Error(err))
+		s.reqLog(ctx).Error("issue access token", zap.Error(err), zap.String("user_id", u.ID))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	if len(evicted) > 0 {
+		if s.evictions != nil {
+			s.evictions.Add(ctx, int64(len(evicted)))
+		}
+		s.record(ctx, "auth.session.evicted", audit.Success, "session_limit", u.ID)
+	}
 
+	s.record(ctx, "auth.login", audit.Success, "", u.ID)
 	return &authv1.LoginResponse{
 		UserId:                 u.ID,
 		AccessToken:            access,
@@ -135,9 +220,60 @@ func (s *Server) Validate(ctx context.Context, req *authv1.ValidateRequest) (*au
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "invalid token")
 	}
+	// Only tokens from Login carry a session; a revoked or expired session
+	// (logout, session limit) invalidates them before they expire.
+	if claims.SessionID == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	active, err := s.s.SessionActive(db.WithReadOnly(ctx), claims.SessionID, claims.Subject)
+	if err != nil {
+		s.reqLog(ctx).Error("look up session", zap.Error(err), zap.String("user_id", claims.Subject))
+		return nil, errs.Internal(err)
+	}
+	if !active {
+		return nil, status.Error(codes.Unauthenticated, "session revoked")
+	}
+	// The current email, from the user cache (CachedStore) on the hot path.
+	u, err := s.s.GetUserByID(db.WithReadOnly(ctx), claims.Subject)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
 
 	return &authv1.ValidateResponse{
-		UserId: claims.Subject,
-		Email:  claims.Email,
+		UserId: u.ID,
+		Email:  u.Email,
 	}, nil
 }
+
+// userAgent returns the caller's User-Agent as forwarded in gRPC metadata.
+func userAgent(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("user-agent"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// reqLog returns the logger annotated with the caller's (masked) client IP.
+func (s *Server) reqLog(ctx context.Context) *zap.Logger {
+	if ip, ok := grpcutil.ClientIP(ctx); ok {
+		return s.log.With(logging.IP("client.addr", ip))
+	}
+	return s.log
+}
+
+// record writes a security audit event about subject (a user id, or the masked
+// attempted email when no user is known).
+func (s *Server) record(ctx context.Context, typ, outcome, reason, subject string) {
+	if s.audit == nil {
+		return
+	}
+	ip, _ := grpcutil.ClientIP(ctx)
+	s.audit.Record(ctx, audit.Event{
+		Type:     typ,
+		Outcome:  outcome,
+		Reason:   reason,
+		Subject:  subject,
+		ClientIP: ip,
+	})
+}
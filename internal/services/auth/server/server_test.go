@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	authv1 "sdk-microservices/gen/api/proto/auth/v1"
+	"sdk-microservices/internal/db"
+	"sdk-microservices/internal/services/auth/jwt"
+	"sdk-microservices/internal/services/auth/password"
+	"sdk-microservices/internal/services/auth/store"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memStore is an in-memory UserStore that enforces the session limit like
+// store.Store (oldest sessions are revoked first).
+type memStore struct {
+	user     store.User
+	sessions []string
+	revoked  map[string]bool
+	// readOnly records whether each SessionActive call allowed a replica.
+	readOnly []bool
+}
+
+func newMemStore(t *testing.T, email, pw string) *memStore {
+	t.Helper()
+	hash, err := password.Hash(pw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &memStore{user: store.User{ID: "u1", Email: email, PasswordHash: hash}, revoked: map[string]bool{}}
+}
+
+func (m *memStore) CreateUser(context.Context, string, string) (*store.User, error) {
+	return nil, store.ErrEmailTaken
+}
+
+func (m *memStore) GetUserByEmail(_ context.Context, email string) (*store.User, error) {
+	if email != m.user.Email {
+		return nil, errors.New("not found")
+	}
+	u := m.user
+	return &u, nil
+}
+
+func (m *memStore) GetUserByID(_ context.Context, id string) (*store.User, error) {
+	if id != m.user.ID {
+		return nil, errors.New("not found")
+	}
+	u := m.user
+	return &u, nil
+}
+
+func (m *memStore) CreateSession(_ context.Context, in store.NewSession, limit store.SessionLimit) (string, []string, error) {
+	var active []string
+	for _, id := range m.sessions {
+		if !m.revoked[id] {
+			active = append(active, id)
+		}
+	}
+	var evicted []string
+	if over := len(active) - limit.Max + 1; limit.Max > 0 && over > 0 {
+		evicted = active[:over]
+		for _, id := range evicted {
+			m.revoked[id] = true
+		}
+	}
+	id := "s" + strconv.Itoa(len(m.sessions)+1)
+	m.sessions = append(m.sessions, id)
+	return id, evicted, nil
+}
+
+func (m *memStore) SessionActive(ctx context.Context, id, userID string) (bool, error) {
+	m.readOnly = append(m.readOnly, db.IsReadOnly(ctx))
+	for _, s := range m.sessions {
+		if s == id {
+			return userID == m.user.ID && !m.revoked[id], nil
+		}
+	}
+	return false, nil
+}
+
+func TestValidateRejectsEvictedSession(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(t, "a@example.com", "correct horse battery")
+	jwtSvc := jwt.New("secret", "test")
+	srv := New(zap.NewNop(), st, jwtSvc, Options{SessionLimit: store.SessionLimit{Max: 1}})
+
+	login := func() *authv1.LoginResponse {
+		t.Helper()
+		resp, err := srv.Login(ctx, &authv1.LoginRequest{Email: "a@example.com", Password: "correct horse battery"})
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		return resp
+	}
+	first := login()
+	if _, err := srv.Validate(ctx, &authv1.ValidateRequest{AccessToken: first.GetAccessToken()}); err != nil {
+		t.Fatalf("Validate(first) before eviction: %v", err)
+	}
+
+	second := login() // evicts the first session
+	if _, err := srv.Validate(ctx, &authv1.ValidateRequest{AccessToken: first.GetAccessToken()}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Validate(evicted) err = %v, want Unauthenticated", err)
+	}
+	resp, err := srv.Validate(ctx, &authv1.ValidateRequest{AccessToken: second.GetAccessToken()})
+	if err != nil || resp.GetUserId() != "u1" {
+		t.Fatalf("Validate(second) = %v, %v", resp, err)
+	}
+	for i, ro := range st.readOnly {
+		if !ro {
+			t.Fatalf("session lookup %d not routed to a replica", i)
+		}
+	}
+	if first.GetRefreshToken() == second.GetRefreshToken() {
+		t.Fatal("refresh tokens repeat across logins")
+	}
+}
+
+func TestValidateRejectsTokenWithoutSession(t *testing.T) {
+	jwtSvc := jwt.New("secret", "test")
+	srv := New(zap.NewNop(), newMemStore(t, "a@example.com", "correct horse battery"), jwtSvc, Options{})
+
+	tok, _, err := jwtSvc.NewAccessToken("u1", "a@example.com", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Validate(context.Background(), &authv1.ValidateRequest{AccessToken: tok}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Validate err = %v, want Unauthenticated", err)
+	}
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheOptions configures NewCachedStore.
+type CacheOptions struct {
+	// TTL bounds how stale a cached user can be (default 5m).
+	TTL time.Duration
+	// Prefix namespaces keys (default "auth:user:").
+	Prefix string
+	// Service names the meter for cache.requests (optional).
+	Service string
+	Log     *zap.Logger
+}
+
+// CachedStore is a Store whose user lookups read through Redis. Concurrent misses
+// for the same key share one database query, and Redis errors fall back to the
+// database. Only found users are cached, so a just-registered user is visible
+// immediately.
+//
+// Cached entries include the password hash (Login needs it): treat the Redis
+// instance with the same care as the database.
+type CachedStore struct {
+	*Store
+
+	rdb   redis.UniversalClient
+	opts  CacheOptions
+	group singleflight.Group
+
+	requests metric.Int64Counter
+}
+
+// NewCachedStore wraps st with a Redis read-through cache.
+func NewCachedStore(st *Store, rdb redis.UniversalClient, opts CacheOptions) *CachedStore {
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "auth:user:"
+	}
+	if opts.Log == nil {
+		opts.Log = zap.NewNop()
+	}
+	c := &CachedStore{Store: st, rdb: rdb, opts: opts}
+	if opts.Service != "" {
+		var err error
+		c.requests, err = otel.Meter("sdk-microservices/"+opts.Service).Int64Counter("cache.requests",
+			metric.WithDescription("Cache lookups by result (hit, miss, error)"),
+			metric.WithUnit("{request}"))
+		if err != nil {
+			opts.Log.Warn("cache metrics disabled (init failed)", zap.Error(err))
+		}
+	}
+	return c
+}
+
+// GetUserByID is Store.GetUserByID through the cache.
+func (c *CachedStore) GetUserByID(ctx context.Context, id string) (*User, error) {
+	return c.get(ctx, c.idKey(ctx, id), func(ctx context.Context) (*User, error) {
+		return c.Store.GetUserByID(ctx, id)
+	})
+}
+
+// GetUserByEmail is Store.GetUserByEmail through the cache.
+func (c *CachedStore) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	return c.get(ctx, c.emailKey(ctx, email), func(ctx context.Context) (*User, error) {
+		return c.Store.GetUserByEmail(ctx, email)
+	})
+}
+
+// UpdateUser updates u (see Store.UpdateUser) and drops the cached entries for
+// both the old and the new email. They are dropped before the write as well as
+// after it, so a lookup racing the update can't leave the old row cached.
+func (c *CachedStore) UpdateUser(ctx context.Context, u *User) (*User, error) {
+	old, err := c.Store.GetUserByID(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{c.idKey(ctx, u.ID), c.emailKey(ctx, old.Email), c.emailKey(ctx, u.Email)}
+	c.del(ctx, keys...)
+	updated, err := c.Store.UpdateUser(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Email != u.Email {
+		keys = append(keys, c.emailKey(ctx, updated.Email))
+	}
+	c.del(ctx, keys...)
+	return updated, nil
+}
+
+func (c *CachedStore) del(ctx context.Context, keys ...string) {
+	if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+		c.opts.Log.Warn("user cache invalidation failed", zap.Error(err))
+	}
+}
+
+// Invalidate drops u's cached entries; call it after updating or deleting a user
+// (with the old email too, if it changed).
+func (c *CachedStore) Invalidate(ctx context.Context, u *User) error {
+	return c.rdb.Del(ctx, c.idKey(ctx, u.ID), c.emailKey(ctx, u.Email)).Err()
+}
+
+func (c *CachedStore) get(ctx context.Context, key string, load func(context.Context) (*User, error)) (*User, error) {
+	b, err := c.rdb.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		var u User
+		if jerr := json.Unmarshal(b, &u); jerr == nil {
+			c.count(ctx, "hit")
+			return &u, nil
+		}
+		c.count(ctx, "error")
+	case errors.Is(err, redis.Nil):
+		c.count(ctx, "miss")
+	default:
+		c.count(ctx, "error")
+		c.opts.Log.Warn("user cache read failed", zap.Error(err))
+	}
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		// Detached so one caller's cancellation doesn't fail the others sharing it.
+		lctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		u, err := load(lctx)
+		if err != nil {
+			return nil, err
+		}
+		c.store(lctx, u)
+		return u, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	u := *v.(*User)
+	return &u, nil
+}
+
+// store caches u under both of its keys.
+func (c *CachedStore) store(ctx context.Context, u *User) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return
+	}
+	_, err = c.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, c.idKey(ctx, u.ID), b, c.opts.TTL)
+		p.Set(ctx, c.emailKey(ctx, u.Email), b, c.opts.TTL)
+		return nil
+	})
+	if err != nil {
+		c.opts.Log.Warn("user cache write failed", zap.Error(err))
+	}
+}
+
+// prefix scopes keys to the tenant in ctx, so schema-per-tenant deployments
+// (where the same email can exist in several tenants) don't share entries.
+func (c *CachedStore) prefix(ctx context.Context) string {
+	if tid, ok := authctx.TenantID(ctx); ok {
+		return c.opts.Prefix + "t:" + tid + ":"
+	}
+	return c.opts.Prefix
+}
+
+func (c *CachedStore) idKey(ctx context.Context, id string) string {
+	return c.prefix(ctx) + "id:" + id
+}
+
+// emailKey hashes the email so addresses don't appear in Redis keys.
+func (c *CachedStore) emailKey(ctx context.Context, email string) string {
+	h := sha256.Sum256([]byte(email))
+	return c.prefix(ctx) + "email:" + hex.EncodeToString(h[:16])
+}
+
+func (c *CachedStore) count(ctx context.Context, result string) {
+	if c.requests != nil {
+		c.requests.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("cache.name", "auth.users"),
+			attribute.String("result", result),
+		))
+	}
+}
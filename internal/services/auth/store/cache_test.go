@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/platform/authctx"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// With Redis unreachable, lookups fall back to the loader, and concurrent misses
+// for one key share a single load.
+func TestCachedStore_FallbackAndSingleflight(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 50 * time.Millisecond})
+	defer rdb.Close()
+	c := NewCachedStore(&Store{}, rdb, CacheOptions{})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (*User, error) {
+		loads.Add(1)
+		<-release
+		return &User{ID: "u1", Email: "a@example.com"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*User, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u, err := c.get(context.Background(), c.idKey(context.Background(), "u1"), load)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = u
+		}()
+	}
+	time.Sleep(200 * time.Millisecond) // let every caller miss and join the flight
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("loads = %d, want 1", n)
+	}
+	results[0].Email = "changed"
+	if results[1].Email != "a@example.com" {
+		t.Fatal("callers must get independent copies")
+	}
+}
+
+func TestCachedStore_EmailKeyHidesAddress(t *testing.T) {
+	c := NewCachedStore(&Store{}, nil, CacheOptions{})
+	if k := c.emailKey(context.Background(), "alice@example.com"); len(k) == 0 || strings.Contains(k, "alice") {
+		t.Fatalf("key %q leaks the email", k)
+	}
+}
+
+func TestCachedStore_KeysScopedByTenant(t *testing.T) {
+	c := NewCachedStore(&Store{}, nil, CacheOptions{})
+	ctx := context.Background()
+	a := c.emailKey(authctx.WithTenantID(ctx, "acme"), "bob@example.com")
+	b := c.emailKey(authctx.WithTenantID(ctx, "globex"), "bob@example.com")
+	if a == b || a == c.emailKey(ctx, "bob@example.com") {
+		t.Fatalf("tenants share cache key %q", a)
+	}
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"sdk-microservices/internal/db"
+	authdb "sdk-microservices/internal/db/gen/auth"
+	"sdk-microservices/internal/db/pagination"
+	"sdk-microservices/internal/platform/errs"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrTooManySessions is returned by CreateSession when the user is at a
+// rejecting SessionLimit.
+var ErrTooManySessions = errs.RateLimited("TOO_MANY_SESSIONS", "too many active sessions", 0)
+
+// SessionLimit caps how many active (unrevoked, unexpired) sessions a user can
+// hold, so stolen credentials can't quietly accumulate refresh tokens.
+type SessionLimit struct {
+	// Max is the cap; 0 means unlimited.
+	Max int
+	// Reject fails new sessions at the cap with ErrTooManySessions instead of
+	// revoking the user's oldest sessions.
+	Reject bool
+}
+
+// NewSession is a session for CreateSession.
+type NewSession struct {
+	UserID           string
+	RefreshTokenHash []byte
+	ExpiresAt        time.Time
+	UserAgent        string
+	// IP is the client address; ignored if it doesn't parse.
+	IP string
+}
+
+// CreateSession stores in, first enforcing limit: at the cap the oldest active
+// sessions are revoked (their ids are returned) or, with limit.Reject, the new
+// session is refused. Concurrent logins of one user are serialized on the user
+// row so they can't both slip under the cap.
+func (s *Store) CreateSession(ctx context.Context, in NewSession, limit SessionLimit) (id string, evicted []string, err error) {
+	params := authdb.InsertSessionParams{
+		UserID:           in.UserID,
+		RefreshTokenHash: in.RefreshTokenHash,
+		ExpiresAt:        in.ExpiresAt,
+		UserAgent:        pgtype.Text{String: in.UserAgent, Valid: in.UserAgent != ""},
+	}
+	if ip, perr := netip.ParseAddr(in.IP); perr == nil {
+		params.Ip = &ip
+	}
+	err = db.WithTx(ctx, s.DB, pgx.TxOptions{}, func(ctx context.Context, tx pgx.Tx) error {
+		q := authdb.New(tx)
+		evicted = nil // fn reruns on serialization failures
+		if limit.Max > 0 {
+			if _, err := q.LockUser(ctx, in.UserID); err != nil {
+				return err
+			}
+			active, err := q.ListActiveSessionIDs(ctx, in.UserID)
+			if err != nil {
+				return err
+			}
+			if over := len(active) - limit.Max + 1; over > 0 {
+				if limit.Reject {
+					return ErrTooManySessions
+				}
+				evicted = active[:over]
+				if _, err := q.RevokeSessions(ctx, evicted); err != nil {
+					return err
+				}
+			}
+		}
+		id, err = q.InsertSession(ctx, params)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return id, evicted, nil
+}
+
+// SessionActive reports whether session id of userID exists and is neither
+// revoked (e.g. evicted by a SessionLimit) nor expired. It reads from a replica
+// when ctx is marked db.WithReadOnly; a session the replica doesn't show as
+// active is checked on the primary, so a fresh login validates at once, while
+// a revocation can take up to the replica lag bound to apply.
+func (s *Store) SessionActive(ctx context.Context, id, userID string) (bool, error) {
+	params := authdb.SessionActiveParams{ID: id, UserID: userID}
+	r := s.reader(ctx)
+	active, err := authdb.New(r).SessionActive(ctx, params)
+	if err == nil && !active && r != s.DB {
+		active, err = authdb.New(s.DB).SessionActive(ctx, params)
+	}
+	return active, err
+}
+
+// Session is a refresh-token session (the token hash is never returned).
+type Session struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	UserAgent string     `db:"user_agent"`
+	IP        string     `db:"ip"`
+}
+
+// sessionOrder lists newest sessions first.
+var sessionOrder = []pagination.Order{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+
+type sessionCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// ListSessions returns one page of userID's sessions, newest first, and the token
+// for the next page ("" on the last page). Reads may go to a replica when ctx is
+// marked db.WithReadOnly.
+func (s *Store) ListSessions(ctx context.Context, userID string, size int, pageToken string) ([]Session, string, error) {
+	return s.SearchSessions(ctx, SessionFilter{UserID: userID}, size, pageToken)
+}
+
+// SessionFilter selects sessions for SearchSessions; zero fields match
+// everything.
+type SessionFilter struct {
+	UserID string
+	// IP is an address (203.0.113.7) or CIDR block (203.0.113.0/24).
+	IP string
+	// UserAgentContains matches the user agent case-insensitively.
+	UserAgentContains string
+	// CreatedAfter (inclusive) and CreatedBefore (exclusive) bound created_at.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// SearchSessions returns one page of the sessions matching every filter,
+// newest first, and the token for the next page ("" on the last page). It
+// serves abuse investigations, so revoked and expired sessions are included.
+// Reads may go to a replica when ctx is marked db.WithReadOnly.
+func (s *Store) SearchSessions(ctx context.Context, f SessionFilter, size int, pageToken string) ([]Session, string, error) {
+	size = pagination.Size(size)
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if f.UserID != "" {
+		where = append(where, "user_id = "+arg(f.UserID)+"::uuid")
+	}
+	if f.IP != "" {
+		if _, err := netip.ParsePrefix(f.IP); err != nil {
+			if _, err := netip.ParseAddr(f.IP); err != nil {
+				return nil, "", errs.Validation(errs.FieldViolation{Field: "ip", Description: "must be an IP address or CIDR block"})
+			}
+		}
+		where = append(where, "ip <<= "+arg(f.IP)+"::inet")
+	}
+	if f.UserAgentContains != "" {
+		where = append(where, "user_agent ILIKE '%' || "+arg(likeEscaper.Replace(f.UserAgentContains))+" || '%'")
+	}
+	if !f.CreatedAfter.IsZero() {
+		where = append(where, "created_at >= "+arg(f.CreatedAfter))
+	}
+	if !f.CreatedBefore.IsZero() {
+		where = append(where, "created_at < "+arg(f.CreatedBefore))
+	}
+	var cur sessionCursor
+	if ok, err := pagination.Decode(pageToken, &cur); err != nil {
+		return nil, "", err
+	} else if ok {
+		w, kargs := pagination.After(sessionOrder, []any{cur.CreatedAt, cur.ID}, len(args)+1)
+		where = append(where, w)
+		args = append(args, kargs...)
+	}
+
+	q := `
+		SELECT id::text, user_id::text, created_at, expires_at, revoked_at,
+		       COALESCE(user_agent, ''), COALESCE(host(ip), '')
+		FROM sessions`
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += " ORDER BY " + pagination.OrderBy(sessionOrder) + " LIMIT " + strconv.Itoa(size+1)
+
+	rows, err := s.reader(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	out, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Session])
+	if err != nil {
+		return nil, "", err
+	}
+	if len(out) <= size {
+		return out, "", nil
+	}
+	out = out[:size]
+	last := out[size-1]
+	next, err := pagination.Encode(sessionCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	return out, next, err
+}
+
+// likeEscaper escapes LIKE wildcards so user input matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ArchiveSessions moves up to limit sessions that were revoked or expired before
+// cutoff into sessions_archive and returns how many moved. Call it repeatedly
+// until it returns less than limit; rows locked by concurrent refreshes are
+// skipped, not waited on. Each batch is one transaction, bounded by the
+// db.QueryClass in ctx.
+func (s *Store) ArchiveSessions(ctx context.Context, cutoff time.Time, limit int) (n int64, err error) {
+	err = db.WithTx(ctx, s.DB, pgx.TxOptions{}, func(ctx context.Context, tx pgx.Tx) error {
+		n, err = authdb.New(tx).ArchiveSessions(ctx, authdb.ArchiveSessionsParams{
+			Cutoff:    cutoff,
+			BatchSize: int32(limit),
+		})
+		return err
+	})
+	return n, err
+}
+
+// PurgeArchivedSessions deletes up to limit sessions archived before cutoff and
+// returns how many were deleted, in one transaction like ArchiveSessions.
+func (s *Store) PurgeArchivedSessions(ctx context.Context, cutoff time.Time, limit int) (n int64, err error) {
+	err = db.WithTx(ctx, s.DB, pgx.TxOptions{}, func(ctx context.Context, tx pgx.Tx) error {
+		n, err = authdb.New(tx).PurgeArchivedSessions(ctx, authdb.PurgeArchivedSessionsParams{
+			Cutoff:    cutoff,
+			BatchSize: int32(limit),
+		})
+		return err
+	})
+	return n, err
+}
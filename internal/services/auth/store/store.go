@@ -2,13 +2,33 @@ package store
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
+	"sdk-microservices/internal/db"
+	authdb "sdk-microservices/internal/db/gen/auth"
+	"sdk-microservices/internal/db/pagination"
+	"sdk-microservices/internal/platform/errs"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrEmailTaken is returned by CreateUser when the email is already registered.
+var ErrEmailTaken = errs.Conflict("EMAIL_TAKEN", "email already registered")
+
+// emailConstraint is the UNIQUE constraint on users.email (migration 001).
+const emailConstraint = "users_email_key"
+
+// ErrVersionConflict is returned by UpdateUser when the user changed since it was
+// read. Callers should re-read the user and reapply their change.
+var ErrVersionConflict = errs.Conflict("VERSION_CONFLICT", "user was modified concurrently")
+
 type Store struct {
 	DB *pgxpool.Pool
+	// Cluster, if set, serves reads marked with db.WithReadOnly from replicas.
+	Cluster *db.Cluster
 }
 
 type User struct {
@@ -17,46 +37,151 @@ type User struct {
 	PasswordHash string    `db:"password_hash"`
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
+	// Version increases on every update; UpdateUser only applies when it still
+	// matches the stored row.
+	Version int64 `db:"version"`
 }
 
 func New(db *pgxpool.Pool) *Store {
 	return &Store{DB: db}
 }
 
+// NewWithCluster returns a Store writing to c.Primary and reading from replicas
+// where the caller allows it (db.WithReadOnly).
+func NewWithCluster(c *db.Cluster) *Store {
+	return &Store{DB: c.Primary, Cluster: c}
+}
+
+// reader returns the pool for SELECT-only queries.
+func (s *Store) reader(ctx context.Context) *pgxpool.Pool {
+	if s.Cluster != nil {
+		return s.Cluster.Pool(ctx)
+	}
+	return s.DB
+}
+
 func (s *Store) CreateUser(ctx context.Context, email, passwordHash string) (*User, error) {
-	var u User
-	err := s.DB.QueryRow(ctx, `
-		INSERT INTO users (email, password_hash)
-		VALUES ($1, $2)
-		RETURNING id::text, email, password_hash, created_at, updated_at
-	`, email, passwordHash).Scan(
-		&u.ID,
-		&u.Email,
-		&u.PasswordHash,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-	)
+	u, err := authdb.New(s.DB).CreateUser(ctx, authdb.CreateUserParams{
+		Email:        email,
+		PasswordHash: passwordHash,
+	})
 	if err != nil {
+		var uv *db.UniqueViolation
+		if errors.As(db.ClassifyError(err), &uv) && uv.Constraint == emailConstraint {
+			return nil, ErrEmailTaken.Wrap(err)
+		}
 		return nil, err
 	}
-	return &u, nil
+	return fromRow(u), nil
 }
 
+// UpdateUser writes u's email and password hash if the stored user is still at
+// u.Version, and returns the updated user with its new version. It fails with
+// ErrVersionConflict if another update got there first, ErrEmailTaken if the new
+// email belongs to someone else, and pgx.ErrNoRows if the user doesn't exist.
+func (s *Store) UpdateUser(ctx context.Context, u *User) (*User, error) {
+	q := authdb.New(s.DB)
+	row, err := q.UpdateUser(ctx, authdb.UpdateUserParams{
+		Email:        u.Email,
+		PasswordHash: u.PasswordHash,
+		ID:           u.ID,
+		Version:      u.Version,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Either the user is gone or its version moved on.
+		if _, gerr := q.GetUserByID(ctx, u.ID); gerr == nil {
+			return nil, ErrVersionConflict.Wrap(err)
+		}
+		return nil, err
+	}
+	if err != nil {
+		var uv *db.UniqueViolation
+		if errors.As(db.ClassifyError(err), &uv) && uv.Constraint == emailConstraint {
+			return nil, ErrEmailTaken.Wrap(err)
+		}
+		return nil, err
+	}
+	return fromRow(row), nil
+}
+
+// GetUserByEmail reads from a replica when ctx is marked db.WithReadOnly. A miss
+// there is retried on the primary, so a user can log in right after registering
+// even if the replica hasn't caught up.
 func (s *Store) GetUserByEmail(ctx context.Context, email string) (*User, error) {
-	var u User
-	err := s.DB.QueryRow(ctx, `
-		SELECT id::text, email, password_hash, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`, email).Scan(
-		&u.ID,
-		&u.Email,
-		&u.PasswordHash,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-	)
+	return s.getUser(ctx, func(q *authdb.Queries) (authdb.User, error) {
+		return q.GetUserByEmail(ctx, email)
+	})
+}
+
+// GetUserByID returns the user with id. Like GetUserByEmail it reads from a
+// replica when ctx is marked db.WithReadOnly, retrying a miss on the primary.
+func (s *Store) GetUserByID(ctx context.Context, id string) (*User, error) {
+	return s.getUser(ctx, func(q *authdb.Queries) (authdb.User, error) {
+		return q.GetUserByID(ctx, id)
+	})
+}
+
+func (s *Store) getUser(ctx context.Context, get func(*authdb.Queries) (authdb.User, error)) (*User, error) {
+	r := s.reader(ctx)
+	u, err := get(authdb.New(r))
+	if errors.Is(err, pgx.ErrNoRows) && r != s.DB {
+		u, err = get(authdb.New(s.DB))
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &u, nil
+	return fromRow(u), nil
+}
+
+func fromRow(u authdb.User) *User {
+	return &User{
+		ID:           u.ID,
+		Email:        u.Email,
+		PasswordHash: u.PasswordHash,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
+		Version:      u.Version,
+	}
+}
+
+// userOrder lists users oldest first, so new sign-ups append to the last page.
+var userOrder = []pagination.Order{{Column: "created_at"}, {Column: "id"}}
+
+type userCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// ListUsers returns one page of users in sign-up order and the token for the next
+// page ("" on the last page). Reads may go to a replica when ctx is marked
+// db.WithReadOnly.
+func (s *Store) ListUsers(ctx context.Context, size int, pageToken string) ([]User, string, error) {
+	size = pagination.Size(size)
+	q := `SELECT id::text, email, password_hash, created_at, updated_at, version FROM users`
+	var args []any
+	var cur userCursor
+	if ok, err := pagination.Decode(pageToken, &cur); err != nil {
+		return nil, "", err
+	} else if ok {
+		where, kargs := pagination.After(userOrder, []any{cur.CreatedAt, cur.ID}, 1)
+		q += " WHERE " + where
+		args = kargs
+	}
+	q += " ORDER BY " + pagination.OrderBy(userOrder) + " LIMIT " + strconv.Itoa(size+1)
+
+	rows, err := s.reader(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	out, err := pgx.CollectRows(rows, pgx.RowToStructByPos[User])
+	if err != nil {
+		return nil, "", err
+	}
+	if len(out) <= size {
+		return out, "", nil
+	}
+	out = out[:size]
+	last := out[size-1]
+	next, err := pagination.Encode(userCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	return out, next, err
 }
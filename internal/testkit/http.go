@@ -0,0 +1,54 @@
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// JSON encodes v as a request body.
+func JSON(t testing.TB, v any) io.Reader {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json marshal err=%v", err)
+	}
+	return bytes.NewReader(b)
+}
+
+// DoHTTP sends a request (JSON for POST) and returns the response; its body
+// is closed with the test.
+func DoHTTP(t testing.TB, ctx context.Context, method, url string, body io.Reader, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		t.Fatalf("NewRequest err=%v", err)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("http do err=%v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// ReadAll returns what is left of r, e.g. a response body for a failure message.
+func ReadAll(t testing.TB, r io.Reader) string {
+	t.Helper()
+	b, _ := io.ReadAll(r)
+	return string(b)
+}
+
+// Bearer returns the Authorization header for token.
+func Bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
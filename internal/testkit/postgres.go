@@ -0,0 +1,62 @@
+//go:build integration
+
+package testkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sdk-microservices/internal/db"
+	"sdk-microservices/internal/db/migrate"
+	"sdk-microservices/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// StartPostgres runs a throwaway Postgres container (needs Docker) with
+// service's migrations applied and returns a pool on it. The container and
+// pool go away with the test.
+func StartPostgres(t testing.TB, ctx context.Context, service string) *pgxpool.Pool {
+	t.Helper()
+	pg, err := postgres.Run(ctx,
+		"postgres:16",
+		postgres.WithDatabase(service),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+	)
+	if err != nil {
+		t.Fatalf("start postgres err=%v", err)
+	}
+	t.Cleanup(func() { _ = pg.Terminate(context.Background()) })
+
+	dsn, err := pg.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("conn string err=%v", err)
+	}
+	pool, err := db.NewPool(ctx, dsn, db.Options{InitialPingTimeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("NewPool err=%v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	Migrate(t, ctx, pool, service)
+	return pool
+}
+
+// Migrate applies service's embedded migrations to pool.
+func Migrate(t testing.TB, ctx context.Context, pool *pgxpool.Pool, service string) {
+	t.Helper()
+	src, err := migrations.For(service)
+	if err != nil {
+		t.Fatalf("embedded migrations err=%v", err)
+	}
+	m, err := migrate.New(pool, src)
+	if err != nil {
+		t.Fatalf("load migrations err=%v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("apply migrations err=%v", err)
+	}
+}
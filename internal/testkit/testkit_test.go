@@ -0,0 +1,46 @@
+package testkit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	hellov1 "sdk-microservices/gen/api/proto/hello/v1"
+)
+
+func TestHarnessHelloThroughGateway(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	h := New(t).WithHelloAuth().WithGateway().Start(ctx)
+
+	resp := h.Do(ctx, "GET", "/v1/hello/kit", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("without token: status=%d, want 401", resp.StatusCode)
+	}
+
+	token := h.MintToken("user-1", "kit@example.com", time.Minute)
+	resp = h.Do(ctx, "GET", "/v1/hello/kit", nil, Bearer(token))
+	if body := ReadAll(t, resp.Body); resp.StatusCode != http.StatusOK || !strings.Contains(body, "kit") {
+		t.Fatalf("with token: status=%d body=%s", resp.StatusCode, body)
+	}
+}
+
+func TestHarnessGRPC(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	h := New(t).WithHello().Start(ctx)
+	if h.GatewayURL != "" || h.AuthAddr != "" {
+		t.Fatalf("unrequested services started: %+v", h)
+	}
+	res, err := hellov1.NewHelloServiceClient(h.Conn(h.HelloAddr)).Hello(ctx, &hellov1.HelloRequest{Name: "kit"})
+	if err != nil {
+		t.Fatalf("Hello: %v", err)
+	}
+	if !strings.Contains(res.GetMessage(), "kit") {
+		t.Fatalf("message = %q", res.GetMessage())
+	}
+}
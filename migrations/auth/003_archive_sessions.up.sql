@@ -0,0 +1,33 @@
+-- Session archival
+--
+-- Revoked/expired sessions are moved out of the hot sessions table into
+-- sessions_archive (see store.ArchiveSessions), keeping the table and its
+-- token-hash index small for the lookup done on every refresh.
+
+CREATE TABLE IF NOT EXISTS sessions_archive (
+  id                 UUID PRIMARY KEY,
+  user_id            UUID NOT NULL,
+  refresh_token_hash BYTEA NOT NULL,
+  created_at         TIMESTAMPTZ NOT NULL,
+  expires_at         TIMESTAMPTZ NOT NULL,
+  revoked_at         TIMESTAMPTZ NULL,
+  user_agent         TEXT NULL,
+  ip                 INET NULL,
+  rotated_from       UUID NULL,
+  archived_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_archive_user_id ON sessions_archive(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_archive_archived_at ON sessions_archive(archived_at);
+
+-- Archiving a session must not be blocked by the session it was rotated into;
+-- the lineage is kept in sessions_archive.rotated_from.
+--
+-- NOT VALID skips scanning the hot sessions table under the ALTER's lock; the
+-- existing rows are checked by 007_validate_sessions_rotated_from_fkey, which
+-- only takes a lock that lets reads and writes continue.
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_rotated_from_fkey;
+ALTER TABLE sessions
+  ADD CONSTRAINT sessions_rotated_from_fkey
+  FOREIGN KEY (rotated_from) REFERENCES sessions(id) ON DELETE SET NULL
+  NOT VALID;
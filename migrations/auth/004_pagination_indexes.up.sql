@@ -0,0 +1,9 @@
+-- Keyset pagination indexes (see internal/db/pagination): each matches a
+-- listing's ORDER BY so a page is an index range scan, never an OFFSET.
+--
+-- Built CONCURRENTLY so sessions stays writable; that can't run inside a
+-- transaction, and a multi-statement file runs as one, hence one index per
+-- migration (users: 008_users_created_id_index). A failed build leaves an
+-- INVALID index that IF NOT EXISTS would skip: drop it before retrying.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_sessions_user_created_id ON sessions(user_id, created_at DESC, id DESC);
@@ -0,0 +1,6 @@
+-- Optimistic concurrency for users
+--
+-- Every update bumps version and is conditioned on the version the caller read
+-- (see store.UpdateUser), so concurrent edits fail instead of overwriting.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
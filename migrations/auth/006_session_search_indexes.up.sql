@@ -0,0 +1,10 @@
+-- Session search (store.SearchSessions)
+--
+-- Investigations filter by IP/CIDR or creation window without a user id; these
+-- keep such searches off full scans. User agent substring matches (ILIKE) are
+-- expected alongside another filter and get no index.
+--
+-- Built CONCURRENTLY, one index per migration (see 004_pagination_indexes); the
+-- IP index is 009_sessions_ip_index.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_sessions_created_id ON sessions(created_at DESC, id DESC);
@@ -0,0 +1,5 @@
+-- Check the existing rows against the foreign key 003_archive_sessions added
+-- NOT VALID. VALIDATE CONSTRAINT takes SHARE UPDATE EXCLUSIVE on sessions, so
+-- logins and refreshes keep going while it scans.
+
+ALTER TABLE sessions VALIDATE CONSTRAINT sessions_rotated_from_fkey;
@@ -0,0 +1,4 @@
+-- Keyset pagination index for ListUsers (see 004_pagination_indexes), built
+-- CONCURRENTLY so sign-ups and updates aren't blocked.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_created_id ON users(created_at, id);
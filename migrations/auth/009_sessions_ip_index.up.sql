@@ -0,0 +1,4 @@
+-- IP/CIDR filter for session search (see 006_session_search_indexes). GiST
+-- builds are slow on a large sessions table, so this one must not block logins.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_sessions_ip ON sessions USING gist (ip inet_ops);
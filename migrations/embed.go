@@ -0,0 +1,19 @@
+// Package migrations embeds each service's SQL migrations (migrations/<service>/)
+// into binaries, so they can be applied without the source tree.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed */*.sql
+var files embed.FS
+
+// For returns the migrations of service (e.g. "auth").
+func For(service string) (fs.FS, error) {
+	if _, err := fs.Stat(files, service); err != nil {
+		return nil, err
+	}
+	return fs.Sub(files, service)
+}